@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"trpc.group/trpc-go/trpc-a2a-go/server"
@@ -58,6 +59,34 @@ type AgentSpec struct {
 	// and made available to the agent under the `/skills` folder.
 	// +optional
 	Skills *SkillForAgent `json:"skills,omitempty"`
+
+	// RateLimits limits the A2A requests the controller proxies to the agent.
+	// Limits set here override the controller's defaults.
+	// +optional
+	RateLimits *A2ARateLimits `json:"rateLimits,omitempty"`
+}
+
+// A2ARateLimits limits the A2A requests to an agent, in total and per user.
+// A limit of 0 disables it.
+type A2ARateLimits struct {
+	// RequestsPerMinute limits the requests to the agent from all users.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RequestsPerMinute *int32 `json:"requestsPerMinute,omitempty"`
+	// RequestsPerMinutePerUser limits the requests to the agent from each user.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RequestsPerMinutePerUser *int32 `json:"requestsPerMinutePerUser,omitempty"`
+	// MaxConcurrentStreams limits the streaming tasks (message/stream and
+	// tasks/resubscribe) open at once with the agent for all users.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrentStreams *int32 `json:"maxConcurrentStreams,omitempty"`
+	// MaxConcurrentStreamsPerUser limits the streaming tasks open at once with
+	// the agent for each user.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrentStreamsPerUser *int32 `json:"maxConcurrentStreamsPerUser,omitempty"`
 }
 
 type SkillForAgent struct {
@@ -82,7 +111,10 @@ type DeclarativeAgentSpec struct {
 	SystemMessageFrom *ValueSource `json:"systemMessageFrom,omitempty"`
 	// The name of the model config to use.
 	// If not specified, the default value is "default-model-config".
-	// Must be in the same namespace as the Agent.
+	// Either the name of a model config in the same namespace as the Agent, or
+	// <namespace>/<name> for a model config in another namespace, which requires a
+	// ReferenceGrant in that namespace. Secrets used by the model config must also
+	// exist in the namespace of the Agent.
 	// +optional
 	ModelConfig string `json:"modelConfig,omitempty"`
 	// Whether to stream the response from the model.
@@ -91,6 +123,11 @@ type DeclarativeAgentSpec struct {
 	Stream *bool `json:"stream,omitempty"`
 	// +kubebuilder:validation:MaxItems=20
 	Tools []*Tool `json:"tools,omitempty"`
+	// Memory is a list of Memory resources the agent can query for additional context.
+	// Can either be a reference to the name of a Memory in the same namespace as the referencing Agent,
+	// or a reference to the name of a Memory in a different namespace in the form <namespace>/<name>, which requires a ReferenceGrant in that namespace.
+	// +optional
+	Memory []string `json:"memory,omitempty"`
 	// A2AConfig instantiates an A2A server for this agent,
 	// served on the HTTP port of the kagent kubernetes
 	// controller (default 8083).
@@ -206,9 +243,11 @@ func (s *Tool) ResolveHeaders(ctx context.Context, client client.Client, namespa
 	return result, nil
 }
 
+// +kubebuilder:validation:XValidation:message="requireApproval must only contain tools listed in toolNames",rule="!has(self.requireApproval) || self.requireApproval.all(t, has(self.toolNames) && t in self.toolNames)"
 type McpServerTool struct {
 	// The reference to the ToolServer that provides the tool.
-	// Can either be a reference to the name of a ToolServer in the same namespace as the referencing Agent, or a reference to the name of an ToolServer in a different namespace in the form <namespace>/<name>
+	// Defaults to the namespace of the referencing Agent. A ToolServer in a different namespace
+	// can be referenced by setting namespace, if a ReferenceGrant in that namespace allows it.
 	// +optional
 	TypedLocalReference `json:",inline"`
 
@@ -216,6 +255,13 @@ type McpServerTool struct {
 	// For a list of all the tools provided by the server,
 	// the client can query the status of the ToolServer object after it has been created
 	ToolNames []string `json:"toolNames,omitempty"`
+
+	// The names of the tools that need a human to approve each call before it
+	// is executed. The agent pauses the task in the input-required state until
+	// the call is approved or rejected, e.g. via /api/tasks/{task_id}/approve.
+	// Must be a subset of ToolNames.
+	// +optional
+	RequireApproval []string `json:"requireApproval,omitempty"`
 }
 
 type TypedLocalReference struct {
@@ -224,6 +270,10 @@ type TypedLocalReference struct {
 	// +optional
 	ApiGroup string `json:"apiGroup"`
 	Name     string `json:"name"`
+	// The namespace of the referenced resource. Defaults to the namespace of the referencing Agent.
+	// Referencing a resource in another namespace requires a ReferenceGrant in that namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 func (t *TypedLocalReference) GroupKind() schema.GroupKind {
@@ -233,6 +283,16 @@ func (t *TypedLocalReference) GroupKind() schema.GroupKind {
 	}
 }
 
+// NamespacedName returns the referenced resource, defaulting to defaultNamespace
+// if the reference has no namespace.
+func (t *TypedLocalReference) NamespacedName(defaultNamespace string) types.NamespacedName {
+	namespace := t.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: t.Name}
+}
+
 type A2AConfig struct {
 	// +kubebuilder:validation:MinItems=1
 	Skills []AgentSkill `json:"skills,omitempty"`
@@ -29,17 +29,18 @@ const (
 )
 
 // DataSourceProvider represents the data source provider type.
-// Currently only Databricks is supported, but designed to be extensible
-// for future data platforms like Snowflake, BigQuery, etc.
-// +kubebuilder:validation:Enum=Databricks
+// Designed to be extensible for future data platforms like Snowflake, BigQuery, etc.
+// +kubebuilder:validation:Enum=Databricks;PostgreSQL
 type DataSourceProvider string
 
 const (
 	DataSourceProviderDatabricks DataSourceProvider = "Databricks"
+	DataSourceProviderPostgreSQL DataSourceProvider = "PostgreSQL"
 )
 
 // DatabricksConfig contains Databricks-specific connection settings.
 // This follows the same pattern as ModelConfig's provider-specific configs.
+// +kubebuilder:validation:XValidation:rule="(has(self.readOnly) && !self.readOnly) || !has(self.allowedStatementTypes) || self.allowedStatementTypes.all(t, t.upperAscii() in ['SELECT', 'WITH', 'DESCRIBE', 'SHOW', 'EXPLAIN'])",message="allowedStatementTypes may only contain read statement types when readOnly is true"
 type DatabricksConfig struct {
 	// WorkspaceURL is the Databricks workspace URL.
 	// Example: https://mycompany.cloud.databricks.com
@@ -68,6 +69,64 @@ type DatabricksConfig struct {
 	// If not set, serverless SQL will be used (requires serverless SQL to be enabled).
 	// +optional
 	WarehouseID string `json:"warehouseId,omitempty"`
+
+	// ReadOnly restricts the execute_sql tool to read statements.
+	// Multi-statement payloads are always rejected, and referenced tables must be
+	// in SemanticModels when it is set.
+	// +kubebuilder:default=true
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty"`
+
+	// AllowedStatementTypes lists the SQL statement types (leading keywords) the execute_sql tool permits.
+	// Defaults to SELECT, WITH, DESCRIBE and SHOW. When ReadOnly is true only
+	// SELECT, WITH, DESCRIBE, SHOW and EXPLAIN may be listed.
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MaxLength=32
+	// +optional
+	AllowedStatementTypes []string `json:"allowedStatementTypes,omitempty"`
+}
+
+// PostgreSQLConfig contains PostgreSQL-specific connection settings.
+type PostgreSQLConfig struct {
+	// Host is the hostname of the PostgreSQL server.
+	// Example: analytics-db.data.svc.cluster.local
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Port is the port of the PostgreSQL server.
+	// +kubebuilder:default=5432
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Database is the name of the database to connect to.
+	// +kubebuilder:validation:MinLength=1
+	Database string `json:"database"`
+
+	// User is the database role used to connect.
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
+
+	// CredentialsSecretRef is the name of the Secret containing the password for User.
+	// The secret must exist in the same namespace as the DataSource.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretRef string `json:"credentialsSecretRef"`
+
+	// CredentialsSecretKey is the key within the secret that contains the password.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretKey string `json:"credentialsSecretKey"`
+
+	// Schema limits discovery to a specific schema within the database.
+	// If not set, the "public" schema is used.
+	// +optional
+	Schema string `json:"schema,omitempty"`
+
+	// SSLMode is the libpq sslmode used when connecting to the server.
+	// +kubebuilder:validation:Enum=disable;allow;prefer;require;verify-ca;verify-full
+	// +kubebuilder:default=prefer
+	// +optional
+	SSLMode string `json:"sslMode,omitempty"`
 }
 
 // SemanticModelRef references a semantic model to expose via the MCP server.
@@ -102,14 +161,15 @@ type DiscoveredModel struct {
 }
 
 // DataSourceSpec defines the desired state of DataSource.
-// A DataSource represents a connection to a data fabric (e.g., Databricks)
+// A DataSource represents a connection to a data fabric (e.g., Databricks, PostgreSQL)
 // and the semantic models to expose to agents via an auto-generated ToolServer.
 //
-// +kubebuilder:validation:XValidation:rule="self.provider == 'Databricks' && has(self.databricks)",message="databricks config is required when provider is Databricks"
+// +kubebuilder:validation:XValidation:rule="self.provider != 'Databricks' || has(self.databricks)",message="databricks config is required when provider is Databricks"
 // +kubebuilder:validation:XValidation:rule="!(has(self.databricks) && self.provider != 'Databricks')",message="databricks config must be nil if the provider is not Databricks"
+// +kubebuilder:validation:XValidation:rule="self.provider != 'PostgreSQL' || has(self.postgresql)",message="postgresql config is required when provider is PostgreSQL"
+// +kubebuilder:validation:XValidation:rule="!(has(self.postgresql) && self.provider != 'PostgreSQL')",message="postgresql config must be nil if the provider is not PostgreSQL"
 type DataSourceSpec struct {
 	// Provider specifies the data platform type.
-	// Currently only Databricks is supported.
 	// +kubebuilder:default=Databricks
 	Provider DataSourceProvider `json:"provider"`
 
@@ -118,7 +178,13 @@ type DataSourceSpec struct {
 	// +optional
 	Databricks *DatabricksConfig `json:"databricks,omitempty"`
 
+	// PostgreSQL contains PostgreSQL-specific configuration.
+	// Required when provider is PostgreSQL.
+	// +optional
+	PostgreSQL *PostgreSQLConfig `json:"postgresql,omitempty"`
+
 	// SemanticModels is the list of semantic models to expose via the MCP server.
+	// For PostgreSQL these are table or view names within the configured schema.
 	// If empty, all discovered models from the catalog/schema will be exposed.
 	// Users can select specific models after seeing what's available in status.availableModels.
 	// +optional
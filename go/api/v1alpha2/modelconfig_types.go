@@ -22,10 +22,13 @@ import (
 
 const (
 	ModelConfigConditionTypeAccepted = "Accepted"
+	// ModelConfigConditionTypeReady reports whether the provider answered the
+	// last connectivity probe, with its error message or latency.
+	ModelConfigConditionTypeReady = "Ready"
 )
 
 // ModelProvider represents the model provider type
-// +kubebuilder:validation:Enum=Anthropic;OpenAI;AzureOpenAI;Ollama;Gemini;GeminiVertexAI;AnthropicVertexAI
+// +kubebuilder:validation:Enum=Anthropic;OpenAI;AzureOpenAI;Ollama;Gemini;GeminiVertexAI;AnthropicVertexAI;OpenAICompatible;Bedrock
 type ModelProvider string
 
 const (
@@ -36,6 +39,8 @@ const (
 	ModelProviderGemini            ModelProvider = "Gemini"
 	ModelProviderGeminiVertexAI    ModelProvider = "GeminiVertexAI"
 	ModelProviderAnthropicVertexAI ModelProvider = "AnthropicVertexAI"
+	ModelProviderOpenAICompatible  ModelProvider = "OpenAICompatible"
+	ModelProviderBedrock           ModelProvider = "Bedrock"
 )
 
 type BaseVertexAIConfig struct {
@@ -210,6 +215,70 @@ type OllamaConfig struct {
 
 type GeminiConfig struct{}
 
+// OpenAICompatibleConfig contains configuration options for servers that
+// implement the OpenAI API, such as vLLM or LiteLLM gateways
+type OpenAICompatibleConfig struct {
+	// Base URL of the API, e.g. http://vllm.models:8000/v1
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	BaseURL string `json:"baseUrl"`
+
+	// Name of the header the API key is sent in. The key is sent as a bearer
+	// token in the Authorization header by default, and as is in other headers.
+	// +optional
+	AuthHeader string `json:"authHeader,omitempty"`
+
+	// Path of the endpoint that lists the models, relative to the base URL.
+	// Defaults to /models.
+	// +optional
+	ModelListPath string `json:"modelListPath,omitempty"`
+
+	// Temperature for sampling
+	// +optional
+	Temperature string `json:"temperature,omitempty"`
+
+	// Maximum tokens to generate
+	// +optional
+	MaxTokens int `json:"maxTokens,omitempty"`
+
+	// Top-p sampling parameter
+	// +optional
+	TopP string `json:"topP,omitempty"`
+}
+
+// BedrockConfig contains AWS Bedrock-specific configuration options
+type BedrockConfig struct {
+	// AWS region of the Bedrock endpoint, e.g. us-east-1
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+
+	// ID or ARN of an inference profile to invoke instead of the model, e.g.
+	// for cross-region inference
+	// +optional
+	InferenceProfile string `json:"inferenceProfile,omitempty"`
+
+	// Name of a secret in the same namespace holding static credentials in the
+	// keys AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optionally
+	// AWS_SESSION_TOKEN. If unset, the agent uses the default AWS credential
+	// chain, e.g. IRSA through an eks.amazonaws.com/role-arn annotation on the
+	// Agent, which is copied to its service account.
+	// +optional
+	CredentialsSecretRef string `json:"credentialsSecretRef,omitempty"`
+
+	// Temperature for sampling
+	// +optional
+	Temperature string `json:"temperature,omitempty"`
+
+	// Maximum tokens to generate
+	// +optional
+	MaxTokens int `json:"maxTokens,omitempty"`
+
+	// Top-p sampling parameter
+	// +optional
+	TopP string `json:"topP,omitempty"`
+}
+
 // TLSConfig contains TLS/SSL configuration options for model provider connections.
 // This enables agents to connect to internal LiteLLM gateways or other providers
 // that use self-signed certificates or custom certificate authorities.
@@ -246,6 +315,50 @@ type TLSConfig struct {
 	DisableSystemCAs bool `json:"disableSystemCAs,omitempty"`
 }
 
+// ModelFallbackTrigger is a kind of model call failure that moves on to the next model of a fallback chain.
+// +kubebuilder:validation:Enum=RateLimited;ServerError;Timeout;ConnectionError
+type ModelFallbackTrigger string
+
+const (
+	// ModelFallbackTriggerRateLimited is an HTTP 429 response.
+	ModelFallbackTriggerRateLimited ModelFallbackTrigger = "RateLimited"
+	// ModelFallbackTriggerServerError is an HTTP 5xx response.
+	ModelFallbackTriggerServerError ModelFallbackTrigger = "ServerError"
+	// ModelFallbackTriggerTimeout is a call that timed out.
+	ModelFallbackTriggerTimeout ModelFallbackTrigger = "Timeout"
+	// ModelFallbackTriggerConnectionError is a call that could not reach the provider.
+	ModelFallbackTriggerConnectionError ModelFallbackTrigger = "ConnectionError"
+)
+
+// DefaultModelFallbackTriggers are used by fallbacks that don't list triggers.
+var DefaultModelFallbackTriggers = []ModelFallbackTrigger{
+	ModelFallbackTriggerRateLimited,
+	ModelFallbackTriggerServerError,
+	ModelFallbackTriggerTimeout,
+}
+
+// ModelFallback is a ModelConfig that is called when the models before it in the chain fail.
+type ModelFallback struct {
+	// The name of a ModelConfig in the same namespace. Fallbacks of the
+	// referenced ModelConfig are not followed.
+	// +kubebuilder:validation:MinLength=1
+	ModelConfig string `json:"modelConfig"`
+
+	// The failures of the previous model that move on to this one.
+	// Defaults to RateLimited, ServerError and Timeout.
+	// +optional
+	// +kubebuilder:validation:MaxItems=4
+	Triggers []ModelFallbackTrigger `json:"triggers,omitempty"`
+}
+
+// GetTriggers returns the triggers of the fallback, or the defaults if none are set.
+func (f ModelFallback) GetTriggers() []ModelFallbackTrigger {
+	if len(f.Triggers) == 0 {
+		return DefaultModelFallbackTriggers
+	}
+	return f.Triggers
+}
+
 // ModelConfigSpec defines the desired state of ModelConfig.
 //
 // +kubebuilder:validation:XValidation:message="provider.openAI must be nil if the provider is not OpenAI",rule="!(has(self.openAI) && self.provider != 'OpenAI')"
@@ -255,6 +368,10 @@ type TLSConfig struct {
 // +kubebuilder:validation:XValidation:message="provider.gemini must be nil if the provider is not Gemini",rule="!(has(self.gemini) && self.provider != 'Gemini')"
 // +kubebuilder:validation:XValidation:message="provider.geminiVertexAI must be nil if the provider is not GeminiVertexAI",rule="!(has(self.geminiVertexAI) && self.provider != 'GeminiVertexAI')"
 // +kubebuilder:validation:XValidation:message="provider.anthropicVertexAI must be nil if the provider is not AnthropicVertexAI",rule="!(has(self.anthropicVertexAI) && self.provider != 'AnthropicVertexAI')"
+// +kubebuilder:validation:XValidation:message="provider.openAICompatible must be nil if the provider is not OpenAICompatible",rule="!(has(self.openAICompatible) && self.provider != 'OpenAICompatible')"
+// +kubebuilder:validation:XValidation:message="provider.openAICompatible must be set if the provider is OpenAICompatible",rule="!(!has(self.openAICompatible) && self.provider == 'OpenAICompatible')"
+// +kubebuilder:validation:XValidation:message="provider.bedrock must be nil if the provider is not Bedrock",rule="!(has(self.bedrock) && self.provider != 'Bedrock')"
+// +kubebuilder:validation:XValidation:message="provider.bedrock must be set if the provider is Bedrock",rule="!(!has(self.bedrock) && self.provider == 'Bedrock')"
 // +kubebuilder:validation:XValidation:message="apiKeySecret must be set if apiKeySecretKey is set",rule="!(has(self.apiKeySecretKey) && !has(self.apiKeySecret))"
 // +kubebuilder:validation:XValidation:message="apiKeySecretKey must be set if apiKeySecret is set",rule="!(has(self.apiKeySecret) && !has(self.apiKeySecretKey))"
 // +kubebuilder:validation:XValidation:message="caCertSecretKey requires caCertSecretRef",rule="!(has(self.tls) && has(self.tls.caCertSecretKey) && size(self.tls.caCertSecretKey) > 0 && (!has(self.tls.caCertSecretRef) || size(self.tls.caCertSecretRef) == 0))"
@@ -306,11 +423,25 @@ type ModelConfigSpec struct {
 	// +optional
 	AnthropicVertexAI *AnthropicVertexAIConfig `json:"anthropicVertexAI,omitempty"`
 
+	// OpenAI-compatible API configuration
+	// +optional
+	OpenAICompatible *OpenAICompatibleConfig `json:"openAICompatible,omitempty"`
+
+	// AWS Bedrock-specific configuration
+	// +optional
+	Bedrock *BedrockConfig `json:"bedrock,omitempty"`
+
 	// TLS configuration for provider connections.
 	// Enables agents to connect to internal LiteLLM gateways or other providers
 	// that use self-signed certificates or custom certificate authorities.
 	// +optional
 	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// Fallbacks are tried in order when a call to this model fails with one of
+	// their triggers, e.g. during a provider outage.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	Fallbacks []ModelFallback `json:"fallbacks,omitempty"`
 }
 
 // ModelConfigStatus defines the observed state of ModelConfig.
@@ -326,6 +457,7 @@ type ModelConfigStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Provider",type="string",JSONPath=".spec.provider"
 // +kubebuilder:printcolumn:name="Model",type="string",JSONPath=".spec.model"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Whether the provider answered the last connectivity probe."
 // +kubebuilder:storageversion
 
 // ModelConfig is the Schema for the modelconfigs API.
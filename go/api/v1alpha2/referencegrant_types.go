@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ReferenceGrantSpec identifies the resources in other namespaces that are
+// allowed to reference resources in the namespace of the ReferenceGrant.
+type ReferenceGrantSpec struct {
+	// From describes the referencing resources that are allowed.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	From []ReferenceGrantFrom `json:"from"`
+
+	// To describes the resources in this namespace that may be referenced.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	To []ReferenceGrantTo `json:"to"`
+}
+
+// ReferenceGrantFrom describes a referencing resource kind in a namespace.
+type ReferenceGrantFrom struct {
+	// Group is the API group of the referencing resource.
+	// +kubebuilder:default=kagent.dev
+	// +optional
+	Group string `json:"group"`
+	// Kind is the kind of the referencing resource.
+	// +kubebuilder:default=Agent
+	// +optional
+	Kind string `json:"kind"`
+	// Namespace is the namespace of the referencing resources.
+	Namespace string `json:"namespace"`
+}
+
+// ReferenceGrantTo describes resources that may be referenced.
+type ReferenceGrantTo struct {
+	// Group is the API group of the referenced resource, e.g. kagent.dev, or "" for core resources such as Services.
+	// +optional
+	Group string `json:"group"`
+	// Kind is the kind of the referenced resource, e.g. Agent, ModelConfig, RemoteMCPServer, MCPServer, Memory or Service.
+	Kind string `json:"kind"`
+	// Name restricts the grant to a single resource. If unset, all resources of the kind may be referenced.
+	// +optional
+	Name *string `json:"name,omitempty"`
+}
+
+// Permits reports whether the grant allows a resource of kind from in namespace
+// fromNamespace to reference the resource to named name.
+func (s *ReferenceGrantSpec) Permits(from schema.GroupKind, fromNamespace string, to schema.GroupKind, name string) bool {
+	fromAllowed := false
+	for _, f := range s.From {
+		if f.Group == from.Group && f.Kind == from.Kind && f.Namespace == fromNamespace {
+			fromAllowed = true
+			break
+		}
+	}
+	if !fromAllowed {
+		return false
+	}
+
+	for _, t := range s.To {
+		if t.Group == to.Group && t.Kind == to.Kind && (t.Name == nil || *t.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=refgrant,categories=kagent
+
+// ReferenceGrant allows Agents in other namespaces to reference tools, agents
+// and model configs in the namespace of the ReferenceGrant. Cross-namespace
+// references are rejected unless a ReferenceGrant in the target namespace
+// permits them.
+type ReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReferenceGrantList contains a list of ReferenceGrant.
+type ReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReferenceGrant{}, &ReferenceGrantList{})
+}
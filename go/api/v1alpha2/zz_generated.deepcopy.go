@@ -48,6 +48,41 @@ func (in *A2AConfig) DeepCopy() *A2AConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *A2ARateLimits) DeepCopyInto(out *A2ARateLimits) {
+	*out = *in
+	if in.RequestsPerMinute != nil {
+		in, out := &in.RequestsPerMinute, &out.RequestsPerMinute
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequestsPerMinutePerUser != nil {
+		in, out := &in.RequestsPerMinutePerUser, &out.RequestsPerMinutePerUser
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConcurrentStreams != nil {
+		in, out := &in.MaxConcurrentStreams, &out.MaxConcurrentStreams
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConcurrentStreamsPerUser != nil {
+		in, out := &in.MaxConcurrentStreamsPerUser, &out.MaxConcurrentStreamsPerUser
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new A2ARateLimits.
+func (in *A2ARateLimits) DeepCopy() *A2ARateLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(A2ARateLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Agent) DeepCopyInto(out *Agent) {
 	*out = *in
@@ -165,6 +200,11 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 		*out = new(SkillForAgent)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RateLimits != nil {
+		in, out := &in.RateLimits, &out.RateLimits
+		*out = new(A2ARateLimits)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentSpec.
@@ -290,6 +330,21 @@ func (in *BaseVertexAIConfig) DeepCopy() *BaseVertexAIConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BedrockConfig) DeepCopyInto(out *BedrockConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BedrockConfig.
+func (in *BedrockConfig) DeepCopy() *BedrockConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BedrockConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ByoDeploymentSpec) DeepCopyInto(out *ByoDeploymentSpec) {
 	*out = *in
@@ -381,6 +436,11 @@ func (in *DataSourceSpec) DeepCopyInto(out *DataSourceSpec) {
 	if in.Databricks != nil {
 		in, out := &in.Databricks, &out.Databricks
 		*out = new(DatabricksConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostgreSQL != nil {
+		in, out := &in.PostgreSQL, &out.PostgreSQL
+		*out = new(PostgreSQLConfig)
 		**out = **in
 	}
 	if in.SemanticModels != nil {
@@ -430,6 +490,16 @@ func (in *DataSourceStatus) DeepCopy() *DataSourceStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabricksConfig) DeepCopyInto(out *DatabricksConfig) {
 	*out = *in
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedStatementTypes != nil {
+		in, out := &in.AllowedStatementTypes, &out.AllowedStatementTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabricksConfig.
@@ -466,6 +536,11 @@ func (in *DeclarativeAgentSpec) DeepCopyInto(out *DeclarativeAgentSpec) {
 			}
 		}
 	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.A2AConfig != nil {
 		in, out := &in.A2AConfig, &out.A2AConfig
 		*out = new(A2AConfig)
@@ -579,6 +654,11 @@ func (in *McpServerTool) DeepCopyInto(out *McpServerTool) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RequireApproval != nil {
+		in, out := &in.RequireApproval, &out.RequireApproval
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new McpServerTool.
@@ -695,11 +775,28 @@ func (in *ModelConfigSpec) DeepCopyInto(out *ModelConfigSpec) {
 		*out = new(AnthropicVertexAIConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OpenAICompatible != nil {
+		in, out := &in.OpenAICompatible, &out.OpenAICompatible
+		*out = new(OpenAICompatibleConfig)
+		**out = **in
+	}
+	if in.Bedrock != nil {
+		in, out := &in.Bedrock, &out.Bedrock
+		*out = new(BedrockConfig)
+		**out = **in
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(TLSConfig)
 		**out = **in
 	}
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]ModelFallback, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelConfigSpec.
@@ -734,6 +831,26 @@ func (in *ModelConfigStatus) DeepCopy() *ModelConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelFallback) DeepCopyInto(out *ModelFallback) {
+	*out = *in
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]ModelFallbackTrigger, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelFallback.
+func (in *ModelFallback) DeepCopy() *ModelFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OllamaConfig) DeepCopyInto(out *OllamaConfig) {
 	*out = *in
@@ -756,6 +873,21 @@ func (in *OllamaConfig) DeepCopy() *OllamaConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenAICompatibleConfig) DeepCopyInto(out *OpenAICompatibleConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenAICompatibleConfig.
+func (in *OpenAICompatibleConfig) DeepCopy() *OpenAICompatibleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenAICompatibleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenAIConfig) DeepCopyInto(out *OpenAIConfig) {
 	*out = *in
@@ -791,6 +923,141 @@ func (in *OpenAIConfig) DeepCopy() *OpenAIConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgreSQLConfig) DeepCopyInto(out *PostgreSQLConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLConfig.
+func (in *PostgreSQLConfig) DeepCopy() *PostgreSQLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgreSQLConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrant) DeepCopyInto(out *ReferenceGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrant.
+func (in *ReferenceGrant) DeepCopy() *ReferenceGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReferenceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantFrom) DeepCopyInto(out *ReferenceGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantFrom.
+func (in *ReferenceGrantFrom) DeepCopy() *ReferenceGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantList) DeepCopyInto(out *ReferenceGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReferenceGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantList.
+func (in *ReferenceGrantList) DeepCopy() *ReferenceGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReferenceGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantSpec) DeepCopyInto(out *ReferenceGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]ReferenceGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]ReferenceGrantTo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantSpec.
+func (in *ReferenceGrantSpec) DeepCopy() *ReferenceGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantTo) DeepCopyInto(out *ReferenceGrantTo) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantTo.
+func (in *ReferenceGrantTo) DeepCopy() *ReferenceGrantTo {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteMCPServer) DeepCopyInto(out *RemoteMCPServer) {
 	*out = *in
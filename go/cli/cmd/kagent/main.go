@@ -83,7 +83,10 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			cli.InvokeCmd(cmd.Context(), invokeCfg)
 		},
-		Example: `kagent invoke --agent "k8s-agent" --task "Get all the pods in the kagent namespace"`,
+		Example: `kagent invoke --agent "k8s-agent" --task "Get all the pods in the kagent namespace"
+
+# Approve the tool calls a paused task is waiting on
+kagent invoke --approve <task-id>`,
 	}
 
 	invokeCmd.Flags().StringVarP(&invokeCfg.Task, "task", "t", "", "Task")
@@ -91,6 +94,9 @@ func main() {
 	invokeCmd.Flags().StringVarP(&invokeCfg.Agent, "agent", "a", "", "Agent")
 	invokeCmd.Flags().BoolVarP(&invokeCfg.Stream, "stream", "S", false, "Stream the response")
 	invokeCmd.Flags().StringVarP(&invokeCfg.File, "file", "f", "", "File to read the task from")
+	invokeCmd.Flags().StringVar(&invokeCfg.Approve, "approve", "", "Approve the tool calls the given paused task is waiting on and resume it")
+	invokeCmd.Flags().StringVar(&invokeCfg.Reject, "reject", "", "Reject the tool calls the given paused task is waiting on and resume it")
+	invokeCmd.Flags().StringVar(&invokeCfg.Reason, "reason", "", "Reason passed to the agent with --approve or --reject")
 	invokeCmd.Flags().StringVarP(&invokeCfg.URLOverride, "url-override", "u", "", "URL override")
 	invokeCmd.Flags().MarkHidden("url-override") //nolint:errcheck
 
@@ -148,6 +154,7 @@ func main() {
 		},
 	}
 
+	var sessionExportFormat string
 	getSessionCmd := &cobra.Command{
 		Use:   "session [session_id]",
 		Short: "Get a session or list all sessions",
@@ -165,9 +172,10 @@ func main() {
 			if len(args) > 0 {
 				resourceName = args[0]
 			}
-			cli.GetSessionCmd(cfg, resourceName)
+			cli.GetSessionCmd(cfg, resourceName, sessionExportFormat)
 		},
 	}
+	getSessionCmd.Flags().StringVar(&sessionExportFormat, "export", "", "Export the session with its tasks and events as json, jsonl or markdown")
 
 	getAgentCmd := &cobra.Command{
 		Use:   "agent [agent_name]",
@@ -44,8 +44,21 @@ func GetAgentCmd(cfg *config.Config, resourceName string) {
 	}
 }
 
-func GetSessionCmd(cfg *config.Config, resourceName string) {
+func GetSessionCmd(cfg *config.Config, resourceName string, exportFormat string) {
 	client := cfg.Client()
+	if exportFormat != "" {
+		if resourceName == "" {
+			fmt.Fprintf(os.Stderr, "A session ID is required to export a session\n")
+			return
+		}
+		export, err := client.Session.ExportSession(context.Background(), resourceName, exportFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export session %s: %v\n", resourceName, err)
+			return
+		}
+		os.Stdout.Write(export) //nolint:errcheck
+		return
+	}
 	if resourceName == "" {
 		sessionList, err := client.Session.ListSessions(context.Background())
 		if err != nil {
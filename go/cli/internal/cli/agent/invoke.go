@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/kagent-dev/kagent/go/cli/internal/config"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	a2aclient "trpc.group/trpc-go/trpc-a2a-go/client"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
@@ -21,6 +23,9 @@ type InvokeCfg struct {
 	Agent       string
 	Stream      bool
 	URLOverride string
+	Approve     string
+	Reject      string
+	Reason      string
 }
 
 func InvokeCmd(ctx context.Context, cfg *InvokeCfg) {
@@ -36,6 +41,11 @@ func InvokeCmd(ctx context.Context, cfg *InvokeCfg) {
 		defer pf.Stop()
 	}
 
+	if cfg.Approve != "" || cfg.Reject != "" {
+		resumeTask(ctx, cfg)
+		return
+	}
+
 	var task string
 	// If task is set, use it. Otherwise, read from file or stdin.
 	if cfg.Task != "" {
@@ -141,3 +151,34 @@ func InvokeCmd(ctx context.Context, cfg *InvokeCfg) {
 		fmt.Fprintf(os.Stdout, "%+v\n", string(jsn))
 	}
 }
+
+// resumeTask approves or rejects the tool calls a paused task is waiting on.
+func resumeTask(ctx context.Context, cfg *InvokeCfg) {
+	if cfg.Approve != "" && cfg.Reject != "" {
+		fmt.Fprintln(os.Stderr, "Only one of --approve and --reject can be set")
+		return
+	}
+
+	clientSet := cfg.Config.Client()
+	request := &api.TaskDecisionRequest{Reason: cfg.Reason}
+
+	var result *api.StandardResponse[*protocol.MessageResult]
+	var err error
+	if cfg.Approve != "" {
+		result, err = clientSet.Task.ApproveTask(ctx, cfg.Approve, request)
+	} else {
+		result, err = clientSet.Task.RejectTask(ctx, cfg.Reject, request)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resuming task: %v\n", err)
+		return
+	}
+
+	jsn, err := json.Marshal(result.Data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling result: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "%+v\n", string(jsn))
+}
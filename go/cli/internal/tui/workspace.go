@@ -71,7 +71,12 @@ func (i sessionListItem) Title() string {
 	}
 	return i.s.ID
 }
-func (i sessionListItem) Description() string { return i.s.ID }
+func (i sessionListItem) Description() string {
+	if i.s.ParentSessionID != nil {
+		return "fork of " + *i.s.ParentSessionID
+	}
+	return i.s.ID
+}
 func (i sessionListItem) FilterValue() string { return i.Title() }
 
 type workspaceModel struct {
@@ -278,6 +283,7 @@ func (m *workspaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.chat != nil {
 			m.chat.SetInputVisible(true)
 		}
+		m.renderDetails()
 		return m, m.startChat(true)
 	case sessionHistoryLoadedMsg:
 		if m.chat != nil && len(msg.items) > 0 {
@@ -312,6 +318,7 @@ func (m *workspaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.sessions.SetItems(items)
 		m.sessions.Select(0)
 		m.current = msg.session
+		m.renderDetails()
 		// Start fresh chat for new session without loading any history
 		return m, m.startChat(false)
 	case tea.KeyMsg:
@@ -512,6 +519,13 @@ func (m *workspaceModel) renderDetails() {
 	}
 	m.details.Reset()
 	fmt.Fprintf(&m.details, "Agent: %s\n", utils.ConvertToKubernetesIdentifier(m.agent.ID))
+	// Fork lineage of the current session
+	if m.current != nil && m.current.ParentSessionID != nil {
+		fmt.Fprintf(&m.details, "\nForked from: %s\n", *m.current.ParentSessionID)
+		if m.current.ParentEventID != nil {
+			fmt.Fprintf(&m.details, "At event: %s\n", *m.current.ParentEventID)
+		}
+	}
 	if m.agent.Agent.Spec.Description != "" {
 		fmt.Fprintf(&m.details, "\n%s\n", m.agent.Agent.Spec.Description)
 	}
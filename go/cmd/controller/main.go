@@ -17,7 +17,6 @@ limitations under the License.
 package main
 
 import (
-	"github.com/kagent-dev/kagent/go/internal/httpserver/auth"
 	"github.com/kagent-dev/kagent/go/pkg/app"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -27,12 +26,10 @@ import (
 
 //nolint:gocyclo
 func main() {
-	authorizer := &auth.NoopAuthorizer{}
-	authenticator := &auth.UnsecureAuthenticator{}
 	app.Start(func(bootstrap app.BootstrapConfig) (*app.ExtensionConfig, error) {
 		return &app.ExtensionConfig{
-			Authenticator:    authenticator,
-			Authorizer:       authorizer,
+			Authenticator:    nil, // selected with --auth-providers
+			Authorizer:       nil, // selected with --authorizer
 			AgentPlugins:     nil,
 			MCPServerPlugins: nil,
 		}, nil
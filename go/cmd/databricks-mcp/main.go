@@ -41,6 +41,8 @@ var (
 	models       = flag.String("models", "", "Comma-separated list of semantic models/tables to expose (if empty, discovers all)")
 	transport    = flag.String("transport", "stdio", "Transport mode: stdio or streamable-http")
 	httpPort     = flag.Int("port", 8080, "HTTP port when using streamable-http transport")
+	readOnly     = flag.Bool("read-only", true, "Only permit read statements in execute_sql")
+	allowedTypes = flag.String("allowed-statement-types", "", "Comma-separated list of SQL statement types permitted in execute_sql (default: SELECT,WITH,DESCRIBE,SHOW)")
 )
 
 func main() {
@@ -69,6 +71,16 @@ func main() {
 		}
 	}
 
+	// Create SQL guard
+	var statementTypes []string
+	if *allowedTypes != "" {
+		statementTypes = strings.Split(*allowedTypes, ",")
+	}
+	guard, err := NewSQLGuard(*readOnly, statementTypes, *catalog, *schema, modelNames)
+	if err != nil {
+		log.Fatalf("Invalid SQL guard configuration: %v", err)
+	}
+
 	// Create Databricks client
 	client := NewDatabricksClient(*workspaceURL, token, *catalog, *schema, *warehouseID)
 
@@ -87,7 +99,7 @@ func main() {
 	)
 
 	// Register tools
-	registerTools(s, client, modelNames)
+	registerTools(s, client, guard, modelNames)
 
 	// Start server based on transport mode
 	switch *transport {
@@ -423,7 +435,7 @@ func (c *DatabricksClient) pollStatementStatus(ctx context.Context, statementID
 }
 
 // registerTools registers all MCP tools with the server
-func registerTools(s *server.MCPServer, client *DatabricksClient, modelNames []string) {
+func registerTools(s *server.MCPServer, client *DatabricksClient, guard *SQLGuard, modelNames []string) {
 	// Tool 1: list_tables - List available tables in the catalog/schema
 	s.AddTool(
 		mcp.NewTool("list_tables",
@@ -522,7 +534,7 @@ func registerTools(s *server.MCPServer, client *DatabricksClient, modelNames []s
 	// Tool 3: execute_sql - Execute SQL against the Databricks warehouse
 	s.AddTool(
 		mcp.NewTool("execute_sql",
-			mcp.WithDescription("Execute a SQL query against the Databricks SQL warehouse. Returns results as a JSON array."),
+			mcp.WithDescription("Execute a single SQL query against the Databricks SQL warehouse. Only read statements (SELECT, WITH, DESCRIBE, SHOW) are permitted unless configured otherwise. Returns results as a JSON array."),
 			mcp.WithString("sql",
 				mcp.Required(),
 				mcp.Description("SQL query to execute"),
@@ -545,6 +557,10 @@ func registerTools(s *server.MCPServer, client *DatabricksClient, modelNames []s
 				maxRows = 100
 			}
 
+			if err := guard.Validate(sql); err != nil {
+				return nil, fmt.Errorf("SQL rejected: %w", err)
+			}
+
 			resp, err := client.ExecuteSQL(ctx, sql, maxRows)
 			if err != nil {
 				return nil, fmt.Errorf("failed to execute SQL: %w", err)
@@ -616,6 +632,9 @@ func registerTools(s *server.MCPServer, client *DatabricksClient, modelNames []s
 
 			fullTableName := client.getFullTableName(tableName)
 			sql := fmt.Sprintf("SELECT * FROM %s LIMIT %d", fullTableName, numRows)
+			if err := guard.Validate(sql); err != nil {
+				return nil, fmt.Errorf("SQL rejected: %w", err)
+			}
 
 			resp, err := client.ExecuteSQL(ctx, sql, numRows)
 			if err != nil {
@@ -675,6 +694,9 @@ func registerTools(s *server.MCPServer, client *DatabricksClient, modelNames []s
 
 			fullTableName := client.getFullTableName(tableName)
 			sql := fmt.Sprintf("SELECT COUNT(*) as row_count FROM %s", fullTableName)
+			if err := guard.Validate(sql); err != nil {
+				return nil, fmt.Errorf("SQL rejected: %w", err)
+			}
 
 			resp, err := client.ExecuteSQL(ctx, sql, 1)
 			if err != nil {
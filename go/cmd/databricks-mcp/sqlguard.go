@@ -0,0 +1,523 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// readStatementTypes are the statement types that never modify data
+var readStatementTypes = []string{"SELECT", "WITH", "DESCRIBE", "SHOW", "EXPLAIN"}
+
+// defaultStatementTypes are the statement types permitted when none are configured
+var defaultStatementTypes = []string{"SELECT", "WITH", "DESCRIBE", "SHOW"}
+
+// writeKeywords are rejected anywhere in a statement when the guard is read-only.
+// This catches writes nested in otherwise read-only statements, e.g. "WITH x AS (...) INSERT INTO ...".
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "DROP": true, "CREATE": true,
+	"ALTER": true, "TRUNCATE": true, "COPY": true, "GRANT": true, "REVOKE": true, "OPTIMIZE": true,
+	"VACUUM": true, "RESTORE": true, "REFRESH": true, "MSCK": true, "CALL": true,
+}
+
+// tableKeywords are followed by a table reference
+var tableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "TABLE": true, "USING": true,
+}
+
+// fromFunctions are the functions whose argument list may contain FROM as part of the
+// function syntax, e.g. EXTRACT(YEAR FROM ts), rather than as the start of a subquery
+var fromFunctions = map[string]bool{
+	"EXTRACT": true, "TRIM": true, "SUBSTRING": true, "POSITION": true, "OVERLAY": true,
+}
+
+// subqueryKeywords start a subquery rather than a table reference
+var subqueryKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true,
+}
+
+// operandPrefixes are keywords that may precede a table reference or subquery in a FROM or JOIN operand
+var operandPrefixes = map[string]bool{
+	"LATERAL": true, "ONLY": true,
+}
+
+// aliasTerminators are keywords that can follow a table reference and therefore are never an alias
+var aliasTerminators = map[string]bool{
+	"WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true,
+	"OUTER": true, "NATURAL": true, "ANTI": true, "SEMI": true, "ON": true, "USING": true, "GROUP": true,
+	"ORDER": true, "HAVING": true, "LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "MINUS": true, "WINDOW": true, "QUALIFY": true, "LATERAL": true, "PIVOT": true,
+	"UNPIVOT": true, "TABLESAMPLE": true, "CLUSTER": true, "DISTRIBUTE": true, "SORT": true, "SET": true,
+	"VALUES": true, "SELECT": true, "WHEN": true, "VERSION": true, "TIMESTAMP": true, "FOR": true,
+}
+
+// SQLGuard validates SQL statements before they are sent to the Databricks SQL warehouse
+type SQLGuard struct {
+	readOnly     bool
+	allowedTypes []string
+	catalog      string
+	schema       string
+	models       []string
+}
+
+// NewSQLGuard creates a new SQLGuard.
+// When readOnly is true only read statement types may be allowed; allowedTypes defaults to
+// SELECT, WITH, DESCRIBE and SHOW. When readOnly is false and allowedTypes is empty, any
+// statement type is permitted. Referenced tables are restricted to models when non-empty.
+func NewSQLGuard(readOnly bool, allowedTypes []string, catalog, schema string, models []string) (*SQLGuard, error) {
+	var types []string
+	for _, t := range allowedTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if readOnly && !slices.Contains(readStatementTypes, t) {
+			return nil, fmt.Errorf("statement type %s is not allowed in read-only mode", t)
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 && readOnly {
+		types = defaultStatementTypes
+	}
+
+	return &SQLGuard{
+		readOnly:     readOnly,
+		allowedTypes: types,
+		catalog:      catalog,
+		schema:       schema,
+		models:       models,
+	}, nil
+}
+
+// Validate returns an error if the SQL is not permitted by the guard
+func (g *SQLGuard) Validate(sql string) error {
+	tokens, err := tokenizeSQL(sql)
+	if err != nil {
+		return err
+	}
+
+	statements := splitStatements(tokens)
+	if len(statements) == 0 {
+		return fmt.Errorf("empty SQL statement")
+	}
+	if len(statements) > 1 {
+		return fmt.Errorf("multiple statements are not allowed")
+	}
+	stmt := statements[0]
+
+	stmtType := statementType(stmt)
+	if len(g.allowedTypes) > 0 && !slices.Contains(g.allowedTypes, stmtType) {
+		return fmt.Errorf("%s statements are not allowed, allowed statement types: %s", stmtType, strings.Join(g.allowedTypes, ", "))
+	}
+
+	if g.readOnly {
+		for _, tok := range stmt {
+			if tok.kind == tokenWord && writeKeywords[strings.ToUpper(tok.value)] {
+				return fmt.Errorf("%s is not allowed in read-only mode", strings.ToUpper(tok.value))
+			}
+		}
+	}
+
+	if len(g.models) == 0 {
+		return nil
+	}
+
+	refs, err := tableReferences(stmt, stmtType)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if !g.isAllowedTable(ref) {
+			return fmt.Errorf("table %q is not available. Use list_tables to see available tables", strings.Join(ref, "."))
+		}
+	}
+	return nil
+}
+
+// isAllowedTable checks a (possibly qualified) table reference against the models allow-list
+func (g *SQLGuard) isAllowedTable(ref []string) bool {
+	name := ref[len(ref)-1]
+	switch len(ref) {
+	case 1:
+	case 2:
+		if g.schema != "" && !strings.EqualFold(ref[0], g.schema) {
+			return false
+		}
+	case 3:
+		if !strings.EqualFold(ref[0], g.catalog) {
+			return false
+		}
+		if g.schema != "" && !strings.EqualFold(ref[1], g.schema) {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, m := range g.models {
+		if strings.EqualFold(m, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type sqlTokenKind int
+
+const (
+	tokenWord sqlTokenKind = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type sqlToken struct {
+	kind  sqlTokenKind
+	value string
+}
+
+// tokenizeSQL splits SQL into tokens, dropping whitespace and comments
+func tokenizeSQL(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/') {
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2
+		case r == '\'' || r == '"' || r == '`':
+			value, next, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			// Double quotes delimit identifiers in ANSI SQL, so they are treated as one
+			// wherever an identifier is expected
+			kind := tokenString
+			if r == '`' || r == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, sqlToken{kind: kind, value: value})
+			i = next
+		case (r == 'r' || r == 'R') && i+1 < len(runes) && (runes[i+1] == '\'' || runes[i+1] == '"'):
+			// Raw string literals don't escape their quotes with backslashes
+			value, next, err := readRaw(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, value: value})
+			i = next
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, value: string(runes[start:i])})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, value: string(runes[start:i])})
+		default:
+			tokens = append(tokens, sqlToken{kind: tokenPunct, value: string(r)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// readQuoted reads a quoted string or identifier starting at runes[start], returning its
+// unquoted value and the index after the closing quote
+func readQuoted(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' && quote != '`' && i+1 < len(runes) {
+			b.WriteRune(runes[i+1])
+			i++
+			continue
+		}
+		if r == quote {
+			// Doubled quotes escape the quote character
+			if i+1 < len(runes) && runes[i+1] == quote {
+				b.WriteRune(quote)
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		}
+		b.WriteRune(r)
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// readRaw reads a raw string literal whose quote is at runes[start], returning its
+// value and the index after the closing quote
+func readRaw(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == quote {
+			return string(runes[start+1 : i]), i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// splitStatements splits tokens on semicolons, dropping empty statements
+func splitStatements(tokens []sqlToken) [][]sqlToken {
+	var statements [][]sqlToken
+	var current []sqlToken
+	for _, tok := range tokens {
+		if tok.kind == tokenPunct && tok.value == ";" {
+			if len(current) > 0 {
+				statements = append(statements, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, tok)
+	}
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements
+}
+
+// statementType returns the leading keyword of a statement, skipping opening parentheses
+func statementType(stmt []sqlToken) string {
+	for _, tok := range stmt {
+		if tok.kind == tokenPunct && tok.value == "(" {
+			continue
+		}
+		if tok.kind == tokenWord {
+			stmtType := strings.ToUpper(tok.value)
+			if stmtType == "DESC" {
+				return "DESCRIBE"
+			}
+			return stmtType
+		}
+		break
+	}
+	return "UNKNOWN"
+}
+
+func isIdent(tok sqlToken) bool {
+	return tok.kind == tokenWord || tok.kind == tokenQuotedIdent
+}
+
+func isPunct(tok sqlToken, value string) bool {
+	return tok.kind == tokenPunct && tok.value == value
+}
+
+// readQualifiedName reads a dotted identifier starting at stmt[i]
+func readQualifiedName(stmt []sqlToken, i int) ([]string, int) {
+	var parts []string
+	for i < len(stmt) && isIdent(stmt[i]) {
+		parts = append(parts, stmt[i].value)
+		i++
+		if i+1 < len(stmt) && isPunct(stmt[i], ".") && isIdent(stmt[i+1]) {
+			i++
+			continue
+		}
+		break
+	}
+	return parts, i
+}
+
+// skipAlias skips an optional [AS] alias, with an optional column alias list, starting at stmt[i]
+func skipAlias(stmt []sqlToken, i int) int {
+	if i < len(stmt) && stmt[i].kind == tokenWord && strings.EqualFold(stmt[i].value, "AS") {
+		i++
+	}
+	if i < len(stmt) && isIdent(stmt[i]) && !aliasTerminators[strings.ToUpper(stmt[i].value)] {
+		i++
+		// e.g. AS r(a, b)
+		if i < len(stmt) && isPunct(stmt[i], "(") {
+			i = closingParen(stmt, i) + 1
+		}
+	}
+	return i
+}
+
+// closingParen returns the index of the parenthesis closing the one at stmt[open],
+// or len(stmt) if it is never closed
+func closingParen(stmt []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(stmt); i++ {
+		switch {
+		case isPunct(stmt[i], "("):
+			depth++
+		case isPunct(stmt[i], ")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(stmt)
+}
+
+// tableReferences extracts the tables referenced by a statement, excluding CTE names
+func tableReferences(stmt []sqlToken, stmtType string) ([][]string, error) {
+	cteNames := map[string]bool{}
+	for i := 0; i+2 < len(stmt); i++ {
+		if isIdent(stmt[i]) && stmt[i+1].kind == tokenWord && strings.EqualFold(stmt[i+1].value, "AS") && isPunct(stmt[i+2], "(") {
+			cteNames[strings.ToLower(stmt[i].value)] = true
+		}
+	}
+
+	var refs [][]string
+	addRef := func(ref []string) {
+		if len(ref) == 1 && cteNames[strings.ToLower(ref[0])] {
+			return
+		}
+		refs = append(refs, ref)
+	}
+
+	switch stmtType {
+	case "DESCRIBE":
+		// DESCRIBE [TABLE] [EXTENDED|DETAIL|FORMATTED|HISTORY] name
+		i := 1
+		for i < len(stmt) && stmt[i].kind == tokenWord && slices.Contains([]string{"TABLE", "EXTENDED", "DETAIL", "FORMATTED", "HISTORY"}, strings.ToUpper(stmt[i].value)) {
+			i++
+		}
+		if i < len(stmt) && strings.EqualFold(stmt[i].value, "QUERY") {
+			// DESCRIBE QUERY <select>: fall through to the generic extraction below
+			break
+		}
+		if ref, _ := readQualifiedName(stmt, i); len(ref) > 0 {
+			addRef(ref)
+		}
+		return refs, nil
+	case "SHOW":
+		// Only SHOW variants that inspect a specific table reference one
+		if len(stmt) < 2 || stmt[1].kind != tokenWord {
+			return refs, nil
+		}
+		i := -1
+		switch strings.ToUpper(stmt[1].value) {
+		case "COLUMNS":
+			for j := 2; j < len(stmt); j++ {
+				if stmt[j].kind == tokenWord && (strings.EqualFold(stmt[j].value, "IN") || strings.EqualFold(stmt[j].value, "FROM")) {
+					i = j + 1
+					break
+				}
+			}
+		case "TBLPROPERTIES", "PARTITIONS":
+			i = 2
+		}
+		if i > 0 {
+			if ref, _ := readQualifiedName(stmt, i); len(ref) > 0 {
+				addRef(ref)
+			}
+		}
+		return refs, nil
+	}
+
+	// funcScopes tracks whether each open parenthesis is the argument list of one of the
+	// fromFunctions, in which case FROM is part of the function syntax. Any other parenthesis
+	// may hold a subquery, so its FROM clauses are checked.
+	var funcScopes []bool
+	inFunc := func() bool {
+		return len(funcScopes) > 0 && funcScopes[len(funcScopes)-1]
+	}
+
+	for i := 0; i < len(stmt); i++ {
+		tok := stmt[i]
+		if isPunct(tok, "(") {
+			isFunc := i > 0 && stmt[i-1].kind == tokenWord && fromFunctions[strings.ToUpper(stmt[i-1].value)]
+			funcScopes = append(funcScopes, isFunc)
+			continue
+		}
+		if isPunct(tok, ")") {
+			if len(funcScopes) > 0 {
+				funcScopes = funcScopes[:len(funcScopes)-1]
+			}
+			continue
+		}
+		if tok.kind != tokenWord || inFunc() {
+			continue
+		}
+
+		keyword := strings.ToUpper(tok.value)
+		if !tableKeywords[keyword] {
+			continue
+		}
+
+		for j := i + 1; j < len(stmt); {
+			// A parenthesised operand is either a subquery, validated as the loop continues
+			// over its tokens, or a table reference, e.g. FROM (t) or FROM ((a JOIN b ON ...))
+			for j < len(stmt) && stmt[j].kind == tokenWord && operandPrefixes[strings.ToUpper(stmt[j].value)] {
+				j++
+			}
+			depth := 0
+			for j < len(stmt) && isPunct(stmt[j], "(") {
+				depth++
+				j++
+			}
+			var next int
+			if depth > 0 && j < len(stmt) && stmt[j].kind == tokenWord && subqueryKeywords[strings.ToUpper(stmt[j].value)] {
+				// Continue after the subquery so that the tables following it are checked too
+				next = closingParen(stmt, j-1) + 1
+				depth--
+			} else {
+				if j >= len(stmt) || !isIdent(stmt[j]) {
+					break
+				}
+				var ref []string
+				ref, next = readQualifiedName(stmt, j)
+				if next < len(stmt) && isPunct(stmt[next], "(") {
+					return nil, fmt.Errorf("table-valued function %s is not allowed", strings.Join(ref, "."))
+				}
+				addRef(ref)
+			}
+
+			// Only FROM supports comma-separated table lists
+			if keyword != "FROM" {
+				break
+			}
+			// Skip an optional alias, inside or outside the closing parentheses
+			next = skipAlias(stmt, next)
+			for ; depth > 0 && next < len(stmt) && isPunct(stmt[next], ")"); depth-- {
+				next = skipAlias(stmt, next+1)
+			}
+			if next < len(stmt) && isPunct(stmt[next], ",") {
+				j = next + 1
+				continue
+			}
+			break
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLGuard_Validate(t *testing.T) {
+	models := []string{"revenue_metrics", "customer_360"}
+
+	tests := []struct {
+		name         string
+		readOnly     bool
+		allowedTypes []string
+		models       []string
+		sql          string
+		wantErr      string
+	}{
+		{
+			name:     "select is allowed",
+			readOnly: true,
+			sql:      "SELECT region, SUM(amount) FROM revenue_metrics GROUP BY region",
+		},
+		{
+			name:     "trailing semicolon is allowed",
+			readOnly: true,
+			sql:      "SELECT 1;",
+		},
+		{
+			name:     "show and describe are allowed",
+			readOnly: true,
+			sql:      "DESCRIBE TABLE EXTENDED revenue_metrics",
+			models:   models,
+		},
+		{
+			name:     "drop is rejected",
+			readOnly: true,
+			sql:      "DROP TABLE revenue_metrics",
+			wantErr:  "DROP statements are not allowed",
+		},
+		{
+			name:     "update is rejected",
+			readOnly: true,
+			sql:      "update revenue_metrics set amount = 0",
+			wantErr:  "UPDATE statements are not allowed",
+		},
+		{
+			name:     "multiple statements are rejected",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics; DELETE FROM revenue_metrics",
+			wantErr:  "multiple statements are not allowed",
+		},
+		{
+			name:     "semicolon inside string is not a statement separator",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics WHERE note = 'a; DROP TABLE x'",
+			models:   models,
+		},
+		{
+			name:     "write nested in CTE is rejected",
+			readOnly: true,
+			sql:      "WITH x AS (SELECT 1) INSERT INTO revenue_metrics SELECT * FROM x",
+			wantErr:  "INSERT is not allowed in read-only mode",
+		},
+		{
+			name:     "comments are ignored",
+			readOnly: true,
+			sql:      "/* DROP TABLE x */ SELECT * FROM revenue_metrics -- DELETE",
+			models:   models,
+		},
+		{
+			name:     "table outside allow-list is rejected",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics r JOIN salaries s ON r.id = s.id",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "comma-separated tables are checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics AS r, salaries",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "tables in subqueries are checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics WHERE id IN (SELECT id FROM salaries)",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "qualified table in another schema is rejected",
+			readOnly: true,
+			sql:      "SELECT * FROM main.hr.revenue_metrics",
+			models:   models,
+			wantErr:  `table "main.hr.revenue_metrics" is not available`,
+		},
+		{
+			name:     "qualified table in configured schema is allowed",
+			readOnly: true,
+			sql:      "SELECT * FROM `main`.`sales`.`customer_360`",
+			models:   models,
+		},
+		{
+			name:     "CTE names are not treated as tables",
+			readOnly: true,
+			sql:      "WITH top AS (SELECT * FROM revenue_metrics ORDER BY amount DESC LIMIT 10) SELECT * FROM top",
+			models:   models,
+		},
+		{
+			name:     "FROM inside function call is not a table",
+			readOnly: true,
+			sql:      "SELECT EXTRACT(YEAR FROM created_at) FROM customer_360",
+			models:   models,
+		},
+		{
+			name:     "table-valued functions are rejected with an allow-list",
+			readOnly: true,
+			sql:      "SELECT * FROM read_files('s3://bucket/path')",
+			models:   models,
+			wantErr:  "table-valued function read_files is not allowed",
+		},
+		{
+			name:     "parenthesised table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM (salaries)",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "nested parenthesised join is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM ((revenue_metrics r JOIN salaries s ON r.id = s.id))",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "parenthesised joined table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics r JOIN (salaries) s ON r.id = s.id",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "parenthesised allowed table is allowed",
+			readOnly: true,
+			sql:      "SELECT * FROM (revenue_metrics) AS r",
+			models:   models,
+		},
+		{
+			name:     "backtick-quoted table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM `salaries`",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "double-quoted table is checked",
+			readOnly: true,
+			sql:      `SELECT * FROM "salaries"`,
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "double-quoted allowed table is allowed",
+			readOnly: true,
+			sql:      `SELECT * FROM "sales"."customer_360"`,
+			models:   models,
+		},
+		{
+			name:     "parenthesised CTE name is not treated as a table",
+			readOnly: true,
+			sql:      "WITH top AS (SELECT * FROM revenue_metrics) SELECT * FROM (top) t, customer_360",
+			models:   models,
+		},
+		{
+			name:     "CTE body is checked",
+			readOnly: true,
+			sql:      "WITH top AS (SELECT * FROM salaries) SELECT * FROM top",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "comma join after parenthesised table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM (revenue_metrics) r, (salaries) s",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "comma join with alias inside parentheses is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM (revenue_metrics r), salaries",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "derived table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM (SELECT * FROM (salaries)) x",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "derived table over allowed tables is allowed",
+			readOnly: true,
+			sql:      "SELECT * FROM (SELECT id FROM revenue_metrics) x, customer_360 c",
+			models:   models,
+		},
+		{
+			name:     "comma join after derived table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM (SELECT id FROM revenue_metrics) AS x, salaries",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "comma join after nested derived table is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM ((SELECT id FROM revenue_metrics) x, salaries)",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "lateral subquery is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics r, LATERAL (SELECT * FROM salaries) s",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "set operation with parenthesised subquery is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics MINUS (SELECT * FROM salaries)",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "subquery after an operator keyword is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics WHERE 'a' LIKE (SELECT max(x) FROM salaries)",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "comma join after column alias list is checked",
+			readOnly: true,
+			sql:      "SELECT * FROM revenue_metrics AS r(a, b), salaries",
+			models:   models,
+			wantErr:  `table "salaries" is not available`,
+		},
+		{
+			name:     "backslash in raw string doesn't escape its quote",
+			readOnly: true,
+			sql:      `SELECT r'\', * FROM hr.salaries -- '`,
+			models:   []string{"revenue"},
+			wantErr:  `table "hr.salaries" is not available`,
+		},
+		{
+			name:     "double-quoted raw string is a string",
+			readOnly: true,
+			sql:      `SELECT R"C:\", * FROM revenue_metrics`,
+			models:   models,
+		},
+		{
+			name:     "FROM inside trim is not a table",
+			readOnly: true,
+			sql:      "SELECT TRIM(BOTH 'x' FROM name) FROM customer_360 AS c(id, name)",
+			models:   models,
+		},
+		{
+			name:         "configured statement types restrict further",
+			readOnly:     true,
+			allowedTypes: []string{"select"},
+			sql:          "SHOW TABLES",
+			wantErr:      "SHOW statements are not allowed",
+		},
+		{
+			name:     "writes are allowed when not read-only",
+			readOnly: false,
+			sql:      "INSERT INTO revenue_metrics VALUES (1, 2)",
+			models:   models,
+		},
+		{
+			name:     "multiple statements are rejected when not read-only",
+			readOnly: false,
+			sql:      "INSERT INTO revenue_metrics VALUES (1); DROP TABLE revenue_metrics",
+			wantErr:  "multiple statements are not allowed",
+		},
+		{
+			name:     "unterminated string is rejected",
+			readOnly: true,
+			sql:      "SELECT 'oops",
+			wantErr:  "unterminated quoted string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, err := NewSQLGuard(tt.readOnly, tt.allowedTypes, "main", "sales", tt.models)
+			require.NoError(t, err)
+
+			err = guard.Validate(tt.sql)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSQLGuard_RejectsWriteTypesInReadOnlyMode(t *testing.T) {
+	_, err := NewSQLGuard(true, []string{"SELECT", "DELETE"}, "main", "", nil)
+	assert.ErrorContains(t, err, "DELETE is not allowed in read-only mode")
+
+	_, err = NewSQLGuard(false, []string{"SELECT", "DELETE"}, "main", "", nil)
+	assert.NoError(t, err)
+}
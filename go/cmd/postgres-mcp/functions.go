@@ -0,0 +1,382 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// defaultFunctions are the built-in functions statements may call. Functions
+// can read any relation the connecting user can (e.g. query_to_xml, dblink or
+// user-defined functions), which the relations of the plan don't show, so
+// statements may only call functions of this list or configured with
+// --functions.
+var defaultFunctions = []string{
+	// aggregates
+	"count", "sum", "avg", "min", "max", "bool_and", "bool_or", "every", "array_agg", "string_agg",
+	"stddev", "stddev_pop", "stddev_samp", "variance", "var_pop", "var_samp", "corr", "covar_pop", "covar_samp",
+	"percentile_cont", "percentile_disc", "mode", "grouping", "rollup", "cube",
+	// window functions
+	"row_number", "rank", "dense_rank", "percent_rank", "cume_dist", "ntile", "lag", "lead",
+	"first_value", "last_value", "nth_value",
+	// conditionals
+	"coalesce", "nullif", "greatest", "least",
+	// math
+	"abs", "ceil", "ceiling", "floor", "round", "trunc", "mod", "power", "sqrt", "exp", "ln", "log", "sign",
+	"div", "width_bucket",
+	// strings
+	"length", "char_length", "lower", "upper", "initcap", "substring", "substr", "position", "strpos",
+	"trim", "btrim", "ltrim", "rtrim", "lpad", "rpad", "left", "right", "replace", "concat", "concat_ws",
+	"split_part", "reverse", "repeat", "starts_with", "regexp_replace", "regexp_match", "regexp_matches",
+	"to_char", "format", "md5",
+	// dates and times
+	"now", "date_trunc", "date_part", "extract", "age", "make_date", "make_timestamp", "make_interval",
+	"to_date", "to_timestamp", "to_number", "date", "justify_days", "justify_hours", "justify_interval",
+	// json and arrays
+	"json_build_object", "jsonb_build_object", "json_build_array", "jsonb_build_array", "json_agg", "jsonb_agg",
+	"json_object_agg", "jsonb_object_agg", "json_extract_path_text", "jsonb_extract_path_text",
+	"jsonb_array_length", "json_array_length", "jsonb_typeof", "json_typeof", "to_json", "to_jsonb",
+	"array_length", "cardinality", "unnest", "generate_series",
+}
+
+// keywordsBeforeParen are keywords that can't name functions, so a parenthesis
+// following them doesn't call one (e.g. IN (, EXISTS ( or CAST ().
+var keywordsBeforeParen = toSet(
+	// reserved keywords
+	"all", "and", "any", "array", "as", "asc", "both", "case", "cast", "check", "collate", "column",
+	"constraint", "default", "desc", "distinct", "do", "else", "end", "except", "false", "fetch", "for",
+	"from", "group", "having", "in", "intersect", "into", "lateral", "leading", "limit", "not", "null",
+	"offset", "on", "only", "or", "order", "placing", "returning", "select", "some", "symmetric", "table",
+	"then", "to", "trailing", "true", "union", "unique", "using", "variadic", "when", "where", "window", "with",
+	// keywords that can name columns but not functions
+	"between", "bigint", "bit", "boolean", "char", "character", "dec", "decimal", "exists", "float", "inout",
+	"int", "integer", "interval", "national", "nchar", "none", "normalize", "numeric", "out", "overlay",
+	"precision", "real", "row", "setof", "smallint", "time", "timestamp", "treat", "values", "varchar",
+	"xmlattributes", "xmlconcat", "xmlelement", "xmlexists", "xmlforest", "xmlnamespaces", "xmlparse",
+	"xmlpi", "xmlroot", "xmlserialize", "xmltable",
+)
+
+// clausesAfterCall are keywords that open a parenthesised clause of the call
+// before them, e.g. count(*) FILTER (WHERE ...) or rank() OVER (...).
+var clausesAfterCall = toSet("filter", "over", "overlaps", "repeatable")
+
+// infixKeywords are keywords that open a parenthesised operand when following
+// another operand, e.g. a LIKE (...) or t JOIN (...).
+var infixKeywords = toSet("join", "like", "ilike", "similar", "is", "overlaps")
+
+var keywordsBeforeBy = toSet("order", "group", "partition")
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// checkFunctions checks that a statement only calls the allowed functions.
+// Unqualified calls of default functions resolve to the built-in ones only if
+// the schema doesn't define functions of the same name.
+func (c *PostgresClient) checkFunctions(ctx context.Context, tx *sql.Tx, statement string) error {
+	called, err := calledFunctions(statement)
+	if err != nil {
+		return fmt.Errorf("statement can't be checked against the allowed functions: %w", err)
+	}
+
+	var builtins []string
+	for _, name := range called {
+		switch {
+		case slices.Contains(c.functions, name):
+		case slices.Contains(defaultFunctions, strings.TrimPrefix(name, "pg_catalog.")):
+			if !strings.HasPrefix(name, "pg_catalog.") {
+				builtins = append(builtins, name)
+			}
+		default:
+			return fmt.Errorf("function %q is not allowed", name)
+		}
+	}
+	if len(builtins) == 0 {
+		return nil
+	}
+
+	var shadowed sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT min(p.proname)
+		FROM pg_catalog.pg_proc p
+		JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname <> 'pg_catalog' AND p.proname = ANY($1) AND pg_catalog.pg_function_is_visible(p.oid)`, builtins).Scan(&shadowed)
+	if err != nil {
+		return fmt.Errorf("failed to resolve functions: %w", err)
+	}
+	if shadowed.Valid {
+		return fmt.Errorf("function %q is not allowed", shadowed.String)
+	}
+	return nil
+}
+
+type pgTokenKind int
+
+const (
+	pgWord pgTokenKind = iota
+	pgQuotedIdent
+	pgString
+	pgNumber
+	pgPunct
+)
+
+type pgToken struct {
+	kind  pgTokenKind
+	value string
+}
+
+// calledFunctions returns the names of the functions a statement calls,
+// lower-cased unless quoted and schema-qualified if they are in the statement.
+// Parentheses are treated as calls unless the syntax around them shows
+// otherwise, so anything it can't tell apart is checked as a call.
+func calledFunctions(statement string) ([]string, error) {
+	tokens, err := tokenizePostgres(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	var functions []string
+	for i, tok := range tokens {
+		if tok.kind != pgPunct || tok.value != "(" || i == 0 {
+			continue
+		}
+		name := tokens[i-1]
+		if name.kind != pgWord && name.kind != pgQuotedIdent {
+			continue
+		}
+		start := i - 1
+		for start >= 2 && tokens[start-1].kind == pgPunct && tokens[start-1].value == "." &&
+			(tokens[start-2].kind == pgWord || tokens[start-2].kind == pgQuotedIdent) {
+			start -= 2
+		}
+		if start == i-1 && !isCall(tokens, start) {
+			continue
+		}
+		parts := make([]string, 0, (i-start+1)/2)
+		for j := start; j < i; j += 2 {
+			parts = append(parts, identifierName(tokens[j]))
+		}
+		functions = append(functions, strings.Join(parts, "."))
+	}
+	return functions, nil
+}
+
+// isCall checks whether the unqualified word at tokens[name], which is followed
+// by a parenthesis, calls a function.
+func isCall(tokens []pgToken, name int) bool {
+	tok := tokens[name]
+	if tok.kind == pgQuotedIdent {
+		return true
+	}
+	word := strings.ToLower(tok.value)
+	if keywordsBeforeParen[word] {
+		return false
+	}
+	if name == 0 {
+		return true
+	}
+	prev := tokens[name-1]
+	switch {
+	case prev.kind == pgWord && (strings.EqualFold(prev.value, "with") || strings.EqualFold(prev.value, "recursive")):
+		// column lists of common table expressions: WITH t(a, b) AS (...)
+		return false
+	case prev.kind == pgWord && strings.EqualFold(prev.value, "as"):
+		// aliases with column lists and type names: AS t(a, b), CAST(x AS varchar(10))
+		return false
+	case prev.kind == pgWord && strings.EqualFold(prev.value, "tablesample"):
+		return false
+	case prev.kind == pgPunct && prev.value == ":" && name >= 2 && tokens[name-2].kind == pgPunct && tokens[name-2].value == ":":
+		// type names of casts: x::numeric(10, 2)
+		return false
+	case clausesAfterCall[word] && prev.kind == pgPunct && prev.value == ")":
+		return false
+	case infixKeywords[word] && isOperand(prev):
+		return false
+	case word == "by" && prev.kind == pgWord && keywordsBeforeBy[strings.ToLower(prev.value)]:
+		return false
+	case word == "sets" && prev.kind == pgWord && strings.EqualFold(prev.value, "grouping"):
+		return false
+	}
+	return true
+}
+
+// isOperand checks whether tok ends an operand.
+func isOperand(tok pgToken) bool {
+	switch tok.kind {
+	case pgWord:
+		return !keywordsBeforeParen[strings.ToLower(tok.value)]
+	case pgPunct:
+		return tok.value == ")"
+	default:
+		return true
+	}
+}
+
+func identifierName(tok pgToken) string {
+	if tok.kind == pgQuotedIdent {
+		return tok.value
+	}
+	return strings.ToLower(tok.value)
+}
+
+// tokenizePostgres splits a statement into tokens, dropping whitespace and
+// comments. It follows PostgreSQL's lexer with standard_conforming_strings on
+// and fails on input it can't split, such as unterminated strings.
+func tokenizePostgres(sql string) ([]pgToken, error) {
+	var tokens []pgToken
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// Block comments nest
+			depth := 0
+			for ; i < len(runes); i++ {
+				if runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '*' {
+					depth++
+					i++
+				} else if runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+					depth--
+					i++
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+			if depth > 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+		case (r == 'e' || r == 'E') && i+1 < len(runes) && runes[i+1] == '\'':
+			// Escape strings escape quotes with backslashes
+			value, next, err := readPostgresQuoted(runes, i+1, true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, pgToken{kind: pgString, value: value})
+			i = next
+		case r == '\'' || r == '"':
+			value, next, err := readPostgresQuoted(runes, i, false)
+			if err != nil {
+				return nil, err
+			}
+			kind := pgString
+			if r == '"' {
+				kind = pgQuotedIdent
+			}
+			tokens = append(tokens, pgToken{kind: kind, value: value})
+			i = next
+		case r == '$' && (i == 0 || !isIdentRune(runes[i-1])):
+			value, next, ok := readDollarQuoted(runes, i)
+			if !ok {
+				// positional parameters like $1
+				tokens = append(tokens, pgToken{kind: pgPunct, value: string(r)})
+				i++
+				continue
+			}
+			if next < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string")
+			}
+			tokens = append(tokens, pgToken{kind: pgString, value: value})
+			i = next
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, pgToken{kind: pgWord, value: string(runes[start:i])})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, pgToken{kind: pgNumber, value: string(runes[start:i])})
+		default:
+			tokens = append(tokens, pgToken{kind: pgPunct, value: string(r)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+// readPostgresQuoted reads a quoted string or identifier whose quote is at
+// runes[start], returning its unquoted value and the index after the closing
+// quote. Doubled quotes escape the quote, as do backslashes in escape strings.
+func readPostgresQuoted(runes []rune, start int, backslashEscapes bool) (string, int, error) {
+	quote := runes[start]
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		r := runes[i]
+		if backslashEscapes && r == '\\' && i+1 < len(runes) {
+			b.WriteRune(runes[i+1])
+			i++
+			continue
+		}
+		if r == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				b.WriteRune(quote)
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		}
+		b.WriteRune(r)
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// readDollarQuoted reads a dollar-quoted string like $tag$...$tag$ starting at
+// runes[start]. It returns false if there is no opening tag, and a negative
+// index if the string isn't terminated.
+func readDollarQuoted(runes []rune, start int) (string, int, bool) {
+	end := start + 1
+	for end < len(runes) && runes[end] != '$' {
+		if !isIdentRune(runes[end]) || (end == start+1 && unicode.IsDigit(runes[end])) {
+			return "", 0, false
+		}
+		end++
+	}
+	if end >= len(runes) {
+		return "", 0, false
+	}
+	tag := string(runes[start : end+1])
+	body := string(runes[end+1:])
+	value, _, found := strings.Cut(body, tag)
+	if !found {
+		return "", -1, true
+	}
+	return value, end + 1 + len([]rune(value)) + len([]rune(tag)), true
+}
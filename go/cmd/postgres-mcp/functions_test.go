@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalledFunctions(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "aggregates and window functions",
+			sql:  "SELECT region, SUM(amount), rank() OVER (PARTITION BY region ORDER BY (amount)) FROM revenue_metrics GROUP BY region",
+			want: []string{"sum", "rank"},
+		},
+		{
+			name: "keywords followed by parentheses aren't calls",
+			sql:  "SELECT CAST(amount AS numeric(10, 2)), amount::varchar(10) FROM revenue_metrics WHERE id IN (1, 2) AND EXISTS (SELECT 1) AND (a, b) = ANY (VALUES (1, 2))",
+		},
+		{
+			name: "aggregate clauses aren't calls",
+			sql:  "SELECT count(*) FILTER (WHERE amount > 0), percentile_cont(0.5) WITHIN GROUP (ORDER BY amount) FROM revenue_metrics",
+			want: []string{"count", "percentile_cont"},
+		},
+		{
+			name: "table aliases and common table expressions",
+			sql:  "WITH top(id) AS (SELECT id FROM revenue_metrics) SELECT * FROM top AS t(id) JOIN (SELECT 1) s ON true",
+		},
+		{
+			name: "functions reading other relations are calls",
+			sql:  "SELECT query_to_xml('select * from hr.salaries', true, false, '')",
+			want: []string{"query_to_xml"},
+		},
+		{
+			name: "functions with column definition lists are calls",
+			sql:  "SELECT * FROM dblink('dbname=hr', 'select * from salaries') AS (id int)",
+			want: []string{"dblink"},
+		},
+		{
+			name: "qualified and quoted names",
+			sql:  `SELECT hr.read_salaries(1), "Lower"(name), PG_CATALOG.lower(name) FROM revenue_metrics`,
+			want: []string{"hr.read_salaries", "Lower", "pg_catalog.lower"},
+		},
+		{
+			name: "calls in strings and comments are ignored",
+			sql:  "SELECT 'pg_read_file(x)', $$dblink()$$, $tag$query_to_xml()$tag$ /* pg_sleep(1) /* nested */ */ -- lo_get(1)\nFROM revenue_metrics",
+		},
+		{
+			name: "backslashes in escape strings escape quotes",
+			sql:  `SELECT E'\'', pg_read_file('x') -- '`,
+			want: []string{"pg_read_file"},
+		},
+		{
+			name: "backslashes in standard strings don't escape quotes",
+			sql:  `SELECT '\', pg_read_file('x') -- '`,
+			want: []string{"pg_read_file"},
+		},
+		{
+			name:    "unterminated string",
+			sql:     "SELECT 'oops",
+			wantErr: "unterminated quoted string",
+		},
+		{
+			name:    "unterminated dollar-quoted string",
+			sql:     "SELECT $x$ pg_sleep(1)",
+			wantErr: "unterminated dollar-quoted string",
+		},
+		{
+			name:    "unterminated nested comment",
+			sql:     "SELECT 1 /* /* */ pg_sleep(1)",
+			wantErr: "unterminated comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			functions, err := calledFunctions(tt.sql)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, functions)
+		})
+	}
+}
@@ -0,0 +1,628 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var (
+	host      = flag.String("host", "", "PostgreSQL host (required)")
+	dbPort    = flag.Int("db-port", 5432, "PostgreSQL port")
+	database  = flag.String("database", "", "PostgreSQL database name (required)")
+	user      = flag.String("user", "", "PostgreSQL user (required)")
+	schema    = flag.String("schema", "public", "Schema to expose")
+	sslMode   = flag.String("sslmode", "prefer", "libpq sslmode: disable, allow, prefer, require, verify-ca or verify-full")
+	models    = flag.String("models", "", "Comma-separated list of tables/views to expose (if empty, discovers all). Views also expose the tables they read to execute_sql")
+	functions = flag.String("functions", "", "Comma-separated list of functions execute_sql may call in addition to common built-in aggregate, string, math, date and json functions")
+	transport = flag.String("transport", "stdio", "Transport mode: stdio or streamable-http")
+	httpPort  = flag.Int("port", 8080, "HTTP port when using streamable-http transport")
+)
+
+func main() {
+	flag.Parse()
+
+	// Validate required flags
+	if *host == "" {
+		log.Fatal("--host is required")
+	}
+	if *database == "" {
+		log.Fatal("--database is required")
+	}
+	if *user == "" {
+		log.Fatal("--user is required")
+	}
+
+	// Get password from environment (injected by the DataSource from secret)
+	password := os.Getenv("PGPASSWORD")
+
+	modelNames := splitList(*models)
+
+	client, err := NewPostgresClient(*host, *dbPort, *database, *user, password, *schema, *sslMode, modelNames, splitList(*functions))
+	if err != nil {
+		log.Fatalf("Failed to create PostgreSQL client: %v", err)
+	}
+	defer client.Close()
+
+	// Test connection on startup
+	log.Println("Testing PostgreSQL connection...")
+	if err := client.TestConnection(context.Background()); err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	log.Println("PostgreSQL connection successful")
+
+	// Create MCP server
+	s := server.NewMCPServer(
+		"postgres-mcp",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+	)
+
+	// Register tools
+	registerTools(s, client, modelNames)
+
+	// Start server based on transport mode
+	switch *transport {
+	case "streamable-http":
+		addr := fmt.Sprintf(":%d", *httpPort)
+		log.Printf("Starting streamable-http server on %s", addr)
+		httpServer := server.NewStreamableHTTPServer(s)
+
+		// Create a mux to handle both health checks and MCP requests
+		mux := http.NewServeMux()
+		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("ok"))
+		})
+		mux.Handle("/mcp", httpServer)
+
+		srv := &http.Server{
+			Addr:    addr,
+			Handler: mux,
+		}
+		if err := srv.ListenAndServe(); err != nil {
+			log.Fatalf("HTTP server error: %v", err)
+		}
+	default: // stdio
+		if err := server.ServeStdio(s); err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+	}
+}
+
+// splitList splits a comma-separated flag value, trimming its elements
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// PostgresClient handles communication with a PostgreSQL database
+type PostgresClient struct {
+	db     *sql.DB
+	schema string
+	// models are the tables/views in the schema statements may read, all if empty
+	models []string
+	// functions are the functions statements may call in addition to defaultFunctions
+	functions []string
+}
+
+// NewPostgresClient creates a new PostgreSQL client
+func NewPostgresClient(host string, port int, database, user, password, schema, sslMode string, models, functions []string) (*PostgresClient, error) {
+	if schema == "" {
+		schema = "public"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, password),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+
+	db, err := sql.Open("pgx", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	db.SetMaxOpenConns(5)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	return &PostgresClient{
+		db:        db,
+		schema:    schema,
+		models:    models,
+		functions: functions,
+	}, nil
+}
+
+// Close closes the underlying connection pool
+func (c *PostgresClient) Close() error {
+	return c.db.Close()
+}
+
+// TestConnection verifies the connection to PostgreSQL by checking schema access
+func (c *PostgresClient) TestConnection(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := c.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1)", c.schema).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("schema %q does not exist", c.schema)
+	}
+	return nil
+}
+
+// TableInfo represents a table or view in the configured schema
+type TableInfo struct {
+	Name       string `json:"name"`
+	SchemaName string `json:"schema_name"`
+	TableType  string `json:"table_type"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// ListTables lists tables and views in the configured schema
+func (c *PostgresClient) ListTables(ctx context.Context) ([]TableInfo, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT c.relname,
+		       n.nspname,
+		       CASE c.relkind
+		           WHEN 'v' THEN 'VIEW'
+		           WHEN 'm' THEN 'MATERIALIZED_VIEW'
+		           WHEN 'f' THEN 'FOREIGN'
+		           ELSE 'TABLE'
+		       END,
+		       COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+		ORDER BY c.relname`, c.schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []TableInfo
+	for rows.Next() {
+		var t TableInfo
+		if err := rows.Scan(&t.Name, &t.SchemaName, &t.TableType, &t.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// ColumnInfo represents a column of a table or view
+type ColumnInfo struct {
+	Name     string `json:"name"`
+	TypeText string `json:"type_text"`
+	Position int    `json:"position"`
+	Comment  string `json:"comment,omitempty"`
+	Nullable bool   `json:"nullable"`
+}
+
+// TableDetails represents detailed table information
+type TableDetails struct {
+	TableInfo
+	Columns []ColumnInfo `json:"columns"`
+}
+
+// GetTable retrieves detailed information about a specific table
+func (c *PostgresClient) GetTable(ctx context.Context, tableName string) (*TableDetails, error) {
+	schemaName, name := c.splitTableName(tableName)
+
+	table := &TableDetails{}
+	err := c.db.QueryRowContext(ctx, `
+		SELECT c.relname,
+		       n.nspname,
+		       CASE c.relkind
+		           WHEN 'v' THEN 'VIEW'
+		           WHEN 'm' THEN 'MATERIALIZED_VIEW'
+		           WHEN 'f' THEN 'FOREIGN'
+		           ELSE 'TABLE'
+		       END,
+		       COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p', 'v', 'm', 'f')`,
+		schemaName, name,
+	).Scan(&table.Name, &table.SchemaName, &table.TableType, &table.Comment)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table %s.%s not found", schemaName, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT a.attname,
+		       format_type(a.atttypid, a.atttypmod),
+		       a.attnum,
+		       COALESCE(col_description(a.attrelid, a.attnum), ''),
+		       NOT a.attnotnull
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, schemaName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col ColumnInfo
+		if err := rows.Scan(&col.Name, &col.TypeText, &col.Position, &col.Comment, &col.Nullable); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	return table, rows.Err()
+}
+
+// splitTableName splits an optionally schema-qualified table name, defaulting to the configured schema
+func (c *PostgresClient) splitTableName(tableName string) (string, string) {
+	if schemaName, name, ok := strings.Cut(tableName, "."); ok {
+		return schemaName, name
+	}
+	return c.schema, tableName
+}
+
+// SQLResult represents the result of a SQL statement
+type SQLResult struct {
+	Columns   []string `json:"columns"`
+	Data      [][]any  `json:"data"`
+	RowCount  int      `json:"row_count"`
+	Truncated bool     `json:"truncated"`
+}
+
+// ExecuteSQL executes a SQL statement in a read-only transaction scoped to the configured schema
+func (c *PostgresClient) ExecuteSQL(ctx context.Context, statement string, maxRows int) (*SQLResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('search_path', $1, true)", c.schema); err != nil {
+		return nil, fmt.Errorf("failed to set search_path: %w", err)
+	}
+	// Statements are checked with backslashes not escaping quotes in strings
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('standard_conforming_strings', 'on', true)"); err != nil {
+		return nil, fmt.Errorf("failed to set standard_conforming_strings: %w", err)
+	}
+
+	// Functions are checked first, as planning can already evaluate them
+	if err := c.checkFunctions(ctx, tx, statement); err != nil {
+		return nil, err
+	}
+	if err := c.checkRelations(ctx, tx, statement); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SQLResult{
+		Columns: columns,
+		Data:    [][]any{},
+	}
+	for rows.Next() {
+		if result.RowCount >= maxRows {
+			result.Truncated = true
+			break
+		}
+
+		values := make([]any, len(columns))
+		scanArgs := make([]any, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		for i, v := range values {
+			// Render raw bytes (e.g. bytea, unknown types) as text for JSON output
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+
+		result.Data = append(result.Data, values)
+		result.RowCount++
+	}
+	return result, rows.Err()
+}
+
+// checkRelations checks that the plan of a statement only reads the allowed models,
+// or the relations the allowed views read
+func (c *PostgresClient) checkRelations(ctx context.Context, tx *sql.Tx, statement string) error {
+	allowed, err := c.allowedRelations(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve allowed tables: %w", err)
+	}
+
+	var plan []byte
+	if err := tx.QueryRowContext(ctx, "EXPLAIN (VERBOSE, FORMAT JSON) "+statement).Scan(&plan); err != nil {
+		return fmt.Errorf("statement can't be checked against the allowed tables: %w", err)
+	}
+	relations, err := planRelations(plan)
+	if err != nil {
+		return err
+	}
+	for _, relation := range relations {
+		if !allowed[relation] {
+			return fmt.Errorf("table %q is not available. Use list_tables to see available tables", relation)
+		}
+	}
+	return nil
+}
+
+// allowedRelations returns the schema-qualified names of the allowed models, or
+// of all relations of the schema without models, and, as views are expanded in
+// plans, of the relations the allowed views read
+func (c *PostgresClient) allowedRelations(ctx context.Context, tx *sql.Tx) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `
+		WITH RECURSIVE allowed(oid) AS (
+		    SELECT c.oid
+		    FROM pg_catalog.pg_class c
+		    JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		    WHERE n.nspname = $1 AND ($3 OR c.relname = ANY($2))
+		    UNION
+		    SELECT d.refobjid
+		    FROM allowed a
+		    JOIN pg_catalog.pg_rewrite r ON r.ev_class = a.oid
+		    JOIN pg_catalog.pg_depend d ON d.classid = 'pg_catalog.pg_rewrite'::regclass
+		        AND d.objid = r.oid
+		        AND d.refclassid = 'pg_catalog.pg_class'::regclass
+		)
+		SELECT n.nspname, c.relname
+		FROM allowed a
+		JOIN pg_catalog.pg_class c ON c.oid = a.oid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace`, c.schema, c.models, len(c.models) == 0)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	allowed := map[string]bool{}
+	for rows.Next() {
+		var schemaName, name string
+		if err := rows.Scan(&schemaName, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		allowed[schemaName+"."+name] = true
+	}
+	return allowed, rows.Err()
+}
+
+// planRelations returns the schema-qualified names of the relations read by a
+// plan of EXPLAIN (VERBOSE, FORMAT JSON)
+func planRelations(plan []byte) ([]string, error) {
+	var nodes any
+	if err := json.Unmarshal(plan, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	var relations []string
+	var walk func(node any)
+	walk = func(node any) {
+		switch n := node.(type) {
+		case map[string]any:
+			if name, ok := n["Relation Name"].(string); ok {
+				schemaName, _ := n["Schema"].(string)
+				relations = append(relations, schemaName+"."+name)
+			}
+			for _, v := range n {
+				walk(v)
+			}
+		case []any:
+			for _, v := range n {
+				walk(v)
+			}
+		}
+	}
+	walk(nodes)
+	return relations, nil
+}
+
+// registerTools registers all MCP tools with the server
+func registerTools(s *server.MCPServer, client *PostgresClient, modelNames []string) {
+	// Tool 1: list_tables - List available tables in the schema
+	s.AddTool(
+		mcp.NewTool("list_tables",
+			mcp.WithDescription("List all available tables and views in the configured PostgreSQL schema"),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tables, err := client.ListTables(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tables: %w", err)
+			}
+
+			// Filter tables if specific models are configured
+			if len(modelNames) > 0 {
+				var filtered []TableInfo
+				for _, t := range tables {
+					if containsModel(modelNames, client.schema, t.Name) {
+						filtered = append(filtered, t)
+					}
+				}
+				tables = filtered
+			}
+
+			result := make([]map[string]string, len(tables))
+			for i, t := range tables {
+				result[i] = map[string]string{
+					"name":        t.Name,
+					"schema":      t.SchemaName,
+					"type":        t.TableType,
+					"description": t.Comment,
+				}
+			}
+
+			jsonBytes, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal result: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonBytes)), nil
+		},
+	)
+
+	// Tool 2: describe_table - Get schema/metadata for a table
+	s.AddTool(
+		mcp.NewTool("describe_table",
+			mcp.WithDescription("Get detailed schema and metadata for a table, including columns, types, and descriptions"),
+			mcp.WithString("table_name",
+				mcp.Required(),
+				mcp.Description("Name of the table to describe (can be just the table name or schema-qualified schema.table)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tableName, err := request.RequireString("table_name")
+			if err != nil {
+				return nil, fmt.Errorf("table_name is required: %w", err)
+			}
+
+			// Validate table is in the schema and the allowed list (if restrictions are set)
+			if !containsModel(modelNames, client.schema, tableName) {
+				return nil, fmt.Errorf("table %q is not available. Use list_tables to see available tables", tableName)
+			}
+
+			table, err := client.GetTable(ctx, tableName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get table details: %w", err)
+			}
+
+			// Format columns for output
+			columns := make([]map[string]any, len(table.Columns))
+			for i, col := range table.Columns {
+				columns[i] = map[string]any{
+					"name":        col.Name,
+					"type":        col.TypeText,
+					"nullable":    col.Nullable,
+					"description": col.Comment,
+					"position":    col.Position,
+				}
+			}
+
+			result := map[string]any{
+				"name":        table.Name,
+				"schema":      table.SchemaName,
+				"type":        table.TableType,
+				"description": table.Comment,
+				"columns":     columns,
+			}
+
+			jsonBytes, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal result: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonBytes)), nil
+		},
+	)
+
+	// Tool 3: execute_sql - Execute SQL against the database
+	s.AddTool(
+		mcp.NewTool("execute_sql",
+			mcp.WithDescription("Execute a read-only SQL query against the PostgreSQL database. Returns results as a JSON array."),
+			mcp.WithString("sql",
+				mcp.Required(),
+				mcp.Description("SQL query to execute"),
+			),
+			mcp.WithNumber("max_rows",
+				mcp.Description("Maximum number of rows to return (default: 100, max: 10000)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			statement, err := request.RequireString("sql")
+			if err != nil {
+				return nil, fmt.Errorf("sql is required: %w", err)
+			}
+
+			maxRows := request.GetInt("max_rows", 100)
+			if maxRows > 10000 {
+				maxRows = 10000
+			}
+			if maxRows <= 0 {
+				maxRows = 100
+			}
+
+			result, err := client.ExecuteSQL(ctx, statement, maxRows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to execute SQL: %w", err)
+			}
+
+			jsonBytes, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal result: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonBytes)), nil
+		},
+	)
+}
+
+// containsModel checks if the optionally schema-qualified model/table name is
+// in the configured schema and the allowed list
+func containsModel(models []string, schema, name string) bool {
+	tableSchema, table := schema, name
+	if s, t, ok := strings.Cut(name, "."); ok {
+		tableSchema, table = s, t
+	}
+	if tableSchema != schema {
+		return false
+	}
+	// If no models specified, all are allowed
+	return len(models) == 0 || slices.Contains(models, table)
+}
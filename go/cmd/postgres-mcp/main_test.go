@@ -0,0 +1,53 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainsModel(t *testing.T) {
+	models := []string{"revenue_metrics", "customer_360"}
+
+	assert.True(t, containsModel(models, "sales", "revenue_metrics"))
+	assert.True(t, containsModel(models, "sales", "sales.customer_360"))
+	assert.False(t, containsModel(models, "sales", "salaries"))
+	assert.False(t, containsModel(models, "sales", "hr.revenue_metrics"))
+
+	// Without an allow-list, all tables of the schema are available
+	assert.True(t, containsModel(nil, "sales", "salaries"))
+	assert.False(t, containsModel(nil, "sales", "hr.salaries"))
+}
+
+func TestPlanRelations(t *testing.T) {
+	plan := `[{"Plan": {
+		"Node Type": "Hash Join",
+		"Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "revenue_metrics", "Schema": "sales", "Alias": "r"},
+			{"Node Type": "Hash", "Plans": [
+				{"Node Type": "Index Scan", "Relation Name": "salaries", "Schema": "hr", "Alias": "s"}
+			]}
+		]
+	}}]`
+
+	relations, err := planRelations([]byte(plan))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sales.revenue_metrics", "hr.salaries"}, relations)
+}
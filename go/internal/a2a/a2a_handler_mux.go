@@ -1,45 +1,94 @@
 package a2a
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/gorilla/mux"
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/internal/usage"
 	common "github.com/kagent-dev/kagent/go/internal/utils"
 	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/utils/ptr"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"trpc.group/trpc-go/trpc-a2a-go/client"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/server"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
 )
 
+// ErrAgentNotFound is returned for messages to agents the mux doesn't serve.
+var ErrAgentNotFound = errors.New("agent not found")
+
 // A2AHandlerMux is an interface that defines methods for adding, getting, and removing agentic task handlers.
 type A2AHandlerMux interface {
 	SetAgentHandler(
 		agentRef string,
 		client *client.A2AClient,
 		card server.AgentCard,
+		rateLimits *v1alpha2.A2ARateLimits,
 	) error
 	RemoveAgentHandler(
 		agentRef string,
 	)
+	// SendMessage sends a message to an agent on behalf of the caller of ctx,
+	// so the controller can message agents itself, e.g. to resume tasks
+	// awaiting approval.
+	SendMessage(
+		ctx context.Context,
+		agentRef string,
+		params protocol.SendMessageParams,
+	) (*protocol.MessageResult, error)
 	http.Handler
 }
 
 type handlerMux struct {
 	handlers       map[string]http.Handler
+	managers       map[string]taskmanager.TaskManager
+	limits         map[string]Limits
 	lock           sync.RWMutex
 	basePathPrefix string
 	authenticator  auth.AuthProvider
+	usage          *usage.Recorder
+	push           *PushNotifier
+	tasks          *TaskStore
+	sessions       *SessionAccess
+	defaultLimits  Limits
+	limiter        *limiter
 }
 
 var _ A2AHandlerMux = &handlerMux{}
 
-func NewA2AHttpMux(pathPrefix string, authenticator auth.AuthProvider) *handlerMux {
+// NewA2AHttpMux creates the mux proxying A2A requests to agents. The token
+// usage reported by agents is recorded with usageRecorder, if set. If push is
+// set, the controller serves the push notifications of agents' tasks and
+// advertises them on their cards. If tasks is set, agents' tasks are stored as
+// they're proxied. If sessions is set, the sharing of sessions is enforced on
+// messages. Requests are limited by defaultLimits, unless the spec of an agent
+// overrides them.
+func NewA2AHttpMux(pathPrefix string, authenticator auth.AuthProvider, usageRecorder *usage.Recorder, push *PushNotifier, tasks *TaskStore, sessions *SessionAccess, defaultLimits Limits) *handlerMux {
 	return &handlerMux{
 		handlers:       make(map[string]http.Handler),
+		managers:       make(map[string]taskmanager.TaskManager),
+		limits:         make(map[string]Limits),
 		basePathPrefix: pathPrefix,
 		authenticator:  authenticator,
+		usage:          usageRecorder,
+		push:           push,
+		tasks:          tasks,
+		sessions:       sessions,
+		defaultLimits:  defaultLimits,
+		limiter:        newLimiter(),
 	}
 }
 
@@ -47,8 +96,18 @@ func (a *handlerMux) SetAgentHandler(
 	agentRef string,
 	client *client.A2AClient,
 	card server.AgentCard,
+	rateLimits *v1alpha2.A2ARateLimits,
 ) error {
-	srv, err := server.NewA2AServer(card, NewPassthroughManager(client), server.WithMiddleWare(authimpl.NewA2AAuthenticator(a.authenticator)))
+	opts := []server.Option{server.WithMiddleWare(authimpl.NewA2AAuthenticator(a.authenticator))}
+	if a.push != nil {
+		card.Capabilities.PushNotifications = ptr.To(true)
+		opts = append(opts,
+			server.WithJWKSEndpoint(true, ""),
+			server.WithPushNotificationAuthenticator(a.push.Authenticator()),
+		)
+	}
+	manager := NewPassthroughManager(client, agentRef, a.usage, a.push, a.tasks, a.sessions)
+	srv, err := server.NewA2AServer(card, manager, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create A2A server: %w", err)
 	}
@@ -57,6 +116,8 @@ func (a *handlerMux) SetAgentHandler(
 	defer a.lock.Unlock()
 
 	a.handlers[agentRef] = srv.Handler()
+	a.managers[agentRef] = manager
+	a.limits[agentRef] = a.defaultLimits.WithOverrides(rateLimits)
 
 	return nil
 }
@@ -67,13 +128,44 @@ func (a *handlerMux) RemoveAgentHandler(
 	a.lock.Lock()
 	defer a.lock.Unlock()
 	delete(a.handlers, agentRef)
+	delete(a.managers, agentRef)
+	delete(a.limits, agentRef)
+	a.limiter.forget(agentRef)
+	deleteAgentMetrics(agentRef)
+}
+
+// SendMessage sends a message to the agent agentRef the same way as the
+// message/send requests proxied by the mux: it counts against the agent's
+// limits, and the agent's task and usage are recorded. Limited messages fail
+// with a *LimitError.
+func (a *handlerMux) SendMessage(
+	ctx context.Context,
+	agentRef string,
+	params protocol.SendMessageParams,
+) (*protocol.MessageResult, error) {
+	a.lock.RLock()
+	manager, ok := a.managers[agentRef]
+	limits := a.limits[agentRef]
+	a.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, agentRef)
+	}
+
+	if !limits.IsZero() {
+		release, limitErr := a.acquire(ctx, agentRef, limits, false)
+		if limitErr != nil {
+			return nil, limitErr
+		}
+		defer release()
+	}
+	return manager.OnSendMessage(ctx, params)
 }
 
-func (a *handlerMux) getHandler(name string) (http.Handler, bool) {
+func (a *handlerMux) getHandler(name string) (http.Handler, Limits, bool) {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
 	handler, ok := a.handlers[name]
-	return handler, ok
+	return handler, a.limits[name], ok
 }
 
 func (a *handlerMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -91,9 +183,10 @@ func (a *handlerMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	handlerName := common.ResourceRefString(agentNamespace, agentName)
+	trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("kagent.agent", handlerName))
 
 	// get the underlying handler
-	handlerHandler, ok := a.getHandler(handlerName)
+	handlerHandler, limits, ok := a.getHandler(handlerName)
 	if !ok {
 		http.Error(
 			w,
@@ -103,5 +196,88 @@ func (a *handlerMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == http.MethodPost && !limits.IsZero() {
+		release, ok := a.admit(w, r, handlerName, limits)
+		if !ok {
+			return
+		}
+		defer release()
+	}
+
 	handlerHandler.ServeHTTP(w, r)
 }
+
+// jsonrpcCodeLimitExceeded is the implementation-defined JSON-RPC server error
+// of requests exceeding a limit.
+const jsonrpcCodeLimitExceeded = -32029
+
+// admit applies the limits of the agent agentRef to a JSON-RPC request. It
+// responds to rejected requests with a JSON-RPC error holding the time to wait
+// before retrying, which the Retry-After header holds too.
+func (a *handlerMux) admit(w http.ResponseWriter, r *http.Request, agentRef string, limits Limits) (func(), bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Malformed requests are left to the A2A server to reject, and count as
+	// unary requests.
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	_ = json.Unmarshal(body, &request)
+	stream := request.Method == protocol.MethodMessageStream || request.Method == protocol.MethodTasksResubscribe
+
+	release, limitErr := a.acquire(r.Context(), agentRef, limits, stream)
+	if limitErr != nil {
+		writeLimitError(w, request.ID, limitErr)
+		return nil, false
+	}
+	return release, true
+}
+
+// acquire applies the limits of the agent agentRef to a request of the caller
+// of ctx.
+func (a *handlerMux) acquire(ctx context.Context, agentRef string, limits Limits, stream bool) (func(), *LimitError) {
+	var user string
+	if session, ok := auth.AuthSessionFrom(ctx); ok && session != nil {
+		principal := session.Principal()
+		user = principal.User.ID
+		if user == "" {
+			user = principal.Agent.ID
+		}
+	}
+
+	release, limitErr := a.limiter.acquire(agentRef, user, limits, stream)
+	if limitErr != nil {
+		a2aLimitedRequests.WithLabelValues(agentRef, limitErr.Limit).Inc()
+		ctrllog.FromContext(ctx).V(1).Info("Limited A2A request", "agent", agentRef, "user", user, "limit", limitErr.Limit, "retryAfter", limitErr.RetryAfter)
+		return nil, limitErr
+	}
+	return release, nil
+}
+
+func writeLimitError(w http.ResponseWriter, id json.RawMessage, err *LimitError) {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	response := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]any{
+			"code":    jsonrpcCodeLimitExceeded,
+			"message": "Rate limit exceeded: " + err.Error(),
+			"data": map[string]any{
+				"limit":             err.Limit,
+				"retryAfterSeconds": err.RetryAfterSeconds(),
+			},
+		},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfterSeconds()))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(response) //nolint:errcheck
+}
@@ -143,7 +143,7 @@ func (a *A2ARegistrar) upsertAgentHandler(ctx context.Context, agent *v1alpha2.A
 	cardCopy := *card
 	cardCopy.URL = fmt.Sprintf("%s/%s/", a.a2aBaseUrl, agentRef)
 
-	if err := a.handlerMux.SetAgentHandler(agentRef.String(), client, cardCopy); err != nil {
+	if err := a.handlerMux.SetAgentHandler(agentRef.String(), client, cardCopy, agent.Spec.RateLimits); err != nil {
 		return fmt.Errorf("set handler for %s: %w", agentRef, err)
 	}
 
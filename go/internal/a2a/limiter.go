@@ -0,0 +1,218 @@
+package a2a
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+)
+
+// Names of the limits, as reported in limit errors and by the limit label of
+// kagent_a2a_limited_requests_total.
+const (
+	limitRequestsPerMinute           = "requests_per_minute"
+	limitRequestsPerMinutePerUser    = "requests_per_minute_per_user"
+	limitMaxConcurrentStreams        = "max_concurrent_streams"
+	limitMaxConcurrentStreamsPerUser = "max_concurrent_streams_per_user"
+)
+
+const (
+	// streamRetryAfter is suggested to clients exceeding a concurrency limit, as
+	// there is no telling when a stream will close.
+	streamRetryAfter = 5 * time.Second
+	// limiterSweepInterval is how often idle limiter state is dropped.
+	limiterSweepInterval = time.Minute
+)
+
+// Limits limits the A2A requests proxied to an agent, in total and per user.
+// A limit of 0 disables it.
+type Limits struct {
+	RequestsPerMinute           int
+	RequestsPerMinutePerUser    int
+	MaxConcurrentStreams        int
+	MaxConcurrentStreamsPerUser int
+}
+
+// WithOverrides returns the limits with those set in the rate limits of an
+// agent's spec replacing them.
+func (l Limits) WithOverrides(overrides *v1alpha2.A2ARateLimits) Limits {
+	if overrides == nil {
+		return l
+	}
+	override := func(limit *int, value *int32) {
+		if value != nil {
+			*limit = int(*value)
+		}
+	}
+	override(&l.RequestsPerMinute, overrides.RequestsPerMinute)
+	override(&l.RequestsPerMinutePerUser, overrides.RequestsPerMinutePerUser)
+	override(&l.MaxConcurrentStreams, overrides.MaxConcurrentStreams)
+	override(&l.MaxConcurrentStreamsPerUser, overrides.MaxConcurrentStreamsPerUser)
+	return l
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// LimitError is returned for requests exceeding a limit.
+type LimitError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
+
+// RetryAfterSeconds returns the time to wait before retrying in whole seconds,
+// as used by the Retry-After header.
+func (e *LimitError) RetryAfterSeconds() int {
+	return max(1, int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+type limiterKey struct {
+	agent string
+	user  string
+}
+
+type rateLimiter struct {
+	limiter           *rate.Limiter
+	requestsPerMinute int
+}
+
+// limiter enforces Limits per agent and per user of an agent.
+type limiter struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	rates     map[limiterKey]*rateLimiter
+	streams   map[limiterKey]int
+	lastSweep time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{
+		now:     time.Now,
+		rates:   make(map[limiterKey]*rateLimiter),
+		streams: make(map[limiterKey]int),
+	}
+}
+
+// acquire admits a request of user to agent, or returns a LimitError. Requests
+// of an empty user only count towards the agent's limits. An admitted stream
+// counts towards the concurrency limits until release is called.
+func (l *limiter) acquire(agent, user string, limits Limits, stream bool) (release func(), err *LimitError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	agentKey := limiterKey{agent: agent}
+	userKey := limiterKey{agent: agent, user: user}
+	perUser := user != ""
+
+	if stream {
+		if limits.MaxConcurrentStreams > 0 && l.streams[agentKey] >= limits.MaxConcurrentStreams {
+			return nil, &LimitError{Limit: limitMaxConcurrentStreams, RetryAfter: streamRetryAfter}
+		}
+		if perUser && limits.MaxConcurrentStreamsPerUser > 0 && l.streams[userKey] >= limits.MaxConcurrentStreamsPerUser {
+			return nil, &LimitError{Limit: limitMaxConcurrentStreamsPerUser, RetryAfter: streamRetryAfter}
+		}
+	}
+
+	// Reserve a token of both rate limits, and give both back unless both have
+	// one available, so a rejected request doesn't count.
+	var reservations []*rate.Reservation
+	var rejection *LimitError
+	reserve := func(key limiterKey, requestsPerMinute int, limit string) {
+		if requestsPerMinute <= 0 || rejection != nil {
+			return
+		}
+		reservation := l.rateLimiter(key, requestsPerMinute).ReserveN(now, 1)
+		reservations = append(reservations, reservation)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			rejection = &LimitError{Limit: limit, RetryAfter: delay}
+		}
+	}
+	reserve(agentKey, limits.RequestsPerMinute, limitRequestsPerMinute)
+	if perUser {
+		reserve(userKey, limits.RequestsPerMinutePerUser, limitRequestsPerMinutePerUser)
+	}
+	if rejection != nil {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+		return nil, rejection
+	}
+
+	if !stream {
+		return func() {}, nil
+	}
+	l.streams[agentKey]++
+	if perUser {
+		l.streams[userKey]++
+	}
+	a2aLimiterStreams.WithLabelValues(agent).Set(float64(l.streams[agentKey]))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.releaseStream(agentKey)
+			if perUser {
+				l.releaseStream(userKey)
+			}
+			a2aLimiterStreams.WithLabelValues(agent).Set(float64(l.streams[agentKey]))
+		})
+	}, nil
+}
+
+func (l *limiter) releaseStream(key limiterKey) {
+	l.streams[key]--
+	if l.streams[key] <= 0 {
+		delete(l.streams, key)
+	}
+}
+
+// rateLimiter returns the rate limiter of key, replacing it if the limit
+// changed.
+func (l *limiter) rateLimiter(key limiterKey, requestsPerMinute int) *rate.Limiter {
+	if existing, ok := l.rates[key]; ok && existing.requestsPerMinute == requestsPerMinute {
+		return existing.limiter
+	}
+	limiter := rate.NewLimiter(rate.Limit(float64(requestsPerMinute)/60), requestsPerMinute)
+	l.rates[key] = &rateLimiter{limiter: limiter, requestsPerMinute: requestsPerMinute}
+	return limiter
+}
+
+// sweep drops the rate limiters that refilled completely, which behave like
+// new ones, so users that stopped sending requests don't accumulate.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < limiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, r := range l.rates {
+		if r.limiter.TokensAt(now) >= float64(r.limiter.Burst()) {
+			delete(l.rates, key)
+		}
+	}
+}
+
+// forget drops the state of an agent that's no longer served.
+func (l *limiter) forget(agent string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key := range l.rates {
+		if key.agent == agent {
+			delete(l.rates, key)
+		}
+	}
+}
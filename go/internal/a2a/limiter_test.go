@@ -0,0 +1,145 @@
+package a2a
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	newTestLimiter := func() *limiter {
+		l := newLimiter()
+		l.now = func() time.Time { return now }
+		return l
+	}
+
+	t.Run("requests per minute per user", func(t *testing.T) {
+		l := newTestLimiter()
+		limits := Limits{RequestsPerMinutePerUser: 2}
+		for range 2 {
+			_, err := l.acquire("default/agent", "alice", limits, false)
+			require.Nil(t, err)
+		}
+		_, err := l.acquire("default/agent", "alice", limits, false)
+		require.NotNil(t, err)
+		assert.Equal(t, limitRequestsPerMinutePerUser, err.Limit)
+		assert.Equal(t, 30*time.Second, err.RetryAfter)
+
+		// Other users and agents have limits of their own
+		_, err = l.acquire("default/agent", "bob", limits, false)
+		assert.Nil(t, err)
+		_, err = l.acquire("default/other", "alice", limits, false)
+		assert.Nil(t, err)
+	})
+
+	t.Run("rejected requests don't count", func(t *testing.T) {
+		l := newTestLimiter()
+		limits := Limits{RequestsPerMinute: 2, RequestsPerMinutePerUser: 1}
+		_, err := l.acquire("default/agent", "alice", limits, false)
+		require.Nil(t, err)
+		_, err = l.acquire("default/agent", "alice", limits, false)
+		require.NotNil(t, err)
+
+		// The agent limit still has a request left for bob
+		_, err = l.acquire("default/agent", "bob", limits, false)
+		require.Nil(t, err)
+		_, err = l.acquire("default/agent", "carol", limits, false)
+		require.NotNil(t, err)
+		assert.Equal(t, limitRequestsPerMinute, err.Limit)
+	})
+
+	t.Run("concurrent streams", func(t *testing.T) {
+		l := newTestLimiter()
+		limits := Limits{MaxConcurrentStreams: 2, MaxConcurrentStreamsPerUser: 1}
+		release, err := l.acquire("default/agent", "alice", limits, true)
+		require.Nil(t, err)
+		_, err = l.acquire("default/agent", "alice", limits, true)
+		require.NotNil(t, err)
+		assert.Equal(t, limitMaxConcurrentStreamsPerUser, err.Limit)
+
+		// Unary requests aren't streams
+		_, err = l.acquire("default/agent", "alice", limits, false)
+		require.Nil(t, err)
+
+		_, err = l.acquire("default/agent", "bob", limits, true)
+		require.Nil(t, err)
+		_, err = l.acquire("default/agent", "carol", limits, true)
+		require.NotNil(t, err)
+		assert.Equal(t, limitMaxConcurrentStreams, err.Limit)
+		assert.Equal(t, 2.0, testutil.ToFloat64(a2aLimiterStreams.WithLabelValues("default/agent")))
+
+		release()
+		release()
+		_, err = l.acquire("default/agent", "alice", limits, true)
+		assert.Nil(t, err)
+	})
+
+	t.Run("agent overrides", func(t *testing.T) {
+		limits := Limits{RequestsPerMinute: 100, MaxConcurrentStreamsPerUser: 2}.WithOverrides(&v1alpha2.A2ARateLimits{
+			RequestsPerMinute:        ptr.To[int32](0),
+			RequestsPerMinutePerUser: ptr.To[int32](10),
+		})
+		assert.Equal(t, Limits{RequestsPerMinutePerUser: 10, MaxConcurrentStreamsPerUser: 2}, limits)
+	})
+}
+
+func TestHandlerMuxLimits(t *testing.T) {
+	a := NewA2AHttpMux("/api/a2a", nil, nil, nil, nil, nil, Limits{RequestsPerMinutePerUser: 1})
+	var served int
+	a.handlers["default/agent"] = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { served++ })
+	a.limits["default/agent"] = a.defaultLimits
+
+	router := mux.NewRouter()
+	router.PathPrefix("/api/a2a/{namespace}/{name}").Handler(a)
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/a2a/default/agent/", strings.NewReader(`{"jsonrpc": "2.0", "id": 7, "method": "message/send"}`))
+		req = req.WithContext(auth.AuthSessionTo(req.Context(), &testSession{principal: auth.Principal{User: auth.User{ID: "alice"}}}))
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		return rw
+	}
+
+	require.Equal(t, http.StatusOK, send().Code)
+	rw := send()
+	require.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, 1, served)
+	assert.Equal(t, "60", rw.Header().Get("Retry-After"))
+
+	var response struct {
+		ID    int `json:"id"`
+		Error struct {
+			Code int `json:"code"`
+			Data struct {
+				Limit             string `json:"limit"`
+				RetryAfterSeconds int    `json:"retryAfterSeconds"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &response))
+	assert.Equal(t, 7, response.ID)
+	assert.Equal(t, jsonrpcCodeLimitExceeded, response.Error.Code)
+	assert.Equal(t, limitRequestsPerMinutePerUser, response.Error.Data.Limit)
+	assert.Equal(t, 60, response.Error.Data.RetryAfterSeconds)
+	assert.Equal(t, 1.0, testutil.ToFloat64(a2aLimitedRequests.WithLabelValues("default/agent", limitRequestsPerMinutePerUser)))
+}
+
+type testSession struct {
+	principal auth.Principal
+}
+
+func (s *testSession) Principal() auth.Principal {
+	return s.principal
+}
@@ -2,19 +2,47 @@ package a2a
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/kagent-dev/kagent/go/internal/tracing"
+	"github.com/kagent-dev/kagent/go/internal/usage"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"trpc.group/trpc-go/trpc-a2a-go/client"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
 )
 
+var errStreamInterrupted = errors.New("stream closed before its final event")
+
 type PassthroughManager struct {
-	client *client.A2AClient
+	client   *client.A2AClient
+	agentRef string
+	usage    *usage.Recorder
+	push     *PushNotifier
+	tasks    *TaskStore
+	sessions *SessionAccess
 }
 
-func NewPassthroughManager(client *client.A2AClient) taskmanager.TaskManager {
+// NewPassthroughManager creates a task manager that forwards requests to the
+// agent agentRef. If recorder is set, the token usage reported in the agent's
+// responses is recorded. If push is set, the controller serves the push
+// notifications of the agent's tasks, which are otherwise left to the agent.
+// If tasks is set, the agent's tasks and their streamed events are stored, so
+// they can still be read and resubscribed to while the agent is unavailable.
+// If sessions is set, viewers of shared sessions can't message agents in them,
+// and tasks are only served to the users of their session.
+func NewPassthroughManager(client *client.A2AClient, agentRef string, recorder *usage.Recorder, push *PushNotifier, tasks *TaskStore, sessions *SessionAccess) taskmanager.TaskManager {
 	return &PassthroughManager{
-		client: client,
+		client:   client,
+		agentRef: agentRef,
+		usage:    recorder,
+		push:     push,
+		tasks:    tasks,
+		sessions: sessions,
 	}
 }
 
@@ -25,7 +53,24 @@ func (m *PassthroughManager) OnSendMessage(ctx context.Context, request protocol
 	if request.Message.Kind == "" {
 		request.Message.Kind = protocol.KindMessage
 	}
-	return m.client.SendMessage(ctx, request)
+	if err := m.sessions.check(ctx, request.Message); err != nil {
+		return nil, err
+	}
+	pushConfig, err := m.takePushConfig(&request)
+	if err != nil {
+		return nil, err
+	}
+	a2aTasksInFlight.WithLabelValues(m.agentRef).Inc()
+	defer a2aTasksInFlight.WithLabelValues(m.agentRef).Dec()
+	ctx, done := m.startRequest(ctx, protocol.MethodMessageSend)
+	result, err := m.client.SendMessage(ctx, request)
+	done(err)
+	if err == nil && result != nil {
+		observer := m.observer(pushConfig)
+		observer.observe(ctx, result.Result)
+		observer.close()
+	}
+	return result, err
 }
 
 func (m *PassthroughManager) OnSendMessageStream(ctx context.Context, request protocol.SendMessageParams) (<-chan protocol.StreamingMessageEvent, error) {
@@ -35,25 +80,328 @@ func (m *PassthroughManager) OnSendMessageStream(ctx context.Context, request pr
 	if request.Message.Kind == "" {
 		request.Message.Kind = protocol.KindMessage
 	}
-	return m.client.StreamMessage(ctx, request)
+	if err := m.sessions.check(ctx, request.Message); err != nil {
+		return nil, err
+	}
+	pushConfig, err := m.takePushConfig(&request)
+	if err != nil {
+		return nil, err
+	}
+	return m.proxyStream(ctx, protocol.MethodMessageStream, m.observer(pushConfig), func(ctx context.Context) (<-chan protocol.StreamingMessageEvent, error) {
+		return m.client.StreamMessage(ctx, request)
+	})
 }
 
 func (m *PassthroughManager) OnGetTask(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error) {
-	return m.client.GetTasks(ctx, params)
+	if err := m.sessions.checkTask(ctx, m.agentRef, params.ID); err != nil {
+		return nil, err
+	}
+	ctx, done := m.startRequest(ctx, protocol.MethodTasksGet)
+	task, err := m.client.GetTasks(ctx, params)
+	done(err)
+	if err != nil && m.tasks != nil {
+		if stored, storeErr := m.tasks.Get(ctx, params); storeErr == nil {
+			ctrllog.FromContext(ctx).V(1).Info("Serving stored task, as the agent failed to", "agent", m.agentRef, "task", params.ID, "error", err.Error())
+			return stored, nil
+		}
+	}
+	return task, err
 }
 
 func (m *PassthroughManager) OnCancelTask(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error) {
-	return m.client.CancelTasks(ctx, params)
+	if err := m.sessions.checkTask(ctx, m.agentRef, params.ID); err != nil {
+		return nil, err
+	}
+	ctx, done := m.startRequest(ctx, protocol.MethodTasksCancel)
+	task, err := m.client.CancelTasks(ctx, params)
+	done(err)
+	if err == nil && task != nil {
+		observer := m.observer(nil)
+		observer.observe(ctx, task)
+		observer.close()
+	}
+	return task, err
 }
 
 func (m *PassthroughManager) OnPushNotificationSet(ctx context.Context, params protocol.TaskPushNotificationConfig) (*protocol.TaskPushNotificationConfig, error) {
+	if err := m.sessions.checkTask(ctx, m.agentRef, params.TaskID); err != nil {
+		return nil, err
+	}
+	if m.push != nil {
+		return m.push.SetConfig(ctx, params)
+	}
 	return m.client.SetPushNotification(ctx, params)
 }
 
 func (m *PassthroughManager) OnPushNotificationGet(ctx context.Context, params protocol.TaskIDParams) (*protocol.TaskPushNotificationConfig, error) {
+	if err := m.sessions.checkTask(ctx, m.agentRef, params.ID); err != nil {
+		return nil, err
+	}
+	if m.push != nil {
+		return m.push.GetConfig(ctx, params)
+	}
 	return m.client.GetPushNotification(ctx, params)
 }
 
+// OnResubscribe attaches to the agent's stream of a task. With a task store,
+// the stored events of the task are replayed first, from the sequence number
+// set in the kagent_from_sequence metadata of params, and they are all the
+// stream has if the task ended or the agent can't resubscribe to it, e.g. as it
+// restarted since. Events stored between the replay and attaching to the
+// agent's stream are missed.
 func (m *PassthroughManager) OnResubscribe(ctx context.Context, params protocol.TaskIDParams) (<-chan protocol.StreamingMessageEvent, error) {
-	return m.client.ResubscribeTask(ctx, params)
+	if err := m.sessions.checkTask(ctx, m.agentRef, params.ID); err != nil {
+		return nil, err
+	}
+	if m.tasks == nil {
+		return m.proxyStream(ctx, protocol.MethodTasksResubscribe, m.observer(nil), func(ctx context.Context) (<-chan protocol.StreamingMessageEvent, error) {
+			return m.client.ResubscribeTask(ctx, params)
+		})
+	}
+
+	replay, _, err := m.tasks.Replay(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	stored, _ := m.tasks.Get(ctx, protocol.TaskQueryParams{ID: params.ID})
+	return m.proxyStream(ctx, protocol.MethodTasksResubscribe, m.observer(nil), func(ctx context.Context) (<-chan protocol.StreamingMessageEvent, error) {
+		if (len(replay) > 0 && isFinalEvent(replay[len(replay)-1].Result)) || (stored != nil && isTerminalState(stored.Status.State)) {
+			return replayThen(ctx, replay, nil), nil
+		}
+		live, err := m.client.ResubscribeTask(ctx, params)
+		if err != nil {
+			if len(replay) == 0 && stored == nil {
+				return nil, err
+			}
+			ctrllog.FromContext(ctx).V(1).Info("Replaying stored task events only, as the agent failed to resubscribe", "agent", m.agentRef, "task", params.ID, "error", err.Error())
+			return replayThen(ctx, replay, nil), nil
+		}
+		return replayThen(ctx, replay, live), nil
+	})
+}
+
+// replayThen returns a stream of the replayed events, followed by those of
+// live if set.
+func replayThen(ctx context.Context, replay []protocol.StreamingMessageEvent, live <-chan protocol.StreamingMessageEvent) <-chan protocol.StreamingMessageEvent {
+	out := make(chan protocol.StreamingMessageEvent, len(replay))
+	for _, event := range replay {
+		out <- event
+	}
+	if live == nil {
+		close(out)
+		return out
+	}
+	go func() {
+		defer close(out)
+		for event := range live {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// startRequest starts the span of a request proxied to the agent. The returned
+// function ends it and records the request's metrics.
+func (m *PassthroughManager) startRequest(ctx context.Context, method string) (context.Context, func(error)) {
+	ctx, span := m.startSpan(ctx, method)
+	return ctx, func(err error) {
+		observeRequest(m.agentRef, method, err)
+		tracing.End(span, err)
+	}
+}
+
+func (m *PassthroughManager) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "a2a "+method, trace.WithAttributes(
+		attribute.String("kagent.agent", m.agentRef),
+		attribute.String("rpc.method", method),
+	))
+}
+
+// proxyStream opens a stream with open and forwards its events, recording
+// their usage and the stream's metrics. The stream counts as in flight, and
+// its span lasts, until it closes. A stream the agent closes before its final
+// event counts as an interrupted upstream error, as the A2A client drops read
+// errors silently. The stream's events go through observer, which is closed
+// with the stream.
+func (m *PassthroughManager) proxyStream(ctx context.Context, method string, observer *eventObserver, open func(context.Context) (<-chan protocol.StreamingMessageEvent, error)) (<-chan protocol.StreamingMessageEvent, error) {
+	start := time.Now()
+	inFlight := a2aTasksInFlight.WithLabelValues(m.agentRef)
+	inFlight.Inc()
+
+	ctx, span := m.startSpan(ctx, method)
+	events, err := open(ctx)
+	observeRequest(m.agentRef, method, err)
+	if err != nil {
+		observer.close()
+		inFlight.Dec()
+		tracing.End(span, err)
+		return nil, err
+	}
+
+	out := make(chan protocol.StreamingMessageEvent, cap(events))
+	go func() {
+		defer close(out)
+		defer inFlight.Dec()
+		defer observer.close()
+		defer func() { a2aStreamDuration.WithLabelValues(m.agentRef).Observe(time.Since(start).Seconds()) }()
+
+		var streamErr error
+		defer func() { tracing.End(span, streamErr) }()
+
+		count, final := 0, false
+		for event := range events {
+			if count == 0 {
+				a2aTimeToFirstEvent.WithLabelValues(m.agentRef).Observe(time.Since(start).Seconds())
+				span.AddEvent("first event")
+			}
+			count++
+			span.SetAttributes(attribute.Int("kagent.a2a.events", count))
+			final = final || isFinalEvent(event.Result)
+			observer.observe(ctx, event.Result)
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !final && ctx.Err() == nil {
+			a2aUpstreamErrors.WithLabelValues(m.agentRef, upstreamErrorStreamInterrupted).Inc()
+			streamErr = errStreamInterrupted
+		}
+	}()
+	return out, nil
+}
+
+// isFinalEvent reports whether a streamed event ends the stream.
+func isFinalEvent(result any) bool {
+	switch event := result.(type) {
+	case *protocol.TaskStatusUpdateEvent:
+		return event.IsFinal()
+	case *protocol.Message, *protocol.Task:
+		return true
+	default:
+		return false
+	}
+}
+
+// takePushConfig removes the push notification config from a request, as the
+// controller serves push notifications instead of the agent, and validates it.
+func (m *PassthroughManager) takePushConfig(request *protocol.SendMessageParams) (*protocol.PushNotificationConfig, error) {
+	if m.push == nil || request.Configuration == nil || request.Configuration.PushNotificationConfig == nil {
+		return nil, nil
+	}
+	configuration := *request.Configuration
+	pushConfig := configuration.PushNotificationConfig
+	configuration.PushNotificationConfig = nil
+	request.Configuration = &configuration
+	if err := m.push.validateConfig(*pushConfig); err != nil {
+		return nil, err
+	}
+	return pushConfig, nil
+}
+
+// eventObserver handles the events of the agent answering one request.
+type eventObserver struct {
+	m          *PassthroughManager
+	pushConfig *protocol.PushNotificationConfig
+	recorder   *taskRecorder
+}
+
+// observer returns an observer of the events answering a request. pushConfig,
+// if set, is registered for the task of the first event that has one.
+func (m *PassthroughManager) observer(pushConfig *protocol.PushNotificationConfig) *eventObserver {
+	o := &eventObserver{m: m, pushConfig: pushConfig}
+	if m.tasks != nil {
+		o.recorder = m.tasks.recorder()
+	}
+	return o
+}
+
+// observe records the usage of an event of the agent, stores it, and notifies
+// the state transition it carries. Events replayed from the task store were
+// handled when first streamed, so they are skipped.
+func (o *eventObserver) observe(ctx context.Context, result any) {
+	if eventSequence(result) > 0 {
+		return
+	}
+	m := o.m
+	m.recordUsage(ctx, result)
+	if o.pushConfig != nil {
+		if taskID := eventTaskID(result); taskID != "" {
+			if _, err := m.push.SetConfig(ctx, protocol.TaskPushNotificationConfig{TaskID: taskID, PushNotificationConfig: *o.pushConfig}); err != nil {
+				ctrllog.FromContext(ctx).Error(err, "Failed to set push notification config", "agent", m.agentRef, "task", taskID)
+			}
+			o.pushConfig = nil
+		}
+	}
+	if o.recorder != nil {
+		o.recorder.record(ctx, result)
+	}
+	if m.push != nil {
+		m.push.Observe(ctx, m.agentRef, result)
+	}
+}
+
+// close releases the task the observer records, if any.
+func (o *eventObserver) close() {
+	if o.recorder != nil {
+		o.recorder.release()
+	}
+}
+
+// eventTaskID returns the ID of the task of an event, if any.
+func eventTaskID(result any) string {
+	switch event := result.(type) {
+	case *protocol.TaskStatusUpdateEvent:
+		return event.TaskID
+	case *protocol.TaskArtifactUpdateEvent:
+		return event.TaskID
+	case *protocol.Task:
+		return event.ID
+	case *protocol.Message:
+		if event.TaskID != nil {
+			return *event.TaskID
+		}
+	}
+	return ""
+}
+
+func (m *PassthroughManager) recordUsage(ctx context.Context, result any) {
+	if m.usage == nil {
+		return
+	}
+
+	var taskID, contextID string
+	var metadata map[string]any
+	switch event := result.(type) {
+	case *protocol.TaskStatusUpdateEvent:
+		taskID, contextID, metadata = event.TaskID, event.ContextID, event.Metadata
+	case *protocol.TaskArtifactUpdateEvent:
+		taskID, contextID, metadata = event.TaskID, event.ContextID, event.Metadata
+	case *protocol.Task:
+		taskID, contextID, metadata = event.ID, event.ContextID, event.Metadata
+	case *protocol.Message:
+		if event.TaskID != nil {
+			taskID = *event.TaskID
+		}
+		if event.ContextID != nil {
+			contextID = *event.ContextID
+		}
+		metadata = event.Metadata
+	}
+	if len(metadata) == 0 {
+		return
+	}
+
+	var userID string
+	if session, ok := auth.AuthSessionFrom(ctx); ok && session != nil {
+		userID = session.Principal().User.ID
+	}
+	if err := m.usage.RecordA2AMetadata(ctx, m.agentRef, userID, taskID, contextID, metadata); err != nil {
+		ctrllog.FromContext(ctx).Error(err, "Failed to record token usage", "agent", m.agentRef, "task", taskID)
+	}
 }
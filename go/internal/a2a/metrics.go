@@ -0,0 +1,122 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Types of upstream errors, as reported by the type label of
+// kagent_a2a_upstream_errors_total.
+const (
+	upstreamErrorTimeout    = "timeout"
+	upstreamErrorCanceled   = "canceled"
+	upstreamErrorConnection = "connection"
+	upstreamErrorHTTPStatus = "http_status"
+	upstreamErrorRPC        = "rpc"
+	upstreamErrorDecode     = "decode"
+	upstreamErrorOther      = "other"
+	// upstreamErrorStreamInterrupted is a stream closed before its final event.
+	upstreamErrorStreamInterrupted = "stream_interrupted"
+)
+
+var (
+	a2aRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_a2a_requests_total",
+		Help: "Number of A2A requests proxied to agents, by agent, method and result.",
+	}, []string{"agent", "method", "result"})
+	a2aTasksInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kagent_a2a_tasks_in_flight",
+		Help: "Number of A2A requests currently being proxied to agents, including open streams, by agent.",
+	}, []string{"agent"})
+	a2aStreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kagent_a2a_stream_duration_seconds",
+		Help:    "Duration of A2A streams proxied from agents, from the request until the stream closes, by agent.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800},
+	}, []string{"agent"})
+	a2aTimeToFirstEvent = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kagent_a2a_time_to_first_event_seconds",
+		Help:    "Time from an A2A streaming request until the agent's first event, by agent.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"agent"})
+	a2aLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_a2a_limited_requests_total",
+		Help: "Number of A2A requests rejected for exceeding a limit, by agent and limit.",
+	}, []string{"agent", "limit"})
+	a2aLimiterStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kagent_a2a_limiter_streams",
+		Help: "Number of streaming A2A tasks counted towards the concurrency limits of an agent, by agent.",
+	}, []string{"agent"})
+	a2aPushDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_a2a_push_deliveries_total",
+		Help: "Number of attempts to deliver push notifications of tasks to webhooks, by agent and result (delivered, retried or failed).",
+	}, []string{"agent", "result"})
+	a2aUpstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_a2a_upstream_errors_total",
+		Help: "Number of errors returned by agents or while reaching them, by agent and type (timeout, canceled, connection, http_status, rpc, decode, stream_interrupted or other).",
+	}, []string{"agent", "type"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(a2aRequests, a2aTasksInFlight, a2aStreamDuration, a2aTimeToFirstEvent, a2aUpstreamErrors,
+		a2aLimitedRequests, a2aLimiterStreams, a2aPushDeliveries)
+}
+
+// deleteAgentMetrics removes the series of an agent that's no longer served.
+func deleteAgentMetrics(agentRef string) {
+	labels := prometheus.Labels{"agent": agentRef}
+	a2aRequests.DeletePartialMatch(labels)
+	a2aTasksInFlight.DeletePartialMatch(labels)
+	a2aStreamDuration.DeletePartialMatch(labels)
+	a2aTimeToFirstEvent.DeletePartialMatch(labels)
+	a2aUpstreamErrors.DeletePartialMatch(labels)
+	a2aLimitedRequests.DeletePartialMatch(labels)
+	a2aLimiterStreams.DeletePartialMatch(labels)
+	a2aPushDeliveries.DeletePartialMatch(labels)
+}
+
+// observeRequest counts a proxied request and, if it failed, its upstream error.
+func observeRequest(agentRef, method string, err error) {
+	if err == nil {
+		a2aRequests.WithLabelValues(agentRef, method, "success").Inc()
+		return
+	}
+	a2aRequests.WithLabelValues(agentRef, method, "error").Inc()
+	a2aUpstreamErrors.WithLabelValues(agentRef, upstreamErrorType(err)).Inc()
+}
+
+// upstreamErrorType classifies an error of the A2A client. The client wraps
+// most errors with fmt.Errorf, so it falls back to matching their messages.
+func upstreamErrorType(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return upstreamErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return upstreamErrorCanceled
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return upstreamErrorTimeout
+		}
+		return upstreamErrorConnection
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "jsonrpc error"):
+		return upstreamErrorRPC
+	case strings.Contains(msg, "unexpected http status"):
+		return upstreamErrorHTTPStatus
+	case strings.Contains(msg, "http request failed"):
+		return upstreamErrorConnection
+	case strings.Contains(msg, "failed to decode"), strings.Contains(msg, "failed to unmarshal"),
+		strings.Contains(msg, "missing required 'result'"), strings.Contains(msg, "did not respond with Content-Type"):
+		return upstreamErrorDecode
+	default:
+		return upstreamErrorOther
+	}
+}
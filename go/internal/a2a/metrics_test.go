@@ -0,0 +1,68 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+func TestUpstreamErrorType(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want string
+	}{
+		"deadline":          {fmt.Errorf("a2aClient.SendMessage: %w", context.DeadlineExceeded), upstreamErrorTimeout},
+		"canceled":          {context.Canceled, upstreamErrorCanceled},
+		"dial":              {fmt.Errorf("http request failed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), upstreamErrorConnection},
+		"http status":       {errors.New("a2aClient.SendMessage: unexpected http status 502: bad gateway"), upstreamErrorHTTPStatus},
+		"json-rpc error":    {errors.New("a2aClient.SendMessage: jsonrpc error -32001: task not found"), upstreamErrorRPC},
+		"bad response body": {errors.New("failed to unmarshal rpc result: unexpected end of JSON input"), upstreamErrorDecode},
+		"other":             {errors.New("boom"), upstreamErrorOther},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, upstreamErrorType(tc.err))
+		})
+	}
+}
+
+func TestProxyStreamMetrics(t *testing.T) {
+	stream := func(t *testing.T, agentRef string, events ...protocol.StreamingMessageEvent) {
+		t.Helper()
+		m := &PassthroughManager{agentRef: agentRef}
+		upstream := make(chan protocol.StreamingMessageEvent, len(events))
+		for _, event := range events {
+			upstream <- event
+		}
+		close(upstream)
+
+		out, err := m.proxyStream(context.Background(), protocol.MethodMessageStream, m.observer(nil), func(context.Context) (<-chan protocol.StreamingMessageEvent, error) {
+			return upstream, nil
+		})
+		require.NoError(t, err)
+		for range out {
+		}
+		assert.Eventually(t, func() bool {
+			return testutil.ToFloat64(a2aTasksInFlight.WithLabelValues(agentRef)) == 0
+		}, time.Second, 10*time.Millisecond)
+	}
+	working := protocol.StreamingMessageEvent{Result: &protocol.TaskStatusUpdateEvent{Status: protocol.TaskStatus{State: protocol.TaskStateWorking}}}
+	completed := protocol.StreamingMessageEvent{Result: &protocol.TaskStatusUpdateEvent{Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}, Final: true}}
+
+	stream(t, "default/complete", working, completed)
+	assert.Equal(t, 1.0, testutil.ToFloat64(a2aRequests.WithLabelValues("default/complete", protocol.MethodMessageStream, "success")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(a2aUpstreamErrors.WithLabelValues("default/complete", upstreamErrorStreamInterrupted)))
+
+	stream(t, "default/interrupted", working)
+	assert.Equal(t, 1.0, testutil.ToFloat64(a2aUpstreamErrors.WithLabelValues("default/interrupted", upstreamErrorStreamInterrupted)))
+
+	deleteAgentMetrics("default/interrupted")
+	assert.Equal(t, 0.0, testutil.ToFloat64(a2aUpstreamErrors.WithLabelValues("default/interrupted", upstreamErrorStreamInterrupted)))
+}
@@ -0,0 +1,464 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// Authentication schemes of push notification configs, which select how the
+// controller signs webhook requests. A config can list both.
+const (
+	// pushSchemeBearer signs requests with a JWT in the Authorization header,
+	// holding the SHA-256 of the body and verifiable with the keys served at the
+	// JWKS endpoint of the agent. The A2A server adds it to every config.
+	pushSchemeBearer = "bearer"
+	// pushSchemeHMAC signs requests with an HMAC-SHA256 of the timestamp and the
+	// body, keyed with the credentials of the config.
+	pushSchemeHMAC = "hmac"
+)
+
+// Headers of webhook requests.
+const (
+	pushTokenHeader     = "X-A2A-Notification-Token"
+	pushTimestampHeader = "X-Kagent-Timestamp"
+	pushSignatureHeader = "X-Kagent-Signature"
+)
+
+// Results of delivery attempts, as reported by the result label of
+// kagent_a2a_push_deliveries_total.
+const (
+	pushResultDelivered = "delivered"
+	pushResultRetried   = "retried"
+	pushResultFailed    = "failed"
+)
+
+// pushStateTTL is how long the last notified state of a task is remembered
+// without a transition, so tasks that never end don't accumulate.
+const pushStateTTL = 24 * time.Hour
+
+// PushOptions configures the delivery of push notifications.
+type PushOptions struct {
+	// MaxAttempts is the number of times a notification is sent to a webhook
+	// before it's given up.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubled for each
+	// further retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout is the timeout of each attempt.
+	Timeout time.Duration
+	// AllowedHosts are the hosts of webhooks that may be cluster-internal,
+	// private or link-local addresses, which are otherwise rejected.
+	AllowedHosts []string
+}
+
+type pushState struct {
+	state    protocol.TaskState
+	notified time.Time
+}
+
+// PushNotifier owns the push notifications of the tasks proxied to agents. It
+// stores the configs set by clients, watches the task state transitions flowing
+// through the proxy, and delivers each transition to the webhooks of the task's
+// configs, retrying failed deliveries with exponential backoff. Every attempt is
+// recorded in the delivery log.
+//
+// The JWT signing key is generated at startup, so receivers should look up
+// unknown key IDs at the JWKS endpoint again.
+type PushNotifier struct {
+	db      database.Client
+	signer  *auth.PushNotificationAuthenticator
+	client  *http.Client
+	options PushOptions
+
+	mu        sync.Mutex
+	states    map[string]pushState
+	queues    map[string]chan struct{}
+	lastSweep time.Time
+	wg        sync.WaitGroup
+}
+
+// NewPushNotifier creates a notifier storing configs and deliveries with db.
+func NewPushNotifier(db database.Client, options PushOptions) (*PushNotifier, error) {
+	signer := auth.NewPushNotificationAuthenticator()
+	if err := signer.GenerateKeyPair(); err != nil {
+		return nil, fmt.Errorf("failed to generate push notification signing key: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialPublicContext
+	return &PushNotifier{
+		db:      db,
+		signer:  signer,
+		client:  &http.Client{Timeout: options.Timeout, Transport: transport},
+		options: options,
+		states:  make(map[string]pushState),
+		queues:  make(map[string]chan struct{}),
+	}, nil
+}
+
+// Authenticator returns the authenticator signing JWTs, whose keys the A2A
+// servers of agents serve at their JWKS endpoint.
+func (n *PushNotifier) Authenticator() *auth.PushNotificationAuthenticator {
+	return n.signer
+}
+
+// SetConfig validates and stores the push notification config of a task,
+// assigning it an ID if it has none.
+func (n *PushNotifier) SetConfig(ctx context.Context, config protocol.TaskPushNotificationConfig) (*protocol.TaskPushNotificationConfig, error) {
+	if err := n.validateConfig(config.PushNotificationConfig); err != nil {
+		return nil, err
+	}
+	if config.PushNotificationConfig.ID == "" {
+		config.PushNotificationConfig.ID = uuid.NewString()
+	}
+	if err := n.db.WithContext(ctx).StorePushNotification(&config); err != nil {
+		return nil, fmt.Errorf("failed to store push notification config: %w", err)
+	}
+	return &config, nil
+}
+
+// GetConfig returns the push notification config of a task with the ID set in
+// the pushNotificationConfigId metadata of params, or its first config.
+func (n *PushNotifier) GetConfig(ctx context.Context, params protocol.TaskIDParams) (*protocol.TaskPushNotificationConfig, error) {
+	if configID, ok := params.Metadata["pushNotificationConfigId"].(string); ok && configID != "" {
+		return n.db.WithContext(ctx).GetPushNotification(params.ID, configID)
+	}
+	configs, err := n.db.WithContext(ctx).ListPushNotifications(params.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push notification configs: %w", err)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no push notification config found for task %s", params.ID)
+	}
+	return configs[0], nil
+}
+
+func (n *PushNotifier) validateConfig(config protocol.PushNotificationConfig) error {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("push notification URL must be an absolute http or https URL: %q", config.URL)
+	}
+	if !n.allowsInternalHost(u.Hostname()) && isInternalHost(u.Hostname()) {
+		return fmt.Errorf("push notification URL must not be a cluster-internal, private or link-local address: %q", config.URL)
+	}
+	if hasPushScheme(config, pushSchemeHMAC) && pushHMACSecret(config) == "" {
+		return errors.New("push notification credentials are required by the hmac scheme")
+	}
+	return nil
+}
+
+// allowsInternalHost reports whether host is one of the allowed hosts that may be internal.
+func (n *PushNotifier) allowsInternalHost(host string) bool {
+	return slices.ContainsFunc(n.options.AllowedHosts, func(allowed string) bool {
+		return strings.EqualFold(allowed, host)
+	})
+}
+
+// isInternalHost reports whether host is a cluster-internal name, e.g. of a
+// service, or an address that isn't publicly routable.
+func isInternalHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return isInternalIP(ip)
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	// Names without a dot resolve with the search domains of the cluster
+	return !strings.Contains(host, ".") ||
+		host == "localhost" ||
+		strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") ||
+		strings.HasSuffix(host, ".svc") ||
+		strings.HasSuffix(host, ".internal")
+}
+
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+type allowInternalKey struct{}
+
+// dialPublicContext dials addresses that aren't internal, unless the context
+// allows them, so that webhook hosts can't resolve to internal addresses.
+func dialPublicContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if allowed, _ := ctx.Value(allowInternalKey{}).(bool); !allowed {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+				return fmt.Errorf("push notification webhook address %s is not allowed", host)
+			}
+			return nil
+		}
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+func hasPushScheme(config protocol.PushNotificationConfig, scheme string) bool {
+	if config.Authentication == nil {
+		return false
+	}
+	return slices.ContainsFunc(config.Authentication.Schemes, func(s string) bool {
+		return strings.EqualFold(s, scheme)
+	})
+}
+
+func pushHMACSecret(config protocol.PushNotificationConfig) string {
+	if config.Authentication == nil || config.Authentication.Credentials == nil {
+		return ""
+	}
+	return *config.Authentication.Credentials
+}
+
+// Observe notifies the state transition of the task an event of the agent
+// agentRef carries, if any. Events not changing the state of their task are
+// ignored. Deliveries run in the background, in order for each task.
+func (n *PushNotifier) Observe(ctx context.Context, agentRef string, result any) {
+	var task protocol.Task
+	switch event := result.(type) {
+	case *protocol.TaskStatusUpdateEvent:
+		task = protocol.Task{
+			ID:        event.TaskID,
+			ContextID: event.ContextID,
+			Kind:      protocol.KindTask,
+			Status:    event.Status,
+			Metadata:  event.Metadata,
+		}
+	case *protocol.Task:
+		task = *event
+	default:
+		return
+	}
+	if task.ID == "" || !n.transition(task.ID, task.Status.State) {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	n.enqueue(task.ID, func() { n.notify(ctx, agentRef, task) })
+}
+
+// transition records the state of a task, reporting whether it changed.
+func (n *PushNotifier) transition(taskID string, state protocol.TaskState) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(n.lastSweep) >= time.Hour {
+		n.lastSweep = now
+		for id, s := range n.states {
+			if now.Sub(s.notified) > pushStateTTL {
+				delete(n.states, id)
+			}
+		}
+	}
+
+	if last, ok := n.states[taskID]; ok && last.state == state {
+		return false
+	}
+	if isTerminalState(state) {
+		delete(n.states, taskID)
+	} else {
+		n.states[taskID] = pushState{state: state, notified: now}
+	}
+	return true
+}
+
+func isTerminalState(state protocol.TaskState) bool {
+	switch state {
+	case protocol.TaskStateCompleted, protocol.TaskStateCanceled, protocol.TaskStateFailed, protocol.TaskStateRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueue runs deliver once the deliveries enqueued before for the task are done.
+func (n *PushNotifier) enqueue(taskID string, deliver func()) {
+	n.mu.Lock()
+	previous := n.queues[taskID]
+	done := make(chan struct{})
+	n.queues[taskID] = done
+	n.mu.Unlock()
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if previous != nil {
+			<-previous
+		}
+		deliver()
+		close(done)
+
+		n.mu.Lock()
+		if n.queues[taskID] == done {
+			delete(n.queues, taskID)
+		}
+		n.mu.Unlock()
+	}()
+}
+
+// wait waits for the enqueued deliveries.
+func (n *PushNotifier) wait() {
+	n.wg.Wait()
+}
+
+// notify delivers the state of task to the webhooks of all its configs.
+func (n *PushNotifier) notify(ctx context.Context, agentRef string, task protocol.Task) {
+	log := ctrllog.FromContext(ctx).WithValues("agent", agentRef, "task", task.ID, "state", task.Status.State)
+
+	configs, err := n.db.WithContext(ctx).ListPushNotifications(task.ID)
+	if err != nil {
+		log.Error(err, "Failed to list push notification configs")
+		return
+	}
+	if len(configs) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(task)
+	if err != nil {
+		log.Error(err, "Failed to serialize push notification")
+		return
+	}
+	for _, config := range configs {
+		n.deliver(ctx, agentRef, task, config.PushNotificationConfig, body)
+	}
+}
+
+// deliver sends body to the webhook of config until it's accepted or the
+// attempts run out, recording each attempt.
+func (n *PushNotifier) deliver(ctx context.Context, agentRef string, task protocol.Task, config protocol.PushNotificationConfig, body []byte) {
+	log := ctrllog.FromContext(ctx).WithValues("agent", agentRef, "task", task.ID, "state", task.Status.State, "config", config.ID)
+
+	backoff := n.options.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		statusCode, err := n.post(ctx, agentRef, config, body)
+
+		delivery := &database.PushNotificationDelivery{
+			ID:         uuid.NewString(),
+			TaskID:     task.ID,
+			ConfigID:   config.ID,
+			AgentID:    agentRef,
+			URL:        config.URL,
+			State:      string(task.Status.State),
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Delivered:  err == nil,
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if storeErr := n.db.WithContext(ctx).StorePushNotificationDelivery(delivery); storeErr != nil {
+			log.Error(storeErr, "Failed to record push notification delivery")
+		}
+
+		switch {
+		case err == nil:
+			a2aPushDeliveries.WithLabelValues(agentRef, pushResultDelivered).Inc()
+			log.V(1).Info("Delivered push notification", "attempt", attempt)
+			return
+		case attempt >= n.options.MaxAttempts || !isRetryableStatus(statusCode):
+			a2aPushDeliveries.WithLabelValues(agentRef, pushResultFailed).Inc()
+			log.Error(err, "Failed to deliver push notification", "attempt", attempt)
+			return
+		}
+		a2aPushDeliveries.WithLabelValues(agentRef, pushResultRetried).Inc()
+		log.V(1).Info("Retrying push notification", "attempt", attempt, "backoff", backoff, "error", err.Error())
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(2*backoff, n.options.MaxBackoff)
+	}
+}
+
+// isRetryableStatus reports whether a failed attempt that got statusCode, or no
+// response if 0, may succeed later.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// post sends a signed webhook request, returning the status code of the
+// response, if any.
+func (n *PushNotifier) post(ctx context.Context, agentRef string, config protocol.PushNotificationConfig, body []byte) (statusCode int, err error) {
+	ctx, span := tracing.Start(ctx, "a2a.push", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("kagent.agent", agentRef),
+		attribute.String("url.full", config.URL),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		tracing.End(span, err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create push notification request: %w", err)
+	}
+	// Requests sent through a proxy dial the proxy, which resolves the webhook host
+	if proxy, _ := http.ProxyFromEnvironment(req); proxy != nil || n.allowsInternalHost(req.URL.Hostname()) {
+		req = req.WithContext(context.WithValue(req.Context(), allowInternalKey{}, true))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if config.Token != "" {
+		req.Header.Set(pushTokenHeader, config.Token)
+	}
+	if hasPushScheme(config, pushSchemeBearer) {
+		authorization, err := n.signer.CreateAuthorizationHeader(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to sign push notification: %w", err)
+		}
+		req.Header.Set("Authorization", authorization)
+	}
+	if hasPushScheme(config, pushSchemeHMAC) {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(pushTimestampHeader, timestamp)
+		req.Header.Set(pushSignatureHeader, "sha256="+signPushHMAC(pushHMACSecret(config), timestamp, body))
+	}
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send push notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("push notification rejected with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// signPushHMAC returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+func signPushHMAC(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
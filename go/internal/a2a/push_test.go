@@ -0,0 +1,282 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	"trpc.group/trpc-go/trpc-a2a-go/client"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/server"
+
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+)
+
+type webhookRequest struct {
+	header http.Header
+	task   protocol.Task
+	body   []byte
+}
+
+// newWebhook starts a webhook recording the notifications it receives, which
+// responds with the given status codes, then 200.
+func newWebhook(t *testing.T, statusCodes ...int) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []webhookRequest
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(body, &task))
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, webhookRequest{header: r.Header, task: task, body: body})
+		if len(requests) <= len(statusCodes) {
+			w.WriteHeader(statusCodes[len(requests)-1])
+		}
+	}))
+	t.Cleanup(webhook.Close)
+	return webhook, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func newTestPushNotifier(t *testing.T) *PushNotifier {
+	t.Helper()
+	n, err := NewPushNotifier(database_fake.NewClient(), PushOptions{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Timeout:        time.Second,
+		// webhooks of the tests listen on the loopback address
+		AllowedHosts: []string{"127.0.0.1"},
+	})
+	require.NoError(t, err)
+	return n
+}
+
+func TestPushNotifier(t *testing.T) {
+	t.Run("delivers signed state transitions with retries", func(t *testing.T) {
+		deleteAgentMetrics("default/push")
+		webhook, requests := newWebhook(t, http.StatusServiceUnavailable)
+		n := newTestPushNotifier(t)
+
+		config, err := n.SetConfig(t.Context(), protocol.TaskPushNotificationConfig{
+			TaskID: "task-1",
+			PushNotificationConfig: protocol.PushNotificationConfig{
+				URL:   webhook.URL,
+				Token: "client-token",
+				Authentication: &protocol.AuthenticationInfo{
+					Schemes:     []string{"bearer", "HMAC"},
+					Credentials: ptr.To("secret"),
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, config.PushNotificationConfig.ID)
+
+		status := func(state protocol.TaskState) *protocol.TaskStatusUpdateEvent {
+			return &protocol.TaskStatusUpdateEvent{TaskID: "task-1", ContextID: "ctx-1", Status: protocol.TaskStatus{State: state}}
+		}
+		n.Observe(t.Context(), "default/push", status(protocol.TaskStateWorking))
+		n.Observe(t.Context(), "default/push", status(protocol.TaskStateWorking))
+		n.Observe(t.Context(), "default/push", &protocol.TaskArtifactUpdateEvent{TaskID: "task-1"})
+		n.Observe(t.Context(), "default/push", status(protocol.TaskStateCompleted))
+		n.Observe(t.Context(), "default/push", &protocol.TaskStatusUpdateEvent{TaskID: "task-2", Status: protocol.TaskStatus{State: protocol.TaskStateWorking}})
+		n.wait()
+
+		received := requests()
+		require.Len(t, received, 3)
+		assert.Equal(t, protocol.TaskStateWorking, received[0].task.Status.State)
+		assert.Equal(t, protocol.TaskStateWorking, received[1].task.Status.State)
+		assert.Equal(t, protocol.TaskStateCompleted, received[2].task.Status.State)
+		assert.Equal(t, "ctx-1", received[2].task.ContextID)
+		for _, request := range received {
+			assert.Equal(t, "client-token", request.header.Get(pushTokenHeader))
+			assert.True(t, strings.HasPrefix(request.header.Get("Authorization"), "Bearer "))
+			timestamp := request.header.Get(pushTimestampHeader)
+			assert.Equal(t, "sha256="+signPushHMAC("secret", timestamp, request.body), request.header.Get(pushSignatureHeader))
+		}
+
+		deliveries, err := n.db.ListPushNotificationDeliveries("task-1")
+		require.NoError(t, err)
+		require.Len(t, deliveries, 3)
+		assert.Equal(t, 1, deliveries[0].Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+		assert.False(t, deliveries[0].Delivered)
+		assert.NotEmpty(t, deliveries[0].Error)
+		assert.Equal(t, 2, deliveries[1].Attempt)
+		assert.True(t, deliveries[1].Delivered)
+		assert.Equal(t, string(protocol.TaskStateCompleted), deliveries[2].State)
+		assert.True(t, deliveries[2].Delivered)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(a2aPushDeliveries.WithLabelValues("default/push", pushResultRetried)))
+		assert.Equal(t, 2.0, testutil.ToFloat64(a2aPushDeliveries.WithLabelValues("default/push", pushResultDelivered)))
+	})
+
+	t.Run("gives up on client errors", func(t *testing.T) {
+		deleteAgentMetrics("default/rejected")
+		webhook, requests := newWebhook(t, http.StatusBadRequest)
+		n := newTestPushNotifier(t)
+		_, err := n.SetConfig(t.Context(), protocol.TaskPushNotificationConfig{
+			TaskID:                 "task-1",
+			PushNotificationConfig: protocol.PushNotificationConfig{URL: webhook.URL},
+		})
+		require.NoError(t, err)
+
+		n.Observe(t.Context(), "default/rejected", &protocol.Task{ID: "task-1", Status: protocol.TaskStatus{State: protocol.TaskStateFailed}})
+		n.wait()
+
+		received := requests()
+		require.Len(t, received, 1)
+		assert.Empty(t, received[0].header.Get("Authorization"))
+		assert.Empty(t, received[0].header.Get(pushSignatureHeader))
+		assert.Equal(t, 1.0, testutil.ToFloat64(a2aPushDeliveries.WithLabelValues("default/rejected", pushResultFailed)))
+	})
+
+	t.Run("invalid configs", func(t *testing.T) {
+		n := newTestPushNotifier(t)
+		for name, config := range map[string]protocol.PushNotificationConfig{
+			"relative URL":            {URL: "/webhook"},
+			"unsupported scheme":      {URL: "ftp://example.com/webhook"},
+			"hmac without credential": {URL: "https://example.com/webhook", Authentication: &protocol.AuthenticationInfo{Schemes: []string{"hmac"}}},
+			"loopback address":        {URL: "http://127.0.0.2:8080/webhook"},
+			"localhost":               {URL: "http://localhost:8080/webhook"},
+			"metadata endpoint":       {URL: "http://169.254.169.254/latest/meta-data"},
+			"private address":         {URL: "http://10.0.0.1/webhook"},
+			"IPv6 loopback address":   {URL: "http://[::1]/webhook"},
+			"service":                 {URL: "http://kagent-controller.kagent.svc:8083/webhook"},
+			"service short name":      {URL: "http://kagent-controller/webhook"},
+			"cluster domain":          {URL: "http://kagent-controller.kagent.svc.cluster.local/webhook"},
+		} {
+			_, err := n.SetConfig(t.Context(), protocol.TaskPushNotificationConfig{TaskID: "task-1", PushNotificationConfig: config})
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("does not dial internal addresses", func(t *testing.T) {
+		deleteAgentMetrics("default/internal")
+		webhook, requests := newWebhook(t)
+		n := newTestPushNotifier(t)
+		n.options.AllowedHosts = nil
+		// stored directly, as if the host resolved to a public address when the config was set
+		require.NoError(t, n.db.StorePushNotification(&protocol.TaskPushNotificationConfig{
+			TaskID:                 "task-1",
+			PushNotificationConfig: protocol.PushNotificationConfig{ID: "config-1", URL: webhook.URL},
+		}))
+
+		n.Observe(t.Context(), "default/internal", &protocol.Task{ID: "task-1", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}})
+		n.wait()
+
+		assert.Empty(t, requests())
+		deliveries, err := n.db.ListPushNotificationDeliveries("task-1")
+		require.NoError(t, err)
+		require.NotEmpty(t, deliveries)
+		assert.Contains(t, deliveries[0].Error, "is not allowed")
+	})
+}
+
+func TestHandlerMuxPushNotifications(t *testing.T) {
+	webhook, requests := newWebhook(t)
+
+	// fake agent completing every message it receives in a task
+	var mu sync.Mutex
+	var received []protocol.SendMessageParams
+	agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any                        `json:"id"`
+			Params protocol.SendMessageParams `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		received = append(received, req.Params)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": protocol.Task{
+				Kind:      protocol.KindTask,
+				ID:        "task-1",
+				ContextID: "ctx-1",
+				Status:    protocol.TaskStatus{State: protocol.TaskStateCompleted},
+			},
+		})
+	}))
+	defer agentServer.Close()
+	agentClient, err := client.NewA2AClient(agentServer.URL)
+	require.NoError(t, err)
+
+	n := newTestPushNotifier(t)
+	a := NewA2AHttpMux("/api/a2a", &authimpl.UnsecureAuthenticator{}, nil, n, nil, nil, Limits{})
+	require.NoError(t, a.SetAgentHandler("default/agent", agentClient, server.AgentCard{
+		Name: "agent",
+		URL:  "http://127.0.0.1:8083/api/a2a/default/agent/",
+	}, nil))
+
+	router := mux.NewRouter()
+	router.PathPrefix("/api/a2a/{namespace}/{name}").Handler(a)
+	call := func(method string, params any) map[string]any {
+		body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/a2a/default/agent/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &response))
+		require.Nil(t, response["error"])
+		return response["result"].(map[string]any)
+	}
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/a2a/default/agent/.well-known/agent-card.json", nil))
+	var card server.AgentCard
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &card))
+	assert.Equal(t, ptr.To(true), card.Capabilities.PushNotifications)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/a2a/default/agent/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), "RS256")
+
+	call(protocol.MethodMessageSend, protocol.SendMessageParams{
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		Configuration: &protocol.SendMessageConfiguration{
+			PushNotificationConfig: &protocol.PushNotificationConfig{URL: webhook.URL},
+		},
+	})
+	n.wait()
+
+	require.Len(t, received, 1)
+	assert.Nil(t, received[0].Configuration.PushNotificationConfig, "the agent doesn't serve push notifications")
+	require.Len(t, requests(), 1)
+	assert.Equal(t, protocol.TaskStateCompleted, requests()[0].task.Status.State)
+
+	config := call(protocol.MethodTasksPushNotificationConfigGet, protocol.TaskIDParams{ID: "task-1"})
+	assert.Equal(t, "task-1", config["taskId"])
+
+	config = call(protocol.MethodTasksPushNotificationConfigSet, protocol.TaskPushNotificationConfig{
+		TaskID:                 "task-2",
+		PushNotificationConfig: protocol.PushNotificationConfig{URL: webhook.URL},
+	})
+	pushConfig := config["pushNotificationConfig"].(map[string]any)
+	assert.NotEmpty(t, pushConfig["id"])
+	assert.Contains(t, pushConfig["metadata"], "jwksUrl")
+}
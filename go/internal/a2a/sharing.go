@@ -0,0 +1,114 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/internal/utils"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+// SessionAccess enforces the sharing of sessions on the messages proxied to
+// agents. A message whose ContextID is a session shared with its user
+// continues the session's conversation, which only the owner and the
+// collaborators of the session may do.
+type SessionAccess struct {
+	db database.Client
+}
+
+// NewSessionAccess creates a SessionAccess reading sessions and their shares
+// from db.
+func NewSessionAccess(db database.Client) *SessionAccess {
+	return &SessionAccess{db: db}
+}
+
+// check returns an error if the user of ctx may not add message to the session
+// of its ContextID. Messages without a ContextID, or whose session isn't
+// shared with the user, start or continue a session of the user's own.
+func (s *SessionAccess) check(ctx context.Context, message protocol.Message) error {
+	if s == nil || message.ContextID == nil || *message.ContextID == "" {
+		return nil
+	}
+	session, ok := auth.AuthSessionFrom(ctx)
+	if !ok || session == nil || session.Principal().User.ID == "" {
+		return nil
+	}
+	userID := session.Principal().User.ID
+
+	_, role, err := database.GetSessionForUser(s.db.WithContext(ctx), *message.ContextID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check access to session %s: %w", *message.ContextID, err)
+	}
+	if role == database.SessionRoleViewer {
+		return fmt.Errorf("user %s can only view session %s", userID, *message.ContextID)
+	}
+	return nil
+}
+
+// checkTask returns an error if the caller of ctx may not access the task
+// taskID through the agent agentRef, as its session isn't the user's own or
+// shared with them, or belongs to another agent. Callers without a user may
+// only access the tasks of sessions of their own verified agent.
+func (s *SessionAccess) checkTask(ctx context.Context, agentRef, taskID string) error {
+	if s == nil {
+		return nil
+	}
+	var principal auth.Principal
+	if session, ok := auth.AuthSessionFrom(ctx); ok && session != nil {
+		principal = session.Principal()
+	}
+	if principal.User.ID == "" && principal.Agent.ID == "" {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	task, err := s.db.WithContext(ctx).GetTask(taskID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get task %s: %w", taskID, err)
+	}
+
+	agentID := utils.ConvertToPythonIdentifier(agentRef)
+	if principal.User.ID == "" {
+		return s.checkAgentTask(ctx, task, utils.ConvertToPythonIdentifier(principal.Agent.ID), agentID)
+	}
+
+	taskSession, _, err := database.GetSessionForUser(s.db.WithContext(ctx), task.ContextID, principal.User.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check access to task %s: %w", taskID, err)
+	}
+	if taskSession.AgentID != nil && *taskSession.AgentID != agentID {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	return nil
+}
+
+// checkAgentTask returns an error unless the session of task belongs to the
+// calling agent callerID, which serves it as agentID.
+func (s *SessionAccess) checkAgentTask(ctx context.Context, task *protocol.Task, callerID, agentID string) error {
+	sessions, err := s.db.WithContext(ctx).ListSessionsWithID(task.ContextID)
+	if err != nil {
+		return fmt.Errorf("failed to check access to task %s: %w", task.ID, err)
+	}
+	if len(sessions) == 0 || callerID != agentID {
+		return fmt.Errorf("task %s not found", task.ID)
+	}
+	for _, session := range sessions {
+		if session.AgentID == nil || *session.AgentID != callerID {
+			return fmt.Errorf("task %s not found", task.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package a2a
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+func TestSessionAccess(t *testing.T) {
+	db := database_fake.NewClient()
+	require.NoError(t, db.StoreSession(&database.Session{ID: "session-1", UserID: "owner"}))
+	require.NoError(t, db.StoreSessionShare(&database.SessionShare{SessionID: "session-1", OwnerID: "owner", UserID: "viewer", Role: database.SessionRoleViewer}))
+	require.NoError(t, db.StoreSessionShare(&database.SessionShare{SessionID: "session-1", OwnerID: "owner", UserID: "collaborator", Role: database.SessionRoleCollaborator}))
+	access := NewSessionAccess(db)
+
+	check := func(userID string, contextID *string) error {
+		ctx := auth.AuthSessionTo(context.Background(), &testSession{principal: auth.Principal{User: auth.User{ID: userID}}})
+		return access.check(ctx, protocol.Message{ContextID: contextID})
+	}
+
+	assert.NoError(t, check("owner", ptr.To("session-1")))
+	assert.NoError(t, check("collaborator", ptr.To("session-1")))
+	assert.Error(t, check("viewer", ptr.To("session-1")), "viewers can't continue the conversation")
+	assert.NoError(t, check("viewer", nil), "messages without a context start a new session")
+	assert.NoError(t, check("other", ptr.To("session-1")), "sessions not shared with the user are their own")
+
+	var disabled *SessionAccess
+	assert.NoError(t, disabled.check(context.Background(), protocol.Message{ContextID: ptr.To("session-1")}))
+}
+
+func TestSessionAccess_CheckTask(t *testing.T) {
+	db := database_fake.NewClient()
+	require.NoError(t, db.StoreSession(&database.Session{ID: "session-1", UserID: "owner", AgentID: ptr.To("kagent__NS__k8s_agent")}))
+	require.NoError(t, db.StoreSessionShare(&database.SessionShare{SessionID: "session-1", OwnerID: "owner", UserID: "viewer", Role: database.SessionRoleViewer}))
+	require.NoError(t, db.StoreTask(&protocol.Task{ID: "task-1", ContextID: "session-1"}))
+	access := NewSessionAccess(db)
+
+	checkTask := func(userID, taskID string) error {
+		ctx := auth.AuthSessionTo(context.Background(), &testSession{principal: auth.Principal{User: auth.User{ID: userID}}})
+		return access.checkTask(ctx, "kagent/k8s-agent", taskID)
+	}
+
+	assert.NoError(t, checkTask("owner", "task-1"))
+	assert.NoError(t, checkTask("viewer", "task-1"), "the session is shared with the viewer")
+	assert.ErrorContains(t, checkTask("other", "task-1"), "task task-1 not found")
+	assert.ErrorContains(t, checkTask("owner", "task-2"), "task task-2 not found")
+
+	ctx := auth.AuthSessionTo(context.Background(), &testSession{principal: auth.Principal{User: auth.User{ID: "owner"}}})
+	assert.ErrorContains(t, access.checkTask(ctx, "kagent/other-agent", "task-1"), "task task-1 not found", "tasks are only served by the agent of their session")
+
+	assert.ErrorContains(t, access.checkTask(context.Background(), "kagent/k8s-agent", "task-1"), "task task-1 not found", "callers without a user are denied")
+
+	asAgent := func(agentID string) context.Context {
+		return auth.AuthSessionTo(context.Background(), &testSession{principal: auth.Principal{Agent: auth.Agent{ID: agentID}}})
+	}
+	assert.NoError(t, access.checkTask(asAgent("kagent/k8s-agent"), "kagent/k8s-agent", "task-1"), "agents access the tasks of their own sessions")
+	assert.ErrorContains(t, access.checkTask(asAgent("kagent/helm-agent"), "kagent/k8s-agent", "task-1"), "task task-1 not found")
+	assert.ErrorContains(t, access.checkTask(asAgent("kagent/helm-agent"), "kagent/helm-agent", "task-1"), "task task-1 not found")
+
+	require.NoError(t, db.StoreTask(&protocol.Task{ID: "task-3", ContextID: "no-session"}))
+	assert.ErrorContains(t, access.checkTask(asAgent("kagent/k8s-agent"), "kagent/k8s-agent", "task-3"), "task task-3 not found", "agents don't own tasks without a session")
+}
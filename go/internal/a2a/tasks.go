@@ -0,0 +1,272 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+)
+
+const (
+	// metadataKeyEventSequence is set in the metadata of the task events the
+	// proxy stores, to their sequence number.
+	metadataKeyEventSequence = "kagent_event_sequence"
+	// metadataKeyFromSequence is read from the metadata of tasks/resubscribe
+	// requests, to replay the stored events after that sequence number. All
+	// stored events are replayed without it.
+	metadataKeyFromSequence = "kagent_from_sequence"
+)
+
+// TaskStore persists the tasks proxied to agents and the status and artifact
+// update events streamed for them, so tasks outlive the agent pods serving
+// them: tasks/get falls back to the stored task when the agent can't serve it,
+// and tasks/resubscribe replays the stored events before attaching to the
+// agent's live stream.
+//
+// The events of a task are recorded by the first stream of it through a
+// replica, so streams following the same task don't record its events twice.
+type TaskStore struct {
+	db database.Client
+
+	mu        sync.Mutex
+	recording map[string]bool
+}
+
+// NewTaskStore creates a task store persisting to db.
+func NewTaskStore(db database.Client) *TaskStore {
+	return &TaskStore{
+		db:        db,
+		recording: make(map[string]bool),
+	}
+}
+
+// taskRecorder records the events of one stream.
+type taskRecorder struct {
+	store    *TaskStore
+	taskID   string
+	owner    bool
+	sequence int
+}
+
+func (s *TaskStore) recorder() *taskRecorder {
+	return &taskRecorder{store: s}
+}
+
+// record stores an event of the stream's task and the task updated with it,
+// setting the event's sequence number in its metadata. Store failures are
+// logged, as they mustn't break the stream.
+func (r *taskRecorder) record(ctx context.Context, result any) {
+	taskID := eventTaskID(result)
+	if taskID == "" {
+		return
+	}
+	if r.taskID == "" {
+		r.claim(ctx, taskID)
+	}
+	if !r.owner || taskID != r.taskID {
+		return
+	}
+
+	var kind string
+	switch event := result.(type) {
+	case *protocol.TaskStatusUpdateEvent:
+		kind, event.Metadata = protocol.KindTaskStatusUpdate, withSequence(event.Metadata, r.sequence+1)
+	case *protocol.TaskArtifactUpdateEvent:
+		kind, event.Metadata = protocol.KindTaskArtifactUpdate, withSequence(event.Metadata, r.sequence+1)
+	case *protocol.Task:
+		kind, event.Metadata = protocol.KindTask, withSequence(event.Metadata, r.sequence+1)
+	default:
+		return
+	}
+
+	log := ctrllog.FromContext(ctx).WithValues("task", taskID)
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Error(err, "Failed to serialize task event")
+		return
+	}
+	db := r.store.db.WithContext(ctx)
+	if err := db.StoreTaskEvent(&database.TaskEvent{TaskID: taskID, Sequence: r.sequence + 1, Kind: kind, Data: string(data)}); err != nil {
+		log.Error(err, "Failed to store task event")
+		return
+	}
+	r.sequence++
+
+	if err := r.store.update(db, result); err != nil {
+		log.Error(err, "Failed to store task")
+	}
+}
+
+// claim makes the recorder record the events of taskID, unless another stream
+// of this replica does.
+func (r *taskRecorder) claim(ctx context.Context, taskID string) {
+	r.taskID = taskID
+
+	r.store.mu.Lock()
+	if r.store.recording[taskID] {
+		r.store.mu.Unlock()
+		return
+	}
+	r.store.recording[taskID] = true
+	r.store.mu.Unlock()
+
+	sequence, err := r.store.db.WithContext(ctx).GetLastTaskEventSequence(taskID)
+	if err != nil {
+		ctrllog.FromContext(ctx).Error(err, "Failed to get last task event sequence", "task", taskID)
+		r.release()
+		return
+	}
+	r.owner = true
+	r.sequence = sequence
+}
+
+// release stops the recorder recording its task's events.
+func (r *taskRecorder) release() {
+	if r.taskID == "" {
+		return
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	delete(r.store.recording, r.taskID)
+	r.owner = false
+}
+
+func withSequence(metadata map[string]any, sequence int) map[string]any {
+	if metadata == nil {
+		metadata = make(map[string]any, 1)
+	}
+	metadata[metadataKeyEventSequence] = sequence
+	return metadata
+}
+
+// update stores the task an event belongs to, updated with the event. Tasks are
+// read again for each event, as agents store them too.
+func (s *TaskStore) update(db database.Client, result any) error {
+	var task *protocol.Task
+	switch event := result.(type) {
+	case *protocol.Task:
+		return db.StoreTask(event)
+	case *protocol.TaskStatusUpdateEvent:
+		task = s.storedTask(db, event.TaskID, event.ContextID)
+		task.Status = event.Status
+	case *protocol.TaskArtifactUpdateEvent:
+		task = s.storedTask(db, event.TaskID, event.ContextID)
+		task.Artifacts = mergeArtifact(task.Artifacts, event)
+	default:
+		return nil
+	}
+	return db.StoreTask(task)
+}
+
+// storedTask returns the stored task, or a new one if it isn't stored yet.
+func (s *TaskStore) storedTask(db database.Client, taskID, contextID string) *protocol.Task {
+	task, err := db.GetTask(taskID)
+	if err != nil {
+		return &protocol.Task{ID: taskID, ContextID: contextID, Kind: protocol.KindTask}
+	}
+	return task
+}
+
+// mergeArtifact adds the artifact of an event to artifacts, appending its parts
+// to those of the artifact with the same ID if the event says so, or replacing
+// that artifact.
+func mergeArtifact(artifacts []protocol.Artifact, event *protocol.TaskArtifactUpdateEvent) []protocol.Artifact {
+	for i, artifact := range artifacts {
+		if artifact.ArtifactID != event.Artifact.ArtifactID {
+			continue
+		}
+		if event.Append != nil && *event.Append {
+			artifacts[i].Parts = append(artifacts[i].Parts, event.Artifact.Parts...)
+		} else {
+			artifacts[i] = event.Artifact
+		}
+		return artifacts
+	}
+	return append(artifacts, event.Artifact)
+}
+
+// Get returns the stored task, with at most historyLength messages of its
+// history if set.
+func (s *TaskStore) Get(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error) {
+	task, err := s.db.WithContext(ctx).GetTask(params.ID)
+	if err != nil {
+		return nil, err
+	}
+	if params.HistoryLength != nil && len(task.History) > *params.HistoryLength {
+		task.History = task.History[len(task.History)-max(*params.HistoryLength, 0):]
+	}
+	return task, nil
+}
+
+// Replay returns the stored events of a task after the sequence number set in
+// the kagent_from_sequence metadata of params, and the sequence number of the
+// last one.
+func (s *TaskStore) Replay(ctx context.Context, params protocol.TaskIDParams) ([]protocol.StreamingMessageEvent, int, error) {
+	from, err := fromSequence(params.Metadata)
+	if err != nil {
+		return nil, 0, err
+	}
+	stored, err := s.db.WithContext(ctx).ListTaskEvents(params.ID, from)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]protocol.StreamingMessageEvent, 0, len(stored))
+	last := from
+	for _, e := range stored {
+		var event protocol.StreamingMessageEvent
+		if err := json.Unmarshal([]byte(e.Data), &event); err != nil {
+			return nil, 0, fmt.Errorf("failed to deserialize event %d of task %s: %w", e.Sequence, e.TaskID, err)
+		}
+		events = append(events, event)
+		last = e.Sequence
+	}
+	return events, last, nil
+}
+
+func fromSequence(metadata map[string]any) (int, error) {
+	sequence, err := sequenceOf(metadata[metadataKeyFromSequence])
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", metadataKeyFromSequence, err)
+	}
+	return sequence, nil
+}
+
+// eventSequence returns the sequence number set in the metadata of a stored
+// event, or 0.
+func eventSequence(result any) int {
+	var metadata map[string]any
+	switch event := result.(type) {
+	case *protocol.TaskStatusUpdateEvent:
+		metadata = event.Metadata
+	case *protocol.TaskArtifactUpdateEvent:
+		metadata = event.Metadata
+	case *protocol.Task:
+		metadata = event.Metadata
+	}
+	sequence, _ := sequenceOf(metadata[metadataKeyEventSequence])
+	return sequence
+}
+
+// sequenceOf parses a sequence number of metadata, which is a float64 once
+// decoded from JSON.
+func sequenceOf(value any) (int, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return int(v), nil
+	case int:
+		return v, nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("unexpected sequence number %v", value)
+	}
+}
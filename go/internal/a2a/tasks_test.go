@@ -0,0 +1,237 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	"trpc.group/trpc-go/trpc-a2a-go/client"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+func statusEvent(state protocol.TaskState) protocol.StreamingMessageEvent {
+	return protocol.StreamingMessageEvent{Result: &protocol.TaskStatusUpdateEvent{
+		Kind:      protocol.KindTaskStatusUpdate,
+		TaskID:    "task-1",
+		ContextID: "ctx-1",
+		Status:    protocol.TaskStatus{State: state},
+		Final:     state == protocol.TaskStateCompleted,
+	}}
+}
+
+func artifactEvent(text string, appendParts bool) protocol.StreamingMessageEvent {
+	return protocol.StreamingMessageEvent{Result: &protocol.TaskArtifactUpdateEvent{
+		Kind:      protocol.KindTaskArtifactUpdate,
+		TaskID:    "task-1",
+		ContextID: "ctx-1",
+		Append:    ptr.To(appendParts),
+		Artifact:  protocol.Artifact{ArtifactID: "answer", Parts: []protocol.Part{protocol.NewTextPart(text)}},
+	}}
+}
+
+// streamThrough proxies events through m as the agent's stream, and returns the
+// events the client receives.
+func streamThrough(t *testing.T, m *PassthroughManager, events ...protocol.StreamingMessageEvent) []protocol.StreamingMessageEvent {
+	t.Helper()
+	upstream := make(chan protocol.StreamingMessageEvent, len(events))
+	for _, event := range events {
+		upstream <- event
+	}
+	close(upstream)
+
+	out, err := m.proxyStream(t.Context(), protocol.MethodMessageStream, m.observer(nil), func(context.Context) (<-chan protocol.StreamingMessageEvent, error) {
+		return upstream, nil
+	})
+	require.NoError(t, err)
+	var received []protocol.StreamingMessageEvent
+	for event := range out {
+		received = append(received, event)
+	}
+	return received
+}
+
+func TestTaskStore(t *testing.T) {
+	db := database_fake.NewClient()
+	store := NewTaskStore(db)
+	m := &PassthroughManager{agentRef: "default/tasks", tasks: store}
+
+	received := streamThrough(t, m,
+		statusEvent(protocol.TaskStateWorking),
+		artifactEvent("Hel", false),
+		artifactEvent("lo", true),
+	)
+	require.Len(t, received, 3)
+	for i, event := range received {
+		assert.Equal(t, i+1, eventSequence(event.Result), "events are tagged with their sequence number")
+	}
+
+	task, err := store.Get(t.Context(), protocol.TaskQueryParams{ID: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ctx-1", task.ContextID)
+	assert.Equal(t, protocol.TaskStateWorking, task.Status.State)
+	require.Len(t, task.Artifacts, 1)
+	assert.Len(t, task.Artifacts[0].Parts, 2)
+
+	// a later stream of the task continues its sequence
+	streamThrough(t, m, statusEvent(protocol.TaskStateCompleted))
+	events, err := db.ListTaskEvents("task-1", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, 4, events[3].Sequence)
+	assert.Equal(t, protocol.KindTaskStatusUpdate, events[3].Kind)
+
+	task, err = store.Get(t.Context(), protocol.TaskQueryParams{ID: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+	assert.Len(t, task.Artifacts, 1, "status updates keep the task's artifacts")
+
+	t.Run("replays from a sequence number", func(t *testing.T) {
+		replay, last, err := store.Replay(t.Context(), protocol.TaskIDParams{ID: "task-1", Metadata: map[string]any{metadataKeyFromSequence: 2.0}})
+		require.NoError(t, err)
+		require.Len(t, replay, 2)
+		assert.Equal(t, 4, last)
+		assert.Equal(t, 3, eventSequence(replay[0].Result))
+		artifact, ok := replay[0].Result.(*protocol.TaskArtifactUpdateEvent)
+		require.True(t, ok)
+		assert.Equal(t, "answer", artifact.Artifact.ArtifactID)
+
+		_, _, err = store.Replay(t.Context(), protocol.TaskIDParams{ID: "task-1", Metadata: map[string]any{metadataKeyFromSequence: "first"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("limits the history of stored tasks", func(t *testing.T) {
+		require.NoError(t, db.StoreTask(&protocol.Task{ID: "task-2", History: []protocol.Message{
+			protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("one")}),
+			protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("two")}),
+		}}))
+		task, err := store.Get(t.Context(), protocol.TaskQueryParams{ID: "task-2", HistoryLength: ptr.To(1)})
+		require.NoError(t, err)
+		require.Len(t, task.History, 1)
+		assert.Equal(t, protocol.MessageRoleAgent, task.History[0].Role)
+	})
+}
+
+func TestPassthroughManagerTaskStoreFallback(t *testing.T) {
+	// agent that is unavailable, as if it were restarting
+	var agentRequests atomic.Int32
+	agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agentRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer agentServer.Close()
+	agentClient, err := client.NewA2AClient(agentServer.URL)
+	require.NoError(t, err)
+
+	newManager := func(t *testing.T) *PassthroughManager {
+		deleteAgentMetrics("default/restarting")
+		return NewPassthroughManager(agentClient, "default/restarting", nil, nil, NewTaskStore(database_fake.NewClient()), nil).(*PassthroughManager)
+	}
+	resubscribe := func(t *testing.T, m *PassthroughManager, metadata map[string]any) []protocol.StreamingMessageEvent {
+		t.Helper()
+		out, err := m.OnResubscribe(t.Context(), protocol.TaskIDParams{ID: "task-1", Metadata: metadata})
+		require.NoError(t, err)
+		var received []protocol.StreamingMessageEvent
+		for event := range out {
+			received = append(received, event)
+		}
+		return received
+	}
+
+	t.Run("serves stored tasks", func(t *testing.T) {
+		m := newManager(t)
+		_, err := m.OnGetTask(t.Context(), protocol.TaskQueryParams{ID: "task-1"})
+		assert.Error(t, err, "unknown tasks fail as the agent does")
+
+		streamThrough(t, m, statusEvent(protocol.TaskStateWorking))
+		task, err := m.OnGetTask(t.Context(), protocol.TaskQueryParams{ID: "task-1"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateWorking, task.Status.State)
+	})
+
+	t.Run("replays stored events when the agent can't resubscribe", func(t *testing.T) {
+		m := newManager(t)
+		streamThrough(t, m, statusEvent(protocol.TaskStateWorking), artifactEvent("Hel", false))
+		requests := agentRequests.Load()
+
+		received := resubscribe(t, m, map[string]any{metadataKeyFromSequence: 1})
+		assert.Greater(t, agentRequests.Load(), requests, "the agent is asked to resubscribe to running tasks")
+		require.Len(t, received, 1)
+		assert.Equal(t, 2, eventSequence(received[0].Result))
+
+		_, err := m.OnResubscribe(t.Context(), protocol.TaskIDParams{ID: "unknown"})
+		assert.Error(t, err)
+	})
+
+	t.Run("replays ended tasks without the agent", func(t *testing.T) {
+		m := newManager(t)
+		streamThrough(t, m, statusEvent(protocol.TaskStateWorking), statusEvent(protocol.TaskStateCompleted))
+		requests := agentRequests.Load()
+
+		received := resubscribe(t, m, nil)
+		assert.Equal(t, requests, agentRequests.Load())
+		require.Len(t, received, 2)
+		assert.True(t, isFinalEvent(received[1].Result))
+	})
+}
+
+func TestPassthroughManagerChecksTaskAccess(t *testing.T) {
+	var agentRequests atomic.Int32
+	agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agentRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer agentServer.Close()
+	agentClient, err := client.NewA2AClient(agentServer.URL)
+	require.NoError(t, err)
+
+	db := database_fake.NewClient()
+	require.NoError(t, db.StoreSession(&database.Session{ID: "ctx-1", UserID: "owner", AgentID: ptr.To("default__NS__tasks")}))
+	store := NewTaskStore(db)
+	deleteAgentMetrics("default/tasks")
+	m := NewPassthroughManager(agentClient, "default/tasks", nil, nil, store, NewSessionAccess(db)).(*PassthroughManager)
+	streamThrough(t, m, statusEvent(protocol.TaskStateWorking), statusEvent(protocol.TaskStateCompleted))
+
+	as := func(userID string) context.Context {
+		return auth.AuthSessionTo(t.Context(), &testSession{principal: auth.Principal{User: auth.User{ID: userID}}})
+	}
+
+	task, err := m.OnGetTask(as("owner"), protocol.TaskQueryParams{ID: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+
+	requests := agentRequests.Load()
+	_, err = m.OnGetTask(as("other"), protocol.TaskQueryParams{ID: "task-1"})
+	assert.ErrorContains(t, err, "task task-1 not found")
+	_, err = m.OnCancelTask(as("other"), protocol.TaskIDParams{ID: "task-1"})
+	assert.ErrorContains(t, err, "task task-1 not found")
+	_, err = m.OnResubscribe(as("other"), protocol.TaskIDParams{ID: "task-1"})
+	assert.ErrorContains(t, err, "task task-1 not found")
+	_, err = m.OnGetTask(t.Context(), protocol.TaskQueryParams{ID: "task-1"})
+	assert.ErrorContains(t, err, "task task-1 not found", "callers without a user are denied")
+	assert.Equal(t, requests, agentRequests.Load(), "tasks of other users aren't proxied")
+
+	other := NewPassthroughManager(agentClient, "default/other", nil, nil, store, NewSessionAccess(db))
+	_, err = other.OnGetTask(as("owner"), protocol.TaskQueryParams{ID: "task-1"})
+	assert.ErrorContains(t, err, "task task-1 not found", "tasks are only served by the agent of their session")
+}
+
+func TestReplayThen(t *testing.T) {
+	live := make(chan protocol.StreamingMessageEvent, 1)
+	live <- statusEvent(protocol.TaskStateCompleted)
+	close(live)
+
+	var states []protocol.TaskState
+	for event := range replayThen(t.Context(), []protocol.StreamingMessageEvent{statusEvent(protocol.TaskStateWorking)}, live) {
+		states = append(states, event.Result.(*protocol.TaskStatusUpdateEvent).Status.State)
+	}
+	assert.Equal(t, []protocol.TaskState{protocol.TaskStateWorking, protocol.TaskStateCompleted}, states)
+}
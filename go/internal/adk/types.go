@@ -16,8 +16,9 @@ type StreamableHTTPConnectionParams struct {
 }
 
 type HttpMcpServerConfig struct {
-	Params StreamableHTTPConnectionParams `json:"params"`
-	Tools  []string                       `json:"tools"`
+	Params          StreamableHTTPConnectionParams `json:"params"`
+	Tools           []string                       `json:"tools"`
+	RequireApproval []string                       `json:"require_approval,omitempty"`
 }
 
 type SseConnectionParams struct {
@@ -28,8 +29,9 @@ type SseConnectionParams struct {
 }
 
 type SseMcpServerConfig struct {
-	Params SseConnectionParams `json:"params"`
-	Tools  []string            `json:"tools"`
+	Params          SseConnectionParams `json:"params"`
+	Tools           []string            `json:"tools"`
+	RequireApproval []string            `json:"require_approval,omitempty"`
 }
 
 type Model interface {
@@ -62,13 +64,23 @@ type OpenAI struct {
 }
 
 const (
-	ModelTypeOpenAI          = "openai"
-	ModelTypeAzureOpenAI     = "azure_openai"
-	ModelTypeAnthropic       = "anthropic"
-	ModelTypeGeminiVertexAI  = "gemini_vertex_ai"
-	ModelTypeGeminiAnthropic = "gemini_anthropic"
-	ModelTypeOllama          = "ollama"
-	ModelTypeGemini          = "gemini"
+	ModelTypeOpenAI           = "openai"
+	ModelTypeAzureOpenAI      = "azure_openai"
+	ModelTypeAnthropic        = "anthropic"
+	ModelTypeGeminiVertexAI   = "gemini_vertex_ai"
+	ModelTypeGeminiAnthropic  = "gemini_anthropic"
+	ModelTypeOllama           = "ollama"
+	ModelTypeGemini           = "gemini"
+	ModelTypeOpenAICompatible = "openai_compatible"
+	ModelTypeBedrock          = "bedrock"
+	ModelTypeFallback         = "fallback"
+)
+
+const (
+	FallbackTriggerRateLimited     = "rate_limited"
+	FallbackTriggerServerError     = "server_error"
+	FallbackTriggerTimeout         = "timeout"
+	FallbackTriggerConnectionError = "connection_error"
 )
 
 func (o *OpenAI) MarshalJSON() ([]byte, error) {
@@ -185,6 +197,118 @@ func (g *Gemini) GetType() string {
 	return ModelTypeGemini
 }
 
+// OpenAICompatible is a server implementing the OpenAI API. The API key is read
+// from OPENAI_COMPATIBLE_API_KEY and sent in AuthHeader, or as a bearer token
+// if AuthHeader is empty.
+type OpenAICompatible struct {
+	BaseModel
+	BaseUrl     string   `json:"base_url"`
+	AuthHeader  string   `json:"auth_header,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+func (o *OpenAICompatible) MarshalJSON() ([]byte, error) {
+	type Alias OpenAICompatible
+
+	return json.Marshal(&struct {
+		Type string `json:"type"`
+		*Alias
+	}{
+		Type:  ModelTypeOpenAICompatible,
+		Alias: (*Alias)(o),
+	})
+}
+
+func (o *OpenAICompatible) GetType() string {
+	return ModelTypeOpenAICompatible
+}
+
+// Bedrock is a model on AWS Bedrock. Credentials are resolved by the default
+// AWS credential chain.
+type Bedrock struct {
+	BaseModel
+	Region           string   `json:"region"`
+	InferenceProfile string   `json:"inference_profile,omitempty"`
+	MaxTokens        *int     `json:"max_tokens,omitempty"`
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+}
+
+func (b *Bedrock) MarshalJSON() ([]byte, error) {
+	type Alias Bedrock
+
+	return json.Marshal(&struct {
+		Type string `json:"type"`
+		*Alias
+	}{
+		Type:  ModelTypeBedrock,
+		Alias: (*Alias)(b),
+	})
+}
+
+func (b *Bedrock) GetType() string {
+	return ModelTypeBedrock
+}
+
+// FallbackModel calls Primary, and moves on to the next of Fallbacks when a
+// call fails with one of that fallback's triggers.
+type FallbackModel struct {
+	Primary   Model
+	Fallbacks []ModelFallback
+}
+
+// ModelFallback is a model of a fallback chain.
+type ModelFallback struct {
+	Model    Model    `json:"model"`
+	Triggers []string `json:"triggers"`
+	// Env maps the environment variables the model reads by default to the
+	// ones that hold its values, as they are renamed to not collide with the
+	// other models of the chain.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+func (f *FallbackModel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":      ModelTypeFallback,
+		"primary":   f.Primary,
+		"fallbacks": f.Fallbacks,
+	})
+}
+
+func (f *FallbackModel) UnmarshalJSON(data []byte) error {
+	var tmp struct {
+		Primary   json.RawMessage `json:"primary"`
+		Fallbacks []struct {
+			Model    json.RawMessage   `json:"model"`
+			Triggers []string          `json:"triggers"`
+			Env      map[string]string `json:"env,omitempty"`
+		} `json:"fallbacks"`
+	}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	primary, err := ParseModel(tmp.Primary)
+	if err != nil {
+		return err
+	}
+	f.Primary = primary
+	f.Fallbacks = nil
+	for _, fallback := range tmp.Fallbacks {
+		model, err := ParseModel(fallback.Model)
+		if err != nil {
+			return err
+		}
+		f.Fallbacks = append(f.Fallbacks, ModelFallback{Model: model, Triggers: fallback.Triggers, Env: fallback.Env})
+	}
+	return nil
+}
+
+func (f *FallbackModel) GetType() string {
+	return ModelTypeFallback
+}
+
 func ParseModel(bytes []byte) (Model, error) {
 	var model BaseModel
 	if err := json.Unmarshal(bytes, &model); err != nil {
@@ -233,6 +357,24 @@ func ParseModel(bytes []byte) (Model, error) {
 			return nil, err
 		}
 		return &ollama, nil
+	case ModelTypeOpenAICompatible:
+		var openAICompatible OpenAICompatible
+		if err := json.Unmarshal(bytes, &openAICompatible); err != nil {
+			return nil, err
+		}
+		return &openAICompatible, nil
+	case ModelTypeBedrock:
+		var bedrock Bedrock
+		if err := json.Unmarshal(bytes, &bedrock); err != nil {
+			return nil, err
+		}
+		return &bedrock, nil
+	case ModelTypeFallback:
+		var fallback FallbackModel
+		if err := json.Unmarshal(bytes, &fallback); err != nil {
+			return nil, err
+		}
+		return &fallback, nil
 	}
 	return nil, fmt.Errorf("unknown model type: %s", model.Type)
 }
@@ -244,6 +386,26 @@ type RemoteAgentConfig struct {
 	Description string            `json:"description,omitempty"`
 }
 
+const (
+	MemoryTypePinecone = "pinecone"
+)
+
+type PineconeMemoryConfig struct {
+	IndexHost      string   `json:"index_host"`
+	TopK           int      `json:"top_k,omitempty"`
+	Namespace      string   `json:"namespace,omitempty"`
+	RecordFields   []string `json:"record_fields,omitempty"`
+	ScoreThreshold *float64 `json:"score_threshold,omitempty"`
+	// Name of the environment variable that holds the Pinecone API key
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+}
+
+type MemoryConfig struct {
+	Name     string                `json:"name"`
+	Type     string                `json:"type"`
+	Pinecone *PineconeMemoryConfig `json:"pinecone,omitempty"`
+}
+
 // See `python/packages/kagent-adk/src/kagent/adk/types.py` for the python version of this
 type AgentConfig struct {
 	Model        Model                 `json:"model"`
@@ -253,6 +415,7 @@ type AgentConfig struct {
 	SseTools     []SseMcpServerConfig  `json:"sse_tools"`
 	RemoteAgents []RemoteAgentConfig   `json:"remote_agents"`
 	ExecuteCode  bool                  `json:"execute_code,omitempty"`
+	Memory       []MemoryConfig        `json:"memory,omitempty"`
 }
 
 func (a *AgentConfig) UnmarshalJSON(data []byte) error {
@@ -263,6 +426,8 @@ func (a *AgentConfig) UnmarshalJSON(data []byte) error {
 		HttpTools    []HttpMcpServerConfig `json:"http_tools"`
 		SseTools     []SseMcpServerConfig  `json:"sse_tools"`
 		RemoteAgents []RemoteAgentConfig   `json:"remote_agents"`
+		ExecuteCode  bool                  `json:"execute_code,omitempty"`
+		Memory       []MemoryConfig        `json:"memory,omitempty"`
 	}
 	if err := json.Unmarshal(data, &tmp); err != nil {
 		return err
@@ -277,6 +442,8 @@ func (a *AgentConfig) UnmarshalJSON(data []byte) error {
 	a.HttpTools = tmp.HttpTools
 	a.SseTools = tmp.SseTools
 	a.RemoteAgents = tmp.RemoteAgents
+	a.ExecuteCode = tmp.ExecuteCode
+	a.Memory = tmp.Memory
 	return nil
 }
 
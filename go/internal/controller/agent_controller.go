@@ -34,9 +34,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	kagentv1alpha1 "github.com/kagent-dev/kagent/go/api/v1alpha1"
 	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 	"github.com/kagent-dev/kagent/go/internal/controller/reconciler"
 	agent_translator "github.com/kagent-dev/kagent/go/internal/controller/translator/agent"
+	common "github.com/kagent-dev/kagent/go/internal/utils"
 	"github.com/kagent-dev/kmcp/api/v1alpha1"
 )
 
@@ -54,6 +56,8 @@ type AgentController struct {
 // +kubebuilder:rbac:groups=kagent.dev,resources=agents,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kagent.dev,resources=agents/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kagent.dev,resources=agents/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kagent.dev,resources=memories,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kagent.dev,resources=referencegrants,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
@@ -98,7 +102,8 @@ func (r *AgentController) SetupWithManager(mgr ctrl.Manager) error {
 
 			return requests
 		}),
-		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		// status updates other than the secret hash, e.g. probe results, don't affect agents
+		builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, modelConfigSecretHashChangedPredicate)),
 	).
 		Watches(
 			&v1alpha2.RemoteMCPServer{},
@@ -140,6 +145,71 @@ func (r *AgentController) SetupWithManager(mgr ctrl.Manager) error {
 				return requests
 			}),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&kagentv1alpha1.Memory{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				requests := []reconcile.Request{}
+
+				for _, agent := range r.findAgentsUsingMemory(ctx, mgr.GetClient(), types.NamespacedName{
+					Name:      obj.GetName(),
+					Namespace: obj.GetNamespace(),
+				}) {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      agent.Name,
+							Namespace: agent.Namespace,
+						},
+					})
+				}
+
+				return requests
+			}),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				requests := []reconcile.Request{}
+
+				for _, agent := range r.findAgentsUsingMemorySecret(ctx, mgr.GetClient(), types.NamespacedName{
+					Name:      obj.GetName(),
+					Namespace: obj.GetNamespace(),
+				}) {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      agent.Name,
+							Namespace: agent.Namespace,
+						},
+					})
+				}
+
+				return requests
+			}),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&v1alpha2.ReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				requests := []reconcile.Request{}
+
+				grant, ok := obj.(*v1alpha2.ReferenceGrant)
+				if !ok {
+					return requests
+				}
+
+				for _, agent := range r.findAgentsAffectedByReferenceGrant(ctx, mgr.GetClient(), grant) {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      agent.Name,
+							Namespace: agent.Namespace,
+						},
+					})
+				}
+
+				return requests
+			}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		)
 
 	if _, err := mgr.GetRESTMapper().RESTMapping(mcpServerGK); err == nil {
@@ -181,10 +251,6 @@ func (r *AgentController) findAgentsUsingMCPServer(ctx context.Context, cl clien
 
 	var agents []*v1alpha2.Agent
 	for _, agent := range agentsList.Items {
-		if agent.Namespace != obj.Namespace {
-			continue
-		}
-
 		if agent.Spec.Type != v1alpha2.AgentType_Declarative {
 			continue
 		}
@@ -198,7 +264,7 @@ func (r *AgentController) findAgentsUsingMCPServer(ctx context.Context, cl clien
 				continue
 			}
 
-			if tool.McpServer.Name == obj.Name {
+			if tool.McpServer.NamespacedName(agent.Namespace) == obj {
 				agents = append(agents, &agent)
 			}
 		}
@@ -226,14 +292,10 @@ func (r *AgentController) findAgentsUsingRemoteMCPServer(ctx context.Context, cl
 
 		for _, tool := range agent.Spec.Declarative.Tools {
 			if tool.McpServer == nil {
-				return
-			}
-
-			if agent.Namespace != obj.Namespace {
 				continue
 			}
 
-			if tool.McpServer.Name == obj.Name {
+			if tool.McpServer.NamespacedName(agent.Namespace) == obj {
 				agents = append(agents, agent)
 				return
 			}
@@ -259,10 +321,6 @@ func (r *AgentController) findAgentsUsingMCPService(ctx context.Context, cl clie
 
 	var agents []*v1alpha2.Agent
 	for _, agent := range agentsList.Items {
-		if agent.Namespace != obj.Namespace {
-			continue
-		}
-
 		if agent.Spec.Type != v1alpha2.AgentType_Declarative {
 			continue
 		}
@@ -276,7 +334,7 @@ func (r *AgentController) findAgentsUsingMCPService(ctx context.Context, cl clie
 				continue
 			}
 
-			if tool.McpServer.Name == obj.Name {
+			if tool.McpServer.NamespacedName(agent.Namespace) == obj {
 				agents = append(agents, &agent)
 			}
 		}
@@ -285,9 +343,24 @@ func (r *AgentController) findAgentsUsingMCPService(ctx context.Context, cl clie
 	return agents
 }
 
+// findAgentsUsingModelConfig returns the declarative agents whose model config
+// is obj, or has obj in its fallback chain.
 func (r *AgentController) findAgentsUsingModelConfig(ctx context.Context, cl client.Client, obj types.NamespacedName) []*v1alpha2.Agent {
 	var agents []*v1alpha2.Agent
 
+	chains := map[types.NamespacedName]bool{obj: true}
+	var modelConfigs v1alpha2.ModelConfigList
+	if err := cl.List(ctx, &modelConfigs, client.InNamespace(obj.Namespace)); err != nil {
+		agentControllerLog.Error(err, "failed to list ModelConfigs in order to reconcile ModelConfig update")
+	}
+	for _, modelConfig := range modelConfigs.Items {
+		for _, fallback := range modelConfig.Spec.Fallbacks {
+			if fallback.ModelConfig == obj.Name {
+				chains[types.NamespacedName{Namespace: modelConfig.Namespace, Name: modelConfig.Name}] = true
+			}
+		}
+	}
+
 	var agentsList v1alpha2.AgentList
 	if err := cl.List(
 		ctx,
@@ -299,16 +372,50 @@ func (r *AgentController) findAgentsUsingModelConfig(ctx context.Context, cl cli
 
 	for i := range agentsList.Items {
 		agent := &agentsList.Items[i]
-		// Must be in the same namespace as the model config
-		if agent.Namespace != obj.Namespace {
+		if agent.Spec.Type != v1alpha2.AgentType_Declarative {
 			continue
 		}
 
-		if agent.Spec.Type != v1alpha2.AgentType_Declarative {
+		// Model config refs may point to a different namespace in the form <namespace>/<name>
+		modelConfigNns, err := common.ParseRefString(agent.Spec.Declarative.ModelConfig, agent.Namespace)
+		if err != nil {
+			continue
+		}
+
+		if chains[modelConfigNns] {
+			agents = append(agents, agent)
+		}
+	}
+
+	return agents
+}
+
+// findAgentsAffectedByReferenceGrant returns the declarative agents in the namespaces
+// a ReferenceGrant allows references from, so that granting or revoking access is
+// picked up without waiting for another change.
+func (r *AgentController) findAgentsAffectedByReferenceGrant(ctx context.Context, cl client.Client, grant *v1alpha2.ReferenceGrant) []*v1alpha2.Agent {
+	var agents []*v1alpha2.Agent
+
+	for _, from := range grant.Spec.From {
+		if from.Group != v1alpha2.GroupVersion.Group || from.Kind != "Agent" {
+			continue
+		}
+
+		var agentsList v1alpha2.AgentList
+		if err := cl.List(
+			ctx,
+			&agentsList,
+			client.InNamespace(from.Namespace),
+		); err != nil {
+			agentControllerLog.Error(err, "failed to list Agents in order to reconcile ReferenceGrant update")
 			continue
 		}
 
-		if agent.Spec.Declarative.ModelConfig == obj.Name {
+		for i := range agentsList.Items {
+			agent := &agentsList.Items[i]
+			if agent.Spec.Type != v1alpha2.AgentType_Declarative {
+				continue
+			}
 			agents = append(agents, agent)
 		}
 	}
@@ -316,6 +423,87 @@ func (r *AgentController) findAgentsUsingModelConfig(ctx context.Context, cl cli
 	return agents
 }
 
+func (r *AgentController) findAgentsUsingMemory(ctx context.Context, cl client.Client, obj types.NamespacedName) []*v1alpha2.Agent {
+	var agents []*v1alpha2.Agent
+
+	var agentsList v1alpha2.AgentList
+	if err := cl.List(
+		ctx,
+		&agentsList,
+	); err != nil {
+		agentControllerLog.Error(err, "failed to list Agents in order to reconcile Memory update")
+		return agents
+	}
+
+	for i := range agentsList.Items {
+		agent := &agentsList.Items[i]
+		if agent.Spec.Type != v1alpha2.AgentType_Declarative {
+			continue
+		}
+
+		for _, memoryRef := range agent.Spec.Declarative.Memory {
+			// Memory refs may point to a different namespace in the form <namespace>/<name>
+			memoryNns, err := common.ParseRefString(memoryRef, agent.Namespace)
+			if err != nil {
+				continue
+			}
+
+			if memoryNns == obj {
+				agents = append(agents, agent)
+				break
+			}
+		}
+	}
+
+	return agents
+}
+
+func (r *AgentController) findAgentsUsingMemorySecret(ctx context.Context, cl client.Client, obj types.NamespacedName) []*v1alpha2.Agent {
+	var memoriesList kagentv1alpha1.MemoryList
+	if err := cl.List(
+		ctx,
+		&memoriesList,
+	); err != nil {
+		agentControllerLog.Error(err, "failed to list Memories in order to reconcile Secret update")
+		return nil
+	}
+
+	var agents []*v1alpha2.Agent
+	for _, memory := range memoriesList.Items {
+		if memory.Spec.APIKeySecretRef == "" {
+			continue
+		}
+
+		secretNns, err := common.ParseRefString(memory.Spec.APIKeySecretRef, memory.Namespace)
+		if err != nil || secretNns != obj {
+			continue
+		}
+
+		agents = append(agents, r.findAgentsUsingMemory(ctx, cl, types.NamespacedName{
+			Name:      memory.Name,
+			Namespace: memory.Namespace,
+		})...)
+	}
+
+	return agents
+}
+
+// modelConfigSecretHashChangedPredicate passes ModelConfig updates that change
+// the hash of the secrets the model config references.
+var modelConfigSecretHashChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldModelConfig, ok := e.ObjectOld.(*v1alpha2.ModelConfig)
+		if !ok {
+			return false
+		}
+		newModelConfig, ok := e.ObjectNew.(*v1alpha2.ModelConfig)
+		if !ok {
+			return false
+		}
+		return oldModelConfig.Status.SecretHash != newModelConfig.Status.SecretHash
+	},
+}
+
 type ownedObjectPredicate = typedOwnedObjectPredicate[client.Object]
 
 type typedOwnedObjectPredicate[object metav1.Object] struct {
@@ -152,5 +152,12 @@ func dataSourceReferencesSecret(ds *v1alpha2.DataSource, secretRef types.Namespa
 		return true
 	}
 
+	// Check if secret is referenced as PostgreSQL credentials
+	if ds.Spec.PostgreSQL != nil &&
+		ds.Spec.PostgreSQL.CredentialsSecretRef != "" &&
+		ds.Spec.PostgreSQL.CredentialsSecretRef == secretRef.Name {
+		return true
+	}
+
 	return false
 }
@@ -122,6 +122,26 @@ func TestDataSourceReferencesSecret(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "matching postgresql secret reference",
+			dataSource: &v1alpha2.DataSource{
+				Spec: v1alpha2.DataSourceSpec{
+					Provider: v1alpha2.DataSourceProviderPostgreSQL,
+					PostgreSQL: &v1alpha2.PostgreSQLConfig{
+						Host:                 "postgres.data.svc",
+						Database:             "analytics",
+						User:                 "analyst",
+						CredentialsSecretRef: "postgres-creds",
+						CredentialsSecretKey: "password",
+					},
+				},
+			},
+			secretRef: types.NamespacedName{
+				Name:      "postgres-creds",
+				Namespace: "",
+			},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
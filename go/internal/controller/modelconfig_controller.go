@@ -54,7 +54,7 @@ type ModelConfigController struct {
 
 func (r *ModelConfigController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = log.FromContext(ctx)
-	return ctrl.Result{}, r.Reconciler.ReconcileKagentModelConfig(ctx, req)
+	return r.Reconciler.ReconcileKagentModelConfig(ctx, req)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -128,5 +128,10 @@ func modelReferencesSecret(model *v1alpha2.ModelConfig, secretObj types.Namespac
 		return true
 	}
 
+	// check if secret is referenced as Bedrock credentials
+	if model.Spec.Bedrock != nil && model.Spec.Bedrock.CredentialsSecretRef != "" && model.Spec.Bedrock.CredentialsSecretRef == secretObj.Name {
+		return true
+	}
+
 	return false
 }
@@ -0,0 +1,316 @@
+// Package modelprobe checks that the provider of a ModelConfig is reachable
+// with the configured credentials, using the cheapest request each provider
+// offers.
+package modelprobe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+)
+
+const (
+	defaultOpenAIBaseURL    = "https://api.openai.com/v1"
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	defaultOllamaHost       = "http://localhost:11434"
+	defaultModelListPath    = "/models"
+
+	anthropicVersion = "2023-06-01"
+
+	// maxErrorMessageLength truncates provider error messages that are not JSON.
+	maxErrorMessageLength = 256
+)
+
+// DefaultGeminiBaseURL is the Gemini API endpoint, which ModelConfig can't
+// override.
+const DefaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// ErrUnsupported is returned for providers that cannot be probed from the
+// controller, such as Vertex AI and Bedrock which may authenticate with the
+// agent's workload identity.
+var ErrUnsupported = errors.New("connectivity probes are not supported for this provider")
+
+// Credentials are the secret values a ModelConfig references.
+type Credentials struct {
+	// APIKey is the value of the API key secret, if any.
+	APIKey string
+	// CACert is the PEM encoded CA certificate of the TLS config, if any.
+	CACert []byte
+}
+
+// Prober probes model providers.
+type Prober struct {
+	// Timeout bounds a single probe.
+	Timeout time.Duration
+
+	// geminiBaseURL is the Gemini API endpoint, which ModelConfig can't override.
+	geminiBaseURL string
+}
+
+// New returns a Prober with the given timeout.
+func New(timeout time.Duration) *Prober {
+	return &Prober{Timeout: timeout, geminiBaseURL: DefaultGeminiBaseURL}
+}
+
+// Probe sends a probe request for modelConfig and returns how long the
+// provider took to answer. A non-nil error carries the provider's error message.
+func (p *Prober) Probe(ctx context.Context, modelConfig *v1alpha2.ModelConfig, creds Credentials) (time.Duration, error) {
+	req, err := p.newRequest(ctx, modelConfig, creds)
+	if err != nil {
+		return 0, err
+	}
+
+	httpClient, err := NewHTTPClient(modelConfig.Spec.TLS, creds.CACert)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	latency := time.Since(start)
+	if err != nil {
+		return latency, fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return latency, fmt.Errorf("%s returned %s: %s", modelConfig.Spec.Provider, resp.Status, ErrorMessage(body))
+	}
+	return latency, nil
+}
+
+// newRequest builds the probe request of the provider:
+//   - OpenAI and Anthropic: retrieve the model
+//   - Gemini: get the model
+//   - Ollama: show the model, which fails if it hasn't been pulled
+//   - OpenAI-compatible servers: list the models
+//   - Azure OpenAI: a chat completion of a single token against the deployment,
+//     since the data plane can't retrieve deployments
+func (p *Prober) newRequest(ctx context.Context, modelConfig *v1alpha2.ModelConfig, creds Credentials) (*http.Request, error) {
+	return newModelsRequest(ctx, modelConfig.Spec, creds, p.geminiBaseURL, modelConfig.Spec.Model)
+}
+
+// NewListRequest builds a request listing the models of the provider of spec,
+// authenticated the same way as its probes:
+//   - OpenAI, Anthropic, Gemini and OpenAI-compatible servers: list the models
+//   - Ollama: list the local models
+//
+// Azure OpenAI can't list deployments from the data plane, so it returns
+// ErrUnsupported like the providers that can't be probed. geminiBaseURL is the
+// Gemini API endpoint, or DefaultGeminiBaseURL.
+func NewListRequest(ctx context.Context, spec v1alpha2.ModelConfigSpec, creds Credentials, geminiBaseURL string) (*http.Request, error) {
+	return newModelsRequest(ctx, spec, creds, geminiBaseURL, "")
+}
+
+// newModelsRequest builds a request to the models API of the provider of spec
+// that retrieves model, or lists the models if model is empty.
+func newModelsRequest(ctx context.Context, spec v1alpha2.ModelConfigSpec, creds Credentials, geminiBaseURL, model string) (*http.Request, error) {
+	var (
+		req *http.Request
+		err error
+	)
+	switch spec.Provider {
+	case v1alpha2.ModelProviderOpenAI:
+		baseURL := defaultOpenAIBaseURL
+		if spec.OpenAI != nil && spec.OpenAI.BaseURL != "" {
+			baseURL = spec.OpenAI.BaseURL
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, modelsURL(baseURL, model, "", "models"), nil)
+		if err != nil {
+			return nil, err
+		}
+		if creds.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+creds.APIKey)
+		}
+		if spec.OpenAI != nil && spec.OpenAI.Organization != "" {
+			req.Header.Set("OpenAI-Organization", spec.OpenAI.Organization)
+		}
+
+	case v1alpha2.ModelProviderAnthropic:
+		baseURL := defaultAnthropicBaseURL
+		if spec.Anthropic != nil && spec.Anthropic.BaseURL != "" {
+			baseURL = spec.Anthropic.BaseURL
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, modelsURL(baseURL, model, "limit=1000", "v1", "models"), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("x-api-key", creds.APIKey)
+		req.Header.Set("anthropic-version", anthropicVersion)
+
+	case v1alpha2.ModelProviderAzureOpenAI:
+		if model == "" {
+			return nil, ErrUnsupported
+		}
+		if spec.AzureOpenAI == nil || spec.AzureOpenAI.Endpoint == "" {
+			return nil, fmt.Errorf("azure openai endpoint is required")
+		}
+		deployment := spec.AzureOpenAI.DeploymentName
+		if deployment == "" {
+			deployment = model
+		}
+		endpoint := joinURL(spec.AzureOpenAI.Endpoint, "openai", "deployments", url.PathEscape(deployment), "chat", "completions") +
+			"?api-version=" + url.QueryEscape(spec.AzureOpenAI.APIVersion)
+		body, _ := json.Marshal(map[string]any{
+			"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+			"max_tokens": 1,
+		})
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if spec.AzureOpenAI.AzureADToken != "" {
+			req.Header.Set("Authorization", "Bearer "+spec.AzureOpenAI.AzureADToken)
+		} else {
+			req.Header.Set("api-key", creds.APIKey)
+		}
+
+	case v1alpha2.ModelProviderOllama:
+		host := defaultOllamaHost
+		if spec.Ollama != nil && spec.Ollama.Host != "" {
+			host = spec.Ollama.Host
+		}
+		if model == "" {
+			req, err = http.NewRequestWithContext(ctx, http.MethodGet, joinURL(host, "api", "tags"), nil)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		body, _ := json.Marshal(map[string]string{"model": model})
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, joinURL(host, "api", "show"), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+	case v1alpha2.ModelProviderOpenAICompatible:
+		if spec.OpenAICompatible == nil || spec.OpenAICompatible.BaseURL == "" {
+			return nil, fmt.Errorf("openai compatible base url is required")
+		}
+		modelListPath := spec.OpenAICompatible.ModelListPath
+		if modelListPath == "" {
+			modelListPath = defaultModelListPath
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, joinURL(spec.OpenAICompatible.BaseURL, strings.TrimPrefix(modelListPath, "/")), nil)
+		if err != nil {
+			return nil, err
+		}
+		if creds.APIKey != "" {
+			if header := spec.OpenAICompatible.AuthHeader; header != "" && !strings.EqualFold(header, "Authorization") {
+				req.Header.Set(header, creds.APIKey)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+creds.APIKey)
+			}
+		}
+
+	case v1alpha2.ModelProviderGemini:
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, modelsURL(geminiBaseURL, model, "pageSize=1000", "models"), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("x-goog-api-key", creds.APIKey)
+
+	default:
+		return nil, ErrUnsupported
+	}
+
+	for name, value := range spec.DefaultHeaders {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// modelsURL returns the URL of model under the models collection at elems of
+// base, or the URL listing the collection with listQuery if model is empty.
+func modelsURL(base, model, listQuery string, elems ...string) string {
+	if model != "" {
+		return joinURL(base, append(elems, url.PathEscape(model))...)
+	}
+	if listQuery != "" {
+		return joinURL(base, elems...) + "?" + listQuery
+	}
+	return joinURL(base, elems...)
+}
+
+func joinURL(base string, elems ...string) string {
+	return strings.TrimSuffix(base, "/") + "/" + strings.Join(elems, "/")
+}
+
+// NewHTTPClient returns a client that verifies the provider's certificate the
+// same way the agent does.
+func NewHTTPClient(tlsConfig *v1alpha2.TLSConfig, caCert []byte) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		clientTLS := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: tlsConfig.DisableVerify, //nolint:gosec // explicitly requested by the ModelConfig
+		}
+
+		if !tlsConfig.DisableVerify && (len(caCert) > 0 || tlsConfig.DisableSystemCAs) {
+			pool := x509.NewCertPool()
+			if !tlsConfig.DisableSystemCAs {
+				systemPool, err := x509.SystemCertPool()
+				if err != nil {
+					return nil, fmt.Errorf("failed to load system CA certificates: %w", err)
+				}
+				pool = systemPool
+			}
+			if len(caCert) > 0 && !pool.AppendCertsFromPEM(caCert) {
+				return nil, fmt.Errorf("no valid PEM certificate found in the CA certificate secret")
+			}
+			clientTLS.RootCAs = pool
+		}
+		transport.TLSClientConfig = clientTLS
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// ErrorMessage extracts the error message of a provider response. OpenAI,
+// Azure, Anthropic and Gemini return {"error": {"message": ...}}, Ollama
+// returns {"error": "..."}.
+func ErrorMessage(body []byte) string {
+	var nested struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &nested); err == nil && nested.Error.Message != "" {
+		return nested.Error.Message
+	}
+
+	var flat struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &flat); err == nil && flat.Error != "" {
+		return flat.Error
+	}
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > maxErrorMessageLength {
+		message = message[:maxErrorMessageLength] + "..."
+	}
+	if message == "" {
+		message = "empty response"
+	}
+	return message
+}
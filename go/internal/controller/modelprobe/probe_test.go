@@ -0,0 +1,268 @@
+package modelprobe
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+)
+
+func modelConfig(spec v1alpha2.ModelConfigSpec) *v1alpha2.ModelConfig {
+	return &v1alpha2.ModelConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
+func TestProbe(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Query  string
+		Header http.Header
+		Body   map[string]any
+	}
+
+	tests := []struct {
+		name   string
+		spec   func(url string) v1alpha2.ModelConfigSpec
+		assert func(t *testing.T, req request)
+	}{
+		{
+			name: "openai retrieves the model",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider:       v1alpha2.ModelProviderOpenAI,
+					Model:          "gpt-4o",
+					OpenAI:         &v1alpha2.OpenAIConfig{BaseURL: url + "/v1/", Organization: "org-1"},
+					DefaultHeaders: map[string]string{"X-Gateway": "team-a"},
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Equal(t, "/v1/models/gpt-4o", req.Path)
+				assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+				assert.Equal(t, "org-1", req.Header.Get("OpenAI-Organization"))
+				assert.Equal(t, "team-a", req.Header.Get("X-Gateway"))
+			},
+		},
+		{
+			name: "anthropic retrieves the model",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider:  v1alpha2.ModelProviderAnthropic,
+					Model:     "claude-sonnet-4-5",
+					Anthropic: &v1alpha2.AnthropicConfig{BaseURL: url},
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Equal(t, "/v1/models/claude-sonnet-4-5", req.Path)
+				assert.Equal(t, "secret", req.Header.Get("x-api-key"))
+				assert.Equal(t, anthropicVersion, req.Header.Get("anthropic-version"))
+			},
+		},
+		{
+			name: "azure openai completes a single token",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderAzureOpenAI,
+					Model:    "gpt-4o",
+					AzureOpenAI: &v1alpha2.AzureOpenAIConfig{
+						Endpoint:       url,
+						APIVersion:     "2024-06-01",
+						DeploymentName: "my-deployment",
+					},
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "/openai/deployments/my-deployment/chat/completions", req.Path)
+				assert.Equal(t, "api-version=2024-06-01", req.Query)
+				assert.Equal(t, "secret", req.Header.Get("api-key"))
+				assert.EqualValues(t, 1, req.Body["max_tokens"])
+			},
+		},
+		{
+			name: "ollama shows the model",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderOllama,
+					Model:    "llama3.2",
+					Ollama:   &v1alpha2.OllamaConfig{Host: url},
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "/api/show", req.Path)
+				assert.Equal(t, "llama3.2", req.Body["model"])
+			},
+		},
+		{
+			name: "openai compatible lists the models with a custom auth header",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderOpenAICompatible,
+					Model:    "mistral-large",
+					OpenAICompatible: &v1alpha2.OpenAICompatibleConfig{
+						BaseURL:       url + "/api/",
+						AuthHeader:    "X-Api-Key",
+						ModelListPath: "/v1/models",
+					},
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Equal(t, "/api/v1/models", req.Path)
+				assert.Equal(t, "secret", req.Header.Get("X-Api-Key"))
+				assert.Empty(t, req.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "openai compatible defaults to bearer auth and /models",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider:         v1alpha2.ModelProviderOpenAICompatible,
+					Model:            "mistral-large",
+					OpenAICompatible: &v1alpha2.OpenAICompatibleConfig{BaseURL: url + "/v1"},
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, "/v1/models", req.Path)
+				assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "gemini gets the model",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderGemini,
+					Model:    "gemini-2.0-flash",
+				}
+			},
+			assert: func(t *testing.T, req request) {
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Equal(t, "/v1beta/models/gemini-2.0-flash", req.Path)
+				assert.Equal(t, "secret", req.Header.Get("x-goog-api-key"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Header: r.Header}
+				_ = json.NewDecoder(r.Body).Decode(&got.Body)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			prober := New(5 * time.Second)
+			prober.geminiBaseURL = server.URL + "/v1beta"
+
+			latency, err := prober.Probe(context.Background(), modelConfig(tt.spec(server.URL)), Credentials{APIKey: "secret"})
+			require.NoError(t, err)
+			assert.Positive(t, latency)
+			tt.assert(t, got)
+		})
+	}
+}
+
+func TestProbeErrors(t *testing.T) {
+	t.Run("provider error message", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`))
+		}))
+		defer server.Close()
+
+		_, err := New(5*time.Second).Probe(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderOpenAI,
+			Model:    "gpt-4o",
+			OpenAI:   &v1alpha2.OpenAIConfig{BaseURL: server.URL},
+		}), Credentials{APIKey: "wrong"})
+		assert.EqualError(t, err, "OpenAI returned 401 Unauthorized: Incorrect API key provided")
+	})
+
+	t.Run("ollama model not pulled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "model 'llama3.2' not found"}`))
+		}))
+		defer server.Close()
+
+		_, err := New(5*time.Second).Probe(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderOllama,
+			Model:    "llama3.2",
+			Ollama:   &v1alpha2.OllamaConfig{Host: server.URL},
+		}), Credentials{})
+		assert.EqualError(t, err, "Ollama returned 404 Not Found: model 'llama3.2' not found")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		latency, err := New(50*time.Millisecond).Probe(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderOpenAI,
+			Model:    "gpt-4o",
+			OpenAI:   &v1alpha2.OpenAIConfig{BaseURL: server.URL},
+		}), Credentials{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.GreaterOrEqual(t, latency, 50*time.Millisecond)
+	})
+
+	t.Run("bedrock is not supported", func(t *testing.T) {
+		_, err := New(5*time.Second).Probe(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderBedrock,
+			Model:    "anthropic.claude-sonnet-4-20250514-v1:0",
+			Bedrock:  &v1alpha2.BedrockConfig{Region: "us-east-1"},
+		}), Credentials{})
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("vertex ai is not supported", func(t *testing.T) {
+		_, err := New(5*time.Second).Probe(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderGeminiVertexAI,
+			Model:    "gemini-2.0-flash",
+		}), Credentials{})
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+}
+
+func TestProbeTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	probe := func(tlsConfig *v1alpha2.TLSConfig, caCert []byte) error {
+		_, err := New(5*time.Second).Probe(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderOpenAI,
+			Model:    "gpt-4o",
+			OpenAI:   &v1alpha2.OpenAIConfig{BaseURL: server.URL},
+			TLS:      tlsConfig,
+		}), Credentials{CACert: caCert})
+		return err
+	}
+
+	assert.ErrorContains(t, probe(nil, nil), "certificate")
+	assert.NoError(t, probe(&v1alpha2.TLSConfig{CACertSecretRef: "ca", CACertSecretKey: "ca.crt"}, caCert))
+	assert.NoError(t, probe(&v1alpha2.TLSConfig{CACertSecretRef: "ca", CACertSecretKey: "ca.crt", DisableSystemCAs: true}, caCert))
+	assert.NoError(t, probe(&v1alpha2.TLSConfig{DisableVerify: true}, nil))
+	assert.ErrorContains(t, probe(&v1alpha2.TLSConfig{CACertSecretRef: "ca", CACertSecretKey: "ca.crt"}, []byte("not a certificate")), "no valid PEM certificate")
+}
@@ -0,0 +1,104 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/controller/modelprobe"
+	"github.com/kagent-dev/kagent/go/internal/utils"
+)
+
+const (
+	modelConfigProbeTimeout = 10 * time.Second
+	// modelConfigProbeBaseBackoff is the delay before re-probing after the first
+	// failure. It doubles with every consecutive failure, up to the probe interval.
+	modelConfigProbeBaseBackoff = 10 * time.Second
+)
+
+type modelConfigProber interface {
+	Probe(ctx context.Context, modelConfig *v1alpha2.ModelConfig, creds modelprobe.Credentials) (time.Duration, error)
+}
+
+// modelConfigProbe probes the providers of model configs and schedules the
+// next probe: after the interval when the provider is reachable, and with
+// exponential backoff while it isn't.
+type modelConfigProbe struct {
+	prober   modelConfigProber
+	interval time.Duration
+	backoff  workqueue.TypedRateLimiter[types.NamespacedName]
+}
+
+func newModelConfigProbe(prober modelConfigProber, interval time.Duration) modelConfigProbe {
+	return modelConfigProbe{
+		prober:   prober,
+		interval: interval,
+		backoff:  workqueue.NewTypedItemExponentialFailureRateLimiter[types.NamespacedName](modelConfigProbeBaseBackoff, max(interval, modelConfigProbeBaseBackoff)),
+	}
+}
+
+func (p modelConfigProbe) enabled() bool {
+	return p.prober != nil && p.interval > 0
+}
+
+func (p modelConfigProbe) forget(name types.NamespacedName) {
+	if p.enabled() {
+		p.backoff.Forget(name)
+	}
+}
+
+// probe returns the Ready condition of the model config and when to probe it
+// again. The condition is nil if probes are disabled.
+func (p modelConfigProbe) probe(ctx context.Context, modelConfig *v1alpha2.ModelConfig, creds modelprobe.Credentials, acceptErr error) (*metav1.Condition, ctrl.Result) {
+	if !p.enabled() {
+		return nil, ctrl.Result{}
+	}
+
+	name := types.NamespacedName{Namespace: modelConfig.Namespace, Name: modelConfig.Name}
+	condition := &metav1.Condition{
+		Type:               v1alpha2.ModelConfigConditionTypeReady,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: modelConfig.Generation,
+	}
+
+	// an invalid model config is reconciled again once its secrets change
+	if acceptErr != nil {
+		p.backoff.Forget(name)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ModelConfigNotAccepted"
+		condition.Message = "The provider is not probed until the model config is accepted"
+		return condition, ctrl.Result{}
+	}
+
+	latency, err := p.prober.Probe(ctx, modelConfig, creds)
+	switch {
+	case errors.Is(err, modelprobe.ErrUnsupported):
+		p.backoff.Forget(name)
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "ProbeNotSupported"
+		condition.Message = fmt.Sprintf("Connectivity probes are not supported for provider %s", modelConfig.Spec.Provider)
+		return condition, ctrl.Result{}
+
+	case err != nil:
+		retryAfter := p.backoff.When(name)
+		reconcileLog.Info("model provider probe failed", "modelConfig", utils.GetObjectRef(modelConfig), "error", err.Error(), "retryAfter", retryAfter)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ProviderUnreachable"
+		condition.Message = fmt.Sprintf("Probe failed after %dms: %v", latency.Milliseconds(), err)
+		return condition, ctrl.Result{RequeueAfter: retryAfter}
+
+	default:
+		p.backoff.Forget(name)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ProviderReachable"
+		condition.Message = fmt.Sprintf("%s responded in %dms", modelConfig.Spec.Provider, latency.Milliseconds())
+		return condition, ctrl.Result{RequeueAfter: p.interval}
+	}
+}
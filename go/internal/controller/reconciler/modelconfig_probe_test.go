@@ -0,0 +1,99 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/controller/modelprobe"
+)
+
+type fakeProber struct {
+	latency time.Duration
+	err     error
+	creds   modelprobe.Credentials
+}
+
+func (f *fakeProber) Probe(_ context.Context, _ *v1alpha2.ModelConfig, creds modelprobe.Credentials) (time.Duration, error) {
+	f.creds = creds
+	return f.latency, f.err
+}
+
+func TestModelConfigProbe(t *testing.T) {
+	modelConfig := &v1alpha2.ModelConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default", Generation: 3},
+		Spec:       v1alpha2.ModelConfigSpec{Provider: v1alpha2.ModelProviderOpenAI, Model: "gpt-4o"},
+	}
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		probe := newModelConfigProbe(&fakeProber{}, 0)
+		condition, result := probe.probe(ctx, modelConfig, modelprobe.Credentials{}, nil)
+		assert.Nil(t, condition)
+		assert.Zero(t, result)
+	})
+
+	t.Run("reachable provider is probed again after the interval", func(t *testing.T) {
+		prober := &fakeProber{latency: 120 * time.Millisecond}
+		probe := newModelConfigProbe(prober, 5*time.Minute)
+
+		condition, result := probe.probe(ctx, modelConfig, modelprobe.Credentials{APIKey: "secret"}, nil)
+		require.NotNil(t, condition)
+		assert.Equal(t, v1alpha2.ModelConfigConditionTypeReady, condition.Type)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, "ProviderReachable", condition.Reason)
+		assert.Equal(t, "OpenAI responded in 120ms", condition.Message)
+		assert.EqualValues(t, 3, condition.ObservedGeneration)
+		assert.Equal(t, 5*time.Minute, result.RequeueAfter)
+		assert.Equal(t, "secret", prober.creds.APIKey)
+	})
+
+	t.Run("failures back off up to the interval", func(t *testing.T) {
+		prober := &fakeProber{latency: 30 * time.Millisecond, err: errors.New("OpenAI returned 401 Unauthorized: Incorrect API key provided")}
+		probe := newModelConfigProbe(prober, time.Minute)
+
+		var delays []time.Duration
+		for range 5 {
+			condition, result := probe.probe(ctx, modelConfig, modelprobe.Credentials{}, nil)
+			require.NotNil(t, condition)
+			assert.Equal(t, metav1.ConditionFalse, condition.Status)
+			assert.Equal(t, "ProviderUnreachable", condition.Reason)
+			assert.Equal(t, "Probe failed after 30ms: OpenAI returned 401 Unauthorized: Incorrect API key provided", condition.Message)
+			delays = append(delays, result.RequeueAfter)
+		}
+		assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}, delays)
+
+		// a successful probe resets the backoff
+		prober.err = nil
+		_, result := probe.probe(ctx, modelConfig, modelprobe.Credentials{}, nil)
+		assert.Equal(t, time.Minute, result.RequeueAfter)
+		prober.err = errors.New("connection refused")
+		_, result = probe.probe(ctx, modelConfig, modelprobe.Credentials{}, nil)
+		assert.Equal(t, 10*time.Second, result.RequeueAfter)
+	})
+
+	t.Run("unsupported provider", func(t *testing.T) {
+		probe := newModelConfigProbe(&fakeProber{err: modelprobe.ErrUnsupported}, time.Minute)
+		condition, result := probe.probe(ctx, modelConfig, modelprobe.Credentials{}, nil)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionUnknown, condition.Status)
+		assert.Equal(t, "ProbeNotSupported", condition.Reason)
+		assert.Zero(t, result)
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		prober := &fakeProber{}
+		probe := newModelConfigProbe(prober, time.Minute)
+		condition, result := probe.probe(ctx, modelConfig, modelprobe.Credentials{}, errors.New("failed to get secret"))
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, "ModelConfigNotAccepted", condition.Reason)
+		assert.Zero(t, result)
+	})
+}
@@ -10,8 +10,10 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/kagent-dev/kagent/go/internal/controller/modelprobe"
 	reconcilerutils "github.com/kagent-dev/kagent/go/internal/controller/reconciler/utils"
 	"github.com/kagent-dev/kmcp/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -28,11 +30,14 @@ import (
 	"github.com/kagent-dev/kagent/go/internal/controller/translator"
 	agent_translator "github.com/kagent-dev/kagent/go/internal/controller/translator/agent"
 	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/internal/tracing"
 	"github.com/kagent-dev/kagent/go/internal/utils"
 	"github.com/kagent-dev/kagent/go/internal/version"
 	mcp_client "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -46,7 +51,7 @@ var (
 
 type KagentReconciler interface {
 	ReconcileKagentAgent(ctx context.Context, req ctrl.Request) error
-	ReconcileKagentModelConfig(ctx context.Context, req ctrl.Request) error
+	ReconcileKagentModelConfig(ctx context.Context, req ctrl.Request) (ctrl.Result, error)
 	ReconcileKagentRemoteMCPServer(ctx context.Context, req ctrl.Request) error
 	ReconcileKagentMCPService(ctx context.Context, req ctrl.Request) error
 	ReconcileKagentMCPServer(ctx context.Context, req ctrl.Request) error
@@ -62,6 +67,8 @@ type kagentReconciler struct {
 
 	defaultModelConfig types.NamespacedName
 
+	modelConfigProbe modelConfigProbe
+
 	// TODO: Remove this lock since we have a DB which we can batch anyway
 	upsertLock sync.Mutex
 }
@@ -71,12 +78,14 @@ func NewKagentReconciler(
 	kube client.Client,
 	dbClient database.Client,
 	defaultModelConfig types.NamespacedName,
+	modelConfigProbeInterval time.Duration,
 ) KagentReconciler {
 	return &kagentReconciler{
 		adkTranslator:      translator,
 		kube:               kube,
 		dbClient:           dbClient,
 		defaultModelConfig: defaultModelConfig,
+		modelConfigProbe:   newModelConfigProbe(modelprobe.New(modelConfigProbeTimeout), modelConfigProbeInterval),
 	}
 }
 
@@ -216,18 +225,20 @@ type secretRef struct {
 	Secret         *corev1.Secret
 }
 
-func (a *kagentReconciler) ReconcileKagentModelConfig(ctx context.Context, req ctrl.Request) error {
+func (a *kagentReconciler) ReconcileKagentModelConfig(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	modelConfig := &v1alpha2.ModelConfig{}
 	if err := a.kube.Get(ctx, req.NamespacedName, modelConfig); err != nil {
 		if apierrors.IsNotFound(err) {
-			return nil
+			a.modelConfigProbe.forget(req.NamespacedName)
+			return ctrl.Result{}, nil
 		}
 
-		return fmt.Errorf("failed to get model %s: %v", req.Name, err)
+		return ctrl.Result{}, fmt.Errorf("failed to get model %s: %v", req.Name, err)
 	}
 
 	var err error
 	var secrets []secretRef
+	var creds modelprobe.Credentials
 
 	// check for api key secret
 	if modelConfig.Spec.APIKeySecret != "" {
@@ -241,6 +252,7 @@ func (a *kagentReconciler) ReconcileKagentModelConfig(ctx context.Context, req c
 				NamespacedName: namespacedName,
 				Secret:         secret,
 			})
+			creds.APIKey = string(secret.Data[modelConfig.Spec.APIKeySecretKey])
 		}
 	}
 
@@ -251,6 +263,22 @@ func (a *kagentReconciler) ReconcileKagentModelConfig(ctx context.Context, req c
 
 		if kubeErr := a.kube.Get(ctx, namespacedName, secret); kubeErr != nil {
 			err = multierror.Append(err, fmt.Errorf("failed to get secret %s: %v", modelConfig.Spec.TLS.CACertSecretRef, kubeErr))
+		} else {
+			secrets = append(secrets, secretRef{
+				NamespacedName: namespacedName,
+				Secret:         secret,
+			})
+			creds.CACert = secret.Data[modelConfig.Spec.TLS.CACertSecretKey]
+		}
+	}
+
+	// check for bedrock credentials secret
+	if modelConfig.Spec.Bedrock != nil && modelConfig.Spec.Bedrock.CredentialsSecretRef != "" {
+		secret := &corev1.Secret{}
+		namespacedName := types.NamespacedName{Namespace: modelConfig.Namespace, Name: modelConfig.Spec.Bedrock.CredentialsSecretRef}
+
+		if kubeErr := a.kube.Get(ctx, namespacedName, secret); kubeErr != nil {
+			err = multierror.Append(err, fmt.Errorf("failed to get secret %s: %v", modelConfig.Spec.Bedrock.CredentialsSecretRef, kubeErr))
 		} else {
 			secrets = append(secrets, secretRef{
 				NamespacedName: namespacedName,
@@ -262,11 +290,15 @@ func (a *kagentReconciler) ReconcileKagentModelConfig(ctx context.Context, req c
 	// compute the hash for the status
 	secretHash := computeStatusSecretHash(secrets)
 
-	return a.reconcileModelConfigStatus(
+	// probe the provider once the model config is accepted
+	ready, result := a.modelConfigProbe.probe(ctx, modelConfig, creds, err)
+
+	return result, a.reconcileModelConfigStatus(
 		ctx,
 		modelConfig,
 		err,
 		secretHash,
+		ready,
 	)
 }
 
@@ -299,7 +331,7 @@ func computeStatusSecretHash(secrets []secretRef) string {
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
-func (a *kagentReconciler) reconcileModelConfigStatus(ctx context.Context, modelConfig *v1alpha2.ModelConfig, err error, secretHash string) error {
+func (a *kagentReconciler) reconcileModelConfigStatus(ctx context.Context, modelConfig *v1alpha2.ModelConfig, err error, secretHash string, ready *metav1.Condition) error {
 	var (
 		status  metav1.ConditionStatus
 		message string
@@ -323,6 +355,13 @@ func (a *kagentReconciler) reconcileModelConfigStatus(ctx context.Context, model
 		Message:            message,
 	})
 
+	// the Ready condition is only reported while probes are enabled
+	if ready != nil {
+		conditionChanged = meta.SetStatusCondition(&modelConfig.Status.Conditions, *ready) || conditionChanged
+	} else {
+		conditionChanged = meta.RemoveStatusCondition(&modelConfig.Status.Conditions, v1alpha2.ModelConfigConditionTypeReady) || conditionChanged
+	}
+
 	// check if the secret hash has changed
 	secretHashChanged := modelConfig.Status.SecretHash != secretHash
 	if secretHashChanged {
@@ -357,20 +396,20 @@ func (a *kagentReconciler) ReconcileKagentDataSource(ctx context.Context, req ct
 
 	// Step 2: Validate credentials secret exists
 	var secretHash string
-	if ds.Spec.Databricks != nil {
+	if secretRefName, secretKey, ok := dataSourceCredentials(ds); ok {
 		secret := &corev1.Secret{}
 		secretName := types.NamespacedName{
 			Namespace: ds.Namespace,
-			Name:      ds.Spec.Databricks.CredentialsSecretRef,
+			Name:      secretRefName,
 		}
 		if err := a.kube.Get(ctx, secretName, secret); err != nil {
 			return a.reconcileDataSourceStatus(ctx, ds, nil, "",
-				fmt.Errorf("credentials secret %q not found: %w", ds.Spec.Databricks.CredentialsSecretRef, err))
+				fmt.Errorf("credentials secret %q not found: %w", secretRefName, err))
 		}
 
-		if _, ok := secret.Data[ds.Spec.Databricks.CredentialsSecretKey]; !ok {
+		if _, ok := secret.Data[secretKey]; !ok {
 			return a.reconcileDataSourceStatus(ctx, ds, nil, "",
-				fmt.Errorf("key %q not found in secret %q", ds.Spec.Databricks.CredentialsSecretKey, ds.Spec.Databricks.CredentialsSecretRef))
+				fmt.Errorf("key %q not found in secret %q", secretKey, secretRefName))
 		}
 
 		// Compute secret hash for change detection
@@ -383,7 +422,10 @@ func (a *kagentReconciler) ReconcileKagentDataSource(ctx context.Context, req ct
 	mcpServerName := fmt.Sprintf("%s-mcp", ds.Name)
 
 	// Step 3: Create/Update Deployment
-	deployment := a.generateDeploymentForDataSource(ds)
+	deployment, err := a.generateDeploymentForDataSource(ds)
+	if err != nil {
+		return a.reconcileDataSourceStatus(ctx, ds, nil, secretHash, err)
+	}
 	if err := controllerutil.SetControllerReference(ds, deployment, a.kube.Scheme()); err != nil {
 		return a.reconcileDataSourceStatus(ctx, ds, nil, secretHash,
 			fmt.Errorf("failed to set owner reference on deployment: %w", err))
@@ -459,11 +501,107 @@ func (a *kagentReconciler) ReconcileKagentDataSource(ctx context.Context, req ct
 	return a.reconcileDataSourceStatus(ctx, ds, nil, secretHash, nil)
 }
 
+// dataSourceCredentials returns the credentials secret name and key of the provider-specific config.
+func dataSourceCredentials(ds *v1alpha2.DataSource) (string, string, bool) {
+	switch {
+	case ds.Spec.Databricks != nil:
+		return ds.Spec.Databricks.CredentialsSecretRef, ds.Spec.Databricks.CredentialsSecretKey, true
+	case ds.Spec.PostgreSQL != nil:
+		return ds.Spec.PostgreSQL.CredentialsSecretRef, ds.Spec.PostgreSQL.CredentialsSecretKey, true
+	}
+	return "", "", false
+}
+
+// dataSourceMCPContainer describes the provider-specific MCP server container for a DataSource.
+type dataSourceMCPContainer struct {
+	name string
+	args []string
+	env  []corev1.EnvVar
+}
+
+// generateMCPContainerForDataSource dispatches on the DataSource provider to build the
+// MCP server binary name, provider-specific args and credential env vars.
+func generateMCPContainerForDataSource(ds *v1alpha2.DataSource) (*dataSourceMCPContainer, error) {
+	secretEnv := func(name, secretName, secretKey string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key: secretKey,
+				},
+			},
+		}
+	}
+
+	switch ds.Spec.Provider {
+	case v1alpha2.DataSourceProviderDatabricks:
+		if ds.Spec.Databricks == nil {
+			return nil, fmt.Errorf("databricks config is required when provider is Databricks")
+		}
+		args := []string{
+			fmt.Sprintf("--workspace-url=%s", ds.Spec.Databricks.WorkspaceURL),
+			fmt.Sprintf("--catalog=%s", ds.Spec.Databricks.Catalog),
+		}
+		if ds.Spec.Databricks.Schema != "" {
+			args = append(args, fmt.Sprintf("--schema=%s", ds.Spec.Databricks.Schema))
+		}
+		if ds.Spec.Databricks.WarehouseID != "" {
+			args = append(args, fmt.Sprintf("--warehouse-id=%s", ds.Spec.Databricks.WarehouseID))
+		}
+		args = append(args, fmt.Sprintf("--read-only=%t", ptr.Deref(ds.Spec.Databricks.ReadOnly, true)))
+		if len(ds.Spec.Databricks.AllowedStatementTypes) > 0 {
+			args = append(args, fmt.Sprintf("--allowed-statement-types=%s", strings.Join(ds.Spec.Databricks.AllowedStatementTypes, ",")))
+		}
+		return &dataSourceMCPContainer{
+			name: "databricks-mcp",
+			args: args,
+			env: []corev1.EnvVar{
+				secretEnv("DATABRICKS_TOKEN", ds.Spec.Databricks.CredentialsSecretRef, ds.Spec.Databricks.CredentialsSecretKey),
+			},
+		}, nil
+	case v1alpha2.DataSourceProviderPostgreSQL:
+		if ds.Spec.PostgreSQL == nil {
+			return nil, fmt.Errorf("postgresql config is required when provider is PostgreSQL")
+		}
+		args := []string{
+			fmt.Sprintf("--host=%s", ds.Spec.PostgreSQL.Host),
+			fmt.Sprintf("--database=%s", ds.Spec.PostgreSQL.Database),
+			fmt.Sprintf("--user=%s", ds.Spec.PostgreSQL.User),
+		}
+		if ds.Spec.PostgreSQL.Port != 0 {
+			args = append(args, fmt.Sprintf("--db-port=%d", ds.Spec.PostgreSQL.Port))
+		}
+		if ds.Spec.PostgreSQL.Schema != "" {
+			args = append(args, fmt.Sprintf("--schema=%s", ds.Spec.PostgreSQL.Schema))
+		}
+		if ds.Spec.PostgreSQL.SSLMode != "" {
+			args = append(args, fmt.Sprintf("--sslmode=%s", ds.Spec.PostgreSQL.SSLMode))
+		}
+		return &dataSourceMCPContainer{
+			name: "postgres-mcp",
+			args: args,
+			env: []corev1.EnvVar{
+				secretEnv("PGPASSWORD", ds.Spec.PostgreSQL.CredentialsSecretRef, ds.Spec.PostgreSQL.CredentialsSecretKey),
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported datasource provider %q", ds.Spec.Provider)
+	}
+}
+
 // generateDeploymentForDataSource creates the Deployment spec for a DataSource MCP server.
-// The deployment runs the databricks-mcp binary in HTTP mode.
-func (a *kagentReconciler) generateDeploymentForDataSource(ds *v1alpha2.DataSource) *appsv1.Deployment {
+// The deployment runs the provider-specific MCP binary (e.g. databricks-mcp) in HTTP mode.
+func (a *kagentReconciler) generateDeploymentForDataSource(ds *v1alpha2.DataSource) (*appsv1.Deployment, error) {
 	mcpServerName := fmt.Sprintf("%s-mcp", ds.Name)
 
+	mcpContainer, err := generateMCPContainerForDataSource(ds)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the list of models to pass to the MCP server
 	var modelNames []string
 	for _, m := range ds.Spec.SemanticModels {
@@ -471,18 +609,10 @@ func (a *kagentReconciler) generateDeploymentForDataSource(ds *v1alpha2.DataSour
 	}
 
 	// Build command args for HTTP mode
-	args := []string{
+	args := append([]string{
 		"--transport=streamable-http",
 		"--port=8080",
-		fmt.Sprintf("--workspace-url=%s", ds.Spec.Databricks.WorkspaceURL),
-		fmt.Sprintf("--catalog=%s", ds.Spec.Databricks.Catalog),
-	}
-	if ds.Spec.Databricks.Schema != "" {
-		args = append(args, fmt.Sprintf("--schema=%s", ds.Spec.Databricks.Schema))
-	}
-	if ds.Spec.Databricks.WarehouseID != "" {
-		args = append(args, fmt.Sprintf("--warehouse-id=%s", ds.Spec.Databricks.WarehouseID))
-	}
+	}, mcpContainer.args...)
 	if len(modelNames) > 0 {
 		args = append(args, fmt.Sprintf("--models=%s", strings.Join(modelNames, ",")))
 	}
@@ -514,22 +644,12 @@ func (a *kagentReconciler) generateDeploymentForDataSource(ds *v1alpha2.DataSour
 				ObjectMeta: metav1.ObjectMeta{Labels: labels},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{{
-						Name:            "databricks-mcp",
-						Image:           fmt.Sprintf("%s/kagent-dev/kagent/databricks-mcp:%s", agent_translator.DefaultImageConfig.Registry, agent_translator.DefaultImageConfig.Tag),
+						Name:            mcpContainer.name,
+						Image:           fmt.Sprintf("%s/kagent-dev/kagent/%s:%s", agent_translator.DefaultImageConfig.Registry, mcpContainer.name, agent_translator.DefaultImageConfig.Tag),
 						ImagePullPolicy: corev1.PullPolicy(agent_translator.DefaultImageConfig.PullPolicy),
 						Args:            args,
 						Ports:           []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
-						Env: []corev1.EnvVar{{
-							Name: "DATABRICKS_TOKEN",
-							ValueFrom: &corev1.EnvVarSource{
-								SecretKeyRef: &corev1.SecretKeySelector{
-									LocalObjectReference: corev1.LocalObjectReference{
-										Name: ds.Spec.Databricks.CredentialsSecretRef,
-									},
-									Key: ds.Spec.Databricks.CredentialsSecretKey,
-								},
-							},
-						}},
+						Env:             mcpContainer.env,
 						ReadinessProbe: &corev1.Probe{
 							ProbeHandler: corev1.ProbeHandler{
 								HTTPGet: &corev1.HTTPGetAction{
@@ -572,7 +692,7 @@ func (a *kagentReconciler) generateDeploymentForDataSource(ds *v1alpha2.DataSour
 				},
 			},
 		},
-	}
+	}, nil
 }
 
 // generateServiceForDataSource creates the Service spec for a DataSource MCP server.
@@ -836,8 +956,13 @@ func (a *kagentReconciler) reconcileRemoteMCPServerStatus(
 	return nil
 }
 
-func (a *kagentReconciler) reconcileAgent(ctx context.Context, agent *v1alpha2.Agent) error {
-	agentOutputs, err := a.adkTranslator.TranslateAgent(ctx, agent)
+func (a *kagentReconciler) reconcileAgent(ctx context.Context, agent *v1alpha2.Agent) (err error) {
+	ctx, span := tracing.Start(ctx, "reconcile agent", trace.WithAttributes(attribute.String("kagent.agent", utils.GetObjectRef(agent))))
+	defer func() { tracing.End(span, err) }()
+
+	translateCtx, translateSpan := tracing.Start(ctx, "translate agent")
+	agentOutputs, err := a.adkTranslator.TranslateAgent(translateCtx, agent)
+	tracing.End(translateSpan, err)
 	if err != nil {
 		return fmt.Errorf("failed to translate agent %s/%s: %v", agent.Namespace, agent.Name, err)
 	}
@@ -847,7 +972,10 @@ func (a *kagentReconciler) reconcileAgent(ctx context.Context, agent *v1alpha2.A
 		return err
 	}
 
-	if err := a.reconcileDesiredObjects(ctx, agent, agentOutputs.Manifest, ownedObjects); err != nil {
+	applyCtx, applySpan := tracing.Start(ctx, "apply agent objects", trace.WithAttributes(attribute.Int("kagent.objects", len(agentOutputs.Manifest))))
+	err = a.reconcileDesiredObjects(applyCtx, agent, agentOutputs.Manifest, ownedObjects)
+	tracing.End(applySpan, err)
+	if err != nil {
 		return fmt.Errorf("failed to reconcile owned objects: %v", err)
 	}
 
@@ -990,7 +1118,7 @@ func (a *kagentReconciler) upsertAgent(ctx context.Context, agent *v1alpha2.Agen
 		Config: agentOutputs.Config,
 	}
 
-	if err := a.dbClient.StoreAgent(dbAgent); err != nil {
+	if err := a.dbClient.WithContext(ctx).StoreAgent(dbAgent); err != nil {
 		return fmt.Errorf("failed to store agent %s: %v", id, err)
 	}
 
@@ -1002,7 +1130,7 @@ func (a *kagentReconciler) upsertToolServerForRemoteMCPServer(ctx context.Contex
 	a.upsertLock.Lock()
 	defer a.upsertLock.Unlock()
 
-	if _, err := a.dbClient.StoreToolServer(toolServer); err != nil {
+	if _, err := a.dbClient.WithContext(ctx).StoreToolServer(toolServer); err != nil {
 		return nil, fmt.Errorf("failed to store toolServer %s: %v", toolServer.Name, err)
 	}
 
@@ -1016,7 +1144,7 @@ func (a *kagentReconciler) upsertToolServerForRemoteMCPServer(ctx context.Contex
 		return nil, fmt.Errorf("failed to fetch tools for toolServer %s: %v", toolServer.Name, err)
 	}
 
-	if err := a.dbClient.RefreshToolsForServer(toolServer.Name, toolServer.GroupKind, tools...); err != nil {
+	if err := a.dbClient.WithContext(ctx).RefreshToolsForServer(toolServer.Name, toolServer.GroupKind, tools...); err != nil {
 		return nil, fmt.Errorf("failed to refresh tools for toolServer %s: %v", toolServer.Name, err)
 	}
 
@@ -1075,7 +1203,7 @@ func (a *kagentReconciler) listTools(ctx context.Context, tsp transport.Interfac
 
 func (a *kagentReconciler) getDiscoveredMCPTools(ctx context.Context, serverRef string) ([]*v1alpha2.MCPTool, error) {
 	// This function is currently only used for RemoteMCPServer
-	allTools, err := a.dbClient.ListToolsForServer(serverRef, schema.GroupKind{Group: "kagent.dev", Kind: "RemoteMCPServer"}.String())
+	allTools, err := a.dbClient.WithContext(ctx).ListToolsForServer(serverRef, schema.GroupKind{Group: "kagent.dev", Kind: "RemoteMCPServer"}.String())
 	if err != nil {
 		return nil, err
 	}
@@ -6,6 +6,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 )
 
 // TestComputeStatusSecretHash_Output verifies the output of the hash function
@@ -191,3 +194,102 @@ func TestComputeStatusSecretHash_Deterministic(t *testing.T) {
 		})
 	}
 }
+
+// TestGenerateMCPContainerForDataSource verifies the MCP server container dispatches on the DataSource provider
+func TestGenerateMCPContainerForDataSource(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         v1alpha2.DataSourceSpec
+		wantName     string
+		wantArgs     []string
+		wantEnv      string
+		wantSecret   string
+		wantErrorMsg string
+	}{
+		{
+			name: "databricks",
+			spec: v1alpha2.DataSourceSpec{
+				Provider: v1alpha2.DataSourceProviderDatabricks,
+				Databricks: &v1alpha2.DatabricksConfig{
+					WorkspaceURL:         "https://example.cloud.databricks.com",
+					CredentialsSecretRef: "databricks-creds",
+					CredentialsSecretKey: "token",
+					Catalog:              "main",
+					Schema:               "sales",
+				},
+			},
+			wantName:   "databricks-mcp",
+			wantArgs:   []string{"--workspace-url=https://example.cloud.databricks.com", "--catalog=main", "--schema=sales", "--read-only=true"},
+			wantEnv:    "DATABRICKS_TOKEN",
+			wantSecret: "databricks-creds",
+		},
+		{
+			name: "databricks with statement guard settings",
+			spec: v1alpha2.DataSourceSpec{
+				Provider: v1alpha2.DataSourceProviderDatabricks,
+				Databricks: &v1alpha2.DatabricksConfig{
+					WorkspaceURL:          "https://example.cloud.databricks.com",
+					CredentialsSecretRef:  "databricks-creds",
+					CredentialsSecretKey:  "token",
+					Catalog:               "main",
+					ReadOnly:              ptr.To(false),
+					AllowedStatementTypes: []string{"SELECT", "INSERT"},
+				},
+			},
+			wantName:   "databricks-mcp",
+			wantArgs:   []string{"--workspace-url=https://example.cloud.databricks.com", "--catalog=main", "--read-only=false", "--allowed-statement-types=SELECT,INSERT"},
+			wantEnv:    "DATABRICKS_TOKEN",
+			wantSecret: "databricks-creds",
+		},
+		{
+			name: "postgresql",
+			spec: v1alpha2.DataSourceSpec{
+				Provider: v1alpha2.DataSourceProviderPostgreSQL,
+				PostgreSQL: &v1alpha2.PostgreSQLConfig{
+					Host:                 "postgres.data.svc",
+					Port:                 5433,
+					Database:             "analytics",
+					User:                 "analyst",
+					CredentialsSecretRef: "postgres-creds",
+					CredentialsSecretKey: "password",
+					SSLMode:              "require",
+				},
+			},
+			wantName:   "postgres-mcp",
+			wantArgs:   []string{"--host=postgres.data.svc", "--database=analytics", "--user=analyst", "--db-port=5433", "--sslmode=require"},
+			wantEnv:    "PGPASSWORD",
+			wantSecret: "postgres-creds",
+		},
+		{
+			name: "postgresql without config",
+			spec: v1alpha2.DataSourceSpec{
+				Provider: v1alpha2.DataSourceProviderPostgreSQL,
+			},
+			wantErrorMsg: "postgresql config is required",
+		},
+		{
+			name: "unsupported provider",
+			spec: v1alpha2.DataSourceSpec{
+				Provider: "Unknown",
+			},
+			wantErrorMsg: "unsupported datasource provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateMCPContainerForDataSource(&v1alpha2.DataSource{Spec: tt.spec})
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.name)
+			assert.Equal(t, tt.wantArgs, got.args)
+			if assert.Len(t, got.env, 1) {
+				assert.Equal(t, tt.wantEnv, got.env[0].Name)
+				assert.Equal(t, tt.wantSecret, got.env[0].ValueFrom.SecretKeyRef.Name)
+			}
+		})
+	}
+}
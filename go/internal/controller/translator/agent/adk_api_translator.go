@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 
+	kagentv1alpha1 "github.com/kagent-dev/kagent/go/api/v1alpha1"
 	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 	"github.com/kagent-dev/kagent/go/internal/adk"
 	"github.com/kagent-dev/kagent/go/internal/controller/translator/labels"
@@ -193,15 +194,16 @@ func (a *adkApiTranslator) validateAgent(ctx context.Context, agent *v1alpha2.Ag
 			return fmt.Errorf("tool must have an agent reference")
 		}
 
-		agentRef := types.NamespacedName{
-			Namespace: agent.Namespace,
-			Name:      tool.Agent.Name,
-		}
+		agentRef := tool.Agent.NamespacedName(agent.Namespace)
 
 		if agentRef.Namespace == agent.Namespace && agentRef.Name == agent.Name {
 			return fmt.Errorf("agent tool cannot be used to reference itself, %s", agentRef)
 		}
 
+		if err := a.checkReferenceGrant(ctx, agent.Namespace, agentGroupKind, agentRef); err != nil {
+			return err
+		}
+
 		toolAgent := &v1alpha2.Agent{}
 		err := a.kube.Get(ctx, agentRef, toolAgent)
 		if err != nil {
@@ -523,15 +525,16 @@ func (a *adkApiTranslator) translateInlineAgent(ctx context.Context, agent *v1al
 				return nil, nil, nil, err
 			}
 		case tool.Agent != nil:
-			agentRef := types.NamespacedName{
-				Namespace: agent.Namespace,
-				Name:      tool.Agent.Name,
-			}
+			agentRef := tool.Agent.NamespacedName(agent.Namespace)
 
 			if agentRef.Namespace == agent.Namespace && agentRef.Name == agent.Name {
 				return nil, nil, nil, fmt.Errorf("agent tool cannot be used to reference itself, %s", agentRef)
 			}
 
+			if err := a.checkReferenceGrant(ctx, agent.Namespace, agentGroupKind, agentRef); err != nil {
+				return nil, nil, nil, err
+			}
+
 			// Translate a nested tool
 			toolAgent := &v1alpha2.Agent{}
 			err := a.kube.Get(ctx, agentRef, toolAgent)
@@ -562,9 +565,92 @@ func (a *adkApiTranslator) translateInlineAgent(ctx context.Context, agent *v1al
 		}
 	}
 
+	for _, memoryRef := range agent.Spec.Declarative.Memory {
+		memoryHashBytes, err := a.translateMemory(ctx, cfg, mdd, agent.Namespace, memoryRef)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		secretHashBytes = append(secretHashBytes, memoryHashBytes...)
+	}
+
 	return cfg, mdd, secretHashBytes, nil
 }
 
+// translateMemory resolves a Memory reference into the agent config. The memory API key
+// is exposed to the agent through an environment variable backed by the referenced Secret,
+// and a hash of its value is returned so that key rotations roll the agent deployment.
+func (a *adkApiTranslator) translateMemory(ctx context.Context, cfg *adk.AgentConfig, mdd *modelDeploymentData, agentNamespace, memoryRef string) ([]byte, error) {
+	memoryNns, err := utils.ParseRefString(memoryRef, agentNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse memory reference %s: %w", memoryRef, err)
+	}
+	if err := a.checkReferenceGrant(ctx, agentNamespace, memoryGroupKind, memoryNns); err != nil {
+		return nil, err
+	}
+
+	memory := &kagentv1alpha1.Memory{}
+	if err := a.kube.Get(ctx, memoryNns, memory); err != nil {
+		return nil, fmt.Errorf("failed to get memory %s: %w", memoryNns, err)
+	}
+
+	switch memory.Spec.Provider {
+	case kagentv1alpha1.Pinecone:
+		if memory.Spec.Pinecone == nil {
+			return nil, fmt.Errorf("pinecone config is required for memory %s", memoryNns)
+		}
+
+		memoryName := utils.ConvertToPythonIdentifier(memoryNns.String())
+		pinecone := &adk.PineconeMemoryConfig{
+			IndexHost:      memory.Spec.Pinecone.IndexHost,
+			TopK:           memory.Spec.Pinecone.TopK,
+			Namespace:      memory.Spec.Pinecone.Namespace,
+			RecordFields:   memory.Spec.Pinecone.RecordFields,
+			ScoreThreshold: utils.ParseStringToFloat64(memory.Spec.Pinecone.ScoreThreshold),
+		}
+
+		var hashBytes []byte
+		if memory.Spec.APIKeySecretRef != "" {
+			secretNns, err := utils.ParseRefString(memory.Spec.APIKeySecretRef, memory.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse api key secret reference for memory %s: %w", memoryNns, err)
+			}
+			// The secret is mounted as an env var on the agent pod, so it must live alongside the agent.
+			if secretNns.Namespace != agentNamespace {
+				return nil, fmt.Errorf("api key secret %s for memory %s must be in the agent namespace %s", secretNns, memoryNns, agentNamespace)
+			}
+
+			apiKey, err := utils.GetSecretValue(ctx, a.kube, secretNns, memory.Spec.APIKeySecretKey)
+			if err != nil {
+				return nil, err
+			}
+			hash := sha256.Sum256([]byte(apiKey))
+			hashBytes = hash[:]
+
+			pinecone.APIKeyEnv = strings.ToUpper(fmt.Sprintf("KAGENT_MEMORY_%s_API_KEY", memoryName))
+			mdd.EnvVars = append(mdd.EnvVars, corev1.EnvVar{
+				Name: pinecone.APIKeyEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: secretNns.Name,
+						},
+						Key: memory.Spec.APIKeySecretKey,
+					},
+				},
+			})
+		}
+
+		cfg.Memory = append(cfg.Memory, adk.MemoryConfig{
+			Name:     memoryName,
+			Type:     adk.MemoryTypePinecone,
+			Pinecone: pinecone,
+		})
+		return hashBytes, nil
+	default:
+		return nil, fmt.Errorf("unsupported memory provider %s for memory %s", memory.Spec.Provider, memoryNns)
+	}
+}
+
 func (a *adkApiTranslator) resolveSystemMessage(ctx context.Context, agent *v1alpha2.Agent) (string, error) {
 	if agent.Spec.Declarative.SystemMessageFrom != nil {
 		return agent.Spec.Declarative.SystemMessageFrom.Resolve(ctx, a.kube, agent.Namespace)
@@ -583,7 +669,7 @@ const (
 
 // populateTLSFields populates TLS configuration fields in the BaseModel
 // from the ModelConfig TLS spec.
-func populateTLSFields(baseModel *adk.BaseModel, tlsConfig *v1alpha2.TLSConfig) {
+func populateTLSFields(baseModel *adk.BaseModel, tlsConfig *v1alpha2.TLSConfig, res modelResources) {
 	if tlsConfig == nil {
 		return
 	}
@@ -594,7 +680,7 @@ func populateTLSFields(baseModel *adk.BaseModel, tlsConfig *v1alpha2.TLSConfig)
 
 	// Set CA cert path if Secret and key are both specified
 	if tlsConfig.CACertSecretRef != "" && tlsConfig.CACertSecretKey != "" {
-		certPath := fmt.Sprintf("%s/%s", tlsCACertMountPath, tlsConfig.CACertSecretKey)
+		certPath := fmt.Sprintf("%s/%s", res.path(tlsCACertMountPath), tlsConfig.CACertSecretKey)
 		baseModel.TLSCACertPath = &certPath
 	}
 }
@@ -603,7 +689,7 @@ func populateTLSFields(baseModel *adk.BaseModel, tlsConfig *v1alpha2.TLSConfig)
 // when TLS configuration is present in the ModelConfig.
 // Note: TLS configuration fields are now included in agent config JSON via BaseModel,
 // so this function only handles volume mounting.
-func addTLSConfiguration(modelDeploymentData *modelDeploymentData, tlsConfig *v1alpha2.TLSConfig) {
+func addTLSConfiguration(modelDeploymentData *modelDeploymentData, tlsConfig *v1alpha2.TLSConfig, res modelResources) {
 	if tlsConfig == nil {
 		return
 	}
@@ -612,7 +698,7 @@ func addTLSConfiguration(modelDeploymentData *modelDeploymentData, tlsConfig *v1
 	if tlsConfig.CACertSecretRef != "" && tlsConfig.CACertSecretKey != "" {
 		// Add volume from Secret
 		modelDeploymentData.Volumes = append(modelDeploymentData.Volumes, corev1.Volume{
-			Name: tlsCACertVolumeName,
+			Name: res.volume(tlsCACertVolumeName),
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
 					SecretName:  tlsConfig.CACertSecretRef,
@@ -623,20 +709,125 @@ func addTLSConfiguration(modelDeploymentData *modelDeploymentData, tlsConfig *v1
 
 		// Add volume mount
 		modelDeploymentData.VolumeMounts = append(modelDeploymentData.VolumeMounts, corev1.VolumeMount{
-			Name:      tlsCACertVolumeName,
-			MountPath: tlsCACertMountPath,
+			Name:      res.volume(tlsCACertVolumeName),
+			MountPath: res.path(tlsCACertMountPath),
 			ReadOnly:  true,
 		})
 	}
 }
 
 func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelConfig string) (adk.Model, *modelDeploymentData, []byte, error) {
+	modelConfigRef, err := utils.ParseRefString(modelConfig, namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := a.checkReferenceGrant(ctx, namespace, modelConfigGroupKind, modelConfigRef); err != nil {
+		return nil, nil, nil, err
+	}
+
 	model := &v1alpha2.ModelConfig{}
-	err := a.kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: modelConfig}, model)
+	err = a.kube.Get(ctx, modelConfigRef, model)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
+	primary, modelDeploymentData, secretHashBytes, err := translateModelConfig(model, modelResources{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(model.Spec.Fallbacks) == 0 {
+		return primary, modelDeploymentData, secretHashBytes, nil
+	}
+	return a.translateFallbacks(ctx, namespace, model, primary, modelDeploymentData, secretHashBytes)
+}
+
+// translateFallbacks wraps the primary model in the fallback chain of the model
+// config. The env vars and volumes of every fallback are renamed, so that
+// models of the same provider don't overwrite each other's credentials.
+// Fallbacks are referenced by the agent in agentNamespace as its model is.
+func (a *adkApiTranslator) translateFallbacks(ctx context.Context, agentNamespace string, model *v1alpha2.ModelConfig, primary adk.Model, modelDeploymentData *modelDeploymentData, secretHashBytes []byte) (adk.Model, *modelDeploymentData, []byte, error) {
+	chain := &adk.FallbackModel{Primary: primary}
+	seen := map[string]bool{model.Name: true}
+
+	for i, fallback := range model.Spec.Fallbacks {
+		if seen[fallback.ModelConfig] {
+			return nil, nil, nil, fmt.Errorf("model config %s appears more than once in the fallback chain of %s", fallback.ModelConfig, utils.GetObjectRef(model))
+		}
+		seen[fallback.ModelConfig] = true
+
+		fallbackRef := types.NamespacedName{Namespace: model.Namespace, Name: fallback.ModelConfig}
+		if err := a.checkReferenceGrant(ctx, agentNamespace, modelConfigGroupKind, fallbackRef); err != nil {
+			return nil, nil, nil, err
+		}
+		fallbackModel := &v1alpha2.ModelConfig{}
+		if err := a.kube.Get(ctx, fallbackRef, fallbackModel); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get fallback model config %s: %w", fallback.ModelConfig, err)
+		}
+
+		res := fallbackModelResources(i + 1)
+		translated, fallbackData, fallbackHashBytes, err := translateModelConfig(fallbackModel, res)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to translate fallback model config %s: %w", fallback.ModelConfig, err)
+		}
+
+		env := map[string]string{}
+		for _, envVar := range fallbackData.EnvVars {
+			env[strings.TrimPrefix(envVar.Name, res.envPrefix)] = envVar.Name
+		}
+		triggers := make([]string, 0, len(fallback.GetTriggers()))
+		for _, trigger := range fallback.GetTriggers() {
+			triggers = append(triggers, fallbackTriggers[trigger])
+		}
+		chain.Fallbacks = append(chain.Fallbacks, adk.ModelFallback{
+			Model:    translated,
+			Triggers: triggers,
+			Env:      env,
+		})
+
+		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, fallbackData.EnvVars...)
+		modelDeploymentData.Volumes = append(modelDeploymentData.Volumes, fallbackData.Volumes...)
+		modelDeploymentData.VolumeMounts = append(modelDeploymentData.VolumeMounts, fallbackData.VolumeMounts...)
+		secretHashBytes = append(secretHashBytes, fallbackHashBytes...)
+	}
+
+	return chain, modelDeploymentData, secretHashBytes, nil
+}
+
+var fallbackTriggers = map[v1alpha2.ModelFallbackTrigger]string{
+	v1alpha2.ModelFallbackTriggerRateLimited:     adk.FallbackTriggerRateLimited,
+	v1alpha2.ModelFallbackTriggerServerError:     adk.FallbackTriggerServerError,
+	v1alpha2.ModelFallbackTriggerTimeout:         adk.FallbackTriggerTimeout,
+	v1alpha2.ModelFallbackTriggerConnectionError: adk.FallbackTriggerConnectionError,
+}
+
+// modelResources names the env vars and volumes of a model. The primary model
+// uses the names its provider reads by default.
+type modelResources struct {
+	envPrefix    string
+	volumeSuffix string
+}
+
+func fallbackModelResources(position int) modelResources {
+	return modelResources{
+		envPrefix:    fmt.Sprintf("KAGENT_FALLBACK_%d_", position),
+		volumeSuffix: fmt.Sprintf("-fallback-%d", position),
+	}
+}
+
+func (r modelResources) env(name string) string {
+	return r.envPrefix + name
+}
+
+func (r modelResources) volume(name string) string {
+	return name + r.volumeSuffix
+}
+
+func (r modelResources) path(path string) string {
+	return path + r.volumeSuffix
+}
+
+// translateModelConfig translates a single model config, without its fallbacks.
+func translateModelConfig(model *v1alpha2.ModelConfig, res modelResources) (adk.Model, *modelDeploymentData, []byte, error) {
 	// Decode hex-encoded secret hash to bytes
 	var secretHashBytes []byte
 	if model.Status.SecretHash != "" {
@@ -650,13 +841,13 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 	modelDeploymentData := &modelDeploymentData{}
 
 	// Add TLS configuration if present
-	addTLSConfiguration(modelDeploymentData, model.Spec.TLS)
+	addTLSConfiguration(modelDeploymentData, model.Spec.TLS, res)
 
 	switch model.Spec.Provider {
 	case v1alpha2.ModelProviderOpenAI:
 		if model.Spec.APIKeySecret != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name: "OPENAI_API_KEY",
+				Name: res.env("OPENAI_API_KEY"),
 				ValueFrom: &corev1.EnvVarSource{
 					SecretKeyRef: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
@@ -674,7 +865,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&openai.BaseModel, model.Spec.TLS)
+		populateTLSFields(&openai.BaseModel, model.Spec.TLS, res)
 
 		if model.Spec.OpenAI != nil {
 			openai.BaseUrl = model.Spec.OpenAI.BaseURL
@@ -702,7 +893,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 
 			if model.Spec.OpenAI.Organization != "" {
 				modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-					Name:  "OPENAI_ORGANIZATION",
+					Name:  res.env("OPENAI_ORGANIZATION"),
 					Value: model.Spec.OpenAI.Organization,
 				})
 			}
@@ -711,7 +902,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 	case v1alpha2.ModelProviderAnthropic:
 		if model.Spec.APIKeySecret != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name: "ANTHROPIC_API_KEY",
+				Name: res.env("ANTHROPIC_API_KEY"),
 				ValueFrom: &corev1.EnvVarSource{
 					SecretKeyRef: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{
@@ -729,7 +920,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&anthropic.BaseModel, model.Spec.TLS)
+		populateTLSFields(&anthropic.BaseModel, model.Spec.TLS, res)
 
 		if model.Spec.Anthropic != nil {
 			anthropic.BaseUrl = model.Spec.Anthropic.BaseURL
@@ -740,7 +931,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			return nil, nil, nil, fmt.Errorf("AzureOpenAI model config is required")
 		}
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name: "AZURE_OPENAI_API_KEY",
+			Name: res.env("AZURE_OPENAI_API_KEY"),
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
@@ -752,19 +943,19 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 		})
 		if model.Spec.AzureOpenAI.AzureADToken != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name:  "AZURE_AD_TOKEN",
+				Name:  res.env("AZURE_AD_TOKEN"),
 				Value: model.Spec.AzureOpenAI.AzureADToken,
 			})
 		}
 		if model.Spec.AzureOpenAI.APIVersion != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name:  "OPENAI_API_VERSION",
+				Name:  res.env("OPENAI_API_VERSION"),
 				Value: model.Spec.AzureOpenAI.APIVersion,
 			})
 		}
 		if model.Spec.AzureOpenAI.Endpoint != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name:  "AZURE_OPENAI_ENDPOINT",
+				Name:  res.env("AZURE_OPENAI_ENDPOINT"),
 				Value: model.Spec.AzureOpenAI.Endpoint,
 			})
 		}
@@ -775,7 +966,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&azureOpenAI.BaseModel, model.Spec.TLS)
+		populateTLSFields(&azureOpenAI.BaseModel, model.Spec.TLS, res)
 
 		return azureOpenAI, modelDeploymentData, secretHashBytes, nil
 	case v1alpha2.ModelProviderGeminiVertexAI:
@@ -783,24 +974,24 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			return nil, nil, nil, fmt.Errorf("GeminiVertexAI model config is required")
 		}
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name:  "GOOGLE_CLOUD_PROJECT",
+			Name:  res.env("GOOGLE_CLOUD_PROJECT"),
 			Value: model.Spec.GeminiVertexAI.ProjectID,
 		})
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name:  "GOOGLE_CLOUD_LOCATION",
+			Name:  res.env("GOOGLE_CLOUD_LOCATION"),
 			Value: model.Spec.GeminiVertexAI.Location,
 		})
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name:  "GOOGLE_GENAI_USE_VERTEXAI",
+			Name:  res.env("GOOGLE_GENAI_USE_VERTEXAI"),
 			Value: "true",
 		})
 		if model.Spec.APIKeySecret != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
-				Value: "/creds/" + model.Spec.APIKeySecretKey,
+				Name:  res.env("GOOGLE_APPLICATION_CREDENTIALS"),
+				Value: res.path("/creds") + "/" + model.Spec.APIKeySecretKey,
 			})
 			modelDeploymentData.Volumes = append(modelDeploymentData.Volumes, corev1.Volume{
-				Name: googleCredsVolumeName,
+				Name: res.volume(googleCredsVolumeName),
 				VolumeSource: corev1.VolumeSource{
 					Secret: &corev1.SecretVolumeSource{
 						SecretName: model.Spec.APIKeySecret,
@@ -808,8 +999,8 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 				},
 			})
 			modelDeploymentData.VolumeMounts = append(modelDeploymentData.VolumeMounts, corev1.VolumeMount{
-				Name:      googleCredsVolumeName,
-				MountPath: "/creds",
+				Name:      res.volume(googleCredsVolumeName),
+				MountPath: res.path("/creds"),
 			})
 		}
 		gemini := &adk.GeminiVertexAI{
@@ -819,7 +1010,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&gemini.BaseModel, model.Spec.TLS)
+		populateTLSFields(&gemini.BaseModel, model.Spec.TLS, res)
 
 		return gemini, modelDeploymentData, secretHashBytes, nil
 	case v1alpha2.ModelProviderAnthropicVertexAI:
@@ -827,20 +1018,20 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			return nil, nil, nil, fmt.Errorf("AnthropicVertexAI model config is required")
 		}
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name:  "GOOGLE_CLOUD_PROJECT",
+			Name:  res.env("GOOGLE_CLOUD_PROJECT"),
 			Value: model.Spec.AnthropicVertexAI.ProjectID,
 		})
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name:  "GOOGLE_CLOUD_LOCATION",
+			Name:  res.env("GOOGLE_CLOUD_LOCATION"),
 			Value: model.Spec.AnthropicVertexAI.Location,
 		})
 		if model.Spec.APIKeySecret != "" {
 			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
-				Value: "/creds/" + model.Spec.APIKeySecretKey,
+				Name:  res.env("GOOGLE_APPLICATION_CREDENTIALS"),
+				Value: res.path("/creds") + "/" + model.Spec.APIKeySecretKey,
 			})
 			modelDeploymentData.Volumes = append(modelDeploymentData.Volumes, corev1.Volume{
-				Name: googleCredsVolumeName,
+				Name: res.volume(googleCredsVolumeName),
 				VolumeSource: corev1.VolumeSource{
 					Secret: &corev1.SecretVolumeSource{
 						SecretName: model.Spec.APIKeySecret,
@@ -848,8 +1039,8 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 				},
 			})
 			modelDeploymentData.VolumeMounts = append(modelDeploymentData.VolumeMounts, corev1.VolumeMount{
-				Name:      googleCredsVolumeName,
-				MountPath: "/creds",
+				Name:      res.volume(googleCredsVolumeName),
+				MountPath: res.path("/creds"),
 			})
 		}
 		anthropic := &adk.GeminiAnthropic{
@@ -859,7 +1050,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&anthropic.BaseModel, model.Spec.TLS)
+		populateTLSFields(&anthropic.BaseModel, model.Spec.TLS, res)
 
 		return anthropic, modelDeploymentData, secretHashBytes, nil
 	case v1alpha2.ModelProviderOllama:
@@ -867,7 +1058,7 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			return nil, nil, nil, fmt.Errorf("ollama model config is required")
 		}
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name:  "OLLAMA_API_BASE",
+			Name:  res.env("OLLAMA_API_BASE"),
 			Value: model.Spec.Ollama.Host,
 		})
 		ollama := &adk.Ollama{
@@ -877,12 +1068,12 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&ollama.BaseModel, model.Spec.TLS)
+		populateTLSFields(&ollama.BaseModel, model.Spec.TLS, res)
 
 		return ollama, modelDeploymentData, secretHashBytes, nil
 	case v1alpha2.ModelProviderGemini:
 		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
-			Name: "GOOGLE_API_KEY",
+			Name: res.env("GOOGLE_API_KEY"),
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
@@ -899,9 +1090,86 @@ func (a *adkApiTranslator) translateModel(ctx context.Context, namespace, modelC
 			},
 		}
 		// Populate TLS fields in BaseModel
-		populateTLSFields(&gemini.BaseModel, model.Spec.TLS)
+		populateTLSFields(&gemini.BaseModel, model.Spec.TLS, res)
 
 		return gemini, modelDeploymentData, secretHashBytes, nil
+	case v1alpha2.ModelProviderOpenAICompatible:
+		if model.Spec.OpenAICompatible == nil {
+			return nil, nil, nil, fmt.Errorf("OpenAICompatible model config is required")
+		}
+		if model.Spec.APIKeySecret != "" {
+			modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
+				Name: res.env("OPENAI_COMPATIBLE_API_KEY"),
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: model.Spec.APIKeySecret,
+						},
+						Key: model.Spec.APIKeySecretKey,
+					},
+				},
+			})
+		}
+		openAICompatible := &adk.OpenAICompatible{
+			BaseModel: adk.BaseModel{
+				Model:   model.Spec.Model,
+				Headers: model.Spec.DefaultHeaders,
+			},
+			BaseUrl:     model.Spec.OpenAICompatible.BaseURL,
+			AuthHeader:  model.Spec.OpenAICompatible.AuthHeader,
+			Temperature: utils.ParseStringToFloat64(model.Spec.OpenAICompatible.Temperature),
+			TopP:        utils.ParseStringToFloat64(model.Spec.OpenAICompatible.TopP),
+		}
+		if model.Spec.OpenAICompatible.MaxTokens > 0 {
+			openAICompatible.MaxTokens = &model.Spec.OpenAICompatible.MaxTokens
+		}
+		// Populate TLS fields in BaseModel
+		populateTLSFields(&openAICompatible.BaseModel, model.Spec.TLS, res)
+
+		return openAICompatible, modelDeploymentData, secretHashBytes, nil
+	case v1alpha2.ModelProviderBedrock:
+		if model.Spec.Bedrock == nil {
+			return nil, nil, nil, fmt.Errorf("Bedrock model config is required")
+		}
+		modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
+			Name:  res.env("AWS_REGION"),
+			Value: model.Spec.Bedrock.Region,
+		})
+		// Static credentials, otherwise the default credential chain (e.g. IRSA) is used
+		if model.Spec.Bedrock.CredentialsSecretRef != "" {
+			for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+				secretKeyRef := &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: model.Spec.Bedrock.CredentialsSecretRef,
+					},
+					Key: key,
+				}
+				if key == "AWS_SESSION_TOKEN" {
+					secretKeyRef.Optional = ptr.To(true)
+				}
+				modelDeploymentData.EnvVars = append(modelDeploymentData.EnvVars, corev1.EnvVar{
+					Name:      res.env(key),
+					ValueFrom: &corev1.EnvVarSource{SecretKeyRef: secretKeyRef},
+				})
+			}
+		}
+		bedrock := &adk.Bedrock{
+			BaseModel: adk.BaseModel{
+				Model:   model.Spec.Model,
+				Headers: model.Spec.DefaultHeaders,
+			},
+			Region:           model.Spec.Bedrock.Region,
+			InferenceProfile: model.Spec.Bedrock.InferenceProfile,
+			Temperature:      utils.ParseStringToFloat64(model.Spec.Bedrock.Temperature),
+			TopP:             utils.ParseStringToFloat64(model.Spec.Bedrock.TopP),
+		}
+		if model.Spec.Bedrock.MaxTokens > 0 {
+			bedrock.MaxTokens = &model.Spec.Bedrock.MaxTokens
+		}
+		// Populate TLS fields in BaseModel
+		populateTLSFields(&bedrock.BaseModel, model.Spec.TLS, res)
+
+		return bedrock, modelDeploymentData, secretHashBytes, nil
 	}
 
 	return nil, nil, nil, fmt.Errorf("unknown model provider: %s", model.Spec.Provider)
@@ -966,8 +1234,13 @@ func (a *adkApiTranslator) translateMCPServerTarget(ctx context.Context, agent *
 		Group: "kagent.dev",
 		Kind:  "MCPServer",
 	}:
+		mcpServerRef := toolServer.NamespacedName(agentNamespace)
+		if err := a.checkReferenceGrant(ctx, agentNamespace, mcpServerGroupKind, mcpServerRef); err != nil {
+			return err
+		}
+
 		mcpServer := &v1alpha1.MCPServer{}
-		err := a.kube.Get(ctx, types.NamespacedName{Namespace: agentNamespace, Name: toolServer.Name}, mcpServer)
+		err := a.kube.Get(ctx, mcpServerRef, mcpServer)
 		if err != nil {
 			return err
 		}
@@ -979,7 +1252,7 @@ func (a *adkApiTranslator) translateMCPServerTarget(ctx context.Context, agent *
 
 		spec.HeadersFrom = append(spec.HeadersFrom, toolHeaders...)
 
-		return a.translateRemoteMCPServerTarget(ctx, agent, agentNamespace, spec, toolServer.ToolNames)
+		return a.translateRemoteMCPServerTarget(ctx, agent, agentNamespace, spec, toolServer.ToolNames, toolServer.RequireApproval)
 	case schema.GroupKind{
 		Group: "",
 		Kind:  "RemoteMCPServer",
@@ -989,15 +1262,32 @@ func (a *adkApiTranslator) translateMCPServerTarget(ctx context.Context, agent *
 		Group: "kagent.dev",
 		Kind:  "RemoteMCPServer",
 	}:
+		remoteMcpServerRef := toolServer.NamespacedName(agentNamespace)
+		if err := a.checkReferenceGrant(ctx, agentNamespace, remoteMCPServerGroupKind, remoteMcpServerRef); err != nil {
+			return err
+		}
+
 		remoteMcpServer := &v1alpha2.RemoteMCPServer{}
-		err := a.kube.Get(ctx, types.NamespacedName{Namespace: agentNamespace, Name: toolServer.Name}, remoteMcpServer)
+		err := a.kube.Get(ctx, remoteMcpServerRef, remoteMcpServer)
 		if err != nil {
 			return err
 		}
 
+		// Headers of the server are resolved in its own namespace, headers of the tool in the agent's namespace
+		if remoteMcpServer.Namespace != agentNamespace {
+			headers, err := remoteMcpServer.Spec.ResolveHeaders(ctx, a.kube, remoteMcpServer.Namespace)
+			if err != nil {
+				return err
+			}
+			remoteMcpServer.Spec.HeadersFrom = make([]v1alpha2.ValueRef, 0, len(headers))
+			for _, name := range slices.Sorted(maps.Keys(headers)) {
+				remoteMcpServer.Spec.HeadersFrom = append(remoteMcpServer.Spec.HeadersFrom, v1alpha2.ValueRef{Name: name, Value: headers[name]})
+			}
+		}
+
 		remoteMcpServer.Spec.HeadersFrom = append(remoteMcpServer.Spec.HeadersFrom, toolHeaders...)
 
-		return a.translateRemoteMCPServerTarget(ctx, agent, agentNamespace, &remoteMcpServer.Spec, toolServer.ToolNames)
+		return a.translateRemoteMCPServerTarget(ctx, agent, agentNamespace, &remoteMcpServer.Spec, toolServer.ToolNames, toolServer.RequireApproval)
 	case schema.GroupKind{
 		Group: "",
 		Kind:  "Service",
@@ -1007,8 +1297,13 @@ func (a *adkApiTranslator) translateMCPServerTarget(ctx context.Context, agent *
 		Group: "core",
 		Kind:  "Service",
 	}:
+		svcRef := toolServer.NamespacedName(agentNamespace)
+		if err := a.checkReferenceGrant(ctx, agentNamespace, serviceGroupKind, svcRef); err != nil {
+			return err
+		}
+
 		svc := &corev1.Service{}
-		err := a.kube.Get(ctx, types.NamespacedName{Namespace: agentNamespace, Name: toolServer.Name}, svc)
+		err := a.kube.Get(ctx, svcRef, svc)
 		if err != nil {
 			return err
 		}
@@ -1020,7 +1315,7 @@ func (a *adkApiTranslator) translateMCPServerTarget(ctx context.Context, agent *
 
 		spec.HeadersFrom = append(spec.HeadersFrom, toolHeaders...)
 
-		return a.translateRemoteMCPServerTarget(ctx, agent, agentNamespace, spec, toolServer.ToolNames)
+		return a.translateRemoteMCPServerTarget(ctx, agent, agentNamespace, spec, toolServer.ToolNames, toolServer.RequireApproval)
 
 	default:
 		return fmt.Errorf("unknown tool server type: %s", gvk)
@@ -1085,7 +1380,7 @@ func ConvertMCPServerToRemoteMCPServer(mcpServer *v1alpha1.MCPServer) (*v1alpha2
 	}, nil
 }
 
-func (a *adkApiTranslator) translateRemoteMCPServerTarget(ctx context.Context, agent *adk.AgentConfig, agentNamespace string, remoteMcpServer *v1alpha2.RemoteMCPServerSpec, toolNames []string) error {
+func (a *adkApiTranslator) translateRemoteMCPServerTarget(ctx context.Context, agent *adk.AgentConfig, agentNamespace string, remoteMcpServer *v1alpha2.RemoteMCPServerSpec, toolNames, requireApproval []string) error {
 	// Ensure toolNames is never nil - Python ADK expects an empty list, not null
 	// This can happen when Kubernetes omits empty arrays from stored resources
 	if toolNames == nil {
@@ -1099,8 +1394,9 @@ func (a *adkApiTranslator) translateRemoteMCPServerTarget(ctx context.Context, a
 			return err
 		}
 		agent.SseTools = append(agent.SseTools, adk.SseMcpServerConfig{
-			Params: *tool,
-			Tools:  toolNames,
+			Params:          *tool,
+			Tools:           toolNames,
+			RequireApproval: requireApproval,
 		})
 	default:
 		tool, err := a.translateStreamableHttpTool(ctx, remoteMcpServer, agentNamespace)
@@ -1108,8 +1404,9 @@ func (a *adkApiTranslator) translateRemoteMCPServerTarget(ctx context.Context, a
 			return err
 		}
 		agent.HttpTools = append(agent.HttpTools, adk.HttpMcpServerConfig{
-			Params: *tool,
-			Tools:  toolNames,
+			Params:          *tool,
+			Tools:           toolNames,
+			RequireApproval: requireApproval,
 		})
 	}
 	return nil
@@ -0,0 +1,100 @@
+package agent_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	schemev1 "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/adk"
+	translator "github.com/kagent-dev/kagent/go/internal/controller/translator/agent"
+)
+
+func TestTranslateAgent_ModelFallbacks(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, schemev1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	modelConfig := func(name string, fallbacks ...string) *v1alpha2.ModelConfig {
+		mc := &v1alpha2.ModelConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: v1alpha2.ModelConfigSpec{
+				Provider: v1alpha2.ModelProviderOllama,
+				Model:    name,
+				Ollama:   &v1alpha2.OllamaConfig{Host: "http://ollama:11434"},
+			},
+		}
+		for _, fallback := range fallbacks {
+			mc.Spec.Fallbacks = append(mc.Spec.Fallbacks, v1alpha2.ModelFallback{ModelConfig: fallback})
+		}
+		return mc
+	}
+
+	agent := &v1alpha2.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "test"},
+		Spec: v1alpha2.AgentSpec{
+			Type: v1alpha2.AgentType_Declarative,
+			Declarative: &v1alpha2.DeclarativeAgentSpec{
+				SystemMessage: "You are a helpful assistant.",
+				ModelConfig:   "primary",
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		primary *v1alpha2.ModelConfig
+		wantErr string
+	}{
+		{
+			name:    "fallbacks of fallbacks are not followed",
+			primary: modelConfig("primary", "secondary"),
+		},
+		{
+			name:    "missing fallback",
+			primary: modelConfig("primary", "missing"),
+			wantErr: "failed to get fallback model config missing",
+		},
+		{
+			name:    "self reference",
+			primary: modelConfig("primary", "primary"),
+			wantErr: "model config primary appears more than once in the fallback chain of test/primary",
+		},
+		{
+			name:    "duplicate fallback",
+			primary: modelConfig("primary", "secondary", "secondary"),
+			wantErr: "model config secondary appears more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.primary, modelConfig("secondary", "tertiary"), modelConfig("tertiary")).
+				Build()
+
+			outputs, err := translator.NewAdkApiTranslator(kubeClient, types.NamespacedName{Namespace: "test", Name: "default-model"}, nil).
+				TranslateAgent(context.Background(), agent.DeepCopy())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			chain, ok := outputs.Config.Model.(*adk.FallbackModel)
+			require.True(t, ok, "expected a fallback model, got %T", outputs.Config.Model)
+			require.Len(t, chain.Fallbacks, 1)
+			assert.Equal(t, "secondary", chain.Fallbacks[0].Model.(*adk.Ollama).Model)
+			assert.Equal(t, []string{adk.FallbackTriggerRateLimited, adk.FallbackTriggerServerError, adk.FallbackTriggerTimeout}, chain.Fallbacks[0].Triggers)
+			assert.Equal(t, map[string]string{"OLLAMA_API_BASE": "KAGENT_FALLBACK_1_OLLAMA_API_BASE"}, chain.Fallbacks[0].Env)
+		})
+	}
+}
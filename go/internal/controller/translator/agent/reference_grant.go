@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	agentGroupKind           = schema.GroupKind{Group: "kagent.dev", Kind: "Agent"}
+	modelConfigGroupKind     = schema.GroupKind{Group: "kagent.dev", Kind: "ModelConfig"}
+	mcpServerGroupKind       = schema.GroupKind{Group: "kagent.dev", Kind: "MCPServer"}
+	remoteMCPServerGroupKind = schema.GroupKind{Group: "kagent.dev", Kind: "RemoteMCPServer"}
+	memoryGroupKind          = schema.GroupKind{Group: "kagent.dev", Kind: "Memory"}
+	serviceGroupKind         = schema.GroupKind{Group: "", Kind: "Service"}
+)
+
+// checkReferenceGrant returns an error unless an Agent in fromNamespace may
+// reference target. References within a namespace are always allowed, references
+// to other namespaces need a ReferenceGrant in the target namespace.
+func (a *adkApiTranslator) checkReferenceGrant(ctx context.Context, fromNamespace string, to schema.GroupKind, target types.NamespacedName) error {
+	if target.Namespace == fromNamespace {
+		return nil
+	}
+
+	grants := &v1alpha2.ReferenceGrantList{}
+	if err := a.kube.List(ctx, grants, client.InNamespace(target.Namespace)); err != nil {
+		return fmt.Errorf("failed to list reference grants in namespace %s: %w", target.Namespace, err)
+	}
+	for _, grant := range grants.Items {
+		if grant.Spec.Permits(agentGroupKind, fromNamespace, to, target.Name) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s %s is not allowed to be referenced from namespace %s: no ReferenceGrant permits it", to.Kind, target, fromNamespace)
+}
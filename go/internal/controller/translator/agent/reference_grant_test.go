@@ -0,0 +1,294 @@
+package agent_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	schemev1 "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha1"
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/adk"
+	translator "github.com/kagent-dev/kagent/go/internal/controller/translator/agent"
+)
+
+func TestTranslateAgent_CrossNamespaceReferences(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, schemev1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	sharedObjects := []client.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "openai-secret", Namespace: "test"},
+			Data:       map[string][]byte{"api-key": []byte("sk-test")},
+		},
+		&v1alpha2.ModelConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared-model", Namespace: "shared"},
+			Spec: v1alpha2.ModelConfigSpec{
+				Provider:        v1alpha2.ModelProviderOpenAI,
+				Model:           "gpt-4o",
+				APIKeySecret:    "openai-secret",
+				APIKeySecretKey: "api-key",
+			},
+		},
+		&v1alpha2.RemoteMCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "toolserver", Namespace: "shared"},
+			Spec: v1alpha2.RemoteMCPServerSpec{
+				URL:      "http://toolserver.shared:8084/mcp",
+				Protocol: v1alpha2.RemoteMCPServerProtocolStreamableHttp,
+			},
+		},
+	}
+
+	agent := &v1alpha2.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "test"},
+		Spec: v1alpha2.AgentSpec{
+			Type: v1alpha2.AgentType_Declarative,
+			Declarative: &v1alpha2.DeclarativeAgentSpec{
+				SystemMessage: "You are a Kubernetes agent.",
+				ModelConfig:   "shared/shared-model",
+				Tools: []*v1alpha2.Tool{{
+					Type: v1alpha2.ToolProviderType_McpServer,
+					McpServer: &v1alpha2.McpServerTool{
+						TypedLocalReference: v1alpha2.TypedLocalReference{
+							Kind:      "RemoteMCPServer",
+							ApiGroup:  "kagent.dev",
+							Name:      "toolserver",
+							Namespace: "shared",
+						},
+						ToolNames: []string{"k8s_get_resources"},
+					},
+				}},
+			},
+		},
+	}
+
+	grant := func(from string, to ...v1alpha2.ReferenceGrantTo) *v1alpha2.ReferenceGrant {
+		return &v1alpha2.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "grant-" + from, Namespace: "shared"},
+			Spec: v1alpha2.ReferenceGrantSpec{
+				From: []v1alpha2.ReferenceGrantFrom{{Group: "kagent.dev", Kind: "Agent", Namespace: from}},
+				To:   to,
+			},
+		}
+	}
+	modelConfigs := v1alpha2.ReferenceGrantTo{Group: "kagent.dev", Kind: "ModelConfig"}
+
+	tests := []struct {
+		name    string
+		grants  []client.Object
+		wantErr string
+	}{
+		{
+			name:    "no grant",
+			wantErr: "ModelConfig shared/shared-model is not allowed to be referenced from namespace test",
+		},
+		{
+			name:    "grant for another namespace",
+			grants:  []client.Object{grant("other", modelConfigs, v1alpha2.ReferenceGrantTo{Group: "kagent.dev", Kind: "RemoteMCPServer"})},
+			wantErr: "ModelConfig shared/shared-model is not allowed",
+		},
+		{
+			name:    "grant for another tool server",
+			grants:  []client.Object{grant("test", modelConfigs, v1alpha2.ReferenceGrantTo{Group: "kagent.dev", Kind: "RemoteMCPServer", Name: ptr.To("other")})},
+			wantErr: "RemoteMCPServer shared/toolserver is not allowed",
+		},
+		{
+			name:   "granted",
+			grants: []client.Object{grant("test", modelConfigs, v1alpha2.ReferenceGrantTo{Group: "kagent.dev", Kind: "RemoteMCPServer", Name: ptr.To("toolserver")})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(sharedObjects...).
+				WithObjects(tt.grants...).
+				Build()
+
+			outputs, err := translator.NewAdkApiTranslator(kubeClient, types.NamespacedName{Namespace: "test", Name: "default-model"}, nil).
+				TranslateAgent(context.Background(), agent.DeepCopy())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, outputs.Config.HttpTools, 1)
+			assert.Equal(t, "http://toolserver.shared:8084/mcp", outputs.Config.HttpTools[0].Params.Url)
+		})
+	}
+}
+
+func TestTranslateAgent_CrossNamespaceFallbacks(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, schemev1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	modelConfig := func(name string, fallbacks ...string) *v1alpha2.ModelConfig {
+		mc := &v1alpha2.ModelConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shared"},
+			Spec: v1alpha2.ModelConfigSpec{
+				Provider: v1alpha2.ModelProviderOllama,
+				Model:    name,
+				Ollama:   &v1alpha2.OllamaConfig{Host: "http://ollama:11434"},
+			},
+		}
+		for _, fallback := range fallbacks {
+			mc.Spec.Fallbacks = append(mc.Spec.Fallbacks, v1alpha2.ModelFallback{ModelConfig: fallback})
+		}
+		return mc
+	}
+	objects := []client.Object{modelConfig("primary", "secondary"), modelConfig("secondary")}
+
+	agent := &v1alpha2.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "test"},
+		Spec: v1alpha2.AgentSpec{
+			Type: v1alpha2.AgentType_Declarative,
+			Declarative: &v1alpha2.DeclarativeAgentSpec{
+				SystemMessage: "You are a helpful assistant.",
+				ModelConfig:   "shared/primary",
+			},
+		},
+	}
+
+	grant := func(names ...string) *v1alpha2.ReferenceGrant {
+		g := &v1alpha2.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "shared"},
+			Spec: v1alpha2.ReferenceGrantSpec{
+				From: []v1alpha2.ReferenceGrantFrom{{Group: "kagent.dev", Kind: "Agent", Namespace: "test"}},
+			},
+		}
+		for _, name := range names {
+			g.Spec.To = append(g.Spec.To, v1alpha2.ReferenceGrantTo{Group: "kagent.dev", Kind: "ModelConfig", Name: ptr.To(name)})
+		}
+		return g
+	}
+
+	tests := []struct {
+		name    string
+		grants  []client.Object
+		wantErr string
+	}{
+		{
+			name:    "fallback not granted",
+			grants:  []client.Object{grant("primary")},
+			wantErr: "ModelConfig shared/secondary is not allowed to be referenced from namespace test",
+		},
+		{
+			name:   "granted",
+			grants: []client.Object{grant("primary", "secondary")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithObjects(tt.grants...).
+				Build()
+
+			outputs, err := translator.NewAdkApiTranslator(kubeClient, types.NamespacedName{Namespace: "test", Name: "default-model"}, nil).
+				TranslateAgent(context.Background(), agent.DeepCopy())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, &adk.FallbackModel{}, outputs.Config.Model)
+		})
+	}
+}
+
+func TestTranslateAgent_CrossNamespaceMemory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, schemev1.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	objects := []client.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "openai-secret", Namespace: "test"},
+			Data:       map[string][]byte{"api-key": []byte("sk-test")},
+		},
+		&v1alpha2.ModelConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-model", Namespace: "test"},
+			Spec: v1alpha2.ModelConfigSpec{
+				Provider:        v1alpha2.ModelProviderOpenAI,
+				Model:           "gpt-4o",
+				APIKeySecret:    "openai-secret",
+				APIKeySecretKey: "api-key",
+			},
+		},
+		&v1alpha1.Memory{
+			ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "shared"},
+			Spec: v1alpha1.MemorySpec{
+				Provider: v1alpha1.Pinecone,
+				Pinecone: &v1alpha1.PineconeConfig{IndexHost: "https://docs.svc.pinecone.io"},
+			},
+		},
+	}
+
+	agent := &v1alpha2.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "test"},
+		Spec: v1alpha2.AgentSpec{
+			Type: v1alpha2.AgentType_Declarative,
+			Declarative: &v1alpha2.DeclarativeAgentSpec{
+				SystemMessage: "You are a helpful assistant.",
+				ModelConfig:   "default-model",
+				Memory:        []string{"shared/docs"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		grants  []client.Object
+		wantErr string
+	}{
+		{
+			name:    "no grant",
+			wantErr: "Memory shared/docs is not allowed to be referenced from namespace test",
+		},
+		{
+			name: "granted",
+			grants: []client.Object{&v1alpha2.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{Name: "memories", Namespace: "shared"},
+				Spec: v1alpha2.ReferenceGrantSpec{
+					From: []v1alpha2.ReferenceGrantFrom{{Group: "kagent.dev", Kind: "Agent", Namespace: "test"}},
+					To:   []v1alpha2.ReferenceGrantTo{{Group: "kagent.dev", Kind: "Memory", Name: ptr.To("docs")}},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithObjects(tt.grants...).
+				Build()
+
+			outputs, err := translator.NewAdkApiTranslator(kubeClient, types.NamespacedName{Namespace: "test", Name: "default-model"}, nil).
+				TranslateAgent(context.Background(), agent.DeepCopy())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, outputs.Config.Memory, 1)
+			assert.Equal(t, "https://docs.svc.pinecone.io", outputs.Config.Memory[0].Pinecone.IndexHost)
+		})
+	}
+}
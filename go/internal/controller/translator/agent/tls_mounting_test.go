@@ -12,7 +12,7 @@ import (
 func Test_addTLSConfiguration_NoTLSConfig(t *testing.T) {
 	mdd := &modelDeploymentData{}
 
-	addTLSConfiguration(mdd, nil)
+	addTLSConfiguration(mdd, nil, modelResources{})
 
 	assert.Empty(t, mdd.Volumes, "Expected no volumes when TLS config is nil")
 	assert.Empty(t, mdd.VolumeMounts, "Expected no volume mounts when TLS config is nil")
@@ -26,7 +26,7 @@ func Test_addTLSConfiguration_WithDisableVerify(t *testing.T) {
 		DisableSystemCAs: true,
 	}
 
-	addTLSConfiguration(mdd, tlsConfig)
+	addTLSConfiguration(mdd, tlsConfig, modelResources{})
 
 	// Should not add volumes/mounts when no CACertSecretRef is set
 	assert.Empty(t, mdd.Volumes, "Expected no volumes when CACertSecretRef is empty")
@@ -43,7 +43,7 @@ func Test_addTLSConfiguration_WithCACertSecret(t *testing.T) {
 		DisableSystemCAs: false,
 	}
 
-	addTLSConfiguration(mdd, tlsConfig)
+	addTLSConfiguration(mdd, tlsConfig, modelResources{})
 
 	// Verify volume is added
 	require.Len(t, mdd.Volumes, 1, "Expected 1 volume for TLS cert secret")
@@ -71,7 +71,7 @@ func Test_addTLSConfiguration_MissingCACertKey(t *testing.T) {
 		// CACertSecretKey not set - both fields are required
 	}
 
-	addTLSConfiguration(mdd, tlsConfig)
+	addTLSConfiguration(mdd, tlsConfig, modelResources{})
 
 	// Should not add volumes when CACertSecretKey is not provided
 	assert.Empty(t, mdd.Volumes, "Expected no volumes when CACertSecretKey is empty")
@@ -86,7 +86,7 @@ func Test_addTLSConfiguration_CustomCertKey(t *testing.T) {
 		CACertSecretKey: "custom-ca.pem",
 	}
 
-	addTLSConfiguration(mdd, tlsConfig)
+	addTLSConfiguration(mdd, tlsConfig, modelResources{})
 
 	// Verify volume is added
 	require.Len(t, mdd.Volumes, 1, "Expected 1 volume for TLS cert with custom key")
@@ -121,7 +121,7 @@ func Test_addTLSConfiguration_DisableSystemCAsFlag(t *testing.T) {
 				DisableSystemCAs: tt.disableSystemCAs,
 			}
 
-			addTLSConfiguration(mdd, tlsConfig)
+			addTLSConfiguration(mdd, tlsConfig, modelResources{})
 
 			// Should not add volumes when no CACertSecretRef is set
 			assert.Empty(t, mdd.Volumes, "Expected no volumes when CACertSecretRef is empty")
@@ -140,7 +140,7 @@ func Test_addTLSConfiguration_AllFieldsCombined(t *testing.T) {
 		DisableSystemCAs: false,
 	}
 
-	addTLSConfiguration(mdd, tlsConfig)
+	addTLSConfiguration(mdd, tlsConfig, modelResources{})
 
 	// Verify volume and mount
 	require.Len(t, mdd.Volumes, 1, "Expected 1 volume for combined TLS config")
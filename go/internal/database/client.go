@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,19 +9,32 @@ import (
 	"time"
 
 	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/tracing"
 	"gorm.io/gorm"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
 type Client interface {
+	// WithContext returns a client whose calls run in ctx. Their spans join the
+	// trace of ctx, and the events and tasks they store record its trace ID.
+	WithContext(ctx context.Context) Client
+	// Transaction runs fn with a client whose calls run in one transaction,
+	// which is rolled back if fn returns an error.
+	Transaction(fn func(tx Client) error) error
+
 	// Store methods
 	StoreFeedback(feedback *Feedback) error
 	StoreSession(session *Session) error
 	StoreAgent(agent *Agent) error
 	StoreTask(task *protocol.Task) error
+	StoreTaskEvent(event *TaskEvent) error
 	StorePushNotification(config *protocol.TaskPushNotificationConfig) error
+	StorePushNotificationDelivery(delivery *PushNotificationDelivery) error
 	StoreToolServer(toolServer *ToolServer) (*ToolServer, error)
 	StoreEvents(messages ...*Event) error
+	StoreUsage(usage *Usage) error
+	StoreSessionShare(share *SessionShare) error
+	StoreSessionShareLink(link *SessionShareLink) error
 
 	// Delete methods
 	DeleteSession(sessionName string, userID string) error
@@ -29,26 +43,41 @@ type Client interface {
 	DeleteTask(taskID string) error
 	DeletePushNotification(taskID string) error
 	DeleteToolsForServer(serverName string, groupKind string) error
+	DeleteSessionShare(sessionID, ownerID, userID string) error
+	DeleteSessionShareLink(sessionID, ownerID, linkID string) error
 
 	// Get methods
 	GetSession(name string, userID string) (*Session, error)
 	GetAgent(name string) (*Agent, error)
 	GetTask(id string) (*protocol.Task, error)
+	GetLastTaskEventSequence(taskID string) (int, error)
 	GetTool(name string) (*Tool, error)
 	GetToolServer(name string) (*ToolServer, error)
 	GetPushNotification(taskID string, configID string) (*protocol.TaskPushNotificationConfig, error)
+	GetSessionShare(sessionID, userID string) (*SessionShare, error)
+	GetSessionShareLink(tokenHash string) (*SessionShareLink, error)
 
 	// List methods
 	ListTools() ([]Tool, error)
 	ListFeedback(userID string) ([]Feedback, error)
+	ListAllFeedback(filter FeedbackFilter) ([]Feedback, error)
 	ListTasksForSession(sessionID string) ([]*protocol.Task, error)
+	ListTaskEvents(taskID string, afterSequence int) ([]TaskEvent, error)
 	ListSessions(userID string) ([]Session, error)
 	ListSessionsForAgent(agentID string, userID string) ([]Session, error)
+	ListSessionsWithID(sessionID string) ([]Session, error)
 	ListAgents() ([]Agent, error)
 	ListToolServers() ([]ToolServer, error)
 	ListToolsForServer(serverName string, groupKind string) ([]Tool, error)
 	ListEventsForSession(sessionID, userID string, options QueryOptions) ([]*Event, error)
 	ListPushNotifications(taskID string) ([]*protocol.TaskPushNotificationConfig, error)
+	ListPushNotificationDeliveries(taskID string) ([]PushNotificationDelivery, error)
+	SummarizeUsage(groupBy UsageGroupBy, filter UsageFilter) ([]UsageAggregate, error)
+	SummarizeFeedback(groupBy FeedbackGroupBy, interval FeedbackInterval, filter FeedbackFilter) ([]FeedbackAggregate, error)
+	SearchSessions(query SearchQuery) ([]SearchResult, error)
+	ListSessionShares(sessionID, ownerID string) ([]SessionShare, error)
+	ListSessionShareLinks(sessionID, ownerID string) ([]SessionShareLink, error)
+	ListSharedSessions(userID string) ([]Session, error)
 
 	// Helper methods
 	RefreshToolsForServer(serverName string, groupKind string, tools ...*v1alpha2.MCPTool) error
@@ -82,14 +111,38 @@ func NewClient(dbManager *Manager) Client {
 	}
 }
 
-// CreateFeedback creates a new feedback record
+func (c *clientImpl) WithContext(ctx context.Context) Client {
+	return &clientImpl{
+		db: c.db.WithContext(ctx),
+	}
+}
+
+func (c *clientImpl) Transaction(fn func(tx Client) error) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&clientImpl{db: tx})
+	})
+}
+
+// traceID returns the ID of the trace the client's calls run in, if any.
+func (c *clientImpl) traceID() string {
+	return tracing.TraceID(c.db.Statement.Context)
+}
+
+// StoreFeedback creates a new feedback record. Feedback is never updated, and
+// its primary key differs between sqlite and postgres, so it isn't upserted.
 func (c *clientImpl) StoreFeedback(feedback *Feedback) error {
-	return save(c.db, feedback)
+	if err := c.db.Create(feedback).Error; err != nil {
+		return fmt.Errorf("failed to store feedback: %w", err)
+	}
+	return nil
 }
 
 // CreateSession creates a new session record
 func (c *clientImpl) StoreSession(session *Session) error {
-	return save(c.db, session)
+	if err := save(c.db, session); err != nil {
+		return err
+	}
+	return c.indexSession(session)
 }
 
 // CreateAgent creates a new agent record
@@ -208,10 +261,16 @@ func (c *clientImpl) ListFeedback(userID string) ([]Feedback, error) {
 
 func (c *clientImpl) StoreEvents(events ...*Event) error {
 	for _, event := range events {
+		if event.TraceID == "" {
+			event.TraceID = c.traceID()
+		}
 		err := save(c.db, event)
 		if err != nil {
 			return fmt.Errorf("failed to create event: %w", err)
 		}
+		if err := c.indexEvent(event); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -234,6 +293,11 @@ func (c *clientImpl) ListSessionsForAgent(agentID string, userID string) ([]Sess
 		Clause{Key: "user_id", Value: userID})
 }
 
+// ListSessionsWithID lists the sessions of all users with the given ID
+func (c *clientImpl) ListSessionsWithID(sessionID string) ([]Session, error) {
+	return list[Session](c.db, Clause{Key: "id", Value: sessionID})
+}
+
 // ListSessions lists all sessions for a user
 func (c *clientImpl) ListSessions(userID string) ([]Session, error) {
 	return list[Session](c.db, Clause{Key: "user_id", Value: userID})
@@ -418,6 +482,8 @@ func (c *clientImpl) StoreTask(task *protocol.Task) error {
 		ID:        task.ID,
 		Data:      string(data),
 		SessionID: task.ContextID,
+		State:     string(task.Status.State),
+		TraceID:   c.traceID(),
 	}
 
 	return save(c.db, &dbTask)
@@ -438,6 +504,32 @@ func (c *clientImpl) GetTask(taskID string) (*protocol.Task, error) {
 	return &task, nil
 }
 
+// StoreTaskEvent stores a streamed event of a task
+func (c *clientImpl) StoreTaskEvent(event *TaskEvent) error {
+	return save(c.db, event)
+}
+
+// GetLastTaskEventSequence returns the sequence number of the last stored event
+// of a task, or 0 if it has none
+func (c *clientImpl) GetLastTaskEventSequence(taskID string) (int, error) {
+	var sequence int
+	err := c.db.Model(&TaskEvent{}).Where("task_id = ?", taskID).Select("COALESCE(MAX(sequence), 0)").Scan(&sequence).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last task event sequence: %w", err)
+	}
+	return sequence, nil
+}
+
+// ListTaskEvents lists the stored events of a task after a sequence number, in order
+func (c *clientImpl) ListTaskEvents(taskID string, afterSequence int) ([]TaskEvent, error) {
+	var events []TaskEvent
+	err := c.db.Where("task_id = ? AND sequence > ?", taskID, afterSequence).Order("sequence ASC").Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task events: %w", err)
+	}
+	return events, nil
+}
+
 // TaskExists checks if a task exists in the database
 func (c *clientImpl) TaskExists(taskID string) bool {
 	var count int64
@@ -501,6 +593,17 @@ func (c *clientImpl) DeletePushNotification(taskID string) error {
 	return delete[PushNotification](c.db, Clause{Key: "task_id", Value: taskID})
 }
 
+// StorePushNotificationDelivery records a push notification delivery attempt
+func (c *clientImpl) StorePushNotificationDelivery(delivery *PushNotificationDelivery) error {
+	return save(c.db, delivery)
+}
+
+// ListPushNotificationDeliveries lists the delivery attempts of the push
+// notifications of a task, oldest first
+func (c *clientImpl) ListPushNotificationDeliveries(taskID string) ([]PushNotificationDelivery, error) {
+	return list[PushNotificationDelivery](c.db, Clause{Key: "task_id", Value: taskID})
+}
+
 // StoreCheckpoint stores a LangGraph checkpoint and its writes atomically
 func (c *clientImpl) StoreCheckpoint(checkpoint *LangGraphCheckpoint) error {
 	err := save(c.db, checkpoint)
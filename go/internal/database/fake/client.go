@@ -1,11 +1,13 @@
 package fake
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 	"github.com/kagent-dev/kagent/go/internal/database"
@@ -29,6 +31,11 @@ type InMemoryFakeClient struct {
 	checkpointWrites  map[string][]*database.LangGraphCheckpointWrite // key: user_id:thread_id:checkpoint_ns:checkpoint_id
 	crewaiMemory      map[string][]*database.CrewAIAgentMemory        // key: user_id:thread_id:agent_id
 	crewaiFlowStates  map[string]*database.CrewAIFlowState            // key: user_id:thread_id
+	usage             map[string]*database.Usage                      // key: userID_usageID
+	pushDeliveries    []database.PushNotificationDelivery
+	taskEvents        map[string][]database.TaskEvent       // key: taskID
+	sessionShares     map[string]*database.SessionShare     // key: sessionID_ownerID_userID
+	sessionShareLinks map[string]*database.SessionShareLink // key: linkID
 	nextFeedbackID    int
 }
 
@@ -48,10 +55,24 @@ func NewClient() database.Client {
 		checkpointWrites:  make(map[string][]*database.LangGraphCheckpointWrite),
 		crewaiMemory:      make(map[string][]*database.CrewAIAgentMemory),
 		crewaiFlowStates:  make(map[string]*database.CrewAIFlowState),
+		usage:             make(map[string]*database.Usage),
+		taskEvents:        make(map[string][]database.TaskEvent),
+		sessionShares:     make(map[string]*database.SessionShare),
+		sessionShareLinks: make(map[string]*database.SessionShareLink),
 		nextFeedbackID:    1,
 	}
 }
 
+// WithContext returns the client itself, the fake doesn't trace its calls
+func (c *InMemoryFakeClient) WithContext(ctx context.Context) database.Client {
+	return c
+}
+
+// Transaction runs fn with the client itself, the writes of a failed fn aren't rolled back.
+func (c *InMemoryFakeClient) Transaction(fn func(tx database.Client) error) error {
+	return fn(c)
+}
+
 func (c *InMemoryFakeClient) sessionKey(sessionID, userID string) string {
 	return fmt.Sprintf("%s_%s", sessionID, userID)
 }
@@ -101,8 +122,11 @@ func (c *InMemoryFakeClient) StoreFeedback(feedback *database.Feedback) error {
 	defer c.mu.Unlock()
 
 	// Copy the feedback and assign an ID
+	feedback.ID = uint(c.nextFeedbackID)
+	if feedback.CreatedAt.IsZero() {
+		feedback.CreatedAt = time.Now()
+	}
 	newFeedback := *feedback
-	newFeedback.ID = uint(c.nextFeedbackID)
 	c.nextFeedbackID++
 
 	key := fmt.Sprintf("%d", newFeedback.ID)
@@ -123,6 +147,27 @@ func (c *InMemoryFakeClient) StoreEvents(events ...*database.Event) error {
 	return nil
 }
 
+// StoreUsage creates a usage record, or updates the token counts of an existing one
+func (c *InMemoryFakeClient) StoreUsage(usage *database.Usage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s_%s", usage.UserID, usage.ID)
+	if existing, ok := c.usage[key]; ok {
+		existing.PromptTokens = usage.PromptTokens
+		existing.CompletionTokens = usage.CompletionTokens
+		existing.CachedTokens = usage.CachedTokens
+		existing.TotalTokens = usage.TotalTokens
+		return nil
+	}
+	stored := *usage
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	c.usage[key] = &stored
+	return nil
+}
+
 // StoreSession creates a new session record
 func (c *InMemoryFakeClient) StoreSession(session *database.Session) error {
 	c.mu.Lock()
@@ -152,12 +197,58 @@ func (c *InMemoryFakeClient) StoreTask(task *protocol.Task) error {
 		return err
 	}
 	c.tasks[task.ID] = &database.Task{
-		ID:   task.ID,
-		Data: string(jsn),
+		ID:        task.ID,
+		Data:      string(jsn),
+		SessionID: task.ContextID,
+		State:     string(task.Status.State),
 	}
 	return nil
 }
 
+// StoreTaskEvent stores a streamed event of a task, replacing one with the same sequence number
+func (c *InMemoryFakeClient) StoreTaskEvent(event *database.TaskEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *event
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	events := slices.DeleteFunc(c.taskEvents[event.TaskID], func(e database.TaskEvent) bool {
+		return e.Sequence == event.Sequence
+	})
+	events = append(events, stored)
+	slices.SortFunc(events, func(a, b database.TaskEvent) int { return a.Sequence - b.Sequence })
+	c.taskEvents[event.TaskID] = events
+	return nil
+}
+
+// GetLastTaskEventSequence returns the sequence number of the last event of a task, or 0
+func (c *InMemoryFakeClient) GetLastTaskEventSequence(taskID string) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	events := c.taskEvents[taskID]
+	if len(events) == 0 {
+		return 0, nil
+	}
+	return events[len(events)-1].Sequence, nil
+}
+
+// ListTaskEvents lists the events of a task after a sequence number, in order
+func (c *InMemoryFakeClient) ListTaskEvents(taskID string, afterSequence int) ([]database.TaskEvent, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.TaskEvent
+	for _, event := range c.taskEvents[taskID] {
+		if event.Sequence > afterSequence {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
 // StorePushNotification creates a new push notification record
 func (c *InMemoryFakeClient) StorePushNotification(config *protocol.TaskPushNotificationConfig) error {
 	c.mu.Lock()
@@ -167,6 +258,19 @@ func (c *InMemoryFakeClient) StorePushNotification(config *protocol.TaskPushNoti
 	return nil
 }
 
+// StorePushNotificationDelivery records a push notification delivery attempt
+func (c *InMemoryFakeClient) StorePushNotificationDelivery(delivery *database.PushNotificationDelivery) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *delivery
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	c.pushDeliveries = append(c.pushDeliveries, stored)
+	return nil
+}
+
 // StoreToolServer creates a new tool server record
 func (c *InMemoryFakeClient) StoreToolServer(toolServer *database.ToolServer) (*database.ToolServer, error) {
 	c.mu.Lock()
@@ -296,6 +400,95 @@ func (c *InMemoryFakeClient) ListFeedback(userID string) ([]database.Feedback, e
 	return result, nil
 }
 
+// ListAllFeedback lists the feedback of all users matching the filter, oldest first
+func (c *InMemoryFakeClient) ListAllFeedback(filter database.FeedbackFilter) ([]database.Feedback, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.Feedback
+	for _, feedback := range c.feedback {
+		if feedbackMatches(feedback, filter) {
+			result = append(result, *feedback)
+		}
+	}
+	slices.SortFunc(result, func(a, b database.Feedback) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return int(a.ID) - int(b.ID)
+	})
+	return result, nil
+}
+
+// SummarizeFeedback counts the feedback matching the filter by the group by dimension and interval
+func (c *InMemoryFakeClient) SummarizeFeedback(groupBy database.FeedbackGroupBy, interval database.FeedbackInterval, filter database.FeedbackFilter) ([]database.FeedbackAggregate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	type key struct{ group, period string }
+	aggregates := map[key]*database.FeedbackAggregate{}
+	for _, feedback := range c.feedback {
+		if !feedbackMatches(feedback, filter) {
+			continue
+		}
+
+		var k key
+		switch groupBy {
+		case database.FeedbackGroupByNone:
+		case database.FeedbackGroupByAgent:
+			k.group = feedback.AgentID
+		case database.FeedbackGroupByAgentVersion:
+			k.group = fmt.Sprintf("%s@%d", feedback.AgentID, feedback.AgentGeneration)
+		case database.FeedbackGroupByModelConfig:
+			k.group = feedback.ModelConfig
+		case database.FeedbackGroupByIssueType:
+			if feedback.IssueType != nil {
+				k.group = string(*feedback.IssueType)
+			}
+		default:
+			return nil, fmt.Errorf("invalid feedback group by %q", groupBy)
+		}
+		switch interval {
+		case database.FeedbackIntervalNone:
+		case database.FeedbackIntervalDay:
+			k.period = feedback.CreatedAt.UTC().Format(time.DateOnly)
+		default:
+			return nil, fmt.Errorf("invalid feedback interval %q", interval)
+		}
+
+		aggregate, ok := aggregates[k]
+		if !ok {
+			aggregate = &database.FeedbackAggregate{Group: k.group, Period: k.period}
+			aggregates[k] = aggregate
+		}
+		aggregate.Total++
+		if feedback.IsPositive {
+			aggregate.Positive++
+		}
+	}
+
+	result := make([]database.FeedbackAggregate, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		result = append(result, *aggregate)
+	}
+	slices.SortFunc(result, func(a, b database.FeedbackAggregate) int {
+		if a.Group != b.Group {
+			return strings.Compare(a.Group, b.Group)
+		}
+		return strings.Compare(a.Period, b.Period)
+	})
+	return result, nil
+}
+
+func feedbackMatches(feedback *database.Feedback, filter database.FeedbackFilter) bool {
+	return (filter.From.IsZero() || !feedback.CreatedAt.Before(filter.From)) &&
+		(filter.To.IsZero() || feedback.CreatedAt.Before(filter.To)) &&
+		(filter.AgentID == "" || feedback.AgentID == filter.AgentID) &&
+		(filter.UserID == "" || feedback.UserID == filter.UserID) &&
+		(filter.ModelConfig == "" || feedback.ModelConfig == filter.ModelConfig) &&
+		(filter.IsPositive == nil || feedback.IsPositive == *filter.IsPositive)
+}
+
 func (c *InMemoryFakeClient) ListTasksForSession(sessionID string) ([]*protocol.Task, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -347,6 +540,23 @@ func (c *InMemoryFakeClient) ListSessionsForAgent(agentID string, userID string)
 	return result, nil
 }
 
+// ListSessionsWithID lists the sessions of all users with the given ID
+func (c *InMemoryFakeClient) ListSessionsWithID(sessionID string) ([]database.Session, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.Session
+	for _, session := range c.sessions {
+		if session.ID == sessionID {
+			result = append(result, *session)
+		}
+	}
+	slices.SortStableFunc(result, func(i, j database.Session) int {
+		return strings.Compare(i.UserID, j.UserID)
+	})
+	return result, nil
+}
+
 // ListAgents lists all agents
 func (c *InMemoryFakeClient) ListAgents() ([]database.Agent, error) {
 	c.mu.RLock()
@@ -427,6 +637,80 @@ func (c *InMemoryFakeClient) ListPushNotifications(taskID string) ([]*protocol.T
 	return result, nil
 }
 
+// ListPushNotificationDeliveries lists the delivery attempts of a task, oldest first
+func (c *InMemoryFakeClient) ListPushNotificationDeliveries(taskID string) ([]database.PushNotificationDelivery, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.PushNotificationDelivery
+	for _, delivery := range c.pushDeliveries {
+		if delivery.TaskID == taskID {
+			result = append(result, delivery)
+		}
+	}
+	return result, nil
+}
+
+// SummarizeUsage aggregates usage records by the group by dimension and model
+func (c *InMemoryFakeClient) SummarizeUsage(groupBy database.UsageGroupBy, filter database.UsageFilter) ([]database.UsageAggregate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	type key struct{ group, model string }
+	aggregates := map[key]*database.UsageAggregate{}
+	for _, usage := range c.usage {
+		if (!filter.From.IsZero() && usage.CreatedAt.Before(filter.From)) ||
+			(!filter.To.IsZero() && !usage.CreatedAt.Before(filter.To)) ||
+			(filter.AgentID != "" && usage.AgentID != filter.AgentID) ||
+			(filter.UserID != "" && usage.UserID != filter.UserID) ||
+			(filter.SessionID != "" && usage.SessionID != filter.SessionID) ||
+			(filter.Model != "" && usage.Model != filter.Model) {
+			continue
+		}
+
+		var group string
+		switch groupBy {
+		case database.UsageGroupByNone:
+		case database.UsageGroupByAgent:
+			group = usage.AgentID
+		case database.UsageGroupByUser:
+			group = usage.UserID
+		case database.UsageGroupBySession:
+			group = usage.SessionID
+		case database.UsageGroupByModel:
+			group = usage.Model
+		case database.UsageGroupByDay:
+			group = usage.CreatedAt.UTC().Format(time.DateOnly)
+		default:
+			return nil, fmt.Errorf("invalid usage group by %q", groupBy)
+		}
+
+		k := key{group: group, model: usage.Model}
+		aggregate, ok := aggregates[k]
+		if !ok {
+			aggregate = &database.UsageAggregate{Group: group, Model: usage.Model}
+			aggregates[k] = aggregate
+		}
+		aggregate.Calls++
+		aggregate.PromptTokens += usage.PromptTokens
+		aggregate.CompletionTokens += usage.CompletionTokens
+		aggregate.CachedTokens += usage.CachedTokens
+		aggregate.TotalTokens += usage.TotalTokens
+	}
+
+	result := make([]database.UsageAggregate, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		result = append(result, *aggregate)
+	}
+	slices.SortFunc(result, func(a, b database.UsageAggregate) int {
+		if a.Group != b.Group {
+			return strings.Compare(a.Group, b.Group)
+		}
+		return strings.Compare(a.Model, b.Model)
+	})
+	return result, nil
+}
+
 // ListEventsForSession retrieves events for a specific session
 func (c *InMemoryFakeClient) ListEventsForSession(sessionID, userID string, options database.QueryOptions) ([]*database.Event, error) {
 	c.mu.RLock()
@@ -440,6 +724,54 @@ func (c *InMemoryFakeClient) ListEventsForSession(sessionID, userID string, opti
 	return events, nil
 }
 
+// SearchSessions matches the words of the query case-insensitively against
+// the text of events and session names, without ranking
+func (c *InMemoryFakeClient) SearchSessions(query database.SearchQuery) ([]database.SearchResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	words := strings.Fields(strings.ToLower(query.Query))
+	if len(words) == 0 {
+		return nil, fmt.Errorf("search query is required")
+	}
+	matches := func(text string) bool {
+		text = strings.ToLower(text)
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+		return true
+	}
+	inRange := func(t time.Time) bool {
+		return (query.From.IsZero() || !t.Before(query.From)) && (query.To.IsZero() || t.Before(query.To))
+	}
+
+	var results []database.SearchResult
+	for _, session := range c.sessions {
+		if session.DeletedAt.Valid || (query.UserID != "" && session.UserID != query.UserID) ||
+			(query.AgentID != "" && (session.AgentID == nil || *session.AgentID != query.AgentID)) {
+			continue
+		}
+		if session.Name != nil && matches(*session.Name) && inRange(session.CreatedAt) {
+			results = append(results, database.SearchResult{SessionID: session.ID, SessionName: session.Name, AgentID: session.AgentID, Snippet: *session.Name, CreatedAt: session.CreatedAt})
+		}
+		for _, event := range c.eventsBySession[session.ID] {
+			if event.UserID != session.UserID || !inRange(event.CreatedAt) {
+				continue
+			}
+			if text := database.EventText(event.Data); matches(text) {
+				results = append(results, database.SearchResult{SessionID: session.ID, SessionName: session.Name, AgentID: session.AgentID, EventID: event.ID, Snippet: text, CreatedAt: event.CreatedAt})
+			}
+		}
+	}
+	slices.SortFunc(results, func(a, b database.SearchResult) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
 // RefreshToolsForServer refreshes a tool server
 func (c *InMemoryFakeClient) RefreshToolsForServer(serverName string, groupKind string, tools ...*v1alpha2.MCPTool) error {
 	c.mu.Lock()
@@ -534,6 +866,9 @@ func (c *InMemoryFakeClient) Clear() {
 	c.pushNotifications = make(map[string]*protocol.TaskPushNotificationConfig)
 	c.checkpoints = make(map[string]*database.LangGraphCheckpoint)
 	c.checkpointWrites = make(map[string][]*database.LangGraphCheckpointWrite)
+	c.usage = make(map[string]*database.Usage)
+	c.pushDeliveries = nil
+	c.taskEvents = make(map[string][]database.TaskEvent)
 	c.nextFeedbackID = 1
 }
 
@@ -879,3 +1214,130 @@ func (c *InMemoryFakeClient) GetCrewAIFlowState(userID, threadID string) (*datab
 
 	return state, nil
 }
+
+func (c *InMemoryFakeClient) sessionShareKey(sessionID, ownerID, userID string) string {
+	return fmt.Sprintf("%s_%s_%s", sessionID, ownerID, userID)
+}
+
+// StoreSessionShare grants a user a role in a session, replacing the role they had in it
+func (c *InMemoryFakeClient) StoreSessionShare(share *database.SessionShare) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *share
+	c.sessionShares[c.sessionShareKey(share.SessionID, share.OwnerID, share.UserID)] = &stored
+	return nil
+}
+
+// DeleteSessionShare revokes the access of a user to a session
+func (c *InMemoryFakeClient) DeleteSessionShare(sessionID, ownerID, userID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.sessionShares, c.sessionShareKey(sessionID, ownerID, userID))
+	return nil
+}
+
+// GetSessionShare returns the share of a session with a user
+func (c *InMemoryFakeClient) GetSessionShare(sessionID, userID string) (*database.SessionShare, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, share := range c.sessionShares {
+		if share.SessionID == sessionID && share.UserID == userID {
+			return share, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListSessionShares lists the users a session is shared with
+func (c *InMemoryFakeClient) ListSessionShares(sessionID, ownerID string) ([]database.SessionShare, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.SessionShare
+	for _, share := range c.sessionShares {
+		if share.SessionID == sessionID && share.OwnerID == ownerID {
+			result = append(result, *share)
+		}
+	}
+	slices.SortStableFunc(result, func(i, j database.SessionShare) int {
+		return strings.Compare(i.UserID, j.UserID)
+	})
+	return result, nil
+}
+
+// ListSharedSessions lists the sessions shared with a user
+func (c *InMemoryFakeClient) ListSharedSessions(userID string) ([]database.Session, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.Session
+	for _, share := range c.sessionShares {
+		if share.UserID != userID {
+			continue
+		}
+		if session, ok := c.sessions[c.sessionKey(share.SessionID, share.OwnerID)]; ok {
+			result = append(result, *session)
+		}
+	}
+	slices.SortStableFunc(result, func(i, j database.Session) int {
+		return strings.Compare(i.ID, j.ID)
+	})
+	return result, nil
+}
+
+// StoreSessionShareLink stores a share link of a session
+func (c *InMemoryFakeClient) StoreSessionShareLink(link *database.SessionShareLink) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *link
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	c.sessionShareLinks[link.ID] = &stored
+	return nil
+}
+
+// GetSessionShareLink returns the share link with the hash of a token
+func (c *InMemoryFakeClient) GetSessionShareLink(tokenHash string) (*database.SessionShareLink, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, link := range c.sessionShareLinks {
+		if link.TokenHash == tokenHash {
+			return link, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListSessionShareLinks lists the share links of a session
+func (c *InMemoryFakeClient) ListSessionShareLinks(sessionID, ownerID string) ([]database.SessionShareLink, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []database.SessionShareLink
+	for _, link := range c.sessionShareLinks {
+		if link.SessionID == sessionID && link.OwnerID == ownerID {
+			result = append(result, *link)
+		}
+	}
+	slices.SortStableFunc(result, func(i, j database.SessionShareLink) int {
+		return i.CreatedAt.Compare(j.CreatedAt)
+	})
+	return result, nil
+}
+
+// DeleteSessionShareLink deletes a share link of a session
+func (c *InMemoryFakeClient) DeleteSessionShareLink(sessionID, ownerID, linkID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if link, ok := c.sessionShareLinks[linkID]; ok && link.SessionID == sessionID && link.OwnerID == ownerID {
+		delete(c.sessionShareLinks, linkID)
+	}
+	return nil
+}
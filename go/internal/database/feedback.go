@@ -0,0 +1,151 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FeedbackGroupBy is the dimension feedback is aggregated by.
+type FeedbackGroupBy string
+
+const (
+	FeedbackGroupByNone  FeedbackGroupBy = ""
+	FeedbackGroupByAgent FeedbackGroupBy = "agent"
+	// FeedbackGroupByAgentVersion groups by agent and generation of its spec,
+	// formatted as namespace/name@generation.
+	FeedbackGroupByAgentVersion FeedbackGroupBy = "agent_version"
+	FeedbackGroupByModelConfig  FeedbackGroupBy = "model_config"
+	FeedbackGroupByIssueType    FeedbackGroupBy = "issue_type"
+)
+
+// FeedbackInterval is the period feedback is bucketed by over time.
+type FeedbackInterval string
+
+const (
+	FeedbackIntervalNone FeedbackInterval = ""
+	FeedbackIntervalDay  FeedbackInterval = "day"
+)
+
+// FeedbackFilter restricts the feedback that is aggregated or listed. Zero
+// fields match all rows.
+type FeedbackFilter struct {
+	// From and To bound the creation time of the feedback, From inclusive and To exclusive.
+	From        time.Time
+	To          time.Time
+	AgentID     string
+	UserID      string
+	ModelConfig string
+	IsPositive  *bool
+}
+
+// FeedbackAggregate counts the feedback of a group in a period.
+type FeedbackAggregate struct {
+	// Group is the value of the group by dimension, empty without grouping.
+	Group string `json:"group"`
+	// Period is the day of the feedback formatted as YYYY-MM-DD in UTC, empty
+	// without an interval.
+	Period   string `json:"period"`
+	Total    int64  `json:"total"`
+	Positive int64  `json:"positive"`
+}
+
+// SummarizeFeedback counts the feedback matching the filter, and how much of it
+// is positive, by the group by dimension and interval.
+func (c *clientImpl) SummarizeFeedback(groupBy FeedbackGroupBy, interval FeedbackInterval, filter FeedbackFilter) ([]FeedbackAggregate, error) {
+	groupExpr, err := c.feedbackGroupExpression(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	periodExpr, err := c.feedbackPeriodExpression(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	selects := []string{"COUNT(*) AS total", "SUM(CASE WHEN is_positive THEN 1 ELSE 0 END) AS positive"}
+	var groups []string
+	if groupExpr != "" {
+		selects = append(selects, groupExpr+` AS "group"`)
+		groups = append(groups, groupExpr)
+	}
+	if periodExpr != "" {
+		selects = append(selects, periodExpr+" AS period")
+		groups = append(groups, periodExpr)
+	}
+
+	query := filterFeedback(c.db.Model(&Feedback{}), filter).Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+
+	var aggregates []FeedbackAggregate
+	if err := query.Scan(&aggregates).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarize feedback: %w", err)
+	}
+	return aggregates, nil
+}
+
+// ListAllFeedback lists the feedback of all users matching the filter, oldest
+// first.
+func (c *clientImpl) ListAllFeedback(filter FeedbackFilter) ([]Feedback, error) {
+	var feedback []Feedback
+	if err := filterFeedback(c.db, filter).Order("created_at ASC, id ASC").Find(&feedback).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+func filterFeedback(query *gorm.DB, filter FeedbackFilter) *gorm.DB {
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	for _, cl := range []Clause{
+		{Key: "agent_id", Value: filter.AgentID},
+		{Key: "user_id", Value: filter.UserID},
+		{Key: "model_config", Value: filter.ModelConfig},
+	} {
+		if cl.Value != "" {
+			query = query.Where(fmt.Sprintf("%s = ?", cl.Key), cl.Value)
+		}
+	}
+	if filter.IsPositive != nil {
+		query = query.Where("is_positive = ?", *filter.IsPositive)
+	}
+	return query
+}
+
+func (c *clientImpl) feedbackGroupExpression(groupBy FeedbackGroupBy) (string, error) {
+	switch groupBy {
+	case FeedbackGroupByNone:
+		return "", nil
+	case FeedbackGroupByAgent:
+		return "COALESCE(agent_id, '')", nil
+	case FeedbackGroupByAgentVersion:
+		return "COALESCE(agent_id, '') || '@' || COALESCE(agent_generation, 0)", nil
+	case FeedbackGroupByModelConfig:
+		return "COALESCE(model_config, '')", nil
+	case FeedbackGroupByIssueType:
+		return "COALESCE(issue_type, '')", nil
+	default:
+		return "", fmt.Errorf("invalid feedback group by %q", groupBy)
+	}
+}
+
+func (c *clientImpl) feedbackPeriodExpression(interval FeedbackInterval) (string, error) {
+	switch interval {
+	case FeedbackIntervalNone:
+		return "", nil
+	case FeedbackIntervalDay:
+		if c.db.Name() == string(DatabaseTypePostgres) {
+			return "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')", nil
+		}
+		return "date(created_at)", nil
+	default:
+		return "", fmt.Errorf("invalid feedback interval %q", interval)
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"k8s.io/utils/ptr"
+)
+
+func TestSummarizeFeedback(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, time.October, d, hour, 0, 0, 0, time.UTC) }
+	tool := FeedbackIssueTypeTool
+
+	manager := newTestManager(t)
+	require.NoError(t, manager.Initialize())
+	client := NewClient(manager)
+
+	for _, feedback := range []*Feedback{
+		{UserID: "user-1", AgentID: "default/a", AgentGeneration: 1, ModelConfig: "default/gpt", IsPositive: true, FeedbackText: "good", Model: gorm.Model{CreatedAt: day(1, 10)}},
+		{UserID: "user-2", AgentID: "default/a", AgentGeneration: 1, ModelConfig: "default/gpt", IsPositive: false, FeedbackText: "no tool", IssueType: &tool, Model: gorm.Model{CreatedAt: day(1, 23)}},
+		{UserID: "user-1", AgentID: "default/a", AgentGeneration: 2, ModelConfig: "default/gemini", IsPositive: true, FeedbackText: "better", Model: gorm.Model{CreatedAt: day(2, 1)}},
+		{UserID: "user-1", AgentID: "default/b", IsPositive: false, FeedbackText: "wrong", Model: gorm.Model{CreatedAt: day(2, 2)}},
+	} {
+		require.NoError(t, client.StoreFeedback(feedback))
+	}
+
+	t.Run("totals", func(t *testing.T) {
+		aggregates, err := client.SummarizeFeedback(FeedbackGroupByNone, FeedbackIntervalNone, FeedbackFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []FeedbackAggregate{{Total: 4, Positive: 2}}, aggregates)
+	})
+
+	t.Run("by agent version over time", func(t *testing.T) {
+		aggregates, err := client.SummarizeFeedback(FeedbackGroupByAgentVersion, FeedbackIntervalDay, FeedbackFilter{AgentID: "default/a"})
+		require.NoError(t, err)
+		assert.Equal(t, []FeedbackAggregate{
+			{Group: "default/a@1", Period: "2026-10-01", Total: 2, Positive: 1},
+			{Group: "default/a@2", Period: "2026-10-02", Total: 1, Positive: 1},
+		}, aggregates)
+	})
+
+	t.Run("by issue type", func(t *testing.T) {
+		aggregates, err := client.SummarizeFeedback(FeedbackGroupByIssueType, FeedbackIntervalNone, FeedbackFilter{IsPositive: ptr.To(false)})
+		require.NoError(t, err)
+		assert.Equal(t, []FeedbackAggregate{
+			{Group: "", Total: 1},
+			{Group: "tool", Total: 1},
+		}, aggregates)
+	})
+
+	t.Run("by model config in a time range", func(t *testing.T) {
+		aggregates, err := client.SummarizeFeedback(FeedbackGroupByModelConfig, FeedbackIntervalNone, FeedbackFilter{From: day(1, 12), To: day(2, 2)})
+		require.NoError(t, err)
+		assert.Equal(t, []FeedbackAggregate{
+			{Group: "default/gemini", Total: 1, Positive: 1},
+			{Group: "default/gpt", Total: 1},
+		}, aggregates)
+	})
+
+	t.Run("invalid group by", func(t *testing.T) {
+		_, err := client.SummarizeFeedback("tenant", FeedbackIntervalNone, FeedbackFilter{})
+		assert.Error(t, err)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		feedback, err := client.ListAllFeedback(FeedbackFilter{UserID: "user-1"})
+		require.NoError(t, err)
+		var texts []string
+		for _, f := range feedback {
+			texts = append(texts, f.FeedbackText)
+		}
+		assert.Equal(t, []string{"good", "better", "wrong"}, texts)
+	})
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sync"
 
@@ -14,6 +15,7 @@ import (
 // Manager handles database connection and initialization
 type Manager struct {
 	db       *gorm.DB
+	dbType   DatabaseType
 	initLock sync.Mutex
 }
 
@@ -79,40 +81,72 @@ func NewManager(config *Config) (*Manager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := db.Use(tracingPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tracing: %w", err)
+	}
 
-	return &Manager{db: db}, nil
+	return &Manager{db: db, dbType: config.DatabaseType}, nil
 }
 
-// Initialize sets up the database tables
+// Initialize migrates the database to the latest schema version
 func (m *Manager) Initialize() error {
 	if !m.initLock.TryLock() {
 		return fmt.Errorf("database initialization already in progress")
 	}
 	defer m.initLock.Unlock()
 
-	// AutoMigrate all models
-	err := m.db.AutoMigrate(
-		&Agent{},
-		&Session{},
-		&Task{},
-		&Event{},
-		&PushNotification{},
-		&Feedback{},
-		&Tool{},
-		&ToolServer{},
-		&LangGraphCheckpoint{},
-		&LangGraphCheckpointWrite{},
-		&CrewAIAgentMemory{},
-		&CrewAIFlowState{},
-	)
+	return m.migrateToLatest()
+}
 
+func (m *Manager) migrateToLatest() error {
+	migrator, err := newMigrator(m.db, m.dbType)
 	if err != nil {
+		return err
+	}
+
+	if err := migrator.migrate(migrator.latestVersion()); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	return nil
 }
 
+// MigrateTo migrates the database up or down to the given schema version
+func (m *Manager) MigrateTo(version int) error {
+	if !m.initLock.TryLock() {
+		return fmt.Errorf("database initialization already in progress")
+	}
+	defer m.initLock.Unlock()
+
+	migrator, err := newMigrator(m.db, m.dbType)
+	if err != nil {
+		return err
+	}
+
+	return migrator.migrate(version)
+}
+
+// DryRun writes the SQL that Initialize would execute, without changing the database
+func (m *Manager) DryRun(w io.Writer) error {
+	migrator, err := newMigrator(m.db, m.dbType)
+	if err != nil {
+		return err
+	}
+
+	return migrator.dryRun(w, migrator.latestVersion())
+}
+
+// SchemaVersion returns the current and the latest schema version
+func (m *Manager) SchemaVersion() (current int, latest int, err error) {
+	migrator, err := newMigrator(m.db, m.dbType)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current, err = migrator.currentVersion()
+	return current, migrator.latestVersion(), err
+}
+
 // Reset drops all tables and optionally recreates them
 func (m *Manager) Reset(recreateTables bool) error {
 	if !m.initLock.TryLock() {
@@ -124,9 +158,13 @@ func (m *Manager) Reset(recreateTables bool) error {
 	err := m.db.Migrator().DropTable(
 		&Agent{},
 		&Session{},
+		&SessionShare{},
+		&SessionShareLink{},
 		&Task{},
+		&TaskEvent{},
 		&Event{},
 		&PushNotification{},
+		&PushNotificationDelivery{},
 		&Feedback{},
 		&Tool{},
 		&ToolServer{},
@@ -134,6 +172,8 @@ func (m *Manager) Reset(recreateTables bool) error {
 		&LangGraphCheckpointWrite{},
 		&CrewAIAgentMemory{},
 		&CrewAIFlowState{},
+		&SchemaVersion{},
+		searchDocumentTable,
 	)
 
 	if err != nil {
@@ -141,7 +181,7 @@ func (m *Manager) Reset(recreateTables bool) error {
 	}
 
 	if recreateTables {
-		return m.Initialize()
+		return m.migrateToLatest()
 	}
 
 	return nil
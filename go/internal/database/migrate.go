@@ -0,0 +1,295 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+const schemaVersionTable = "schema_version"
+
+// migrationFileName matches <version>_<name>.<up|down>.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change with the SQL to apply and revert it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaVersion records an applied migration.
+type SchemaVersion struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaVersion) TableName() string { return schemaVersionTable }
+
+// MigrationStep is a migration planned to run in one direction.
+type MigrationStep struct {
+	Migration
+	Down bool
+	// Statements are the SQL statements the step executes, in order.
+	Statements []string
+	// adopt marks the step that stamps a database created by AutoMigrate
+	// with the baseline version instead of creating its tables.
+	adopt bool
+}
+
+func (s MigrationStep) String() string {
+	direction := "up"
+	if s.Down {
+		direction = "down"
+	}
+	if s.adopt {
+		direction = "adopt"
+	}
+	return fmt.Sprintf("%04d_%s (%s)", s.Version, s.Name, direction)
+}
+
+// loadMigrations reads the migrations of a dialect, ordered by version. Every
+// version needs an up and a down file, and versions must start at 1 without gaps.
+func loadMigrations(fsys fs.FS, dialect DatabaseType) ([]Migration, error) {
+	dir := path.Join("migrations", string(dialect))
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations for %s: %w", dialect, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("missing migration %d for %s", version, dialect)
+		}
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %04d_%s for %s needs both an up and a down file", version, migration.Name, dialect)
+		}
+		migrations = append(migrations, *migration)
+	}
+	return migrations, nil
+}
+
+// splitStatements splits a migration into statements. Statements end with a
+// semicolon at the end of a line, and lines starting with -- are comments.
+func splitStatements(sql string) []string {
+	var statements []string
+	var current strings.Builder
+	for line := range strings.SplitSeq(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+type migrator struct {
+	db         *gorm.DB
+	dialect    DatabaseType
+	migrations []Migration
+}
+
+func newMigrator(db *gorm.DB, dialect DatabaseType) (*migrator, error) {
+	migrations, err := loadMigrations(migrationsFS, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+func (m *migrator) latestVersion() int {
+	return len(m.migrations)
+}
+
+// currentVersion returns the highest applied version, or 0 for an empty database.
+func (m *migrator) currentVersion() (int, error) {
+	if !m.db.Migrator().HasTable(schemaVersionTable) {
+		return 0, nil
+	}
+	var version int
+	if err := m.db.Model(&SchemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// isLegacy reports whether the database was created by AutoMigrate before
+// versioned migrations were introduced.
+func (m *migrator) isLegacy() bool {
+	return !m.db.Migrator().HasTable(schemaVersionTable) && m.db.Migrator().HasTable("agent")
+}
+
+// plan returns the steps that bring the database to target.
+func (m *migrator) plan(target int) ([]MigrationStep, error) {
+	if target < 0 || target > m.latestVersion() {
+		return nil, fmt.Errorf("invalid schema version %d, must be between 0 and %d", target, m.latestVersion())
+	}
+
+	current, err := m.currentVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []MigrationStep
+	if current == 0 && m.isLegacy() {
+		// The tables of the baseline migration already exist. Only fill in what
+		// older AutoMigrate schemas lack, then continue from the baseline.
+		steps = append(steps, MigrationStep{Migration: m.migrations[0], Statements: m.legacyFixups(), adopt: true})
+		current = 1
+		if target == 0 {
+			return nil, fmt.Errorf("cannot migrate down a database without schema version history")
+		}
+	}
+
+	for version := current + 1; version <= target; version++ {
+		migration := m.migrations[version-1]
+		steps = append(steps, MigrationStep{Migration: migration, Statements: splitStatements(migration.Up)})
+	}
+	for version := current; version > target; version-- {
+		migration := m.migrations[version-1]
+		steps = append(steps, MigrationStep{Migration: migration, Down: true, Statements: splitStatements(migration.Down)})
+	}
+	return steps, nil
+}
+
+// legacyFixups returns the statements that bring an AutoMigrate schema in line
+// with the baseline migration.
+func (m *migrator) legacyFixups() []string {
+	var statements []string
+	if !m.db.Migrator().HasColumn("task", "state") {
+		statements = append(statements,
+			m.quote("ALTER TABLE `task` ADD COLUMN `state` text;"),
+			m.quote("CREATE INDEX `idx_task_state` ON `task`(`state`);"),
+		)
+	}
+	return statements
+}
+
+func (m *migrator) quote(statement string) string {
+	if m.dialect == DatabaseTypePostgres {
+		return strings.ReplaceAll(statement, "`", `"`)
+	}
+	return statement
+}
+
+func (m *migrator) createSchemaVersionTable() string {
+	timestampType := "datetime"
+	if m.dialect == DatabaseTypePostgres {
+		timestampType = "timestamptz"
+	}
+	return m.quote(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (`version` integer NOT NULL,`name` text NOT NULL,`applied_at` %s NOT NULL,PRIMARY KEY (`version`));", schemaVersionTable, timestampType))
+}
+
+// migrate applies the steps to reach target. Each step runs in its own
+// transaction together with its schema_version bookkeeping.
+func (m *migrator) migrate(target int) error {
+	steps, err := m.plan(target)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	if err := m.db.Exec(m.createSchemaVersionTable()).Error; err != nil {
+		return fmt.Errorf("failed to create %s table: %w", schemaVersionTable, err)
+	}
+
+	for _, step := range steps {
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range step.Statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return fmt.Errorf("%s: %w", statement, err)
+				}
+			}
+			if step.Down {
+				return tx.Delete(&SchemaVersion{}, step.Version).Error
+			}
+			return tx.Create(&SchemaVersion{Version: step.Version, Name: step.Name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", step, err)
+		}
+	}
+	return nil
+}
+
+// dryRun writes the SQL that migrate would execute to reach target.
+func (m *migrator) dryRun(w io.Writer, target int) error {
+	steps, err := m.plan(target)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		_, err := fmt.Fprintf(w, "-- schema is up to date at version %d\n", target)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n\n", m.createSchemaVersionTable()); err != nil {
+		return err
+	}
+	for _, step := range steps {
+		lines := []string{fmt.Sprintf("-- %s", step)}
+		lines = append(lines, step.Statements...)
+		if step.Down {
+			lines = append(lines, m.quote(fmt.Sprintf("DELETE FROM `%s` WHERE `version` = %d;", schemaVersionTable, step.Version)))
+		} else {
+			lines = append(lines, m.quote(fmt.Sprintf("INSERT INTO `%s` (`version`,`name`,`applied_at`) VALUES (%d,'%s',CURRENT_TIMESTAMP);", schemaVersionTable, step.Version, step.Name)))
+		}
+		if _, err := fmt.Fprintf(w, "%s\n\n", strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
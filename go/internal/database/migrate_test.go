@@ -0,0 +1,252 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// legacyModels are the models of databases created by AutoMigrate, before
+// versioned migrations were introduced.
+var legacyModels = []any{
+	&Agent{},
+	&Session{},
+	&Task{},
+	&Event{},
+	&PushNotification{},
+	&Feedback{},
+	&Tool{},
+	&ToolServer{},
+	&LangGraphCheckpoint{},
+	&LangGraphCheckpointWrite{},
+	&CrewAIAgentMemory{},
+	&CrewAIFlowState{},
+}
+
+var allModels = append(slices.Clone(legacyModels),
+	&Usage{},
+	&PushNotificationDelivery{},
+	&TaskEvent{},
+	&SessionShare{},
+	&SessionShareLink{},
+)
+
+// autoMigrateLegacy creates the tables of legacyModels with AutoMigrate, without
+// the columns added to them after versioned migrations were introduced.
+func autoMigrateLegacy(t *testing.T, manager *Manager) {
+	t.Helper()
+	require.NoError(t, manager.db.AutoMigrate(legacyModels...))
+	for _, table := range []string{"event", "task"} {
+		require.NoError(t, manager.db.Exec("DROP INDEX idx_"+table+"_trace_id").Error)
+		require.NoError(t, manager.db.Exec("ALTER TABLE "+table+" DROP COLUMN trace_id").Error)
+	}
+	require.NoError(t, manager.db.Exec("DROP INDEX idx_session_parent_session_id").Error)
+	for _, column := range []string{"parent_session_id", "parent_event_id"} {
+		require.NoError(t, manager.db.Exec("ALTER TABLE session DROP COLUMN "+column).Error)
+	}
+	require.NoError(t, manager.db.Exec("DROP INDEX idx_event_author_id").Error)
+	require.NoError(t, manager.db.Exec("ALTER TABLE event DROP COLUMN author_id").Error)
+	for _, column := range []string{"session_id", "task_id", "agent_id"} {
+		require.NoError(t, manager.db.Exec("DROP INDEX idx_feedback_"+column).Error)
+	}
+	for _, column := range []string{"session_id", "task_id", "event_id", "agent_id", "agent_generation", "model_config"} {
+		require.NoError(t, manager.db.Exec("ALTER TABLE feedback DROP COLUMN "+column).Error)
+	}
+}
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager, err := NewManager(&Config{
+		DatabaseType: DatabaseTypeSqlite,
+		SqliteConfig: &SqliteConfig{DatabasePath: filepath.Join(t.TempDir(), "kagent.db")},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager
+}
+
+type tableSchema struct {
+	Columns []string
+	Indexes []string
+}
+
+func sqliteSchema(t *testing.T, manager *Manager) map[string]tableSchema {
+	t.Helper()
+	schema := map[string]tableSchema{}
+	for _, model := range allModels {
+		table := model.(Model).TableName()
+		columnTypes, err := manager.db.Migrator().ColumnTypes(table)
+		require.NoError(t, err)
+		indexes, err := manager.db.Migrator().GetIndexes(table)
+		require.NoError(t, err)
+
+		var ts tableSchema
+		for _, column := range columnTypes {
+			ts.Columns = append(ts.Columns, column.Name()+" "+column.DatabaseTypeName())
+		}
+		for _, index := range indexes {
+			ts.Indexes = append(ts.Indexes, index.Name())
+		}
+		slices.Sort(ts.Columns)
+		slices.Sort(ts.Indexes)
+		schema[table] = ts
+	}
+	return schema
+}
+
+func TestMigrationsMatchModels(t *testing.T) {
+	migrated := newTestManager(t)
+	require.NoError(t, migrated.Initialize())
+
+	autoMigrated := newTestManager(t)
+	require.NoError(t, autoMigrated.db.AutoMigrate(allModels...))
+
+	assert.Equal(t, sqliteSchema(t, autoMigrated), sqliteSchema(t, migrated),
+		"the migrations must produce the schema of the models, add a migration for model changes")
+
+	current, latest, err := migrated.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, latest, current)
+}
+
+func TestMigrateUpgradesAutoMigrateDatabase(t *testing.T) {
+	seed := func(t *testing.T, manager *Manager) {
+		require.NoError(t, manager.db.Omit("ParentSessionID", "ParentEventID").Create(&Session{ID: "session-1", UserID: "user-1", AgentID: ptr.To("agent-1")}).Error)
+		data, err := json.Marshal(&protocol.Task{ID: "task-1", ContextID: "session-1", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}})
+		require.NoError(t, err)
+		require.NoError(t, manager.db.Omit("TraceID").Create(&Task{ID: "task-1", SessionID: "session-1", State: string(protocol.TaskStateCompleted), Data: string(data)}).Error)
+	}
+
+	t.Run("current schema", func(t *testing.T) {
+		manager := newTestManager(t)
+		autoMigrateLegacy(t, manager)
+		seed(t, manager)
+
+		require.NoError(t, manager.Initialize())
+
+		current, latest, err := manager.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latest, current)
+
+		session, err := NewClient(manager).GetSession("session-1", "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "agent-1", *session.AgentID)
+
+		// migrating again is a no-op
+		require.NoError(t, manager.Initialize())
+	})
+
+	t.Run("schema without task state", func(t *testing.T) {
+		manager := newTestManager(t)
+		autoMigrateLegacy(t, manager)
+		seed(t, manager)
+		require.NoError(t, manager.db.Migrator().DropIndex(&Task{}, "idx_task_state"))
+		require.NoError(t, manager.db.Migrator().DropColumn(&Task{}, "state"))
+
+		var dryRun bytes.Buffer
+		require.NoError(t, manager.DryRun(&dryRun))
+		assert.Contains(t, dryRun.String(), "ALTER TABLE `task` ADD COLUMN `state` text;")
+
+		require.NoError(t, manager.Initialize())
+
+		assert.True(t, manager.db.Migrator().HasColumn(&Task{}, "state"))
+		task, err := NewClient(manager).GetTask("task-1")
+		require.NoError(t, err)
+		assert.Equal(t, "session-1", task.ContextID)
+	})
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	manager := newTestManager(t)
+	require.NoError(t, manager.Initialize())
+
+	require.NoError(t, manager.MigrateTo(0))
+	for _, model := range allModels {
+		assert.False(t, manager.db.Migrator().HasTable(model), "table %s should be dropped", model.(Model).TableName())
+	}
+	current, latest, err := manager.SchemaVersion()
+	require.NoError(t, err)
+	assert.Zero(t, current)
+
+	require.NoError(t, manager.MigrateTo(latest))
+	for _, model := range allModels {
+		assert.True(t, manager.db.Migrator().HasTable(model))
+	}
+}
+
+func TestMigrateFeedbackMessageIDs(t *testing.T) {
+	manager := newTestManager(t)
+	require.NoError(t, manager.MigrateTo(8))
+	require.NoError(t, manager.db.Exec("INSERT INTO feedback (user_id, message_id, is_positive, feedback_text) VALUES ('user-1', 42, true, 'great')").Error)
+
+	require.NoError(t, manager.Initialize())
+	feedback, err := NewClient(manager).ListFeedback("user-1")
+	require.NoError(t, err)
+	require.Len(t, feedback, 1)
+	assert.Equal(t, "42", feedback[0].MessageID)
+	assert.True(t, feedback[0].IsPositive)
+	assert.Equal(t, "great", feedback[0].FeedbackText)
+
+	require.NoError(t, NewClient(manager).StoreFeedback(&Feedback{UserID: "user-1", MessageID: "msg-1", FeedbackText: "wrong"}))
+	require.NoError(t, manager.MigrateTo(8))
+	var messageIDs []int64
+	require.NoError(t, manager.db.Raw("SELECT COALESCE(message_id, -1) FROM feedback ORDER BY id").Scan(&messageIDs).Error)
+	assert.Equal(t, []int64{42, -1}, messageIDs, "message IDs that aren't integers are lost")
+}
+
+func TestDryRunDoesNotChangeDatabase(t *testing.T) {
+	manager := newTestManager(t)
+
+	var out bytes.Buffer
+	require.NoError(t, manager.DryRun(&out))
+
+	assert.Contains(t, out.String(), "-- 0001_initial_schema (up)")
+	assert.Contains(t, out.String(), "CREATE TABLE `agent`")
+	assert.Contains(t, out.String(), "INSERT INTO `schema_version`")
+	assert.False(t, manager.db.Migrator().HasTable(&Agent{}))
+	assert.False(t, manager.db.Migrator().HasTable(&SchemaVersion{}))
+}
+
+func TestLoadMigrations(t *testing.T) {
+	sqlite, err := loadMigrations(migrationsFS, DatabaseTypeSqlite)
+	require.NoError(t, err)
+	postgres, err := loadMigrations(migrationsFS, DatabaseTypePostgres)
+	require.NoError(t, err)
+
+	require.Equal(t, len(sqlite), len(postgres), "every migration needs a sqlite and a postgres version")
+	for i := range sqlite {
+		assert.Equal(t, sqlite[i].Version, postgres[i].Version)
+		assert.Equal(t, sqlite[i].Name, postgres[i].Name)
+	}
+
+	_, err = loadMigrations(fstest.MapFS{
+		"migrations/sqlite/0001_init.up.sql":   {Data: []byte("CREATE TABLE a (id text);")},
+		"migrations/sqlite/0001_init.down.sql": {Data: []byte("DROP TABLE a;")},
+		"migrations/sqlite/0003_more.up.sql":   {Data: []byte("CREATE TABLE b (id text);")},
+		"migrations/sqlite/0003_more.down.sql": {Data: []byte("DROP TABLE b;")},
+	}, DatabaseTypeSqlite)
+	assert.ErrorContains(t, err, "missing migration 2")
+
+	_, err = loadMigrations(fstest.MapFS{
+		"migrations/sqlite/0001_init.up.sql": {Data: []byte("CREATE TABLE a (id text);")},
+	}, DatabaseTypeSqlite)
+	assert.ErrorContains(t, err, "needs both an up and a down file")
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`-- comment
+CREATE TABLE a (
+  id text
+);
+
+CREATE INDEX idx_a ON a(id);`)
+	assert.Equal(t, []string{"CREATE TABLE a (\n  id text\n);", "CREATE INDEX idx_a ON a(id);"}, statements)
+}
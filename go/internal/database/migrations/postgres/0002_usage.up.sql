@@ -0,0 +1,6 @@
+CREATE TABLE "usage" ("id" text NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,"agent_id" text,"user_id" text,"session_id" text,"task_id" text,"model" text,"prompt_tokens" bigint,"completion_tokens" bigint,"cached_tokens" bigint,"total_tokens" bigint,PRIMARY KEY ("id"));
+CREATE INDEX "idx_usage_created_at" ON "usage" ("created_at");
+CREATE INDEX "idx_usage_agent_id" ON "usage" ("agent_id");
+CREATE INDEX "idx_usage_user_id" ON "usage" ("user_id");
+CREATE INDEX "idx_usage_session_id" ON "usage" ("session_id");
+CREATE INDEX "idx_usage_model" ON "usage" ("model");
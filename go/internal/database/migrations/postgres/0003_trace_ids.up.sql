@@ -0,0 +1,4 @@
+ALTER TABLE "event" ADD COLUMN "trace_id" text;
+CREATE INDEX "idx_event_trace_id" ON "event" ("trace_id");
+ALTER TABLE "task" ADD COLUMN "trace_id" text;
+CREATE INDEX "idx_task_trace_id" ON "task" ("trace_id");
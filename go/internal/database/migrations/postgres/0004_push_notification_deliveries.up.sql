@@ -0,0 +1,4 @@
+CREATE TABLE "push_notification_delivery" ("id" text NOT NULL,"created_at" timestamptz,"task_id" text NOT NULL,"config_id" text NOT NULL,"agent_id" text,"url" text,"state" text,"attempt" bigint,"status_code" bigint,"error" text,"delivered" boolean,PRIMARY KEY ("id"));
+CREATE INDEX "idx_push_notification_delivery_created_at" ON "push_notification_delivery" ("created_at");
+CREATE INDEX "idx_push_notification_delivery_task_id" ON "push_notification_delivery" ("task_id");
+CREATE INDEX "idx_push_notification_delivery_agent_id" ON "push_notification_delivery" ("agent_id");
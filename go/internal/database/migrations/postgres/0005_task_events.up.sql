@@ -0,0 +1 @@
+CREATE TABLE "task_event" ("task_id" text NOT NULL,"sequence" bigint NOT NULL,"created_at" timestamptz,"kind" text,"data" text NOT NULL,PRIMARY KEY ("task_id","sequence"));
@@ -0,0 +1,24 @@
+CREATE TABLE "search_document" ("doc_type" text NOT NULL,"doc_id" text NOT NULL,"user_id" text NOT NULL,"session_id" text NOT NULL,"content" text NOT NULL,"content_tsv" tsvector GENERATED ALWAYS AS (to_tsvector('english', "content")) STORED,PRIMARY KEY ("doc_type","doc_id","user_id"));
+CREATE INDEX "idx_search_document_content_tsv" ON "search_document" USING GIN ("content_tsv");
+
+-- Index the text parts of existing events, either ADK events or A2A messages, and session names.
+-- Events that aren't valid JSON are skipped rather than failing the migration.
+CREATE FUNCTION pg_temp.kagent_jsonb_or_null("data" text) RETURNS jsonb LANGUAGE plpgsql IMMUTABLE AS $$ BEGIN RETURN "data"::jsonb; EXCEPTION WHEN others THEN RETURN NULL; END $$;
+INSERT INTO "search_document" ("doc_type","doc_id","user_id","session_id","content")
+SELECT 'event', "event"."id", "event"."user_id", "event"."session_id", "text"."content"
+FROM (
+  SELECT "id", "user_id", "session_id", pg_temp.kagent_jsonb_or_null("data") AS "data_json"
+  FROM "event"
+  WHERE "deleted_at" IS NULL AND "session_id" IS NOT NULL
+) AS "event"
+CROSS JOIN LATERAL (
+  SELECT string_agg("part"."text" #>> '{}', E'\n') AS "content" FROM (
+    SELECT jsonb_path_query("event"."data_json", 'lax $.parts[*].text') AS "text"
+    UNION ALL
+    SELECT jsonb_path_query("event"."data_json", 'lax $.content.parts[*].text')
+  ) AS "part"
+) AS "text"
+WHERE "event"."data_json" IS NOT NULL AND "text"."content" IS NOT NULL AND "text"."content" <> '';
+DROP FUNCTION pg_temp.kagent_jsonb_or_null(text);
+INSERT INTO "search_document" ("doc_type","doc_id","user_id","session_id","content")
+SELECT 'session', "id", "user_id", "id", "name" FROM "session" WHERE "deleted_at" IS NULL AND "user_id" IS NOT NULL AND "name" IS NOT NULL AND "name" <> '';
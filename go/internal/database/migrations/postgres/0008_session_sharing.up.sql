@@ -0,0 +1,7 @@
+CREATE TABLE "session_share" ("session_id" text NOT NULL,"owner_id" text NOT NULL,"user_id" text NOT NULL,"role" text NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("session_id","owner_id","user_id"));
+CREATE INDEX "idx_session_share_user_id" ON "session_share" ("user_id");
+CREATE TABLE "session_share_link" ("id" text NOT NULL,"token_hash" text NOT NULL,"session_id" text NOT NULL,"owner_id" text NOT NULL,"role" text NOT NULL,"expires_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX "idx_session_share_link_session_id" ON "session_share_link" ("session_id");
+CREATE UNIQUE INDEX "idx_session_share_link_token_hash" ON "session_share_link" ("token_hash");
+ALTER TABLE "event" ADD COLUMN "author_id" text;
+CREATE INDEX "idx_event_author_id" ON "event" ("author_id");
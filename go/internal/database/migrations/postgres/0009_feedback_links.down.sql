@@ -0,0 +1,11 @@
+ALTER TABLE "feedback" DROP COLUMN IF EXISTS "model_config";
+ALTER TABLE "feedback" DROP COLUMN IF EXISTS "agent_generation";
+DROP INDEX IF EXISTS "idx_feedback_agent_id";
+ALTER TABLE "feedback" DROP COLUMN IF EXISTS "agent_id";
+ALTER TABLE "feedback" DROP COLUMN IF EXISTS "event_id";
+DROP INDEX IF EXISTS "idx_feedback_task_id";
+ALTER TABLE "feedback" DROP COLUMN IF EXISTS "task_id";
+DROP INDEX IF EXISTS "idx_feedback_session_id";
+ALTER TABLE "feedback" DROP COLUMN IF EXISTS "session_id";
+-- Message IDs that aren't integers are lost
+ALTER TABLE "feedback" ALTER COLUMN "message_id" TYPE bigint USING CASE WHEN "message_id" ~ '^[0-9]+$' THEN "message_id"::bigint END;
@@ -0,0 +1,10 @@
+ALTER TABLE "feedback" ALTER COLUMN "message_id" TYPE text USING "message_id"::text;
+ALTER TABLE "feedback" ADD COLUMN "session_id" text;
+CREATE INDEX "idx_feedback_session_id" ON "feedback" ("session_id");
+ALTER TABLE "feedback" ADD COLUMN "task_id" text;
+CREATE INDEX "idx_feedback_task_id" ON "feedback" ("task_id");
+ALTER TABLE "feedback" ADD COLUMN "event_id" text;
+ALTER TABLE "feedback" ADD COLUMN "agent_id" text;
+CREATE INDEX "idx_feedback_agent_id" ON "feedback" ("agent_id");
+ALTER TABLE "feedback" ADD COLUMN "agent_generation" bigint;
+ALTER TABLE "feedback" ADD COLUMN "model_config" text;
@@ -0,0 +1,5 @@
+-- Usage of an event ID reported for several users is only kept for one of them
+DELETE FROM "usage" a USING "usage" b WHERE a."id" = b."id" AND a."user_id" > b."user_id";
+ALTER TABLE "usage" DROP CONSTRAINT "usage_pkey";
+ALTER TABLE "usage" ADD PRIMARY KEY ("id");
+ALTER TABLE "usage" ALTER COLUMN "user_id" DROP NOT NULL;
@@ -0,0 +1,4 @@
+UPDATE "usage" SET "user_id" = '' WHERE "user_id" IS NULL;
+ALTER TABLE "usage" ALTER COLUMN "user_id" SET NOT NULL;
+ALTER TABLE "usage" DROP CONSTRAINT "usage_pkey";
+ALTER TABLE "usage" ADD PRIMARY KEY ("id","user_id");
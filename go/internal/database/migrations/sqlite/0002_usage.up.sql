@@ -0,0 +1,6 @@
+CREATE TABLE `usage` (`id` text NOT NULL,`created_at` datetime,`updated_at` datetime,`agent_id` text,`user_id` text,`session_id` text,`task_id` text,`model` text,`prompt_tokens` integer,`completion_tokens` integer,`cached_tokens` integer,`total_tokens` integer,PRIMARY KEY (`id`));
+CREATE INDEX `idx_usage_created_at` ON `usage`(`created_at`);
+CREATE INDEX `idx_usage_agent_id` ON `usage`(`agent_id`);
+CREATE INDEX `idx_usage_user_id` ON `usage`(`user_id`);
+CREATE INDEX `idx_usage_session_id` ON `usage`(`session_id`);
+CREATE INDEX `idx_usage_model` ON `usage`(`model`);
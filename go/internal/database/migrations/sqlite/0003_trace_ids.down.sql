@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_task_trace_id`;
+ALTER TABLE `task` DROP COLUMN `trace_id`;
+DROP INDEX IF EXISTS `idx_event_trace_id`;
+ALTER TABLE `event` DROP COLUMN `trace_id`;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `push_notification_delivery`;
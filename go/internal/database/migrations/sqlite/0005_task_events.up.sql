@@ -0,0 +1 @@
+CREATE TABLE `task_event` (`task_id` text NOT NULL,`sequence` integer NOT NULL,`created_at` datetime,`kind` text,`data` text NOT NULL,PRIMARY KEY (`task_id`,`sequence`));
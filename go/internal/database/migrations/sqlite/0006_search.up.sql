@@ -0,0 +1,18 @@
+CREATE VIRTUAL TABLE `search_document` USING fts5(`content`, `doc_type` UNINDEXED, `doc_id` UNINDEXED, `session_id` UNINDEXED, `user_id` UNINDEXED, tokenize = 'porter unicode61');
+
+-- Index the text parts of existing events, either ADK events or A2A messages, and session names
+INSERT INTO `search_document` (`content`, `doc_type`, `doc_id`, `session_id`, `user_id`)
+SELECT `content`, 'event', `id`, `session_id`, `user_id` FROM (
+  SELECT `event`.`id`, `event`.`session_id`, `event`.`user_id`, (
+    SELECT group_concat(json_extract(`part`.`value`, '$.text'), char(10)) FROM (
+      SELECT `value` FROM json_each(`event`.`data`, '$.parts')
+      UNION ALL
+      SELECT `value` FROM json_each(`event`.`data`, '$.content.parts')
+    ) AS `part`
+  ) AS `content`
+  FROM `event`
+  WHERE `event`.`deleted_at` IS NULL AND json_valid(`event`.`data`)
+)
+WHERE `content` IS NOT NULL AND `content` != '';
+INSERT INTO `search_document` (`content`, `doc_type`, `doc_id`, `session_id`, `user_id`)
+SELECT `name`, 'session', `id`, `id`, `user_id` FROM `session` WHERE `deleted_at` IS NULL AND `name` IS NOT NULL AND `name` != '';
@@ -0,0 +1,3 @@
+ALTER TABLE `session` DROP COLUMN `parent_event_id`;
+DROP INDEX IF EXISTS `idx_session_parent_session_id`;
+ALTER TABLE `session` DROP COLUMN `parent_session_id`;
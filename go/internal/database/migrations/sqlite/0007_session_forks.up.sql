@@ -0,0 +1,3 @@
+ALTER TABLE `session` ADD COLUMN `parent_session_id` text;
+CREATE INDEX `idx_session_parent_session_id` ON `session`(`parent_session_id`);
+ALTER TABLE `session` ADD COLUMN `parent_event_id` text;
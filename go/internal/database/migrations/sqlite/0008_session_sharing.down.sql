@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_event_author_id`;
+ALTER TABLE `event` DROP COLUMN `author_id`;
+DROP TABLE IF EXISTS `session_share_link`;
+DROP TABLE IF EXISTS `session_share`;
@@ -0,0 +1,8 @@
+-- Message IDs that aren't integers are lost
+CREATE TABLE `feedback_old` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`user_id` text NOT NULL,`message_id` integer,`is_positive` numeric DEFAULT false,`feedback_text` text NOT NULL,`issue_type` text);
+INSERT INTO `feedback_old` (`id`,`created_at`,`updated_at`,`deleted_at`,`user_id`,`message_id`,`is_positive`,`feedback_text`,`issue_type`)
+SELECT `id`,`created_at`,`updated_at`,`deleted_at`,`user_id`,CASE WHEN `message_id` GLOB '[0-9]*' AND `message_id` NOT GLOB '*[^0-9]*' THEN CAST(`message_id` AS integer) END,`is_positive`,`feedback_text`,`issue_type` FROM `feedback`;
+DROP TABLE `feedback`;
+ALTER TABLE `feedback_old` RENAME TO `feedback`;
+CREATE INDEX `idx_feedback_message_id` ON `feedback`(`message_id`);
+CREATE INDEX `idx_feedback_deleted_at` ON `feedback`(`deleted_at`);
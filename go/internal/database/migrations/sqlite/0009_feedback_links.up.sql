@@ -0,0 +1,12 @@
+-- message_id was an integer, which can't hold the IDs of A2A messages, and SQLite
+-- can't change the type of a column, so the table is rebuilt
+CREATE TABLE `feedback_new` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`user_id` text NOT NULL,`message_id` text,`session_id` text,`task_id` text,`event_id` text,`agent_id` text,`agent_generation` integer,`model_config` text,`is_positive` numeric DEFAULT false,`feedback_text` text NOT NULL,`issue_type` text);
+INSERT INTO `feedback_new` (`id`,`created_at`,`updated_at`,`deleted_at`,`user_id`,`message_id`,`is_positive`,`feedback_text`,`issue_type`)
+SELECT `id`,`created_at`,`updated_at`,`deleted_at`,`user_id`,CAST(`message_id` AS text),`is_positive`,`feedback_text`,`issue_type` FROM `feedback`;
+DROP TABLE `feedback`;
+ALTER TABLE `feedback_new` RENAME TO `feedback`;
+CREATE INDEX `idx_feedback_message_id` ON `feedback`(`message_id`);
+CREATE INDEX `idx_feedback_deleted_at` ON `feedback`(`deleted_at`);
+CREATE INDEX `idx_feedback_session_id` ON `feedback`(`session_id`);
+CREATE INDEX `idx_feedback_task_id` ON `feedback`(`task_id`);
+CREATE INDEX `idx_feedback_agent_id` ON `feedback`(`agent_id`);
@@ -0,0 +1,11 @@
+-- Usage of an event ID reported for several users is only kept for one of them
+CREATE TABLE `usage_old` (`id` text NOT NULL,`created_at` datetime,`updated_at` datetime,`agent_id` text,`user_id` text,`session_id` text,`task_id` text,`model` text,`prompt_tokens` integer,`completion_tokens` integer,`cached_tokens` integer,`total_tokens` integer,PRIMARY KEY (`id`));
+INSERT OR IGNORE INTO `usage_old` (`id`,`created_at`,`updated_at`,`agent_id`,`user_id`,`session_id`,`task_id`,`model`,`prompt_tokens`,`completion_tokens`,`cached_tokens`,`total_tokens`)
+SELECT `id`,`created_at`,`updated_at`,`agent_id`,`user_id`,`session_id`,`task_id`,`model`,`prompt_tokens`,`completion_tokens`,`cached_tokens`,`total_tokens` FROM `usage`;
+DROP TABLE `usage`;
+ALTER TABLE `usage_old` RENAME TO `usage`;
+CREATE INDEX `idx_usage_created_at` ON `usage`(`created_at`);
+CREATE INDEX `idx_usage_agent_id` ON `usage`(`agent_id`);
+CREATE INDEX `idx_usage_user_id` ON `usage`(`user_id`);
+CREATE INDEX `idx_usage_session_id` ON `usage`(`session_id`);
+CREATE INDEX `idx_usage_model` ON `usage`(`model`);
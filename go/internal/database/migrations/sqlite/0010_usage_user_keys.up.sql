@@ -0,0 +1,11 @@
+-- SQLite can't change the primary key of a table, so the table is rebuilt
+CREATE TABLE `usage_new` (`id` text NOT NULL,`user_id` text NOT NULL,`created_at` datetime,`updated_at` datetime,`agent_id` text,`session_id` text,`task_id` text,`model` text,`prompt_tokens` integer,`completion_tokens` integer,`cached_tokens` integer,`total_tokens` integer,PRIMARY KEY (`id`,`user_id`));
+INSERT INTO `usage_new` (`id`,`user_id`,`created_at`,`updated_at`,`agent_id`,`session_id`,`task_id`,`model`,`prompt_tokens`,`completion_tokens`,`cached_tokens`,`total_tokens`)
+SELECT `id`,COALESCE(`user_id`,''),`created_at`,`updated_at`,`agent_id`,`session_id`,`task_id`,`model`,`prompt_tokens`,`completion_tokens`,`cached_tokens`,`total_tokens` FROM `usage`;
+DROP TABLE `usage`;
+ALTER TABLE `usage_new` RENAME TO `usage`;
+CREATE INDEX `idx_usage_created_at` ON `usage`(`created_at`);
+CREATE INDEX `idx_usage_agent_id` ON `usage`(`agent_id`);
+CREATE INDEX `idx_usage_user_id` ON `usage`(`user_id`);
+CREATE INDEX `idx_usage_session_id` ON `usage`(`session_id`);
+CREATE INDEX `idx_usage_model` ON `usage`(`model`);
@@ -32,6 +32,11 @@ type Event struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	Data string `gorm:"type:text;not null" json:"data"` // JSON serialized protocol.Message
+	// TraceID is the ID of the trace of the request that stored the event.
+	TraceID string `gorm:"index" json:"trace_id,omitempty"`
+	// AuthorID is the user who added the event to a session shared with them.
+	// It's empty for the events of the owner of the session.
+	AuthorID string `gorm:"index" json:"author_id,omitempty"`
 }
 
 func (m *Event) Parse() (protocol.Message, error) {
@@ -64,6 +69,10 @@ type Session struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	AgentID *string `gorm:"index" json:"agent_id"`
+	// ParentSessionID and ParentEventID are the session and the event of it the
+	// session was forked from.
+	ParentSessionID *string `gorm:"index" json:"parent_session_id,omitempty"`
+	ParentEventID   *string `json:"parent_event_id,omitempty"`
 }
 
 type Task struct {
@@ -73,6 +82,10 @@ type Task struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 	Data      string         `gorm:"type:text;not null" json:"data"` // JSON serialized task data
 	SessionID string         `gorm:"index" json:"session_id"`
+	// State mirrors the A2A task state, so paused (input-required) tasks can be queried.
+	State string `gorm:"index" json:"state"`
+	// TraceID is the ID of the trace of the request that last stored the task.
+	TraceID string `gorm:"index" json:"trace_id,omitempty"`
 }
 
 func (t *Task) Parse() (protocol.Task, error) {
@@ -96,6 +109,49 @@ func ParseTasks(tasks []Task) ([]*protocol.Task, error) {
 	return result, nil
 }
 
+// TaskEvent is a status or artifact update of a task streamed from an agent
+// through the A2A proxy, kept so clients resubscribing to the task can replay
+// the events they missed. Sequence numbers the events of a task from 1.
+type TaskEvent struct {
+	TaskID    string    `gorm:"primaryKey;not null" json:"task_id"`
+	Sequence  int       `gorm:"primaryKey;not null;autoIncrement:false" json:"sequence"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	Kind      string    `json:"kind"`
+	Data      string    `gorm:"type:text;not null" json:"data"` // JSON serialized event
+}
+
+// Roles of users in sessions. The owner of a session grants the other roles
+// with SessionShare and SessionShareLink.
+const (
+	SessionRoleOwner = "owner"
+	// SessionRoleCollaborator can read a session and continue its conversation.
+	SessionRoleCollaborator = "collaborator"
+	// SessionRoleViewer can only read a session.
+	SessionRoleViewer = "viewer"
+)
+
+// SessionShare grants a user access to the session of its owner.
+type SessionShare struct {
+	SessionID string    `gorm:"primaryKey;not null" json:"session_id"`
+	OwnerID   string    `gorm:"primaryKey;not null" json:"owner_id"`
+	UserID    string    `gorm:"primaryKey;not null;index" json:"user_id"`
+	Role      string    `gorm:"not null" json:"role"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SessionShareLink grants access to a session to the users redeeming its
+// token, until it expires. Only the hash of the token is stored.
+type SessionShareLink struct {
+	ID        string     `gorm:"primaryKey;not null" json:"id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	SessionID string     `gorm:"index;not null" json:"session_id"`
+	OwnerID   string     `gorm:"not null" json:"owner_id"`
+	Role      string     `gorm:"not null" json:"role"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
 type PushNotification struct {
 	ID        string         `gorm:"primaryKey;not null" json:"id"`
 	TaskID    string         `gorm:"not null;index" json:"task_id"`
@@ -105,6 +161,24 @@ type PushNotification struct {
 	Data      string         `gorm:"type:text;not null" json:"data"` // JSON serialized push notification config
 }
 
+// PushNotificationDelivery records an attempt of the controller to deliver a
+// task state transition to the webhook of a push notification config. Failed
+// attempts are retried, each recording its own delivery.
+type PushNotificationDelivery struct {
+	ID        string    `gorm:"primaryKey;not null" json:"id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+	TaskID    string    `gorm:"not null;index" json:"task_id"`
+	ConfigID  string    `gorm:"not null" json:"config_id"`
+	AgentID   string    `gorm:"index" json:"agent_id"`
+	URL       string    `json:"url"`
+	// State is the task state that was notified.
+	State      string `json:"state"`
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Delivered  bool   `json:"delivered"`
+}
+
 // FeedbackIssueType represents the category of feedback issue
 type FeedbackIssueType string
 
@@ -115,14 +189,43 @@ const (
 	FeedbackIssueTypeTool         FeedbackIssueType = "tool"         // Should have run the tool
 )
 
-// Feedback represents user feedback on agent responses
+// Feedback represents user feedback on agent responses. The response is the
+// A2A message MessageID of task TaskID in session SessionID, and the session
+// event EventID it was converted from, when known. AgentID, AgentGeneration
+// and ModelConfig record the agent, the generation of its spec and the
+// ModelConfig it used when the feedback was given.
 type Feedback struct {
 	gorm.Model
-	UserID       string             `gorm:"primaryKey;not null" json:"user_id"`
-	MessageID    uint               `gorm:"index;constraint:OnDelete:CASCADE" json:"message_id"`
-	IsPositive   bool               `gorm:"default:false" json:"is_positive"`
-	FeedbackText string             `gorm:"not null" json:"feedback_text"`
-	IssueType    *FeedbackIssueType `json:"issue_type,omitempty"`
+	UserID          string             `gorm:"primaryKey;not null" json:"user_id"`
+	MessageID       string             `gorm:"index" json:"message_id"`
+	SessionID       string             `gorm:"index" json:"session_id,omitempty"`
+	TaskID          string             `gorm:"index" json:"task_id,omitempty"`
+	EventID         string             `json:"event_id,omitempty"`
+	AgentID         string             `gorm:"index" json:"agent_id,omitempty"`
+	AgentGeneration int64              `json:"agent_generation,omitempty"`
+	ModelConfig     string             `json:"model_config,omitempty"`
+	IsPositive      bool               `gorm:"default:false" json:"is_positive"`
+	FeedbackText    string             `gorm:"not null" json:"feedback_text"`
+	IssueType       *FeedbackIssueType `json:"issue_type,omitempty"`
+}
+
+// Usage records the tokens consumed by a single model call of an agent. It is
+// keyed by the user and the ID of the ADK event that reported the usage, so the
+// same call reported on several paths is only counted once. Usage rows are kept when the
+// sessions they belong to are pruned, so past cost stays accountable.
+type Usage struct {
+	ID               string    `gorm:"primaryKey;not null" json:"id"`
+	UserID           string    `gorm:"primaryKey;not null;index" json:"user_id"`
+	CreatedAt        time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	AgentID          string    `gorm:"index" json:"agent_id"`
+	SessionID        string    `gorm:"index" json:"session_id"`
+	TaskID           string    `json:"task_id,omitempty"`
+	Model            string    `gorm:"index" json:"model"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	CachedTokens     int64     `json:"cached_tokens"`
+	TotalTokens      int64     `json:"total_tokens"`
 }
 
 // Tool represents a single tool that can be used by an agent
@@ -206,9 +309,14 @@ type CrewAIFlowState struct {
 func (Agent) TableName() string                    { return "agent" }
 func (Event) TableName() string                    { return "event" }
 func (Session) TableName() string                  { return "session" }
+func (SessionShare) TableName() string             { return "session_share" }
+func (SessionShareLink) TableName() string         { return "session_share_link" }
 func (Task) TableName() string                     { return "task" }
+func (TaskEvent) TableName() string                { return "task_event" }
 func (PushNotification) TableName() string         { return "push_notification" }
+func (PushNotificationDelivery) TableName() string { return "push_notification_delivery" }
 func (Feedback) TableName() string                 { return "feedback" }
+func (Usage) TableName() string                    { return "usage" }
 func (Tool) TableName() string                     { return "tool" }
 func (ToolServer) TableName() string               { return "toolserver" }
 func (LangGraphCheckpoint) TableName() string      { return "lg_checkpoint" }
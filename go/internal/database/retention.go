@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetentionPolicy configures which rows Prune removes. A zero value disables a rule.
+type RetentionPolicy struct {
+	// MaxAge soft-deletes sessions without new events for longer than MaxAge,
+	// together with their events, tasks and push notification configs. LangGraph
+	// threads and CrewAI memories and flow states not updated for longer than
+	// MaxAge are soft-deleted as well.
+	MaxAge time.Duration
+	// MaxSessionsPerAgent keeps at most this many of the most recently updated
+	// sessions of each user and agent, and soft-deletes the rest.
+	MaxSessionsPerAgent int
+	// PurgeDeletedAfter permanently removes rows that were soft-deleted longer
+	// ago than PurgeDeletedAfter.
+	PurgeDeletedAfter time.Duration
+}
+
+// Enabled reports whether any retention rule is set.
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxSessionsPerAgent > 0 || p.PurgeDeletedAfter > 0
+}
+
+// PruneResult counts the rows removed by Prune, by table name.
+type PruneResult struct {
+	// Deleted counts soft-deleted rows.
+	Deleted map[string]int64
+	// Purged counts permanently removed rows.
+	Purged map[string]int64
+}
+
+func (r *PruneResult) deleted(table string, rows int64) {
+	if rows > 0 {
+		r.Deleted[table] += rows
+	}
+}
+
+func (r *PruneResult) purged(table string, rows int64) {
+	if rows > 0 {
+		r.Purged[table] += rows
+	}
+}
+
+// Prune applies the retention policy relative to now.
+func (m *Manager) Prune(ctx context.Context, policy RetentionPolicy, now time.Time) (*PruneResult, error) {
+	result := &PruneResult{Deleted: map[string]int64{}, Purged: map[string]int64{}}
+	db := m.db.WithContext(ctx)
+
+	if policy.MaxAge > 0 {
+		if err := pruneByAge(db, now.Add(-policy.MaxAge), result); err != nil {
+			return result, err
+		}
+	}
+
+	if policy.MaxSessionsPerAgent > 0 {
+		if err := pruneSessionsPerAgent(db, policy.MaxSessionsPerAgent, result); err != nil {
+			return result, err
+		}
+	}
+
+	if policy.PurgeDeletedAfter > 0 {
+		if err := purgeDeleted(db, now.Add(-policy.PurgeDeletedAfter), result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func pruneByAge(db *gorm.DB, cutoff time.Time, result *PruneResult) error {
+	var sessions []Session
+	err := db.Select("id", "user_id").
+		Where("updated_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM event WHERE event.session_id = session.id AND event.user_id = session.user_id AND event.created_at >= ? AND event.deleted_at IS NULL)", cutoff).
+		Find(&sessions).Error
+	if err != nil {
+		return fmt.Errorf("failed to list expired sessions: %w", err)
+	}
+	if err := deleteSessions(db, sessions, result); err != nil {
+		return err
+	}
+
+	var threads []LangGraphCheckpoint
+	err = db.Model(&LangGraphCheckpoint{}).
+		Select("user_id", "thread_id").
+		Group("user_id, thread_id").
+		Having("MAX(created_at) < ?", cutoff).
+		Find(&threads).Error
+	if err != nil {
+		return fmt.Errorf("failed to list expired checkpoint threads: %w", err)
+	}
+	for _, thread := range threads {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Where("user_id = ? AND thread_id = ?", thread.UserID, thread.ThreadID).Delete(&LangGraphCheckpoint{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(LangGraphCheckpoint{}.TableName(), res.RowsAffected)
+
+			res = tx.Where("user_id = ? AND thread_id = ?", thread.UserID, thread.ThreadID).Delete(&LangGraphCheckpointWrite{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(LangGraphCheckpointWrite{}.TableName(), res.RowsAffected)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete checkpoints of thread %s: %w", thread.ThreadID, err)
+		}
+	}
+
+	for _, model := range []Model{&CrewAIAgentMemory{}, &CrewAIFlowState{}} {
+		res := db.Where("updated_at < ?", cutoff).Delete(model)
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete expired %s rows: %w", model.TableName(), res.Error)
+		}
+		result.deleted(model.TableName(), res.RowsAffected)
+	}
+
+	return nil
+}
+
+func pruneSessionsPerAgent(db *gorm.DB, maxSessions int, result *PruneResult) error {
+	ranked := db.Model(&Session{}).
+		Select("id, user_id, ROW_NUMBER() OVER (PARTITION BY user_id, agent_id ORDER BY updated_at DESC) AS row_num")
+
+	var excess []Session
+	err := db.Table("(?) AS ranked", ranked).
+		Select("id", "user_id").
+		Where("row_num > ?", maxSessions).
+		Scan(&excess).Error
+	if err != nil {
+		return fmt.Errorf("failed to list excess sessions: %w", err)
+	}
+
+	return deleteSessions(db, excess, result)
+}
+
+// deleteSessions soft-deletes sessions with their events, tasks and the push
+// notification configs of those tasks, and deletes the push notification
+// deliveries and streamed events of those tasks.
+func deleteSessions(db *gorm.DB, sessions []Session, result *PruneResult) error {
+	for _, session := range sessions {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Where("task_id IN (?)", tx.Model(&Task{}).Select("id").Where("session_id = ?", session.ID)).Delete(&PushNotification{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(PushNotification{}.TableName(), res.RowsAffected)
+
+			res = tx.Where("task_id IN (?)", tx.Model(&Task{}).Select("id").Where("session_id = ?", session.ID)).Delete(&PushNotificationDelivery{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(PushNotificationDelivery{}.TableName(), res.RowsAffected)
+
+			res = tx.Where("task_id IN (?)", tx.Model(&Task{}).Select("id").Where("session_id = ?", session.ID)).Delete(&TaskEvent{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(TaskEvent{}.TableName(), res.RowsAffected)
+
+			res = tx.Where("session_id = ?", session.ID).Delete(&Task{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(Task{}.TableName(), res.RowsAffected)
+
+			res = tx.Where("session_id = ? AND user_id = ?", session.ID, session.UserID).Delete(&Event{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(Event{}.TableName(), res.RowsAffected)
+
+			res = tx.Where("id = ? AND user_id = ?", session.ID, session.UserID).Delete(&Session{})
+			if res.Error != nil {
+				return res.Error
+			}
+			result.deleted(Session{}.TableName(), res.RowsAffected)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete session %s: %w", session.ID, err)
+		}
+	}
+	return nil
+}
+
+func purgeDeleted(db *gorm.DB, cutoff time.Time, result *PruneResult) error {
+	models := []Model{
+		&Agent{},
+		&Session{},
+		&Task{},
+		&Event{},
+		&PushNotification{},
+		&Feedback{},
+		&Tool{},
+		&ToolServer{},
+		&LangGraphCheckpoint{},
+		&LangGraphCheckpointWrite{},
+		&CrewAIAgentMemory{},
+		&CrewAIFlowState{},
+	}
+	for _, model := range models {
+		res := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(model)
+		if res.Error != nil {
+			return fmt.Errorf("failed to purge deleted %s rows: %w", model.TableName(), res.Error)
+		}
+		result.purged(model.TableName(), res.RowsAffected)
+	}
+
+	// The search index isn't soft-deleted, its documents go with the purged rows
+	res := db.Exec("DELETE FROM "+searchDocumentTable+" WHERE "+
+		"(doc_type = ? AND NOT EXISTS (SELECT 1 FROM event WHERE event.id = "+searchDocumentTable+".doc_id AND event.user_id = "+searchDocumentTable+".user_id)) OR "+
+		"(doc_type = ? AND NOT EXISTS (SELECT 1 FROM session WHERE session.id = "+searchDocumentTable+".doc_id AND session.user_id = "+searchDocumentTable+".user_id))",
+		searchDocumentEvent, searchDocumentSession)
+	if res.Error != nil {
+		return fmt.Errorf("failed to purge %s rows: %w", searchDocumentTable, res.Error)
+	}
+	result.purged(searchDocumentTable, res.RowsAffected)
+
+	// Shares aren't soft-deleted either, they go with the purged sessions
+	res = db.Where("NOT EXISTS (SELECT 1 FROM session WHERE session.id = session_share.session_id AND session.user_id = session_share.owner_id)").Delete(&SessionShare{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to purge %s rows: %w", SessionShare{}.TableName(), res.Error)
+	}
+	result.purged(SessionShare{}.TableName(), res.RowsAffected)
+
+	res = db.Where("expires_at < ? OR NOT EXISTS (SELECT 1 FROM session WHERE session.id = session_share_link.session_id AND session.user_id = session_share_link.owner_id)", cutoff).Delete(&SessionShareLink{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to purge %s rows: %w", SessionShareLink{}.TableName(), res.Error)
+	}
+	result.purged(SessionShareLink{}.TableName(), res.RowsAffected)
+	return nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"k8s.io/utils/ptr"
+)
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+
+	setup := func(t *testing.T) *Manager {
+		manager := newTestManager(t)
+		require.NoError(t, manager.Initialize())
+		db := manager.db
+
+		require.NoError(t, db.Create([]*Session{
+			{ID: "stale", UserID: "user-1", AgentID: ptr.To("agent-1"), CreatedAt: daysAgo(60), UpdatedAt: daysAgo(40)},
+			{ID: "active", UserID: "user-1", AgentID: ptr.To("agent-1"), CreatedAt: daysAgo(60), UpdatedAt: daysAgo(40)},
+			{ID: "recent", UserID: "user-1", AgentID: ptr.To("agent-1"), CreatedAt: daysAgo(1), UpdatedAt: daysAgo(1)},
+			{ID: "other-agent", UserID: "user-1", AgentID: ptr.To("agent-2"), CreatedAt: daysAgo(2), UpdatedAt: daysAgo(2)},
+		}).Error)
+		require.NoError(t, db.Create([]*Event{
+			{ID: "stale-1", SessionID: "stale", UserID: "user-1", Data: "{}", CreatedAt: daysAgo(40)},
+			{ID: "active-1", SessionID: "active", UserID: "user-1", Data: "{}", CreatedAt: daysAgo(40)},
+			{ID: "active-2", SessionID: "active", UserID: "user-1", Data: "{}", CreatedAt: daysAgo(3)},
+		}).Error)
+		require.NoError(t, db.Create([]*Task{
+			{ID: "stale-task", SessionID: "stale", Data: "{}", CreatedAt: daysAgo(40), UpdatedAt: daysAgo(40)},
+		}).Error)
+		require.NoError(t, db.Create(&PushNotification{ID: "stale-push", TaskID: "stale-task", Data: "{}"}).Error)
+		require.NoError(t, db.Create(&TaskEvent{TaskID: "stale-task", Sequence: 1, Kind: "status-update", Data: "{}"}).Error)
+		require.NoError(t, db.Create([]*LangGraphCheckpoint{
+			{UserID: "user-1", ThreadID: "old-thread", CheckpointID: "1", Metadata: "{}", Checkpoint: "{}", CheckpointType: "json", CreatedAt: daysAgo(40)},
+			{UserID: "user-1", ThreadID: "live-thread", CheckpointID: "1", Metadata: "{}", Checkpoint: "{}", CheckpointType: "json", CreatedAt: daysAgo(40)},
+			{UserID: "user-1", ThreadID: "live-thread", CheckpointID: "2", Metadata: "{}", Checkpoint: "{}", CheckpointType: "json", CreatedAt: daysAgo(1)},
+		}).Error)
+		require.NoError(t, db.Create(&LangGraphCheckpointWrite{UserID: "user-1", ThreadID: "old-thread", CheckpointID: "1", Value: "{}", ValueType: "json", Channel: "c", TaskID: "t"}).Error)
+		require.NoError(t, db.Create(&CrewAIAgentMemory{UserID: "user-1", ThreadID: "old-thread", MemoryData: "{}", CreatedAt: daysAgo(40), UpdatedAt: daysAgo(40)}).Error)
+		return manager
+	}
+
+	sessionIDs := func(t *testing.T, db *gorm.DB) []string {
+		var ids []string
+		require.NoError(t, db.Model(&Session{}).Order("id").Pluck("id", &ids).Error)
+		return ids
+	}
+
+	t.Run("max age", func(t *testing.T) {
+		manager := setup(t)
+
+		result, err := manager.Prune(context.Background(), RetentionPolicy{MaxAge: 30 * 24 * time.Hour}, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"active", "other-agent", "recent"}, sessionIDs(t, manager.db))
+		assert.Equal(t, map[string]int64{
+			"session":             1,
+			"event":               1,
+			"task":                1,
+			"task_event":          1,
+			"push_notification":   1,
+			"lg_checkpoint":       1,
+			"lg_checkpoint_write": 1,
+			"crewai_agent_memory": 1,
+		}, result.Deleted)
+		assert.Empty(t, result.Purged)
+
+		var checkpoints int64
+		require.NoError(t, manager.db.Model(&LangGraphCheckpoint{}).Count(&checkpoints).Error)
+		assert.EqualValues(t, 2, checkpoints)
+	})
+
+	t.Run("max sessions per agent", func(t *testing.T) {
+		manager := setup(t)
+
+		result, err := manager.Prune(context.Background(), RetentionPolicy{MaxSessionsPerAgent: 2}, now)
+		require.NoError(t, err)
+
+		// stale and active were updated at the same time, one of them is kept
+		ids := sessionIDs(t, manager.db)
+		assert.Len(t, ids, 3)
+		assert.Contains(t, ids, "recent")
+		assert.Contains(t, ids, "other-agent")
+		assert.EqualValues(t, 1, result.Deleted["session"])
+	})
+
+	t.Run("max sessions per agent ignores deleted sessions", func(t *testing.T) {
+		manager := setup(t)
+		require.NoError(t, manager.db.Where("id = ?", "recent").Delete(&Session{}).Error)
+
+		result, err := manager.Prune(context.Background(), RetentionPolicy{MaxSessionsPerAgent: 2}, now)
+		require.NoError(t, err)
+		assert.Empty(t, result.Deleted)
+	})
+
+	t.Run("purge deleted rows", func(t *testing.T) {
+		manager := setup(t)
+		require.NoError(t, manager.db.Model(&Session{}).Where("id = ?", "stale").Update("deleted_at", daysAgo(10)).Error)
+		require.NoError(t, manager.db.Model(&Session{}).Where("id = ?", "recent").Update("deleted_at", daysAgo(1)).Error)
+		client := NewClient(manager).(*clientImpl)
+		require.NoError(t, client.indexSession(&Session{ID: "stale", UserID: "user-1", Name: ptr.To("stale chat")}))
+		require.NoError(t, client.indexSession(&Session{ID: "recent", UserID: "user-1", Name: ptr.To("recent chat")}))
+		require.NoError(t, client.StoreSessionShare(&SessionShare{SessionID: "stale", OwnerID: "user-1", UserID: "user-2", Role: SessionRoleViewer}))
+		require.NoError(t, client.StoreSessionShare(&SessionShare{SessionID: "recent", OwnerID: "user-1", UserID: "user-2", Role: SessionRoleViewer}))
+		require.NoError(t, client.StoreSessionShareLink(&SessionShareLink{ID: "expired", TokenHash: "expired", SessionID: "active", OwnerID: "user-1", Role: SessionRoleViewer, ExpiresAt: ptr.To(daysAgo(10))}))
+		require.NoError(t, client.StoreSessionShareLink(&SessionShareLink{ID: "recently-expired", TokenHash: "recently-expired", SessionID: "active", OwnerID: "user-1", Role: SessionRoleViewer, ExpiresAt: ptr.To(daysAgo(1))}))
+
+		result, err := manager.Prune(context.Background(), RetentionPolicy{PurgeDeletedAfter: 7 * 24 * time.Hour}, now)
+		require.NoError(t, err)
+
+		var ids []string
+		require.NoError(t, manager.db.Unscoped().Model(&Session{}).Order("id").Pluck("id", &ids).Error)
+		assert.Equal(t, []string{"active", "other-agent", "recent"}, ids)
+		assert.Equal(t, map[string]int64{"session": 1, "search_document": 1, "session_share": 1, "session_share_link": 1}, result.Purged)
+	})
+}
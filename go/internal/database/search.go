@@ -0,0 +1,262 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// searchDocumentTable indexes the text of events and the names of sessions for
+// full-text search. It's an FTS5 table on SQLite, and a table with a tsvector
+// column on Postgres, so it has no model.
+const searchDocumentTable = "search_document"
+
+// Types of the documents of the search index.
+const (
+	searchDocumentEvent   = "event"
+	searchDocumentSession = "session"
+)
+
+// Highlighting of the matches in search snippets.
+const (
+	searchHighlightStart = "<mark>"
+	searchHighlightStop  = "</mark>"
+)
+
+// defaultSearchLimit is the number of results of searches without a limit.
+const defaultSearchLimit = 50
+
+// SearchQuery selects the sessions and events to search. Zero fields other than
+// Query match all rows.
+type SearchQuery struct {
+	// Query is the text searched for. Results match all of its words.
+	Query   string
+	UserID  string
+	AgentID string
+	// From and To bound the creation time of the matches, From inclusive and To exclusive.
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// SearchResult is an event or a session name matching a search.
+type SearchResult struct {
+	SessionID   string  `json:"session_id"`
+	SessionName *string `json:"session_name,omitempty"`
+	AgentID     *string `json:"agent_id,omitempty"`
+	// EventID is the matching event, empty if the session name matches.
+	EventID string `json:"event_id,omitempty"`
+	// Snippet is an excerpt of the matching text with the matches highlighted
+	// with <mark> tags. The text isn't escaped.
+	Snippet   string    `json:"snippet"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// searchIndex is the full-text search backend of a database.
+type searchIndex interface {
+	// index stores a document, or deletes it if content is empty.
+	index(db *gorm.DB, docType, docID, sessionID, userID, content string) error
+	search(db *gorm.DB, query SearchQuery) ([]SearchResult, error)
+}
+
+func (c *clientImpl) searchIndex() searchIndex {
+	if c.db.Name() == string(DatabaseTypePostgres) {
+		return postgresSearch{}
+	}
+	return sqliteSearch{}
+}
+
+// SearchSessions searches the text of the events and the names of the sessions
+// of a user, best matches first.
+func (c *clientImpl) SearchSessions(query SearchQuery) ([]SearchResult, error) {
+	if strings.TrimSpace(query.Query) == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	results, err := c.searchIndex().search(c.db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+	return results, nil
+}
+
+func (c *clientImpl) indexEvent(event *Event) error {
+	if err := c.searchIndex().index(c.db, searchDocumentEvent, event.ID, event.SessionID, event.UserID, EventText(event.Data)); err != nil {
+		return fmt.Errorf("failed to index event: %w", err)
+	}
+	return nil
+}
+
+func (c *clientImpl) indexSession(session *Session) error {
+	var name string
+	if session.Name != nil {
+		name = *session.Name
+	}
+	if err := c.searchIndex().index(c.db, searchDocumentSession, session.ID, session.ID, session.UserID, name); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	return nil
+}
+
+// EventText returns the text parts of the data of an event, which is either an
+// ADK event with parts in its content, or an A2A message.
+func EventText(data string) string {
+	type part struct {
+		Text string `json:"text"`
+	}
+	var event struct {
+		Parts   []part `json:"parts"`
+		Content *struct {
+			Parts []part `json:"parts"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return ""
+	}
+	parts := event.Parts
+	if event.Content != nil {
+		parts = append(parts, event.Content.Parts...)
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if text := strings.TrimSpace(p.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// searchRow is a search result as selected by the backends. The creation times
+// are selected separately, as SQLite drivers only parse times of columns.
+type searchRow struct {
+	DocType          string
+	DocID            string
+	SessionID        string
+	SessionName      *string
+	AgentID          *string
+	Snippet          string
+	EventCreatedAt   *time.Time
+	SessionCreatedAt *time.Time
+}
+
+func (r searchRow) result() SearchResult {
+	result := SearchResult{
+		SessionID:   r.SessionID,
+		SessionName: r.SessionName,
+		AgentID:     r.AgentID,
+		Snippet:     r.Snippet,
+	}
+	if r.DocType == searchDocumentEvent {
+		result.EventID = r.DocID
+	}
+	if r.EventCreatedAt != nil {
+		result.CreatedAt = *r.EventCreatedAt
+	} else if r.SessionCreatedAt != nil {
+		result.CreatedAt = *r.SessionCreatedAt
+	}
+	return result
+}
+
+// searchFilters joins the documents with their sessions s and events e,
+// skipping deleted ones, and applies the filters of the query.
+func searchFilters(db *gorm.DB, query SearchQuery) *gorm.DB {
+	db = db.
+		Joins("JOIN session s ON s.id = search_document.session_id AND s.user_id = search_document.user_id AND s.deleted_at IS NULL").
+		Joins("LEFT JOIN event e ON search_document.doc_type = ? AND e.id = search_document.doc_id AND e.user_id = search_document.user_id", searchDocumentEvent).
+		Where("search_document.doc_type = ? OR (e.id IS NOT NULL AND e.deleted_at IS NULL)", searchDocumentSession)
+	if query.UserID != "" {
+		db = db.Where("search_document.user_id = ?", query.UserID)
+	}
+	if query.AgentID != "" {
+		db = db.Where("s.agent_id = ?", query.AgentID)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("COALESCE(e.created_at, s.created_at) >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("COALESCE(e.created_at, s.created_at) < ?", query.To)
+	}
+	return db
+}
+
+func scanSearchRows(db *gorm.DB) ([]SearchResult, error) {
+	var rows []searchRow
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, row.result())
+	}
+	return results, nil
+}
+
+const searchSelect = "search_document.doc_type, search_document.doc_id, search_document.session_id, s.name AS session_name, s.agent_id, e.created_at AS event_created_at, s.created_at AS session_created_at"
+
+// sqliteSearch indexes documents in an FTS5 table.
+type sqliteSearch struct{}
+
+func (sqliteSearch) index(db *gorm.DB, docType, docID, sessionID, userID, content string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// FTS5 tables have no unique constraints to upsert on
+		err := tx.Exec("DELETE FROM "+searchDocumentTable+" WHERE doc_type = ? AND doc_id = ? AND user_id = ?", docType, docID, userID).Error
+		if err != nil || content == "" {
+			return err
+		}
+		return tx.Exec("INSERT INTO "+searchDocumentTable+" (content, doc_type, doc_id, session_id, user_id) VALUES (?, ?, ?, ?, ?)",
+			content, docType, docID, sessionID, userID).Error
+	})
+}
+
+func (sqliteSearch) search(db *gorm.DB, query SearchQuery) ([]SearchResult, error) {
+	q := db.Table(searchDocumentTable).
+		Select(searchSelect+", snippet(search_document, 0, ?, ?, '…', 16) AS snippet", searchHighlightStart, searchHighlightStop).
+		Where("search_document MATCH ?", fts5Query(query.Query))
+	q = searchFilters(q, query).Order("bm25(search_document)").Limit(query.Limit)
+	return scanSearchRows(q)
+}
+
+// fts5Query quotes the words of a query, so they're matched as is rather than
+// parsed as FTS5 query syntax.
+func fts5Query(query string) string {
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+// postgresSearch indexes documents in a table with a generated tsvector column.
+type postgresSearch struct{}
+
+func (postgresSearch) index(db *gorm.DB, docType, docID, sessionID, userID, content string) error {
+	if content == "" {
+		return db.Exec("DELETE FROM "+searchDocumentTable+" WHERE doc_type = ? AND doc_id = ? AND user_id = ?", docType, docID, userID).Error
+	}
+	return db.Table(searchDocumentTable).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "doc_type"}, {Name: "doc_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"session_id", "content"}),
+	}).Create(map[string]any{
+		"doc_type":   docType,
+		"doc_id":     docID,
+		"session_id": sessionID,
+		"user_id":    userID,
+		"content":    content,
+	}).Error
+}
+
+func (postgresSearch) search(db *gorm.DB, query SearchQuery) ([]SearchResult, error) {
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=16, MinWords=4", searchHighlightStart, searchHighlightStop)
+	q := db.Table(searchDocumentTable).
+		Select(searchSelect+", ts_headline('english', search_document.content, q.query, ?) AS snippet", options).
+		Joins("CROSS JOIN websearch_to_tsquery('english', ?) AS q(query)", query.Query).
+		Where("search_document.content_tsv @@ q.query")
+	q = searchFilters(q, query).Order("ts_rank(search_document.content_tsv, q.query) DESC").Limit(query.Limit)
+	return scanSearchRows(q)
+}
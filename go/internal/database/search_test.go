@@ -0,0 +1,120 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+)
+
+func adkEvent(text string) string {
+	return `{"author":"agent","content":{"role":"model","parts":[{"text":"` + text + `"},{"function_call":{"name":"k8s_get_resources"}}]}}`
+}
+
+func TestSearchSessions(t *testing.T) {
+	manager := newTestManager(t)
+	require.NoError(t, manager.Initialize())
+	client := NewClient(manager)
+
+	require.NoError(t, client.StoreSession(&Session{ID: "ingress", UserID: "user-1", AgentID: ptr.To("k8s_agent"), Name: ptr.To("Ingress debugging")}))
+	require.NoError(t, client.StoreSession(&Session{ID: "helm", UserID: "user-1", AgentID: ptr.To("helm_agent")}))
+	require.NoError(t, client.StoreSession(&Session{ID: "other-user", UserID: "user-2", AgentID: ptr.To("k8s_agent")}))
+	require.NoError(t, client.StoreEvents(
+		&Event{ID: "ingress-1", SessionID: "ingress", UserID: "user-1", Data: adkEvent("The ingress controller was missing its TLS secret, I recreated it.")},
+		&Event{ID: "ingress-2", SessionID: "ingress", UserID: "user-1", Data: `{"kind":"message","role":"user","parts":[{"kind":"text","text":"thanks, what about the pods?"}]}`},
+		&Event{ID: "helm-1", SessionID: "helm", UserID: "user-1", Data: adkEvent("Upgraded the release, its ingresses are unchanged.")},
+		&Event{ID: "other-user-1", SessionID: "other-user", UserID: "user-2", Data: adkEvent("Fixed the ingress.")},
+	))
+
+	search := func(t *testing.T, query SearchQuery) []SearchResult {
+		t.Helper()
+		if query.UserID == "" {
+			query.UserID = "user-1"
+		}
+		results, err := client.SearchSessions(query)
+		require.NoError(t, err)
+		return results
+	}
+	ids := func(results []SearchResult) []string {
+		var ids []string
+		for _, result := range results {
+			if result.EventID != "" {
+				ids = append(ids, result.EventID)
+			} else {
+				ids = append(ids, "session:"+result.SessionID)
+			}
+		}
+		return ids
+	}
+
+	t.Run("matches event text and session names", func(t *testing.T) {
+		results := search(t, SearchQuery{Query: "ingress"})
+		assert.ElementsMatch(t, []string{"ingress-1", "helm-1", "session:ingress"}, ids(results), "words are stemmed")
+		for _, result := range results {
+			assert.Contains(t, result.Snippet, searchHighlightStart)
+			assert.False(t, result.CreatedAt.IsZero())
+			if result.EventID == "ingress-1" {
+				assert.Equal(t, "Ingress debugging", *result.SessionName)
+				assert.Equal(t, "k8s_agent", *result.AgentID)
+				assert.Contains(t, result.Snippet, "The <mark>ingress</mark> controller")
+			}
+		}
+
+		assert.Equal(t, []string{"ingress-2"}, ids(search(t, SearchQuery{Query: "PODS"})), "A2A messages are indexed")
+		assert.Empty(t, search(t, SearchQuery{Query: "k8s_get_resources"}), "only text parts are indexed")
+		assert.Equal(t, []string{"ingress-1"}, ids(search(t, SearchQuery{Query: "ingress tls"})), "all words match")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Equal(t, []string{"helm-1"}, ids(search(t, SearchQuery{Query: "ingress", AgentID: "helm_agent"})))
+		assert.Equal(t, []string{"other-user-1"}, ids(search(t, SearchQuery{Query: "ingress", UserID: "user-2"})))
+		assert.Empty(t, search(t, SearchQuery{Query: "ingress", From: time.Now().Add(time.Hour)}))
+		assert.Empty(t, search(t, SearchQuery{Query: "ingress", To: time.Now().Add(-time.Hour)}))
+		assert.Len(t, search(t, SearchQuery{Query: "ingress", Limit: 1}), 1)
+	})
+
+	t.Run("query syntax is matched literally", func(t *testing.T) {
+		for _, query := range []string{`ingress"`, "ingress AND", "NEAR(ingress", "tls*"} {
+			_, err := client.SearchSessions(SearchQuery{UserID: "user-1", Query: query})
+			assert.NoError(t, err, query)
+		}
+		_, err := client.SearchSessions(SearchQuery{UserID: "user-1", Query: " "})
+		assert.Error(t, err)
+	})
+
+	t.Run("follows updates and deletes", func(t *testing.T) {
+		require.NoError(t, client.StoreEvents(&Event{ID: "helm-1", SessionID: "helm", UserID: "user-1", Data: adkEvent("Rolled back the release.")}))
+		assert.Empty(t, search(t, SearchQuery{Query: "ingress", AgentID: "helm_agent"}))
+		assert.Equal(t, []string{"helm-1"}, ids(search(t, SearchQuery{Query: "rolled"})))
+
+		require.NoError(t, client.StoreSession(&Session{ID: "helm", UserID: "user-1", AgentID: ptr.To("helm_agent"), Name: ptr.To("Helm rollback")}))
+		assert.Equal(t, []string{"session:helm"}, ids(search(t, SearchQuery{Query: "rollback"})))
+
+		require.NoError(t, client.DeleteSession("ingress", "user-1"))
+		assert.Empty(t, search(t, SearchQuery{Query: "tls"}))
+	})
+}
+
+func TestSearchIndexesExistingData(t *testing.T) {
+	manager := newTestManager(t)
+	require.NoError(t, manager.MigrateTo(5))
+	require.NoError(t, manager.db.Omit("ParentSessionID", "ParentEventID").Create(&Session{ID: "session-1", UserID: "user-1", Name: ptr.To("Ingress debugging")}).Error)
+	require.NoError(t, manager.db.Omit("AuthorID").Create([]*Event{
+		{ID: "event-1", SessionID: "session-1", UserID: "user-1", Data: adkEvent("Recreated the TLS secret.")},
+		{ID: "event-2", SessionID: "session-1", UserID: "user-1", Data: "not json"},
+	}).Error)
+
+	require.NoError(t, manager.Initialize())
+
+	results, err := NewClient(manager).SearchSessions(SearchQuery{UserID: "user-1", Query: "tls"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "event-1", results[0].EventID)
+
+	results, err = NewClient(manager).SearchSessions(SearchQuery{UserID: "user-1", Query: "debugging"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "session-1", results[0].SessionID)
+}
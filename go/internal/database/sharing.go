@@ -0,0 +1,108 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StoreSessionShare grants a user a role in a session, replacing the role they
+// had in it.
+func (c *clientImpl) StoreSessionShare(share *SessionShare) error {
+	err := c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "owner_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(share).Error
+	if err != nil {
+		return fmt.Errorf("failed to store session share: %w", err)
+	}
+	return nil
+}
+
+// DeleteSessionShare revokes the access of a user to a session.
+func (c *clientImpl) DeleteSessionShare(sessionID, ownerID, userID string) error {
+	err := c.db.Where("session_id = ? AND owner_id = ? AND user_id = ?", sessionID, ownerID, userID).Delete(&SessionShare{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete session share: %w", err)
+	}
+	return nil
+}
+
+// GetSessionShare returns the share of a session with a user.
+func (c *clientImpl) GetSessionShare(sessionID, userID string) (*SessionShare, error) {
+	return get[SessionShare](c.db,
+		Clause{Key: "session_id", Value: sessionID},
+		Clause{Key: "user_id", Value: userID})
+}
+
+// ListSessionShares lists the users a session is shared with.
+func (c *clientImpl) ListSessionShares(sessionID, ownerID string) ([]SessionShare, error) {
+	return list[SessionShare](c.db,
+		Clause{Key: "session_id", Value: sessionID},
+		Clause{Key: "owner_id", Value: ownerID})
+}
+
+// ListSharedSessions lists the sessions shared with a user.
+func (c *clientImpl) ListSharedSessions(userID string) ([]Session, error) {
+	var sessions []Session
+	err := c.db.
+		Select("session.*").
+		Joins("JOIN session_share ON session_share.session_id = session.id AND session_share.owner_id = session.user_id").
+		Where("session_share.user_id = ?", userID).
+		Order("session.created_at ASC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// StoreSessionShareLink stores a share link of a session.
+func (c *clientImpl) StoreSessionShareLink(link *SessionShareLink) error {
+	return save(c.db, link)
+}
+
+// GetSessionShareLink returns the share link with the hash of a token.
+func (c *clientImpl) GetSessionShareLink(tokenHash string) (*SessionShareLink, error) {
+	return get[SessionShareLink](c.db, Clause{Key: "token_hash", Value: tokenHash})
+}
+
+// ListSessionShareLinks lists the share links of a session.
+func (c *clientImpl) ListSessionShareLinks(sessionID, ownerID string) ([]SessionShareLink, error) {
+	return list[SessionShareLink](c.db,
+		Clause{Key: "session_id", Value: sessionID},
+		Clause{Key: "owner_id", Value: ownerID})
+}
+
+// DeleteSessionShareLink deletes a share link of a session. Users who redeemed
+// it keep their access.
+func (c *clientImpl) DeleteSessionShareLink(sessionID, ownerID, linkID string) error {
+	err := c.db.Where("id = ? AND session_id = ? AND owner_id = ?", linkID, sessionID, ownerID).Delete(&SessionShareLink{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete session share link: %w", err)
+	}
+	return nil
+}
+
+// GetSessionForUser returns a session that the user owns or that's shared
+// with them, and the role of the user in it.
+func GetSessionForUser(c Client, sessionID, userID string) (*Session, string, error) {
+	session, err := c.GetSession(sessionID, userID)
+	if err == nil {
+		return session, SessionRoleOwner, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", err
+	}
+	share, err := c.GetSessionShare(sessionID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	session, err = c.GetSession(sessionID, share.OwnerID)
+	if err != nil {
+		return nil, "", err
+	}
+	return session, share.Role, nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"k8s.io/utils/ptr"
+)
+
+func TestSessionSharing(t *testing.T) {
+	manager := newTestManager(t)
+	require.NoError(t, manager.Initialize())
+	client := NewClient(manager)
+
+	require.NoError(t, client.StoreSession(&Session{ID: "session-1", UserID: "alice", Name: ptr.To("Shared")}))
+	require.NoError(t, client.StoreSession(&Session{ID: "session-2", UserID: "alice"}))
+	require.NoError(t, client.StoreSessionShare(&SessionShare{SessionID: "session-1", OwnerID: "alice", UserID: "bob", Role: SessionRoleViewer}))
+	require.NoError(t, client.StoreSessionShare(&SessionShare{SessionID: "session-1", OwnerID: "alice", UserID: "carol", Role: SessionRoleViewer}))
+	// Sharing again changes the role
+	require.NoError(t, client.StoreSessionShare(&SessionShare{SessionID: "session-1", OwnerID: "alice", UserID: "carol", Role: SessionRoleCollaborator}))
+
+	t.Run("roles", func(t *testing.T) {
+		for userID, role := range map[string]string{"alice": SessionRoleOwner, "bob": SessionRoleViewer, "carol": SessionRoleCollaborator} {
+			session, got, err := GetSessionForUser(client, "session-1", userID)
+			require.NoError(t, err, userID)
+			assert.Equal(t, "alice", session.UserID, userID)
+			assert.Equal(t, role, got, userID)
+		}
+
+		_, _, err := GetSessionForUser(client, "session-2", "bob")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		shares, err := client.ListSessionShares("session-1", "alice")
+		require.NoError(t, err)
+		require.Len(t, shares, 2)
+
+		sessions, err := client.ListSharedSessions("carol")
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "session-1", sessions[0].ID)
+		assert.Equal(t, "Shared", *sessions[0].Name)
+	})
+
+	t.Run("unshare", func(t *testing.T) {
+		require.NoError(t, client.DeleteSessionShare("session-1", "alice", "bob"))
+		_, _, err := GetSessionForUser(client, "session-1", "bob")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("links", func(t *testing.T) {
+		require.NoError(t, client.StoreSessionShareLink(&SessionShareLink{ID: "link-1", TokenHash: "hash-1", SessionID: "session-1", OwnerID: "alice", Role: SessionRoleViewer}))
+
+		link, err := client.GetSessionShareLink("hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, "link-1", link.ID)
+
+		links, err := client.ListSessionShareLinks("session-1", "alice")
+		require.NoError(t, err)
+		assert.Len(t, links, 1)
+
+		// Only the owner of the session deletes its links
+		require.NoError(t, client.DeleteSessionShareLink("session-1", "bob", "link-1"))
+		_, err = client.GetSessionShareLink("hash-1")
+		require.NoError(t, err)
+		require.NoError(t, client.DeleteSessionShareLink("session-1", "alice", "link-1"))
+		_, err = client.GetSessionShareLink("hash-1")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+}
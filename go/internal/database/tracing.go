@@ -0,0 +1,81 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/kagent-dev/kagent/go/internal/tracing"
+)
+
+const tracingSpanKey = "kagent:tracing_span"
+
+// tracingPlugin creates a span for each statement gorm executes, as a child of
+// the span in the context of the statement (see Client.WithContext).
+type tracingPlugin struct{}
+
+var _ gorm.Plugin = tracingPlugin{}
+
+func (tracingPlugin) Name() string {
+	return "kagent:tracing"
+}
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("kagent:tracing:before_create", p.before("create")),
+		callbacks.Create().After("gorm:create").Register("kagent:tracing:after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("kagent:tracing:before_query", p.before("query")),
+		callbacks.Query().After("gorm:query").Register("kagent:tracing:after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("kagent:tracing:before_update", p.before("update")),
+		callbacks.Update().After("gorm:update").Register("kagent:tracing:after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("kagent:tracing:before_delete", p.before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("kagent:tracing:after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("kagent:tracing:before_row", p.before("row")),
+		callbacks.Row().After("gorm:row").Register("kagent:tracing:after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("kagent:tracing:before_raw", p.before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("kagent:tracing:after_raw", p.after),
+	)
+}
+
+func (tracingPlugin) before(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "db " + op
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := tracing.Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system.name", db.Name()),
+				attribute.String("db.operation.name", op),
+				attribute.String("db.collection.name", db.Statement.Table),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (tracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.response.returned_rows", db.RowsAffected),
+	)
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/tracing"
+)
+
+func TestClientTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	manager := newTestManager(t)
+	require.NoError(t, manager.Initialize())
+
+	ctx, span := tracing.Start(context.Background(), "request")
+	client := NewClient(manager).WithContext(ctx)
+	require.NoError(t, client.StoreTask(&protocol.Task{ID: "task-1", ContextID: "session-1", Status: protocol.TaskStatus{State: protocol.TaskStateWorking}}))
+	require.NoError(t, client.StoreEvents(&Event{ID: "event-1", SessionID: "session-1", UserID: "user-1", Data: "{}"}))
+	span.End()
+
+	traceID := span.SpanContext().TraceID()
+	var names []string
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID() == traceID && s.Parent().SpanID() == span.SpanContext().SpanID() {
+			names = append(names, s.Name())
+		}
+	}
+	assert.Contains(t, names, "db create task")
+	assert.Contains(t, names, "db create event")
+
+	task, err := get[Task](manager.db, Clause{Key: "id", Value: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, traceID.String(), task.TraceID)
+	event, err := get[Event](manager.db, Clause{Key: "id", Value: "event-1"})
+	require.NoError(t, err)
+	assert.Equal(t, traceID.String(), event.TraceID)
+
+	// Calls without a trace store no trace ID
+	require.NoError(t, NewClient(manager).StoreTask(&protocol.Task{ID: "task-2", ContextID: "session-1"}))
+	task, err = get[Task](manager.db, Clause{Key: "id", Value: "task-2"})
+	require.NoError(t, err)
+	assert.Empty(t, task.TraceID)
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// UsageGroupBy is the dimension usage is aggregated by.
+type UsageGroupBy string
+
+const (
+	UsageGroupByNone    UsageGroupBy = ""
+	UsageGroupByAgent   UsageGroupBy = "agent"
+	UsageGroupByUser    UsageGroupBy = "user"
+	UsageGroupBySession UsageGroupBy = "session"
+	UsageGroupByModel   UsageGroupBy = "model"
+	UsageGroupByDay     UsageGroupBy = "day"
+)
+
+// UsageFilter restricts the usage that is aggregated. Zero fields match all rows.
+type UsageFilter struct {
+	// From and To bound the creation time of the usage, From inclusive and To exclusive.
+	From      time.Time
+	To        time.Time
+	AgentID   string
+	UserID    string
+	SessionID string
+	Model     string
+}
+
+// UsageAggregate sums the usage of a group and model. Usage is always split by
+// model, so that its cost can be computed.
+type UsageAggregate struct {
+	// Group is the value of the group by dimension, empty without grouping.
+	// Days are formatted as YYYY-MM-DD in UTC.
+	Group            string `json:"group"`
+	Model            string `json:"model"`
+	Calls            int64  `json:"calls"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	CachedTokens     int64  `json:"cached_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+}
+
+// StoreUsage stores the usage of a model call. Usage is keyed by user and ID,
+// as event IDs are only unique for a user. Storing the same key again only
+// updates the token counts, e.g. when a streamed call reports its final usage.
+func (c *clientImpl) StoreUsage(usage *Usage) error {
+	err := c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "prompt_tokens", "completion_tokens", "cached_tokens", "total_tokens"}),
+	}).Create(usage).Error
+	if err != nil {
+		return fmt.Errorf("failed to store usage: %w", err)
+	}
+	return nil
+}
+
+// SummarizeUsage aggregates the usage matching the filter by the group by
+// dimension and model.
+func (c *clientImpl) SummarizeUsage(groupBy UsageGroupBy, filter UsageFilter) ([]UsageAggregate, error) {
+	groupExpr, err := c.usageGroupExpression(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	selects := "model, COUNT(*) AS calls, SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, SUM(cached_tokens) AS cached_tokens, SUM(total_tokens) AS total_tokens"
+	groups := "model"
+	if groupExpr != "" {
+		selects = groupExpr + ` AS "group", ` + selects
+		groups = groupExpr + ", model"
+	}
+
+	query := c.db.Model(&Usage{}).Select(selects).Group(groups)
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	for _, cl := range []Clause{
+		{Key: "agent_id", Value: filter.AgentID},
+		{Key: "user_id", Value: filter.UserID},
+		{Key: "session_id", Value: filter.SessionID},
+		{Key: "model", Value: filter.Model},
+	} {
+		if cl.Value != "" {
+			query = query.Where(fmt.Sprintf("%s = ?", cl.Key), cl.Value)
+		}
+	}
+
+	var aggregates []UsageAggregate
+	if err := query.Order(groups).Scan(&aggregates).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarize usage: %w", err)
+	}
+	return aggregates, nil
+}
+
+func (c *clientImpl) usageGroupExpression(groupBy UsageGroupBy) (string, error) {
+	switch groupBy {
+	case UsageGroupByNone:
+		return "", nil
+	case UsageGroupByAgent:
+		return "agent_id", nil
+	case UsageGroupByUser:
+		return "user_id", nil
+	case UsageGroupBySession:
+		return "session_id", nil
+	case UsageGroupByModel:
+		return "model", nil
+	case UsageGroupByDay:
+		if c.db.Name() == string(DatabaseTypePostgres) {
+			return "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')", nil
+		}
+		return "date(created_at)", nil
+	default:
+		return "", fmt.Errorf("invalid usage group by %q", groupBy)
+	}
+}
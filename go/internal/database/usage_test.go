@@ -0,0 +1,77 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, time.October, d, hour, 0, 0, 0, time.UTC) }
+
+	manager := newTestManager(t)
+	require.NoError(t, manager.Initialize())
+	client := NewClient(manager)
+
+	for _, usage := range []*Usage{
+		{ID: "1", AgentID: "default/a", UserID: "user-1", SessionID: "s-1", Model: "gpt-4o", PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110, CreatedAt: day(1, 10)},
+		{ID: "2", AgentID: "default/a", UserID: "user-2", SessionID: "s-2", Model: "gpt-4o", PromptTokens: 200, CompletionTokens: 20, CachedTokens: 50, TotalTokens: 220, CreatedAt: day(1, 23)},
+		{ID: "3", AgentID: "default/b", UserID: "user-1", SessionID: "s-3", Model: "gemini-2.5-flash", PromptTokens: 300, CompletionTokens: 30, TotalTokens: 330, CreatedAt: day(2, 1)},
+	} {
+		require.NoError(t, client.StoreUsage(usage))
+	}
+
+	// Storing an ID of a user again updates the token counts only
+	require.NoError(t, client.StoreUsage(&Usage{ID: "3", UserID: "user-1", AgentID: "other", Model: "other", PromptTokens: 400, CompletionTokens: 40, TotalTokens: 440}))
+
+	t.Run("totals per model", func(t *testing.T) {
+		aggregates, err := client.SummarizeUsage(UsageGroupByNone, UsageFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []UsageAggregate{
+			{Model: "gemini-2.5-flash", Calls: 1, PromptTokens: 400, CompletionTokens: 40, TotalTokens: 440},
+			{Model: "gpt-4o", Calls: 2, PromptTokens: 300, CompletionTokens: 30, CachedTokens: 50, TotalTokens: 330},
+		}, aggregates)
+	})
+
+	t.Run("by user", func(t *testing.T) {
+		aggregates, err := client.SummarizeUsage(UsageGroupByUser, UsageFilter{})
+		require.NoError(t, err)
+		require.Len(t, aggregates, 3)
+		assert.Equal(t, "user-1", aggregates[0].Group)
+		assert.Equal(t, "gemini-2.5-flash", aggregates[0].Model)
+		assert.Equal(t, "user-1", aggregates[1].Group)
+		assert.Equal(t, "gpt-4o", aggregates[1].Model)
+		assert.Equal(t, "user-2", aggregates[2].Group)
+	})
+
+	t.Run("by day within a time range", func(t *testing.T) {
+		aggregates, err := client.SummarizeUsage(UsageGroupByDay, UsageFilter{From: day(1, 12), To: day(3, 0)})
+		require.NoError(t, err)
+		assert.Equal(t, []UsageAggregate{
+			{Group: "2026-10-01", Model: "gpt-4o", Calls: 1, PromptTokens: 200, CompletionTokens: 20, CachedTokens: 50, TotalTokens: 220},
+			{Group: "2026-10-02", Model: "gemini-2.5-flash", Calls: 1, PromptTokens: 400, CompletionTokens: 40, TotalTokens: 440},
+		}, aggregates)
+	})
+
+	t.Run("filtered by agent", func(t *testing.T) {
+		aggregates, err := client.SummarizeUsage(UsageGroupBySession, UsageFilter{AgentID: "default/a", UserID: "user-1"})
+		require.NoError(t, err)
+		require.Len(t, aggregates, 1)
+		assert.Equal(t, "s-1", aggregates[0].Group)
+	})
+
+	t.Run("IDs of other users are stored apart", func(t *testing.T) {
+		require.NoError(t, client.StoreUsage(&Usage{ID: "1", AgentID: "default/a", UserID: "user-3", Model: "gpt-4o", PromptTokens: 1, TotalTokens: 1}))
+		aggregates, err := client.SummarizeUsage(UsageGroupByNone, UsageFilter{UserID: "user-1", Model: "gpt-4o"})
+		require.NoError(t, err)
+		require.Len(t, aggregates, 1)
+		assert.Equal(t, int64(110), aggregates[0].TotalTokens)
+	})
+
+	t.Run("invalid group by", func(t *testing.T) {
+		_, err := client.SummarizeUsage("tool", UsageFilter{})
+		assert.Error(t, err)
+	})
+}
@@ -2,11 +2,15 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 
+	"github.com/kagent-dev/kagent/go/internal/tracing"
 	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -19,6 +23,9 @@ func (s *SimpleSession) Principal() auth.Principal {
 	return s.P
 }
 
+// UnsecureAuthenticator trusts the user and agent named by the request headers.
+// It's meant for development only and can't be combined with other providers,
+// which would let requests they reject fall back to trusting headers.
 type UnsecureAuthenticator struct{}
 
 func (a *UnsecureAuthenticator) Authenticate(ctx context.Context, reqHeaders http.Header, query url.Values) (auth.Session, error) {
@@ -47,15 +54,59 @@ func (a *UnsecureAuthenticator) Authenticate(ctx context.Context, reqHeaders htt
 
 func (a *UnsecureAuthenticator) UpstreamAuth(r *http.Request, session auth.Session, upstreamPrincipal auth.Principal) error {
 	// for unsecure, just forward user id in header
+	forwardSession(r, session)
+	return nil
+}
+
+// forwardSession forwards the user id and the original Authorization header of a session.
+func forwardSession(r *http.Request, session auth.Session) {
 	if session == nil || session.Principal().User.ID == "" {
-		return nil
+		return
 	}
 	r.Header.Set("X-User-Id", session.Principal().User.ID)
 
 	if simpleSession, ok := session.(*SimpleSession); ok && simpleSession.authHeader != "" {
 		r.Header.Set("Authorization", simpleSession.authHeader)
 	}
+}
+
+// ChainAuthenticator tries each provider in order and uses the first one that
+// authenticates the request. Upstream auth is delegated to that same provider.
+type ChainAuthenticator struct {
+	providers []auth.AuthProvider
+}
+
+type chainSession struct {
+	auth.Session
+	provider auth.AuthProvider
+}
+
+func NewChainAuthenticator(providers ...auth.AuthProvider) *ChainAuthenticator {
+	return &ChainAuthenticator{
+		providers: providers,
+	}
+}
 
+func (a *ChainAuthenticator) Authenticate(ctx context.Context, reqHeaders http.Header, query url.Values) (auth.Session, error) {
+	var errs []error
+	for _, provider := range a.providers {
+		session, err := provider.Authenticate(ctx, reqHeaders, query)
+		if err == nil {
+			return &chainSession{Session: session, provider: provider}, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no authenticators configured")
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (a *ChainAuthenticator) UpstreamAuth(r *http.Request, session auth.Session, upstreamPrincipal auth.Principal) error {
+	if cs, ok := session.(*chainSession); ok {
+		return cs.provider.UpstreamAuth(r, cs.Session, upstreamPrincipal)
+	}
+	forwardSession(r, session)
 	return nil
 }
 
@@ -107,10 +158,23 @@ func A2ARequestHandler(authProvider auth.AuthProvider, agentNns types.Namespaced
 			}
 		}
 
-		resp, err = client.Do(req)
+		// The span covers the request until the agent's response headers, the
+		// stream of a streaming response is covered by the A2A proxy's span.
+		ctx, span := tracing.Start(ctx, "a2a.upstream",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("kagent.agent", upstreamPrincipal.Agent.ID),
+				attribute.String("url.full", req.URL.String()),
+			),
+		)
+		defer func() { tracing.End(span, err) }()
+		tracing.Inject(ctx, req.Header)
+
+		resp, err = client.Do(req.WithContext(ctx))
 		if err != nil {
 			return nil, fmt.Errorf("a2aClient.httpRequestHandler: http request failed: %w", err)
 		}
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 		return resp, nil
 	}
@@ -1,13 +1,18 @@
 package auth_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
 	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/internal/tracing"
 	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestAuthnMiddleware(t *testing.T) {
@@ -55,3 +60,38 @@ func TestAuthnMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestA2ARequestHandlerPropagatesTraceContext(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Config{}, "test"); err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstream.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	handler := authimpl.A2ARequestHandler(&authimpl.UnsecureAuthenticator{}, types.NamespacedName{Namespace: "default", Name: "agent"})
+	resp, err := handler.Handle(ctx, upstream.Client(), req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if !strings.HasPrefix(traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+		t.Fatalf("Expected the trace to be propagated upstream, got traceparent %q", traceparent)
+	}
+}
@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+var (
+	// ErrNoBearerToken is returned when a request carries no bearer token.
+	ErrNoBearerToken = errors.New("no bearer token in request")
+)
+
+const (
+	defaultOIDCUsernameClaim = "sub"
+	defaultOIDCRolesClaim    = "groups"
+
+	oidcDiscoveryPath     = "/.well-known/openid-configuration"
+	jwksMinRefreshPeriod  = 5 * time.Minute
+	jwksForcedRefreshWait = time.Minute
+)
+
+// OIDCConfig configures an OIDCAuthenticator.
+type OIDCConfig struct {
+	// IssuerURL is the expected "iss" claim. Unless JWKSURL is set, it is also
+	// used to discover the JWKS endpoint via /.well-known/openid-configuration.
+	IssuerURL string
+	// Audience is the expected "aud" claim. Audience validation is skipped when empty.
+	Audience string
+	// JWKSURL overrides the JWKS endpoint returned by discovery.
+	JWKSURL string
+	// UsernameClaim is the claim used as the user ID. Defaults to "sub".
+	UsernameClaim string
+	// RolesClaim is the claim mapped to auth.User.Roles. Nested claims can be
+	// addressed with a dot-separated path, e.g. "realm_access.roles". Defaults to "groups".
+	RolesClaim string
+	// AcceptableSkew is the clock skew tolerated when validating exp/nbf/iat.
+	AcceptableSkew time.Duration
+	// HTTPClient is used for discovery and JWKS requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// OIDCAuthenticator authenticates requests carrying an OIDC/JWT bearer token.
+// Tokens are verified against the issuer's JWKS, which is cached and refreshed
+// in the background. Principals are users only: agents authenticate with their
+// service account tokens through the TokenReviewAuthenticator.
+type OIDCAuthenticator struct {
+	config OIDCConfig
+	cache  *jwk.Cache
+	// resolve deduplicates concurrent resolutions of the JWKS endpoint
+	resolve singleflight.Group
+
+	mu             sync.Mutex
+	jwksURL        string
+	lastForcedSync time.Time
+}
+
+var _ auth.AuthProvider = (*OIDCAuthenticator)(nil)
+
+// NewOIDCAuthenticator creates an OIDCAuthenticator. The context bounds the
+// lifetime of the background JWKS refresh.
+func NewOIDCAuthenticator(ctx context.Context, config OIDCConfig) (*OIDCAuthenticator, error) {
+	if config.IssuerURL == "" {
+		return nil, fmt.Errorf("oidc issuer URL is required")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = defaultOIDCUsernameClaim
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = defaultOIDCRolesClaim
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	return &OIDCAuthenticator{
+		config: config,
+		cache:  jwk.NewCache(ctx),
+	}, nil
+}
+
+func (a *OIDCAuthenticator) Authenticate(ctx context.Context, reqHeaders http.Header, query url.Values) (auth.Session, error) {
+	authHeader := reqHeaders.Get("Authorization")
+	rawToken, ok := bearerToken(authHeader)
+	if !ok {
+		return nil, ErrNoBearerToken
+	}
+
+	token, err := a.verify(ctx, rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+
+	userID, err := stringClaim(token, a.config.UsernameClaim)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SimpleSession{
+		P: auth.Principal{
+			User: auth.User{
+				ID:    userID,
+				Roles: stringsClaim(token, a.config.RolesClaim),
+			},
+		},
+		authHeader: authHeader,
+	}, nil
+}
+
+// UpstreamAuth forwards the caller's identity and bearer token to the upstream agent.
+func (a *OIDCAuthenticator) UpstreamAuth(r *http.Request, session auth.Session, upstreamPrincipal auth.Principal) error {
+	forwardSession(r, session)
+	return nil
+}
+
+func (a *OIDCAuthenticator) verify(ctx context.Context, rawToken string) (jwt.Token, error) {
+	keySet, jwksURL, err := a.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.parse(ctx, rawToken, keySet)
+	if err == nil || !a.shouldForceRefresh() {
+		return token, err
+	}
+
+	// The signing key may have been rotated since the last refresh.
+	if _, refreshErr := a.cache.Refresh(ctx, jwksURL); refreshErr != nil {
+		return nil, fmt.Errorf("%w (jwks refresh failed: %v)", err, refreshErr)
+	}
+	return a.parse(ctx, rawToken, keySet)
+}
+
+func (a *OIDCAuthenticator) parse(ctx context.Context, rawToken string, keySet jwk.Set) (jwt.Token, error) {
+	opts := []jwt.ParseOption{
+		jwt.WithKeySet(keySet, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithValidate(true),
+		jwt.WithContext(ctx),
+		jwt.WithIssuer(a.config.IssuerURL),
+		jwt.WithAcceptableSkew(a.config.AcceptableSkew),
+	}
+	if a.config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(a.config.Audience))
+	}
+	return jwt.ParseString(rawToken, opts...)
+}
+
+// shouldForceRefresh rate-limits out-of-band JWKS refreshes so that invalid
+// tokens cannot be used to hammer the identity provider.
+func (a *OIDCAuthenticator) shouldForceRefresh() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if time.Since(a.lastForcedSync) < jwksForcedRefreshWait {
+		return false
+	}
+	a.lastForcedSync = time.Now()
+	return true
+}
+
+// keySet lazily resolves the JWKS endpoint and registers it with the cache, so
+// that the controller can start even if the identity provider is unreachable.
+// A failed resolution is retried by the next request.
+func (a *OIDCAuthenticator) keySet(ctx context.Context) (jwk.Set, string, error) {
+	a.mu.Lock()
+	jwksURL := a.jwksURL
+	a.mu.Unlock()
+
+	if jwksURL == "" {
+		// The network requests are made without holding the mutex, so that they
+		// don't block the rate limiting of forced refreshes.
+		resolved, err, _ := a.resolve.Do("jwks", func() (any, error) {
+			return a.resolveJWKSURL(ctx)
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		jwksURL = resolved.(string)
+	}
+
+	return jwk.NewCachedSet(a.cache, jwksURL), jwksURL, nil
+}
+
+func (a *OIDCAuthenticator) resolveJWKSURL(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	resolved := a.jwksURL
+	a.mu.Unlock()
+	if resolved != "" {
+		return resolved, nil
+	}
+
+	jwksURL := a.config.JWKSURL
+	if jwksURL == "" {
+		discovered, err := a.discoverJWKSURL(ctx)
+		if err != nil {
+			return "", err
+		}
+		jwksURL = discovered
+	}
+	if err := a.cache.Register(jwksURL,
+		jwk.WithHTTPClient(a.config.HTTPClient),
+		jwk.WithMinRefreshInterval(jwksMinRefreshPeriod),
+	); err != nil {
+		return "", fmt.Errorf("failed to register jwks url %s: %w", jwksURL, err)
+	}
+	if _, err := a.cache.Refresh(ctx, jwksURL); err != nil {
+		_ = a.cache.Unregister(jwksURL)
+		return "", fmt.Errorf("failed to fetch jwks from %s: %w", jwksURL, err)
+	}
+
+	a.mu.Lock()
+	a.jwksURL = jwksURL
+	a.lastForcedSync = time.Now()
+	a.mu.Unlock()
+	return jwksURL, nil
+}
+
+func (a *OIDCAuthenticator) discoverJWKSURL(ctx context.Context) (string, error) {
+	discoveryURL := strings.TrimSuffix(a.config.IssuerURL, "/") + oidcDiscoveryPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create oidc discovery request: %w", err)
+	}
+	resp, err := a.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc discovery returned status %d", resp.StatusCode)
+	}
+
+	var metadata struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("failed to decode oidc discovery document: %w", err)
+	}
+	if metadata.Issuer != a.config.IssuerURL {
+		return "", fmt.Errorf("oidc discovery issuer %q does not match configured issuer %q", metadata.Issuer, a.config.IssuerURL)
+	}
+	if metadata.JWKSURI == "" {
+		return "", fmt.Errorf("oidc discovery document has no jwks_uri")
+	}
+	return metadata.JWKSURI, nil
+}
+
+func bearerToken(authHeader string) (string, bool) {
+	scheme, token, ok := strings.Cut(authHeader, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func claim(token jwt.Token, path string) (any, bool) {
+	name, rest, nested := strings.Cut(path, ".")
+	value, ok := token.Get(name)
+	for ok && nested {
+		obj, isObj := value.(map[string]any)
+		if !isObj {
+			return nil, false
+		}
+		name, rest, nested = strings.Cut(rest, ".")
+		value, ok = obj[name]
+	}
+	return value, ok
+}
+
+func stringClaim(token jwt.Token, path string) (string, error) {
+	value, ok := claim(token, path)
+	if !ok {
+		return "", fmt.Errorf("token has no %q claim", path)
+	}
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("token claim %q is not a non-empty string", path)
+	}
+	return s, nil
+}
+
+// stringsClaim reads a list claim. String claims are split on whitespace and
+// commas, which covers the common "scope" encoding.
+func stringsClaim(token jwt.Token, path string) []string {
+	value, ok := claim(token, path)
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	case []string:
+		return v
+	case []any:
+		roles := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
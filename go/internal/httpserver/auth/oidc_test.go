@@ -0,0 +1,213 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+)
+
+type testIssuer struct {
+	server *httptest.Server
+	key    jwk.Key
+
+	discoveries atomic.Int32
+	unavailable atomic.Bool
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := jwk.FromRaw(rsaKey)
+	require.NoError(t, err)
+	require.NoError(t, key.Set(jwk.KeyIDKey, "test-key"))
+	require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256))
+
+	pub, err := key.PublicKey()
+	require.NoError(t, err)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pub))
+
+	issuer := &testIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer.discoveries.Add(1)
+		if issuer.unavailable.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.server.URL,
+			"jwks_uri": issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	builder := jwt.NewBuilder().
+		Issuer(i.server.URL).
+		Audience([]string{"kagent"}).
+		IssuedAt(time.Now()).
+		Expiration(time.Now().Add(time.Hour))
+	for k, v := range claims {
+		builder = builder.Claim(k, v)
+	}
+	token, err := builder.Build()
+	require.NoError(t, err)
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, i.key))
+	require.NoError(t, err)
+	return string(signed)
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	issuer := newTestIssuer(t)
+	otherIssuer := newTestIssuer(t)
+
+	testCases := []struct {
+		name          string
+		rolesClaim    string
+		authHeader    string
+		expectedUser  string
+		expectedRoles []string
+		expectedErr   string
+	}{
+		{
+			name:          "valid token maps subject and groups",
+			authHeader:    "Bearer " + issuer.sign(t, map[string]any{"sub": "alice", "groups": []string{"admins", "devs"}}),
+			expectedUser:  "alice",
+			expectedRoles: []string{"admins", "devs"},
+		},
+		{
+			name:          "nested roles claim",
+			rolesClaim:    "realm_access.roles",
+			authHeader:    "Bearer " + issuer.sign(t, map[string]any{"sub": "bob", "realm_access": map[string]any{"roles": []string{"viewer"}}}),
+			expectedUser:  "bob",
+			expectedRoles: []string{"viewer"},
+		},
+		{
+			name:        "missing bearer token",
+			expectedErr: "no bearer token",
+		},
+		{
+			name:        "token signed by another issuer",
+			authHeader:  "Bearer " + otherIssuer.sign(t, map[string]any{"sub": "mallory"}),
+			expectedErr: "invalid bearer token",
+		},
+		{
+			name:        "token without username claim",
+			authHeader:  "Bearer " + issuer.sign(t, map[string]any{}),
+			expectedErr: `token has no "sub" claim`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			authn, err := authimpl.NewOIDCAuthenticator(t.Context(), authimpl.OIDCConfig{
+				IssuerURL:  issuer.server.URL,
+				Audience:   "kagent",
+				RolesClaim: tt.rolesClaim,
+			})
+			require.NoError(t, err)
+
+			headers := http.Header{}
+			if tt.authHeader != "" {
+				headers.Set("Authorization", tt.authHeader)
+			}
+			headers.Set("X-Agent-Name", "kagent__NS__k8s_agent")
+			session, err := authn.Authenticate(context.Background(), headers, url.Values{})
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedUser, session.Principal().User.ID)
+			assert.Equal(t, tt.expectedRoles, session.Principal().User.Roles)
+			assert.Empty(t, session.Principal().Agent.ID, "users can't claim to be agents")
+
+			upstream, err := http.NewRequest(http.MethodPost, "http://agent", nil)
+			require.NoError(t, err)
+			require.NoError(t, authn.UpstreamAuth(upstream, session, session.Principal()))
+			assert.Equal(t, tt.authHeader, upstream.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestOIDCAuthenticator_RejectsWrongAudience(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	authn, err := authimpl.NewOIDCAuthenticator(t.Context(), authimpl.OIDCConfig{
+		IssuerURL: issuer.server.URL,
+		Audience:  "someone-else",
+	})
+	require.NoError(t, err)
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+issuer.sign(t, map[string]any{"sub": "alice"}))
+	_, err = authn.Authenticate(context.Background(), headers, url.Values{})
+	assert.ErrorContains(t, err, "invalid bearer token")
+}
+
+func TestOIDCAuthenticator_RetriesFailedDiscovery(t *testing.T) {
+	issuer := newTestIssuer(t)
+	authn, err := authimpl.NewOIDCAuthenticator(context.Background(), authimpl.OIDCConfig{IssuerURL: issuer.server.URL})
+	require.NoError(t, err)
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+issuer.sign(t, map[string]any{"sub": "alice"}))
+
+	issuer.unavailable.Store(true)
+	_, err = authn.Authenticate(context.Background(), headers, url.Values{})
+	assert.ErrorContains(t, err, "oidc discovery returned status 503")
+
+	issuer.unavailable.Store(false)
+	session, err := authn.Authenticate(context.Background(), headers, url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", session.Principal().User.ID)
+	assert.Equal(t, int32(2), issuer.discoveries.Load())
+}
+
+func TestOIDCAuthenticator_DiscoversOnceForConcurrentRequests(t *testing.T) {
+	issuer := newTestIssuer(t)
+	authn, err := authimpl.NewOIDCAuthenticator(context.Background(), authimpl.OIDCConfig{IssuerURL: issuer.server.URL})
+	require.NoError(t, err)
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+issuer.sign(t, map[string]any{"sub": "alice"}))
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := authn.Authenticate(context.Background(), headers, url.Values{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), issuer.discoveries.Load())
+}
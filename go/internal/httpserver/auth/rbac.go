@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+const (
+	// RBACPolicyKey is the ConfigMap key holding the RBAC policy.
+	RBACPolicyKey = "policy.yaml"
+
+	rbacWildcard = "*"
+)
+
+// rbacNamespacedResources are the resource types named "<namespace>/<name>".
+// Other resources, e.g. sessions, tasks and feedback, belong to users rather
+// than namespaces, so rules restricted to namespaces don't apply to them.
+var rbacNamespacedResources = []string{"Agent", "ModelConfig", "ToolServer", "Memory", "DataSource"}
+
+// RBACPolicy maps roles to the verbs they may perform on resource types and namespaces.
+//
+// Example:
+//
+//	defaultRoles: [viewer]
+//	roles:
+//	  viewer:
+//	  - resources: ["*"]
+//	    verbs: [get]
+//	  team-a-editor:
+//	  - resources: [Agent, ModelConfig]
+//	    verbs: [get, create, update, delete]
+//	    namespaces: [team-a]
+//	users:
+//	  admin@kagent.dev: [admin]
+type RBACPolicy struct {
+	// DefaultRoles are granted to every authenticated principal.
+	DefaultRoles []string `json:"defaultRoles,omitempty"`
+	// Roles maps a role name, as found in auth.User.Roles, to its rules.
+	Roles map[string][]RBACRule `json:"roles"`
+	// Users binds roles to user IDs directly, in addition to the roles provided by the authenticator.
+	Users map[string][]string `json:"users,omitempty"`
+}
+
+// RBACRule grants verbs on resource types, optionally restricted to namespaces.
+type RBACRule struct {
+	// Resources are auth.Resource types, e.g. Agent, ModelConfig or Session. "*" matches all types.
+	Resources []string `json:"resources"`
+	// Verbs are auth.Verb values. "*" matches all verbs.
+	Verbs []auth.Verb `json:"verbs"`
+	// Namespaces restrict the rule to namespaced resources in these namespaces,
+	// so it must not name resources that aren't namespaced, e.g. Session or Task.
+	// If empty or "*", the rule applies to all namespaces and to non-namespaced resources.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ParseRBACPolicy parses and validates a YAML or JSON RBAC policy.
+func ParseRBACPolicy(data []byte) (*RBACPolicy, error) {
+	var policy RBACPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse rbac policy: %w", err)
+	}
+
+	for name, rules := range policy.Roles {
+		for i, rule := range rules {
+			if len(rule.Resources) == 0 {
+				return nil, fmt.Errorf("role %s rule %d: resources must not be empty", name, i)
+			}
+			if len(rule.Verbs) == 0 {
+				return nil, fmt.Errorf("role %s rule %d: verbs must not be empty", name, i)
+			}
+			for _, verb := range rule.Verbs {
+				switch verb {
+				case auth.VerbGet, auth.VerbCreate, auth.VerbUpdate, auth.VerbDelete, rbacWildcard:
+				default:
+					return nil, fmt.Errorf("role %s rule %d: unknown verb %q", name, i, verb)
+				}
+			}
+			if rule.namespaceRestricted() {
+				for _, resource := range rule.Resources {
+					if resource != rbacWildcard && !slices.Contains(rbacNamespacedResources, resource) {
+						return nil, fmt.Errorf("role %s rule %d: resource %s is not namespaced and can't be restricted to namespaces", name, i, resource)
+					}
+				}
+			}
+		}
+	}
+	for _, role := range policy.DefaultRoles {
+		if _, ok := policy.Roles[role]; !ok {
+			return nil, fmt.Errorf("default role %s is not defined", role)
+		}
+	}
+	for user, roles := range policy.Users {
+		for _, role := range roles {
+			if _, ok := policy.Roles[role]; !ok {
+				return nil, fmt.Errorf("role %s bound to user %s is not defined", role, user)
+			}
+		}
+	}
+
+	return &policy, nil
+}
+
+// RBACAuthorizer authorizes requests against an RBACPolicy. The policy can be
+// swapped at runtime with SetPolicy; until a policy is set, every check is denied.
+//
+// Namespaced resources are identified by a "<namespace>/<name>" resource name.
+// A check of a namespaced resource type without a resource name (e.g. listing)
+// is allowed if the principal may perform the verb on the resource type in at
+// least one namespace; callers are expected to filter the results per item.
+type RBACAuthorizer struct {
+	policy atomic.Pointer[RBACPolicy]
+}
+
+var _ auth.Authorizer = (*RBACAuthorizer)(nil)
+
+func NewRBACAuthorizer() *RBACAuthorizer {
+	return &RBACAuthorizer{}
+}
+
+// SetPolicy replaces the active policy. A nil policy denies every request.
+func (a *RBACAuthorizer) SetPolicy(policy *RBACPolicy) {
+	a.policy.Store(policy)
+}
+
+func (a *RBACAuthorizer) Check(ctx context.Context, principal auth.Principal, verb auth.Verb, resource auth.Resource) error {
+	policy := a.policy.Load()
+	if policy == nil {
+		return fmt.Errorf("no rbac policy loaded")
+	}
+
+	namespace, _, namespaced := strings.Cut(resource.Name, "/")
+	for _, role := range policy.rolesFor(principal) {
+		for _, rule := range policy.Roles[role] {
+			if rule.allows(verb, resource.Type, namespace, namespaced, resource.Name == "") {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("user %q is not allowed to %s %s %q", principal.User.ID, verb, resource.Type, resource.Name)
+}
+
+func (p *RBACPolicy) rolesFor(principal auth.Principal) []string {
+	roles := make([]string, 0, len(p.DefaultRoles)+len(principal.User.Roles))
+	roles = append(roles, p.DefaultRoles...)
+	roles = append(roles, principal.User.Roles...)
+	roles = append(roles, p.Users[principal.User.ID]...)
+	return roles
+}
+
+func (r RBACRule) allows(verb auth.Verb, resourceType, namespace string, namespaced, unnamed bool) bool {
+	if !slices.Contains(r.Verbs, verb) && !slices.Contains(r.Verbs, rbacWildcard) {
+		return false
+	}
+	if !slices.Contains(r.Resources, resourceType) && !slices.Contains(r.Resources, rbacWildcard) {
+		return false
+	}
+	if !r.namespaceRestricted() {
+		return true
+	}
+	if !slices.Contains(rbacNamespacedResources, resourceType) {
+		return false
+	}
+	return unnamed || namespaced && slices.Contains(r.Namespaces, namespace)
+}
+
+func (r RBACRule) namespaceRestricted() bool {
+	return len(r.Namespaces) > 0 && !slices.Contains(r.Namespaces, rbacWildcard)
+}
@@ -0,0 +1,204 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+const testPolicy = `
+defaultRoles: [session-user]
+roles:
+  admin:
+  - resources: ["*"]
+    verbs: ["*"]
+  session-user:
+  - resources: [Session, Task]
+    verbs: [get, create, update, delete]
+  team-a-editor:
+  - resources: [Agent, ModelConfig]
+    verbs: [get, create, update, delete]
+    namespaces: [team-a]
+  team-a-viewer:
+  - resources: ["*"]
+    verbs: [get]
+    namespaces: [team-a]
+  viewer:
+  - resources: ["*"]
+    verbs: [get]
+users:
+  root@kagent.dev: [admin]
+`
+
+func TestRBACAuthorizer(t *testing.T) {
+	policy, err := authimpl.ParseRBACPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	authorizer := authimpl.NewRBACAuthorizer()
+	authorizer.SetPolicy(policy)
+
+	user := func(id string, roles ...string) auth.Principal {
+		return auth.Principal{User: auth.User{ID: id, Roles: roles}}
+	}
+
+	testCases := []struct {
+		name      string
+		principal auth.Principal
+		verb      auth.Verb
+		resource  auth.Resource
+		allowed   bool
+	}{
+		{
+			name:      "default role allows sessions",
+			principal: user("alice"),
+			verb:      auth.VerbDelete,
+			resource:  auth.Resource{Type: "Session", Name: "abc"},
+			allowed:   true,
+		},
+		{
+			name:      "no role denies agents",
+			principal: user("alice"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Agent", Name: "team-a/k8s-agent"},
+		},
+		{
+			name:      "viewer can get any agent",
+			principal: user("bob", "viewer"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Agent", Name: "team-b/k8s-agent"},
+			allowed:   true,
+		},
+		{
+			name:      "viewer cannot delete",
+			principal: user("bob", "viewer"),
+			verb:      auth.VerbDelete,
+			resource:  auth.Resource{Type: "Agent", Name: "team-b/k8s-agent"},
+		},
+		{
+			name:      "namespaced role allows its namespace",
+			principal: user("carol", "team-a-editor"),
+			verb:      auth.VerbUpdate,
+			resource:  auth.Resource{Type: "ModelConfig", Name: "team-a/default"},
+			allowed:   true,
+		},
+		{
+			name:      "namespaced role denies other namespaces",
+			principal: user("carol", "team-a-editor"),
+			verb:      auth.VerbUpdate,
+			resource:  auth.Resource{Type: "ModelConfig", Name: "team-b/default"},
+		},
+		{
+			name:      "namespaced role allows listing",
+			principal: user("carol", "team-a-editor"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Agent"},
+			allowed:   true,
+		},
+		{
+			name:      "namespaced role denies other resource types",
+			principal: user("carol", "team-a-editor"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Memory", Name: "team-a/pinecone"},
+		},
+		{
+			name:      "namespaced wildcard role allows namespaced resources of its namespace",
+			principal: user("erin", "team-a-viewer"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Memory", Name: "team-a/pinecone"},
+			allowed:   true,
+		},
+		{
+			name:      "namespaced wildcard role denies listing resources that aren't namespaced",
+			principal: user("erin", "team-a-viewer"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Usage"},
+		},
+		{
+			name:      "namespaced wildcard role denies resources that aren't namespaced",
+			principal: user("erin", "team-a-viewer"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Feedback", Name: "team-a/1"},
+		},
+		{
+			name:      "user binding grants admin",
+			principal: user("root@kagent.dev"),
+			verb:      auth.VerbDelete,
+			resource:  auth.Resource{Type: "DataSource", Name: "kagent/databricks"},
+			allowed:   true,
+		},
+		{
+			name:      "unknown roles are ignored",
+			principal: user("dave", "does-not-exist"),
+			verb:      auth.VerbGet,
+			resource:  auth.Resource{Type: "Agent"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizer.Check(context.Background(), tt.principal, tt.verb, tt.resource)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestRBACAuthorizer_DeniesWithoutPolicy(t *testing.T) {
+	authorizer := authimpl.NewRBACAuthorizer()
+	err := authorizer.Check(context.Background(), auth.Principal{User: auth.User{ID: "alice"}}, auth.VerbGet, auth.Resource{Type: "Agent"})
+	assert.ErrorContains(t, err, "no rbac policy loaded")
+}
+
+func TestParseRBACPolicy_Invalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      string
+		expectedErr string
+	}{
+		{
+			name:        "unknown verb",
+			policy:      "roles: {viewer: [{resources: [Agent], verbs: [list]}]}",
+			expectedErr: `unknown verb "list"`,
+		},
+		{
+			name:        "empty resources",
+			policy:      "roles: {viewer: [{verbs: [get]}]}",
+			expectedErr: "resources must not be empty",
+		},
+		{
+			name:        "namespaces on resources that aren't namespaced",
+			policy:      "roles: {viewer: [{resources: [Agent, Session], verbs: [get], namespaces: [team-a]}]}",
+			expectedErr: "resource Session is not namespaced",
+		},
+		{
+			name:        "undefined default role",
+			policy:      "defaultRoles: [viewer]\nroles: {}",
+			expectedErr: "default role viewer is not defined",
+		},
+		{
+			name:        "undefined user role",
+			policy:      "roles: {}\nusers: {alice: [admin]}",
+			expectedErr: "role admin bound to user alice is not defined",
+		},
+		{
+			name:        "unknown field",
+			policy:      "rules: []",
+			expectedErr: "failed to parse rbac policy",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authimpl.ParseRBACPolicy([]byte(tt.policy))
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var rbacLog = ctrl.Log.WithName("rbac-policy")
+
+// RBACPolicyWatcher keeps an RBACAuthorizer in sync with a ConfigMap. It runs
+// on every replica, since each replica serves the HTTP API.
+type RBACPolicyWatcher struct {
+	config     *rest.Config
+	scheme     *runtime.Scheme
+	configMap  types.NamespacedName
+	authorizer *RBACAuthorizer
+}
+
+var _ manager.Runnable = (*RBACPolicyWatcher)(nil)
+var _ manager.LeaderElectionRunnable = (*RBACPolicyWatcher)(nil)
+
+func NewRBACPolicyWatcher(config *rest.Config, scheme *runtime.Scheme, configMap types.NamespacedName, authorizer *RBACAuthorizer) *RBACPolicyWatcher {
+	return &RBACPolicyWatcher{
+		config:     config,
+		scheme:     scheme,
+		configMap:  configMap,
+		authorizer: authorizer,
+	}
+}
+
+func (w *RBACPolicyWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start watches the policy ConfigMap with a dedicated cache, so that the
+// policy is reloaded even if its namespace is not among the watched namespaces.
+func (w *RBACPolicyWatcher) Start(ctx context.Context) error {
+	c, err := cache.New(w.config, cache.Options{
+		Scheme: w.scheme,
+		DefaultNamespaces: map[string]cache.Config{
+			w.configMap.Namespace: {},
+		},
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {
+				Field: fields.OneTermEqualSelector("metadata.name", w.configMap.Name),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create rbac policy cache: %w", err)
+	}
+
+	informer, err := c.GetInformer(ctx, &corev1.ConfigMap{})
+	if err != nil {
+		return fmt.Errorf("failed to get rbac policy informer: %w", err)
+	}
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			w.load(obj)
+		},
+		UpdateFunc: func(_, obj any) {
+			w.load(obj)
+		},
+		DeleteFunc: func(obj any) {
+			rbacLog.Info("RBAC policy ConfigMap deleted, denying all requests", "configMap", w.configMap)
+			w.authorizer.SetPolicy(nil)
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to add rbac policy event handler: %w", err)
+	}
+
+	rbacLog.Info("Watching RBAC policy", "configMap", w.configMap)
+	return c.Start(ctx)
+}
+
+func (w *RBACPolicyWatcher) load(obj any) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+
+	data, ok := cm.Data[RBACPolicyKey]
+	if !ok {
+		rbacLog.Info("RBAC policy ConfigMap has no policy key, denying all requests", "configMap", w.configMap, "key", RBACPolicyKey)
+		w.authorizer.SetPolicy(nil)
+		return
+	}
+
+	policy, err := ParseRBACPolicy([]byte(data))
+	if err != nil {
+		// keep serving the last valid policy
+		rbacLog.Error(err, "Invalid RBAC policy, keeping the previous policy", "configMap", w.configMap, "resourceVersion", cm.ResourceVersion)
+		return
+	}
+
+	w.authorizer.SetPolicy(policy)
+	rbacLog.Info("Loaded RBAC policy", "configMap", w.configMap, "resourceVersion", cm.ResourceVersion, "roles", len(policy.Roles))
+}
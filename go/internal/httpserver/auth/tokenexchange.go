@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+const (
+	grantTypeTokenExchange  = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken    = "urn:ietf:params:oauth:token-type:access_token"
+	tokenTypeJWT            = "urn:ietf:params:oauth:token-type:jwt"
+	defaultActorTokenExpiry = time.Hour
+
+	// tokens are refreshed this long before they expire
+	tokenExpiryLeeway = 30 * time.Second
+)
+
+// TokenExchangeConfig configures a TokenExchangeAuthenticator.
+type TokenExchangeConfig struct {
+	// TokenURL is the STS token endpoint.
+	TokenURL string
+	// Audience is sent as the "audience" parameter of the exchange. Defaults to
+	// the upstream agent ID (<namespace>/<name>).
+	Audience string
+	// Scope is sent as the "scope" parameter of the exchange, if set.
+	Scope string
+	// ActorTokenAudiences are the audiences of the service account tokens
+	// requested for agents. Defaults to the API server audience.
+	ActorTokenAudiences []string
+	// HTTPClient is used for STS requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// TokenExchangeAuthenticator wraps another AuthProvider and replaces the
+// bearer token forwarded to agents with an on-behalf-of token obtained through
+// OAuth 2.0 Token Exchange (RFC 8693).
+//
+// The caller's bearer token is the subject token, and a token for the upstream
+// agent's service account is the actor token. Agents forward the delegated
+// token to the MCP servers they call. Actor and delegated tokens are cached
+// until shortly before they expire. Requests without a bearer token are
+// forwarded as the wrapped provider leaves them.
+type TokenExchangeAuthenticator struct {
+	auth.AuthProvider
+
+	kube   client.Client
+	config TokenExchangeConfig
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+type cachedToken struct {
+	token   string
+	expires time.Time
+}
+
+type tokenExchangeResponse struct {
+	AccessToken      string `json:"access_token"`
+	IssuedTokenType  string `json:"issued_token_type"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+var _ auth.AuthProvider = (*TokenExchangeAuthenticator)(nil)
+
+// +kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
+
+func NewTokenExchangeAuthenticator(provider auth.AuthProvider, kube client.Client, config TokenExchangeConfig) (*TokenExchangeAuthenticator, error) {
+	if config.TokenURL == "" {
+		return nil, fmt.Errorf("token exchange URL is required")
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &TokenExchangeAuthenticator{
+		AuthProvider: provider,
+		kube:         kube,
+		config:       config,
+		tokens:       map[string]cachedToken{},
+	}, nil
+}
+
+func (a *TokenExchangeAuthenticator) UpstreamAuth(r *http.Request, session auth.Session, upstreamPrincipal auth.Principal) error {
+	if err := a.AuthProvider.UpstreamAuth(r, session, upstreamPrincipal); err != nil {
+		return err
+	}
+
+	subjectToken, ok := bearerToken(r.Header.Get("Authorization"))
+	if !ok {
+		return nil
+	}
+	agent, err := agentServiceAccount(upstreamPrincipal.Agent.ID)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.exchange(r.Context(), subjectToken, agent)
+	if err != nil {
+		return fmt.Errorf("token exchange for agent %s failed: %w", agent, err)
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *TokenExchangeAuthenticator) exchange(ctx context.Context, subjectToken string, agent types.NamespacedName) (string, error) {
+	audience := a.config.Audience
+	if audience == "" {
+		audience = agent.String()
+	}
+
+	key := tokenCacheKey("exchange", subjectToken, agent.String(), audience)
+	if token, ok := a.cached(key); ok {
+		return token, nil
+	}
+
+	actorToken, err := a.actorToken(ctx, agent)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":           {grantTypeTokenExchange},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {tokenTypeAccessToken},
+		"actor_token":          {actorToken},
+		"actor_token_type":     {tokenTypeJWT},
+		"requested_token_type": {tokenTypeAccessToken},
+		"audience":             {audience},
+	}
+	if a.config.Scope != "" {
+		form.Set("scope", a.config.Scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read sts response: %w", err)
+	}
+
+	var tokenResp tokenExchangeResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(body, &tokenResp) == nil && tokenResp.Error != "" {
+			return "", fmt.Errorf("sts returned %d: %s %s", resp.StatusCode, tokenResp.Error, tokenResp.ErrorDescription)
+		}
+		return "", fmt.Errorf("sts returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode sts response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("sts response has no access_token")
+	}
+
+	// tokens without an expiry are not cached
+	if tokenResp.ExpiresIn > 0 {
+		a.store(key, tokenResp.AccessToken, time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second))
+	}
+	return tokenResp.AccessToken, nil
+}
+
+// actorToken requests a short-lived token for the agent's service account,
+// which has the same name as the agent.
+func (a *TokenExchangeAuthenticator) actorToken(ctx context.Context, agent types.NamespacedName) (string, error) {
+	key := tokenCacheKey("actor", agent.String())
+	if token, ok := a.cached(key); ok {
+		return token, nil
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agent.Name,
+			Namespace: agent.Namespace,
+		},
+	}
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         a.config.ActorTokenAudiences,
+			ExpirationSeconds: ptr.To(int64(defaultActorTokenExpiry.Seconds())),
+		},
+	}
+	if err := a.kube.SubResource("token").Create(ctx, serviceAccount, tokenRequest); err != nil {
+		return "", fmt.Errorf("failed to request service account token: %w", err)
+	}
+
+	a.store(key, tokenRequest.Status.Token, tokenRequest.Status.ExpirationTimestamp.Time)
+	return tokenRequest.Status.Token, nil
+}
+
+func (a *TokenExchangeAuthenticator) cached(key string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cached, ok := a.tokens[key]
+	if !ok || time.Now().Add(tokenExpiryLeeway).After(cached.expires) {
+		return "", false
+	}
+	return cached.token, true
+}
+
+func (a *TokenExchangeAuthenticator) store(key, token string, expires time.Time) {
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k, v := range a.tokens {
+		if now.After(v.expires) {
+			delete(a.tokens, k)
+		}
+	}
+	a.tokens[key] = cachedToken{token: token, expires: expires}
+}
+
+func tokenCacheKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func agentServiceAccount(agentID string) (types.NamespacedName, error) {
+	namespace, name, ok := strings.Cut(agentID, "/")
+	if !ok || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid upstream agent %q", agentID)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
@@ -0,0 +1,115 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+func TestTokenExchangeAuthenticator(t *testing.T) {
+	var exchanges []url.Values
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		exchanges = append(exchanges, r.PostForm)
+		if r.PostForm.Get("subject_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "token revoked"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "obo-" + r.PostForm.Get("subject_token"),
+			"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+			"token_type":        "Bearer",
+			"expires_in":        3600,
+		})
+	}))
+	defer sts.Close()
+
+	var tokenRequests []string
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+				require.Equal(t, "token", subResourceName)
+				sa := obj.(*corev1.ServiceAccount)
+				tokenRequests = append(tokenRequests, sa.Namespace+"/"+sa.Name)
+				subResource.(*authenticationv1.TokenRequest).Status = authenticationv1.TokenRequestStatus{
+					Token:               "sa-token-" + sa.Name,
+					ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+				}
+				return nil
+			},
+		}).
+		Build()
+
+	authn, err := authimpl.NewTokenExchangeAuthenticator(&authimpl.UnsecureAuthenticator{}, kubeClient, authimpl.TokenExchangeConfig{
+		TokenURL: sts.URL,
+		Scope:    "agents",
+	})
+	require.NoError(t, err)
+
+	upstream := auth.Principal{Agent: auth.Agent{ID: "kagent/k8s-agent"}}
+	forward := func(authHeader string) (*http.Request, error) {
+		headers := http.Header{}
+		if authHeader != "" {
+			headers.Set("Authorization", authHeader)
+		}
+		session, err := authn.Authenticate(context.Background(), headers, url.Values{})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "http://agent", nil)
+		require.NoError(t, err)
+		return req, authn.UpstreamAuth(req, session, upstream)
+	}
+
+	t.Run("exchanges the caller token", func(t *testing.T) {
+		req, err := forward("Bearer user-token")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer obo-user-token", req.Header.Get("Authorization"))
+		assert.Equal(t, "admin@kagent.dev", req.Header.Get("X-User-Id"))
+
+		require.Len(t, exchanges, 1)
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", exchanges[0].Get("grant_type"))
+		assert.Equal(t, "user-token", exchanges[0].Get("subject_token"))
+		assert.Equal(t, "sa-token-k8s-agent", exchanges[0].Get("actor_token"))
+		assert.Equal(t, "kagent/k8s-agent", exchanges[0].Get("audience"))
+		assert.Equal(t, "agents", exchanges[0].Get("scope"))
+	})
+
+	t.Run("reuses cached tokens", func(t *testing.T) {
+		req, err := forward("Bearer user-token")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer obo-user-token", req.Header.Get("Authorization"))
+		assert.Len(t, exchanges, 1)
+		assert.Equal(t, []string{"kagent/k8s-agent"}, tokenRequests)
+	})
+
+	t.Run("forwards requests without a token", func(t *testing.T) {
+		req, err := forward("")
+		require.NoError(t, err)
+		assert.Empty(t, req.Header.Get("Authorization"))
+		assert.Len(t, exchanges, 1)
+	})
+
+	t.Run("surfaces sts errors", func(t *testing.T) {
+		_, err := forward("Bearer revoked")
+		assert.ErrorContains(t, err, "invalid_grant token revoked")
+	})
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+const (
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+	defaultTokenReviewCacheTTL = 30 * time.Second
+)
+
+// DefaultTokenReviewAudiences matches the audience of the projected service
+// account token mounted into agent pods.
+var DefaultTokenReviewAudiences = []string{"kagent"}
+
+// TokenReviewAuthenticator authenticates agents by validating their service
+// account bearer token with the Kubernetes TokenReview API.
+//
+// Agents call the API on behalf of users, so when the service account is the
+// one of an Agent, which has the same namespace/name, the user ID is taken from
+// the user_id query parameter or X-User-Id header. Otherwise, and when neither
+// is set, the user ID is the service account username. The agent ID is the
+// namespace/name of the Agent, and empty for other service accounts.
+type TokenReviewAuthenticator struct {
+	kube      client.Client
+	audiences []string
+	cacheTTL  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]tokenReviewResult
+}
+
+type tokenReviewResult struct {
+	user authenticationv1.UserInfo
+	// isAgent is whether the service account belongs to an Agent
+	isAgent bool
+	expires time.Time
+}
+
+var _ auth.AuthProvider = (*TokenReviewAuthenticator)(nil)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+
+func NewTokenReviewAuthenticator(kube client.Client, audiences []string) *TokenReviewAuthenticator {
+	if len(audiences) == 0 {
+		audiences = DefaultTokenReviewAudiences
+	}
+	return &TokenReviewAuthenticator{
+		kube:      kube,
+		audiences: audiences,
+		cacheTTL:  defaultTokenReviewCacheTTL,
+		cache:     map[string]tokenReviewResult{},
+	}
+}
+
+func (a *TokenReviewAuthenticator) Authenticate(ctx context.Context, reqHeaders http.Header, query url.Values) (auth.Session, error) {
+	authHeader := reqHeaders.Get("Authorization")
+	rawToken, ok := bearerToken(authHeader)
+	if !ok {
+		return nil, ErrNoBearerToken
+	}
+
+	result, err := a.review(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+	userInfo := result.user
+
+	agentID, ok := serviceAccountAgentID(userInfo.Username)
+	if !ok {
+		return nil, fmt.Errorf("token does not belong to a service account: %s", userInfo.Username)
+	}
+
+	// Only agents act on behalf of users, other service accounts act as themselves
+	var userID string
+	if result.isAgent {
+		userID = query.Get("user_id")
+		if userID == "" {
+			userID = reqHeaders.Get("X-User-Id")
+		}
+	} else {
+		agentID = ""
+	}
+	if userID == "" {
+		userID = userInfo.Username
+	}
+
+	return &SimpleSession{
+		P: auth.Principal{
+			User: auth.User{
+				ID:    userID,
+				Roles: userInfo.Groups,
+			},
+			Agent: auth.Agent{
+				ID: agentID,
+			},
+		},
+		authHeader: authHeader,
+	}, nil
+}
+
+// UpstreamAuth forwards the delegated user ID. The calling agent's own token is
+// not forwarded, as it is scoped to the kagent audience.
+func (a *TokenReviewAuthenticator) UpstreamAuth(r *http.Request, session auth.Session, upstreamPrincipal auth.Principal) error {
+	if session == nil || session.Principal().User.ID == "" {
+		return nil
+	}
+	r.Header.Set("X-User-Id", session.Principal().User.ID)
+	return nil
+}
+
+func (a *TokenReviewAuthenticator) review(ctx context.Context, rawToken string) (tokenReviewResult, error) {
+	sum := sha256.Sum256([]byte(rawToken))
+	key := hex.EncodeToString(sum[:])
+
+	a.mu.Lock()
+	cached, ok := a.cache[key]
+	a.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached, nil
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     rawToken,
+			Audiences: a.audiences,
+		},
+	}
+	if err := a.kube.Create(ctx, review); err != nil {
+		return tokenReviewResult{}, fmt.Errorf("token review failed: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return tokenReviewResult{}, fmt.Errorf("token not authenticated: %s", review.Status.Error)
+		}
+		return tokenReviewResult{}, fmt.Errorf("token not authenticated")
+	}
+
+	result := tokenReviewResult{user: review.Status.User}
+	if agentID, ok := serviceAccountAgentID(review.Status.User.Username); ok {
+		isAgent, err := a.isAgent(ctx, agentID)
+		if err != nil {
+			return tokenReviewResult{}, err
+		}
+		result.isAgent = isAgent
+	}
+
+	now := time.Now()
+	a.mu.Lock()
+	for k, v := range a.cache {
+		if now.After(v.expires) {
+			delete(a.cache, k)
+		}
+	}
+	result.expires = now.Add(a.cacheTTL)
+	a.cache[key] = result
+	a.mu.Unlock()
+
+	return result, nil
+}
+
+// isAgent returns whether an Agent with the namespace/name of a service account exists,
+// as agents run with the service account of their name.
+func (a *TokenReviewAuthenticator) isAgent(ctx context.Context, agentID string) (bool, error) {
+	namespace, name, _ := strings.Cut(agentID, "/")
+	err := a.kube.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &v1alpha2.Agent{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get agent %s: %w", agentID, err)
+	}
+	return true, nil
+}
+
+// serviceAccountAgentID converts "system:serviceaccount:<namespace>:<name>" into "<namespace>/<name>".
+func serviceAccountAgentID(username string) (string, bool) {
+	rest, ok := strings.CutPrefix(username, serviceAccountUsernamePrefix)
+	if !ok {
+		return "", false
+	}
+	namespace, name, ok := strings.Cut(rest, ":")
+	if !ok || namespace == "" || name == "" {
+		return "", false
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}.String(), true
+}
@@ -0,0 +1,168 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+)
+
+func newAgentScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, v1alpha2.AddToScheme(s))
+	return s
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	reviews := 0
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(newAgentScheme(t)).
+		WithObjects(&v1alpha2.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "kagent", Name: "k8s-agent"}}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				review := obj.(*authenticationv1.TokenReview)
+				reviews++
+				switch review.Spec.Token {
+				case "agent-token":
+					review.Status = authenticationv1.TokenReviewStatus{
+						Authenticated: true,
+						Audiences:     review.Spec.Audiences,
+						User: authenticationv1.UserInfo{
+							Username: "system:serviceaccount:kagent:k8s-agent",
+							Groups:   []string{"system:serviceaccounts"},
+						},
+					}
+				case "sa-token":
+					review.Status = authenticationv1.TokenReviewStatus{
+						Authenticated: true,
+						Audiences:     review.Spec.Audiences,
+						User:          authenticationv1.UserInfo{Username: "system:serviceaccount:default:ci"},
+					}
+				case "user-token":
+					review.Status = authenticationv1.TokenReviewStatus{
+						Authenticated: true,
+						User:          authenticationv1.UserInfo{Username: "alice"},
+					}
+				default:
+					review.Status = authenticationv1.TokenReviewStatus{Error: "invalid token"}
+				}
+				return nil
+			},
+		}).
+		Build()
+
+	testCases := []struct {
+		name          string
+		token         string
+		userHeader    string
+		expectedUser  string
+		expectedAgent string
+		expectedErr   string
+	}{
+		{
+			name:          "service account token with delegated user",
+			token:         "agent-token",
+			userHeader:    "alice@example.com",
+			expectedUser:  "alice@example.com",
+			expectedAgent: "kagent/k8s-agent",
+		},
+		{
+			name:          "service account token without delegated user",
+			token:         "agent-token",
+			expectedUser:  "system:serviceaccount:kagent:k8s-agent",
+			expectedAgent: "kagent/k8s-agent",
+		},
+		{
+			name:         "service account of no agent can't delegate users",
+			token:        "sa-token",
+			userHeader:   "alice@example.com",
+			expectedUser: "system:serviceaccount:default:ci",
+		},
+		{
+			name:        "non service account token",
+			token:       "user-token",
+			expectedErr: "does not belong to a service account",
+		},
+		{
+			name:        "invalid token",
+			token:       "bogus",
+			expectedErr: "token not authenticated: invalid token",
+		},
+		{
+			name:        "missing token",
+			expectedErr: "no bearer token",
+		},
+	}
+
+	authn := authimpl.NewTokenReviewAuthenticator(kubeClient, nil)
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			if tt.token != "" {
+				headers.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.userHeader != "" {
+				headers.Set("X-User-Id", tt.userHeader)
+			}
+
+			session, err := authn.Authenticate(context.Background(), headers, url.Values{})
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedUser, session.Principal().User.ID)
+			assert.Equal(t, tt.expectedAgent, session.Principal().Agent.ID)
+		})
+	}
+
+	// the second agent-token request is served from the cache
+	assert.Equal(t, 4, reviews)
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(newAgentScheme(t)).
+		WithObjects(&v1alpha2.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "kagent", Name: "k8s-agent"}}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				obj.(*authenticationv1.TokenReview).Status = authenticationv1.TokenReviewStatus{
+					Authenticated: true,
+					User:          authenticationv1.UserInfo{Username: "system:serviceaccount:kagent:k8s-agent"},
+				}
+				return nil
+			},
+		}).
+		Build()
+	authn := authimpl.NewChainAuthenticator(authimpl.NewTokenReviewAuthenticator(kubeClient, nil))
+
+	_, err := authn.Authenticate(context.Background(), http.Header{}, url.Values{})
+	assert.ErrorContains(t, err, "no bearer token")
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer agent-token")
+	headers.Set("X-User-Id", "alice@example.com")
+	session, err := authn.Authenticate(context.Background(), headers, url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, "kagent/k8s-agent", session.Principal().Agent.ID)
+
+	upstream, err := http.NewRequest(http.MethodPost, "http://agent", nil)
+	require.NoError(t, err)
+	require.NoError(t, authn.UpstreamAuth(upstream, session, session.Principal()))
+	assert.Equal(t, "alice@example.com", upstream.Header.Get("X-User-Id"))
+	assert.Empty(t, upstream.Header.Get("Authorization"))
+}
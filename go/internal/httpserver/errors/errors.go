@@ -89,3 +89,11 @@ func NewForbiddenError(message string, err error) *APIError {
 		Err:     err,
 	}
 }
+
+func NewTooManyRequestsError(message string, err error) *APIError {
+	return &APIError{
+		Code:    http.StatusTooManyRequests,
+		Message: message,
+		Err:     err,
+	}
+}
@@ -45,6 +45,9 @@ func (h *AgentsHandler) HandleListAgents(w ErrorResponseWriter, r *http.Request)
 	agentsWithID := make([]api.AgentResponse, 0)
 	for _, agent := range agentList.Items {
 		agentRef := utils.GetObjectRef(&agent)
+		if !IsAllowed(h.Authorizer, r, auth.Resource{Type: "Agent", Name: agentRef}) {
+			continue
+		}
 		log.V(1).Info("Processing Agent", "agentRef", agentRef)
 
 		// When listing agents, we don't want a failure when a single agent has an issue, so we ignore the error.
@@ -90,9 +93,9 @@ func (h *AgentsHandler) getAgentResponse(ctx context.Context, log logr.Logger, a
 	if agent.Spec.Type == v1alpha2.AgentType_Declarative {
 		// Get the ModelConfig for the team
 		modelConfig := &v1alpha2.ModelConfig{}
-		objKey := client.ObjectKey{
-			Namespace: agent.Namespace,
-			Name:      agent.Spec.Declarative.ModelConfig,
+		objKey, err := utils.ParseRefString(agent.Spec.Declarative.ModelConfig, agent.Namespace)
+		if err != nil {
+			return response, err
 		}
 		if err := h.KubeClient.Get(
 			ctx,
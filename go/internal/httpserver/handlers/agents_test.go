@@ -21,6 +21,7 @@ import (
 	"github.com/kagent-dev/kagent/go/internal/httpserver/auth"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
 	common "github.com/kagent-dev/kagent/go/internal/utils"
+	pkgauth "github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 )
 
@@ -332,6 +333,44 @@ func TestHandleListAgents(t *testing.T) {
 		require.Equal(t, false, response.Data[0].Accepted)
 		require.Equal(t, true, response.Data[0].DeploymentReady)
 	})
+
+	t.Run("filters agents by rbac policy", func(t *testing.T) {
+		modelConfig := createTestModelConfig()
+		visibleAgent := createTestAgent("visible-agent", modelConfig)
+		hiddenAgent := createTestAgent("hidden-agent", modelConfig)
+		hiddenAgent.Namespace = "other"
+
+		handler, _ := setupTestHandler(visibleAgent, hiddenAgent, modelConfig)
+
+		policy, err := auth.ParseRBACPolicy([]byte(`
+roles:
+  default-viewer:
+  - resources: [Agent]
+    verbs: [get]
+    namespaces: [default]
+`))
+		require.NoError(t, err)
+		authorizer := auth.NewRBACAuthorizer()
+		authorizer.SetPolicy(policy)
+		handler.Authorizer = authorizer
+
+		req := httptest.NewRequest("GET", "/api/agents", nil)
+		req = req.WithContext(pkgauth.AuthSessionTo(req.Context(), &auth.SimpleSession{
+			P: pkgauth.Principal{User: pkgauth.User{ID: "test-user", Roles: []string{"default-viewer"}}},
+		}))
+
+		w := httptest.NewRecorder()
+
+		handler.HandleListAgents(&testErrorResponseWriter{w}, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response api.StandardResponse[[]api.AgentResponse]
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Len(t, response.Data, 1)
+		require.Equal(t, "visible-agent", response.Data[0].Agent.Name)
+	})
 }
 
 func TestHandleUpdateAgent(t *testing.T) {
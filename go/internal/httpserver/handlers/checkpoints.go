@@ -6,6 +6,7 @@ import (
 
 	"github.com/kagent-dev/kagent/go/internal/database"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -72,6 +73,11 @@ type KAgentCheckpointTupleResponse struct {
 func (h *CheckpointsHandler) HandlePutCheckpoint(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("checkpoints-handler").WithValues("operation", "put")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Checkpoint"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -114,7 +120,7 @@ func (h *CheckpointsHandler) HandlePutCheckpoint(w ErrorResponseWriter, r *http.
 		CheckpointType:     req.Type,
 	}
 	// Store checkpoint and writes atomically
-	if err := h.DatabaseService.StoreCheckpoint(checkpoint); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreCheckpoint(checkpoint); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to store checkpoint", err))
 		return
 	}
@@ -128,6 +134,11 @@ func (h *CheckpointsHandler) HandlePutCheckpoint(w ErrorResponseWriter, r *http.
 func (h *CheckpointsHandler) HandleListCheckpoints(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("checkpoints-handler").WithValues("operation", "list")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Checkpoint"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -157,7 +168,7 @@ func (h *CheckpointsHandler) HandleListCheckpoints(w ErrorResponseWriter, r *htt
 	log = log.WithValues("userID", userID, "threadID", threadID, "checkpointNS", checkpointNS, "limit", limit)
 
 	log.V(1).Info("Listing checkpoints")
-	checkpoints, err := h.DatabaseService.ListCheckpoints(userID, threadID, checkpointNS, checkpointID, limit)
+	checkpoints, err := h.DatabaseService.WithContext(r.Context()).ListCheckpoints(userID, threadID, checkpointNS, checkpointID, limit)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to list checkpoints", err))
 		return
@@ -204,6 +215,11 @@ func (h *CheckpointsHandler) HandleListCheckpoints(w ErrorResponseWriter, r *htt
 func (h *CheckpointsHandler) HandlePutWrites(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("checkpoints-handler").WithValues("operation", "put")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Checkpoint"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -243,7 +259,7 @@ func (h *CheckpointsHandler) HandlePutWrites(w ErrorResponseWriter, r *http.Requ
 	log.V(1).Info("Storing checkpoint with writes", "writesCount", len(writes))
 
 	// Store checkpoint and writes atomically
-	if err := h.DatabaseService.StoreCheckpointWrites(writes); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreCheckpointWrites(writes); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to store checkpoint writes", err))
 		return
 	}
@@ -271,7 +287,12 @@ func (h *CheckpointsHandler) HandleDeleteThread(w ErrorResponseWriter, r *http.R
 
 	log = log.WithValues("userID", userID, "threadID", threadID)
 
-	if err := h.DatabaseService.DeleteCheckpoint(userID, threadID); err != nil {
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Checkpoint", Name: threadID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	if err := h.DatabaseService.WithContext(r.Context()).DeleteCheckpoint(userID, threadID); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to delete thread", err))
 		return
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kagent-dev/kagent/go/internal/database"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -51,6 +52,11 @@ type KagentFlowStateResponse struct {
 func (h *CrewAIHandler) HandleStoreMemory(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("crewai-handler").WithValues("operation", "store-memory")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "CrewAIMemory"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -90,7 +96,7 @@ func (h *CrewAIHandler) HandleStoreMemory(w ErrorResponseWriter, r *http.Request
 	}
 
 	// Store memory
-	if err := h.DatabaseService.StoreCrewAIMemory(memory); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreCrewAIMemory(memory); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to store CrewAI memory", err))
 		return
 	}
@@ -104,6 +110,11 @@ func (h *CrewAIHandler) HandleStoreMemory(w ErrorResponseWriter, r *http.Request
 func (h *CrewAIHandler) HandleGetMemory(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("crewai-handler").WithValues("operation", "list-memory")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "CrewAIMemory"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -132,7 +143,7 @@ func (h *CrewAIHandler) HandleGetMemory(w ErrorResponseWriter, r *http.Request)
 	// Otherwise, list memories for a specific agent
 	if taskDescription != "" {
 		log.V(1).Info("Searching CrewAI memory by task description")
-		memories, err = h.DatabaseService.SearchCrewAIMemoryByTask(userID, threadID, taskDescription, limit)
+		memories, err = h.DatabaseService.WithContext(r.Context()).SearchCrewAIMemoryByTask(userID, threadID, taskDescription, limit)
 	} else {
 		w.RespondWithError(errors.NewBadRequestError("Either agent_id or q (task description) parameter is required", nil))
 		return
@@ -167,6 +178,11 @@ func (h *CrewAIHandler) HandleGetMemory(w ErrorResponseWriter, r *http.Request)
 func (h *CrewAIHandler) HandleResetMemory(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("crewai-handler").WithValues("operation", "reset-memory")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "CrewAIMemory"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -182,7 +198,7 @@ func (h *CrewAIHandler) HandleResetMemory(w ErrorResponseWriter, r *http.Request
 	log = log.WithValues("userID", userID, "threadID", threadID)
 
 	log.V(1).Info("Resetting CrewAI memory")
-	err = h.DatabaseService.ResetCrewAIMemory(userID, threadID)
+	err = h.DatabaseService.WithContext(r.Context()).ResetCrewAIMemory(userID, threadID)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to reset CrewAI memory", err))
 		return
@@ -197,6 +213,11 @@ func (h *CrewAIHandler) HandleResetMemory(w ErrorResponseWriter, r *http.Request
 func (h *CrewAIHandler) HandleStoreFlowState(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("crewai-handler").WithValues("operation", "store-flow-state")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "CrewAIFlowState"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -241,7 +262,7 @@ func (h *CrewAIHandler) HandleStoreFlowState(w ErrorResponseWriter, r *http.Requ
 	}
 
 	// Store flow state
-	if err := h.DatabaseService.StoreCrewAIFlowState(state); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreCrewAIFlowState(state); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to store CrewAI flow state", err))
 		return
 	}
@@ -255,6 +276,11 @@ func (h *CrewAIHandler) HandleStoreFlowState(w ErrorResponseWriter, r *http.Requ
 func (h *CrewAIHandler) HandleGetFlowState(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("crewai-handler").WithValues("operation", "get-flow-state")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "CrewAIFlowState"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -270,7 +296,7 @@ func (h *CrewAIHandler) HandleGetFlowState(w ErrorResponseWriter, r *http.Reques
 	log = log.WithValues("userID", userID, "threadID", threadID)
 
 	log.V(1).Info("Getting CrewAI flow state")
-	state, err := h.DatabaseService.GetCrewAIFlowState(userID, threadID)
+	state, err := h.DatabaseService.WithContext(r.Context()).GetCrewAIFlowState(userID, threadID)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to get CrewAI flow state", err))
 		return
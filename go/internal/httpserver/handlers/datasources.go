@@ -47,10 +47,14 @@ func (h *DataSourcesHandler) HandleListDataSources(w ErrorResponseWriter, r *htt
 	}
 
 	// Convert CRDs to API response format
-	responses := make([]api.DataSourceResponse, len(dataSourceList.Items))
-	for i, ds := range dataSourceList.Items {
-		responses[i] = api.DataSourceResponse{
-			Ref:                common.GetObjectRef(&ds),
+	responses := make([]api.DataSourceResponse, 0, len(dataSourceList.Items))
+	for _, ds := range dataSourceList.Items {
+		dsRef := common.GetObjectRef(&ds)
+		if !IsAllowed(h.Authorizer, r, auth.Resource{Type: "DataSource", Name: dsRef}) {
+			continue
+		}
+		responses = append(responses, api.DataSourceResponse{
+			Ref:                dsRef,
 			Provider:           string(ds.Spec.Provider),
 			Databricks:         ds.Spec.Databricks,
 			SemanticModels:     ds.Spec.SemanticModels,
@@ -58,7 +62,7 @@ func (h *DataSourcesHandler) HandleListDataSources(w ErrorResponseWriter, r *htt
 			GeneratedMCPServer: ds.Status.GeneratedMCPServer,
 			Connected:          isConditionTrue(ds.Status.Conditions, v1alpha2.DataSourceConditionTypeConnected),
 			Ready:              isConditionTrue(ds.Status.Conditions, v1alpha2.DataSourceConditionTypeReady),
-		}
+		})
 	}
 
 	log.Info("Successfully listed DataSources", "count", len(responses))
@@ -82,11 +86,6 @@ func (h *DataSourcesHandler) HandleCreateDataSource(w ErrorResponseWriter, r *ht
 	log := ctrllog.FromContext(r.Context()).WithName("datasources-handler").WithValues("operation", "create")
 	log.Info("Received request to create DataSource")
 
-	if err := Check(h.Authorizer, r, auth.Resource{Type: "DataSource"}); err != nil {
-		w.RespondWithError(err)
-		return
-	}
-
 	// Parse request body
 	var req api.CreateDataSourceRequest
 	if err := DecodeJSONBody(r, &req); err != nil {
@@ -115,6 +114,11 @@ func (h *DataSourcesHandler) HandleCreateDataSource(w ErrorResponseWriter, r *ht
 
 	log = log.WithValues("name", req.Name, "namespace", req.Namespace, "catalog", req.Catalog, "schema", req.Schema)
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "DataSource", Name: types.NamespacedName{Namespace: req.Namespace, Name: req.Name}.String()}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	// Get configuration from an existing DataSource
 	existingConfig, err := h.getExistingDatabricksConfig(r.Context())
 	if err != nil {
@@ -1,14 +1,23 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"slices"
 
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 	"github.com/kagent-dev/kagent/go/internal/database"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/internal/sessionexport"
+	"github.com/kagent-dev/kagent/go/internal/utils"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
 // FeedbackHandler handles user feedback submissions
@@ -21,10 +30,22 @@ func NewFeedbackHandler(base *Base) *FeedbackHandler {
 	return &FeedbackHandler{Base: base}
 }
 
-// HandleCreateFeedback handles the submission of user feedback and forwards it to the Python backend
+// feedbackAdminResource is the resource type principals must be allowed to get
+// to query the feedback of other users.
+const feedbackAdminResource = "FeedbackAdmin"
+
+// HandleCreateFeedback handles the submission of user feedback. Feedback on a
+// message of a task or session is linked to the task, the session, the event
+// the message was converted from, and the agent with its generation and
+// ModelConfig.
 func (h *FeedbackHandler) HandleCreateFeedback(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("feedback-handler").WithValues("operation", "create-feedback")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Feedback"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	log.Info("Received feedback submission")
 
 	// Read request body
@@ -49,8 +70,19 @@ func (h *FeedbackHandler) HandleCreateFeedback(w ErrorResponseWriter, r *http.Re
 		w.RespondWithError(errors.NewBadRequestError("Missing required field: feedbackText", nil))
 		return
 	}
+	// Feedback is always given by the caller, whatever user the body names
+	if feedbackReq.UserID, err = GetUserID(r); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
 
-	err = h.DatabaseService.StoreFeedback(&feedbackReq)
+	if err := h.linkFeedback(r.Context(), &feedbackReq); err != nil {
+		log.Error(err, "Failed to link feedback")
+		w.RespondWithError(err)
+		return
+	}
+
+	err = h.DatabaseService.WithContext(r.Context()).StoreFeedback(&feedbackReq)
 	if err != nil {
 		log.Error(err, "Failed to create feedback")
 		w.RespondWithError(errors.NewInternalServerError("Failed to create feedback", err))
@@ -65,6 +97,11 @@ func (h *FeedbackHandler) HandleCreateFeedback(w ErrorResponseWriter, r *http.Re
 func (h *FeedbackHandler) HandleListFeedback(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("feedback-handler").WithValues("operation", "list-feedback")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Feedback"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	log.Info("Listing feedback")
 
 	userID, err := GetUserID(r)
@@ -74,7 +111,7 @@ func (h *FeedbackHandler) HandleListFeedback(w ErrorResponseWriter, r *http.Requ
 		return
 	}
 
-	feedback, err := h.DatabaseService.ListFeedback(userID)
+	feedback, err := h.DatabaseService.WithContext(r.Context()).ListFeedback(userID)
 	if err != nil {
 		log.Error(err, "Failed to list feedback")
 		w.RespondWithError(errors.NewInternalServerError("Failed to list feedback", err))
@@ -85,3 +122,369 @@ func (h *FeedbackHandler) HandleListFeedback(w ErrorResponseWriter, r *http.Requ
 	data := api.NewResponse(feedback, "Successfully listed feedback", false)
 	RespondWithJSON(w, http.StatusOK, data)
 }
+
+// linkFeedback fills in the session of the task of feedback, the session event
+// of its message and the agent of its session. The session must be readable by
+// the user giving the feedback.
+func (h *FeedbackHandler) linkFeedback(ctx context.Context, feedback *database.Feedback) error {
+	db := h.DatabaseService.WithContext(ctx)
+
+	if feedback.TaskID != "" {
+		task, err := db.GetTask(feedback.TaskID)
+		if err != nil {
+			return errors.NewNotFoundError("Task not found", err)
+		}
+		if feedback.SessionID == "" {
+			feedback.SessionID = task.ContextID
+		} else if task.ContextID != feedback.SessionID {
+			return errors.NewBadRequestError("Task is not part of the session", nil)
+		}
+	}
+	if feedback.SessionID == "" {
+		return nil
+	}
+
+	session, _, err := database.GetSessionForUser(db, feedback.SessionID, feedback.UserID)
+	if err != nil {
+		return errors.NewNotFoundError("Session not found", err)
+	}
+	if session.AgentID != nil {
+		feedback.AgentID = utils.ConvertToKubernetesIdentifier(*session.AgentID)
+	}
+	if feedback.TaskID != "" && feedback.MessageID != "" && feedback.EventID == "" {
+		events, err := db.ListTaskEvents(feedback.TaskID, 0)
+		if err != nil {
+			return errors.NewInternalServerError("Failed to get events of task", err)
+		}
+		feedback.EventID = messageEventID(events, feedback.MessageID)
+	}
+
+	if feedback.AgentID != "" {
+		h.linkAgentVersion(ctx, feedback)
+	}
+	return nil
+}
+
+// linkAgentVersion records the generation and the ModelConfig of the agent of
+// feedback. Agents that can't be found, e.g. as they were deleted, are skipped.
+func (h *FeedbackHandler) linkAgentVersion(ctx context.Context, feedback *database.Feedback) {
+	log := ctrllog.FromContext(ctx).WithName("feedback-handler").WithValues("agent", feedback.AgentID)
+
+	agentRef, err := utils.ParseRefString(feedback.AgentID, utils.GetResourceNamespace())
+	if err != nil {
+		log.V(1).Info("Invalid agent reference of feedback", "error", err)
+		return
+	}
+	agent := &v1alpha2.Agent{}
+	if err := h.KubeClient.Get(ctx, agentRef, agent); err != nil {
+		log.V(1).Info("Failed to get agent of feedback", "error", err)
+		return
+	}
+
+	feedback.AgentGeneration = agent.Generation
+	if agent.Spec.Declarative != nil && agent.Spec.Declarative.ModelConfig != "" {
+		if modelConfigRef, err := utils.ParseRefString(agent.Spec.Declarative.ModelConfig, agent.Namespace); err == nil {
+			feedback.ModelConfig = modelConfigRef.String()
+		}
+	}
+}
+
+// messageEventID returns the ID of the session event an A2A message of a task
+// was converted from, which the agent sets in the metadata of the status update
+// carrying the message.
+func messageEventID(events []database.TaskEvent, messageID string) string {
+	for _, event := range events {
+		if event.Kind != protocol.KindTaskStatusUpdate {
+			continue
+		}
+		var update protocol.TaskStatusUpdateEvent
+		if err := json.Unmarshal([]byte(event.Data), &update); err != nil {
+			continue
+		}
+		if update.Status.Message == nil || update.Status.Message.MessageID != messageID {
+			continue
+		}
+		if eventID, ok := update.Metadata["kagent_event_id"].(string); ok {
+			return eventID
+		}
+	}
+	return ""
+}
+
+// parseFeedbackFilter parses the agent, user, model_config, rating (positive
+// or negative), from and to query parameters of feedback requests. The feedback
+// of principals that may not get FeedbackAdmin resources is limited to their own.
+func (h *FeedbackHandler) parseFeedbackFilter(r *http.Request) (database.FeedbackFilter, error) {
+	query := r.URL.Query()
+	filter := database.FeedbackFilter{
+		AgentID:     query.Get("agent"),
+		ModelConfig: query.Get("model_config"),
+	}
+
+	var apiErr *errors.APIError
+	if filter.UserID, apiErr = ScopeToUser(h.Authorizer, r, feedbackAdminResource, query.Get("user")); apiErr != nil {
+		return filter, apiErr
+	}
+
+	switch rating := query.Get("rating"); rating {
+	case "":
+	case sessionexport.RatingPositive, sessionexport.RatingNegative:
+		isPositive := rating == sessionexport.RatingPositive
+		filter.IsPositive = &isPositive
+	default:
+		return filter, errors.NewBadRequestError("Invalid rating, must be positive or negative", nil)
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from"), false); err != nil {
+		return filter, errors.NewBadRequestError("Invalid from", err)
+	}
+	if filter.To, err = parseTimeParam(query.Get("to"), true); err != nil {
+		return filter, errors.NewBadRequestError("Invalid to", err)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return filter, errors.NewBadRequestError("to must be after from", nil)
+	}
+	return filter, nil
+}
+
+// HandleGetFeedbackStats handles GET /api/feedback/stats requests. The group_by
+// query parameter (agent, agent_version, model_config or issue_type) splits the
+// feedback into groups, interval=day splits each group by day, and the filters
+// of parseFeedbackFilter limit the feedback counted. Only admins may count the
+// feedback of other users.
+func (h *FeedbackHandler) HandleGetFeedbackStats(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("feedback-handler").WithValues("operation", "stats")
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Feedback"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	query := r.URL.Query()
+	groupBy := database.FeedbackGroupBy(query.Get("group_by"))
+	switch groupBy {
+	case database.FeedbackGroupByNone, database.FeedbackGroupByAgent, database.FeedbackGroupByAgentVersion,
+		database.FeedbackGroupByModelConfig, database.FeedbackGroupByIssueType:
+	default:
+		w.RespondWithError(errors.NewBadRequestError("Invalid group_by, must be one of agent, agent_version, model_config or issue_type", nil))
+		return
+	}
+	interval := database.FeedbackInterval(query.Get("interval"))
+	switch interval {
+	case database.FeedbackIntervalNone, database.FeedbackIntervalDay:
+	default:
+		w.RespondWithError(errors.NewBadRequestError("Invalid interval, must be day", nil))
+		return
+	}
+
+	filter, err := h.parseFeedbackFilter(r)
+	if err != nil {
+		w.RespondWithError(err)
+		return
+	}
+	log = log.WithValues("groupBy", groupBy, "interval", interval, "from", filter.From, "to", filter.To)
+
+	aggregates, err := h.DatabaseService.WithContext(r.Context()).SummarizeFeedback(groupBy, interval, filter)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to query feedback", err))
+		return
+	}
+
+	report := api.FeedbackReport{
+		GroupBy:  string(groupBy),
+		Interval: string(interval),
+		Groups:   []api.FeedbackGroup{},
+	}
+	if !filter.From.IsZero() {
+		report.From = &filter.From
+	}
+	if !filter.To.IsZero() {
+		report.To = &filter.To
+	}
+
+	var total database.FeedbackAggregate
+	groups := map[string]int{}
+	for _, aggregate := range aggregates {
+		total.Total += aggregate.Total
+		total.Positive += aggregate.Positive
+		if groupBy == database.FeedbackGroupByNone && interval == database.FeedbackIntervalNone {
+			continue
+		}
+
+		i, ok := groups[aggregate.Group]
+		if !ok {
+			i = len(report.Groups)
+			groups[aggregate.Group] = i
+			report.Groups = append(report.Groups, api.FeedbackGroup{Key: aggregate.Group})
+		}
+		group := &report.Groups[i]
+		group.Total += aggregate.Total
+		group.Positive += aggregate.Positive
+		if interval != database.FeedbackIntervalNone {
+			group.Periods = append(group.Periods, api.FeedbackPeriod{Period: aggregate.Period, FeedbackTotals: feedbackTotals(aggregate.Total, aggregate.Positive)})
+		}
+	}
+	report.Total = feedbackTotals(total.Total, total.Positive)
+	for i := range report.Groups {
+		report.Groups[i].FeedbackTotals = feedbackTotals(report.Groups[i].Total, report.Groups[i].Positive)
+	}
+
+	log.Info("Successfully queried feedback", "groups", len(report.Groups))
+	data := api.NewResponse(report, "Successfully queried feedback", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+func feedbackTotals(total, positive int64) api.FeedbackTotals {
+	totals := api.FeedbackTotals{Total: total, Positive: positive}
+	if total > 0 {
+		totals.PositiveRatio = float64(positive) / float64(total)
+	}
+	return totals
+}
+
+// HandleExportFeedback handles GET /api/feedback/export requests. It renders the
+// feedback matching the filters of parseFeedbackFilter as a JSONL dataset with a
+// record per response that was rated: the conversation up to the response, the
+// response and its rating. Feedback whose response can no longer be found is
+// left out. Only admins may export the feedback of other users.
+func (h *FeedbackHandler) HandleExportFeedback(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("feedback-handler").WithValues("operation", "export")
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Feedback"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	filter, err := h.parseFeedbackFilter(r)
+	if err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	db := h.DatabaseService.WithContext(r.Context())
+	feedback, err := db.ListAllFeedback(filter)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list feedback", err))
+		return
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	conversations := &feedbackConversations{db: db, sessions: map[string][]*database.Event{}, tasks: map[string]*protocol.Task{}}
+	var records int
+	for i := range feedback {
+		conversation, index, err := conversations.find(&feedback[i])
+		if err != nil {
+			w.RespondWithError(errors.NewInternalServerError("Failed to get conversation of feedback", err))
+			return
+		}
+		if index < 0 {
+			continue
+		}
+		record, ok := sessionexport.NewDatasetRecord(&feedback[i], conversation, index)
+		if !ok {
+			continue
+		}
+		if err := encoder.Encode(record); err != nil {
+			w.RespondWithError(errors.NewInternalServerError("Failed to export feedback", err))
+			return
+		}
+		records++
+	}
+
+	log.Info("Successfully exported feedback", "feedback", len(feedback), "records", records)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="feedback.jsonl"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes()) //nolint:errcheck
+}
+
+// feedbackConversations finds the conversations feedback was given on, caching
+// the events of sessions and the tasks shared by feedback.
+type feedbackConversations struct {
+	db       database.Client
+	sessions map[string][]*database.Event
+	tasks    map[string]*protocol.Task
+}
+
+// find returns the data of the messages of the conversation of feedback in
+// chronological order, and the index of the rated response in it, or -1 if it
+// can't be found. The conversation is the session, if the session event of the
+// response is known, and the history of its task otherwise.
+func (c *feedbackConversations) find(feedback *database.Feedback) ([]string, int, error) {
+	if feedback.SessionID != "" && feedback.EventID != "" {
+		events, err := c.sessionEvents(feedback)
+		if err != nil {
+			return nil, -1, err
+		}
+		conversation := make([]string, len(events))
+		index := -1
+		for i, event := range events {
+			conversation[i] = event.Data
+			if event.ID == feedback.EventID {
+				index = i
+			}
+		}
+		if index >= 0 {
+			return conversation, index, nil
+		}
+	}
+
+	if feedback.TaskID == "" || feedback.MessageID == "" {
+		return nil, -1, nil
+	}
+	task := c.task(feedback.TaskID)
+	if task == nil {
+		return nil, -1, nil
+	}
+	messages := task.History
+	if task.Status.Message != nil && !slices.ContainsFunc(messages, func(message protocol.Message) bool {
+		return message.MessageID == task.Status.Message.MessageID
+	}) {
+		messages = append(slices.Clone(messages), *task.Status.Message)
+	}
+	var conversation []string
+	for _, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return nil, -1, fmt.Errorf("failed to serialize message %s: %w", message.MessageID, err)
+		}
+		conversation = append(conversation, string(data))
+		if message.MessageID == feedback.MessageID {
+			return conversation, len(conversation) - 1, nil
+		}
+	}
+	return nil, -1, nil
+}
+
+func (c *feedbackConversations) sessionEvents(feedback *database.Feedback) ([]*database.Event, error) {
+	if events, ok := c.sessions[feedback.SessionID]; ok {
+		return events, nil
+	}
+	// Sessions that were deleted or are no longer shared with the user have no events
+	var events []*database.Event
+	session, _, err := database.GetSessionForUser(c.db, feedback.SessionID, feedback.UserID)
+	if err == nil {
+		if events, err = c.db.ListEventsForSession(session.ID, session.UserID, database.QueryOptions{}); err != nil {
+			return nil, err
+		}
+		events = slices.Clone(events)
+		slices.SortStableFunc(events, func(a, b *database.Event) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	}
+	c.sessions[feedback.SessionID] = events
+	return events, nil
+}
+
+// task returns a task, or nil if it was deleted, e.g. with its session.
+func (c *feedbackConversations) task(taskID string) *protocol.Task {
+	if task, ok := c.tasks[taskID]; ok {
+		return task
+	}
+	task, err := c.db.GetTask(taskID)
+	if err != nil {
+		task = nil
+	}
+	c.tasks[taskID] = task
+	return task
+}
@@ -0,0 +1,249 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/database"
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
+	pkgauth "github.com/kagent-dev/kagent/go/pkg/auth"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+func TestFeedbackHandler(t *testing.T) {
+	setupHandler := func(t *testing.T) (*handlers.FeedbackHandler, database.Client) {
+		agent := &v1alpha2.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "k8s-agent", Namespace: "default", Generation: 3},
+			Spec: v1alpha2.AgentSpec{
+				Type:        v1alpha2.AgentType_Declarative,
+				Declarative: &v1alpha2.DeclarativeAgentSpec{ModelConfig: "gpt-4o"},
+			},
+		}
+		kubeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(agent).Build()
+		dbClient := database_fake.NewClient()
+
+		require.NoError(t, dbClient.StoreSession(&database.Session{ID: "session-1", UserID: "test-user", AgentID: ptr.To("default__NS__k8s_agent")}))
+		require.NoError(t, dbClient.StoreTask(&protocol.Task{
+			ID:        "task-1",
+			ContextID: "session-1",
+			History: []protocol.Message{
+				{Kind: protocol.KindMessage, MessageID: "msg-1", Role: protocol.MessageRoleUser, Parts: []protocol.Part{protocol.NewTextPart("How many pods?")}},
+				{Kind: protocol.KindMessage, MessageID: "msg-2", Role: protocol.MessageRoleAgent, Parts: []protocol.Part{protocol.NewTextPart("There are 3 pods.")}},
+			},
+		}))
+		update, err := json.Marshal(&protocol.TaskStatusUpdateEvent{
+			TaskID:    "task-1",
+			ContextID: "session-1",
+			Kind:      protocol.KindTaskStatusUpdate,
+			Status: protocol.TaskStatus{
+				State:   protocol.TaskStateWorking,
+				Message: &protocol.Message{Kind: protocol.KindMessage, MessageID: "msg-2", Role: protocol.MessageRoleAgent, Parts: []protocol.Part{protocol.NewTextPart("There are 3 pods.")}},
+			},
+			Metadata: map[string]any{"kagent_event_id": "event-2"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, dbClient.StoreTaskEvent(&database.TaskEvent{TaskID: "task-1", Sequence: 1, Kind: protocol.KindTaskStatusUpdate, Data: string(update)}))
+		require.NoError(t, dbClient.StoreEvents(
+			&database.Event{ID: "event-1", SessionID: "session-1", UserID: "test-user", CreatedAt: time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC),
+				Data: `{"author":"user","content":{"role":"user","parts":[{"text":"How many pods?"}]}}`},
+			&database.Event{ID: "event-2", SessionID: "session-1", UserID: "test-user", CreatedAt: time.Date(2026, time.October, 1, 10, 0, 1, 0, time.UTC),
+				Data: `{"author":"k8s_agent","content":{"role":"model","parts":[{"text":"There are 3 pods."}]}}`},
+		))
+
+		base := &handlers.Base{
+			KubeClient:      kubeClient,
+			DatabaseService: dbClient,
+			Authorizer:      &authimpl.NoopAuthorizer{},
+		}
+		return handlers.NewFeedbackHandler(base), dbClient
+	}
+
+	create := func(t *testing.T, handler *handlers.FeedbackHandler, feedback *database.Feedback) *mockErrorResponseWriter {
+		body, err := json.Marshal(feedback)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/feedback", bytes.NewBuffer(body))
+		req = setUser(req, "test-user")
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleCreateFeedback(responseRecorder, req)
+		return responseRecorder
+	}
+
+	t.Run("HandleCreateFeedback links the task, session, event and agent", func(t *testing.T) {
+		handler, dbClient := setupHandler(t)
+		responseRecorder := create(t, handler, &database.Feedback{MessageID: "msg-2", TaskID: "task-1", IsPositive: true, FeedbackText: "Correct"})
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+		feedback, err := dbClient.ListFeedback("test-user")
+		require.NoError(t, err)
+		require.Len(t, feedback, 1)
+		assert.Equal(t, "session-1", feedback[0].SessionID)
+		assert.Equal(t, "event-2", feedback[0].EventID)
+		assert.Equal(t, "default/k8s-agent", feedback[0].AgentID)
+		assert.Equal(t, int64(3), feedback[0].AgentGeneration)
+		assert.Equal(t, "default/gpt-4o", feedback[0].ModelConfig)
+	})
+
+	t.Run("HandleCreateFeedback rejects sessions of other users", func(t *testing.T) {
+		handler, dbClient := setupHandler(t)
+		require.NoError(t, dbClient.StoreSession(&database.Session{ID: "session-2", UserID: "other-user"}))
+		require.NoError(t, dbClient.StoreTask(&protocol.Task{ID: "task-2", ContextID: "session-2"}))
+
+		// The user of the body is ignored, so feedback can't be given as the owner of the session
+		responseRecorder := create(t, handler, &database.Feedback{UserID: "other-user", MessageID: "msg-1", TaskID: "task-2", FeedbackText: "Wrong"})
+		assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+
+		responseRecorder = create(t, handler, &database.Feedback{MessageID: "msg-2", TaskID: "missing", FeedbackText: "Wrong"})
+		assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+	})
+
+	t.Run("HandleCreateFeedback ignores the user of the body", func(t *testing.T) {
+		handler, dbClient := setupHandler(t)
+		responseRecorder := create(t, handler, &database.Feedback{UserID: "other-user", MessageID: "msg-2", TaskID: "task-1", FeedbackText: "Correct"})
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+		feedback, err := dbClient.ListFeedback("test-user")
+		require.NoError(t, err)
+		assert.Len(t, feedback, 1)
+		feedback, err = dbClient.ListFeedback("other-user")
+		require.NoError(t, err)
+		assert.Empty(t, feedback)
+	})
+
+	t.Run("HandleGetFeedbackStats", func(t *testing.T) {
+		handler, dbClient := setupHandler(t)
+		issueType := database.FeedbackIssueTypeFactual
+		for _, feedback := range []*database.Feedback{
+			{Model: gorm.Model{CreatedAt: time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC)}, UserID: "test-user", AgentID: "default/k8s-agent", AgentGeneration: 3, IsPositive: true, FeedbackText: "a"},
+			{Model: gorm.Model{CreatedAt: time.Date(2026, time.October, 1, 11, 0, 0, 0, time.UTC)}, UserID: "test-user", AgentID: "default/k8s-agent", AgentGeneration: 3, FeedbackText: "b", IssueType: &issueType},
+			{Model: gorm.Model{CreatedAt: time.Date(2026, time.October, 2, 10, 0, 0, 0, time.UTC)}, UserID: "test-user", AgentID: "default/k8s-agent", AgentGeneration: 4, IsPositive: true, FeedbackText: "c"},
+		} {
+			require.NoError(t, dbClient.StoreFeedback(feedback))
+		}
+
+		get := func(target string) (api.FeedbackReport, *mockErrorResponseWriter) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req = setUser(req, "test-user")
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleGetFeedbackStats(responseRecorder, req)
+
+			var response api.StandardResponse[api.FeedbackReport]
+			if responseRecorder.Code == http.StatusOK {
+				require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			}
+			return response.Data, responseRecorder
+		}
+
+		report, responseRecorder := get("/api/feedback/stats?group_by=agent_version&interval=day")
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+		assert.Equal(t, api.FeedbackTotals{Total: 3, Positive: 2, PositiveRatio: 2.0 / 3}, report.Total)
+		require.Len(t, report.Groups, 2)
+		assert.Equal(t, "default/k8s-agent@3", report.Groups[0].Key)
+		assert.Equal(t, 0.5, report.Groups[0].PositiveRatio)
+		assert.Equal(t, []api.FeedbackPeriod{{Period: "2026-10-01", FeedbackTotals: api.FeedbackTotals{Total: 2, Positive: 1, PositiveRatio: 0.5}}}, report.Groups[0].Periods)
+		assert.Equal(t, "default/k8s-agent@4", report.Groups[1].Key)
+
+		report, responseRecorder = get("/api/feedback/stats?group_by=issue_type&rating=negative")
+		require.Equal(t, http.StatusOK, responseRecorder.Code)
+		require.Len(t, report.Groups, 1)
+		assert.Equal(t, "factual", report.Groups[0].Key)
+		assert.Equal(t, int64(1), report.Groups[0].Total)
+
+		for _, target := range []string{
+			"/api/feedback/stats?group_by=session",
+			"/api/feedback/stats?interval=hour",
+			"/api/feedback/stats?rating=meh",
+			"/api/feedback/stats?from=2026-10-02&to=2026-10-01",
+		} {
+			_, responseRecorder = get(target)
+			assert.Equal(t, http.StatusBadRequest, responseRecorder.Code, target)
+		}
+	})
+
+	t.Run("HandleExportFeedback", func(t *testing.T) {
+		handler, dbClient := setupHandler(t)
+		require.Equal(t, http.StatusOK, create(t, handler, &database.Feedback{MessageID: "msg-2", TaskID: "task-1", IsPositive: true, FeedbackText: "Correct"}).Code)
+		// Feedback without its session event falls back to the history of its task
+		require.NoError(t, dbClient.StoreFeedback(&database.Feedback{UserID: "test-user", MessageID: "msg-2", TaskID: "task-1", FeedbackText: "Too short"}))
+		// Feedback on a message that can't be found is left out
+		require.NoError(t, dbClient.StoreFeedback(&database.Feedback{UserID: "test-user", MessageID: "msg-9", TaskID: "task-1", FeedbackText: "Gone"}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/feedback/export", nil)
+		req = setUser(req, "test-user")
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleExportFeedback(responseRecorder, req)
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+		assert.Equal(t, "application/x-ndjson", responseRecorder.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(responseRecorder.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		for i, rating := range []string{"positive", "negative"} {
+			var record api.FeedbackDatasetRecord
+			require.NoError(t, json.Unmarshal([]byte(lines[i]), &record))
+			assert.Equal(t, rating, record.Rating)
+			assert.Equal(t, []api.DatasetMessage{{Role: "user", Content: "How many pods?"}}, record.Context)
+			assert.Equal(t, api.DatasetMessage{Role: "assistant", Content: "There are 3 pods."}, record.Response)
+		}
+	})
+
+	t.Run("limits stats and export to the caller unless admin", func(t *testing.T) {
+		handler, dbClient := setupHandler(t)
+		require.Equal(t, http.StatusOK, create(t, handler, &database.Feedback{MessageID: "msg-2", TaskID: "task-1", IsPositive: true, FeedbackText: "Correct"}).Code)
+		require.NoError(t, dbClient.StoreFeedback(&database.Feedback{UserID: "other-user", AgentID: "default/k8s-agent", IsPositive: true, FeedbackText: "Other"}))
+
+		policy, err := authimpl.ParseRBACPolicy([]byte(`
+roles:
+  user:
+  - resources: [Feedback]
+    verbs: [get]
+  admin:
+  - resources: ["*"]
+    verbs: ["*"]
+`))
+		require.NoError(t, err)
+		authorizer := authimpl.NewRBACAuthorizer()
+		authorizer.SetPolicy(policy)
+		handler.Authorizer = authorizer
+
+		get := func(target, role string, handle func(handlers.ErrorResponseWriter, *http.Request)) *mockErrorResponseWriter {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req = req.WithContext(pkgauth.AuthSessionTo(req.Context(), &authimpl.SimpleSession{
+				P: pkgauth.Principal{User: pkgauth.User{ID: "test-user", Roles: []string{role}}},
+			}))
+			responseRecorder := newMockErrorResponseWriter()
+			handle(responseRecorder, req)
+			return responseRecorder
+		}
+		total := func(responseRecorder *mockErrorResponseWriter) int64 {
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			var response api.StandardResponse[api.FeedbackReport]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			return response.Data.Total.Total
+		}
+
+		assert.Equal(t, int64(1), total(get("/api/feedback/stats?agent=default/k8s-agent", "user", handler.HandleGetFeedbackStats)))
+		assert.Equal(t, int64(2), total(get("/api/feedback/stats?agent=default/k8s-agent", "admin", handler.HandleGetFeedbackStats)))
+		assert.Equal(t, int64(1), total(get("/api/feedback/stats?user=other-user", "admin", handler.HandleGetFeedbackStats)))
+		assert.Equal(t, http.StatusForbidden, get("/api/feedback/stats?user=other-user", "user", handler.HandleGetFeedbackStats).Code)
+		assert.Equal(t, http.StatusForbidden, get("/api/feedback/export?user=other-user", "user", handler.HandleExportFeedback).Code)
+
+		responseRecorder := get("/api/feedback/export", "user", handler.HandleExportFeedback)
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+		assert.Len(t, strings.Split(strings.TrimSpace(responseRecorder.Body.String()), "\n"), 1)
+	})
+}
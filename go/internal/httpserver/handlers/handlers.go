@@ -5,6 +5,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/internal/modelcatalog"
+	"github.com/kagent-dev/kagent/go/internal/usage"
 	"github.com/kagent-dev/kagent/go/pkg/auth"
 )
 
@@ -27,6 +29,7 @@ type Handlers struct {
 	CrewAI              *CrewAIHandler
 	DataSources         *DataSourcesHandler
 	DatabricksDiscovery *DatabricksDiscoveryHandler
+	Usage               *UsageHandler
 }
 
 // Base holds common dependencies for all handlers
@@ -38,7 +41,7 @@ type Base struct {
 }
 
 // NewHandlers creates a new Handlers instance with all handler components
-func NewHandlers(kubeClient client.Client, defaultModelConfig types.NamespacedName, dbService database.Client, watchedNamespaces []string, authorizer auth.Authorizer) *Handlers {
+func NewHandlers(kubeClient client.Client, defaultModelConfig types.NamespacedName, dbService database.Client, watchedNamespaces []string, authorizer auth.Authorizer, agents AgentMessenger, modelCatalog *modelcatalog.Catalog, usageRecorder *usage.Recorder, usagePrices usage.PriceTable) *Handlers {
 	base := &Base{
 		KubeClient:         kubeClient,
 		DefaultModelConfig: defaultModelConfig,
@@ -49,9 +52,9 @@ func NewHandlers(kubeClient client.Client, defaultModelConfig types.NamespacedNa
 	return &Handlers{
 		Health:              NewHealthHandler(),
 		ModelConfig:         NewModelConfigHandler(base),
-		Model:               NewModelHandler(base),
+		Model:               NewModelHandler(base, modelCatalog),
 		Provider:            NewProviderHandler(base),
-		Sessions:            NewSessionsHandler(base),
+		Sessions:            NewSessionsHandler(base, usageRecorder),
 		Agents:              NewAgentsHandler(base),
 		Tools:               NewToolsHandler(base),
 		ToolServers:         NewToolServersHandler(base),
@@ -59,10 +62,11 @@ func NewHandlers(kubeClient client.Client, defaultModelConfig types.NamespacedNa
 		Memory:              NewMemoryHandler(base),
 		Feedback:            NewFeedbackHandler(base),
 		Namespaces:          NewNamespacesHandler(base, watchedNamespaces),
-		Tasks:               NewTasksHandler(base),
+		Tasks:               NewTasksHandler(base, agents),
 		Checkpoints:         NewCheckpointsHandler(base),
 		CrewAI:              NewCrewAIHandler(base),
 		DataSources:         NewDataSourcesHandler(base),
 		DatabricksDiscovery: NewDatabricksDiscoveryHandler(base),
+		Usage:               NewUsageHandler(base, usagePrices),
 	}
 }
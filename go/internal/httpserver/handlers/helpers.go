@@ -79,6 +79,30 @@ func Check(authorizer auth.Authorizer, r *http.Request, res auth.Resource) *erro
 	return nil
 }
 
+// ScopeToUser returns the user a query over the data of all users is limited
+// to. Principals that may get resources of adminType may query any user, or all
+// users if requested is empty. Other principals are limited to themselves and
+// may not query other users.
+func ScopeToUser(authorizer auth.Authorizer, r *http.Request, adminType, requested string) (string, *errors.APIError) {
+	principal, err := GetPrincipal(r)
+	if err != nil {
+		return "", errors.NewBadRequestError("Failed to get user ID", err)
+	}
+	if authorizer.Check(r.Context(), principal, auth.VerbGet, auth.Resource{Type: adminType}) == nil {
+		return requested, nil
+	}
+	if requested != "" && requested != principal.User.ID {
+		return "", errors.NewForbiddenError("Not authorized", fmt.Errorf("user %q is not allowed to get %s of user %q", principal.User.ID, adminType, requested))
+	}
+	return principal.User.ID, nil
+}
+
+// IsAllowed reports whether the request principal may act on the resource. It is
+// used to filter the items of list responses.
+func IsAllowed(authorizer auth.Authorizer, r *http.Request, res auth.Resource) bool {
+	return Check(authorizer, r, res) == nil
+}
+
 func GetPrincipal(r *http.Request) (auth.Principal, error) {
 	log := ctrllog.Log.WithName("http-helpers")
 
@@ -44,9 +44,12 @@ func (h *MemoryHandler) HandleListMemories(w ErrorResponseWriter, r *http.Reques
 		return
 	}
 
-	memoryResponses := make([]api.MemoryResponse, len(memoryList.Items))
-	for i, memory := range memoryList.Items {
+	memoryResponses := make([]api.MemoryResponse, 0, len(memoryList.Items))
+	for _, memory := range memoryList.Items {
 		memoryRef := common.GetObjectRef(&memory)
+		if !IsAllowed(h.Authorizer, r, auth.Resource{Type: "Memory", Name: memoryRef}) {
+			continue
+		}
 		log.V(1).Info("Processing Memory", "memoryRef", memoryRef)
 
 		memoryParams := make(map[string]any)
@@ -54,13 +57,13 @@ func (h *MemoryHandler) HandleListMemories(w ErrorResponseWriter, r *http.Reques
 			FlattenStructToMap(memory.Spec.Pinecone, memoryParams)
 		}
 
-		memoryResponses[i] = api.MemoryResponse{
+		memoryResponses = append(memoryResponses, api.MemoryResponse{
 			Ref:             memoryRef,
 			ProviderName:    string(memory.Spec.Provider),
 			APIKeySecretRef: memory.Spec.APIKeySecretRef,
 			APIKeySecretKey: memory.Spec.APIKeySecretKey,
 			MemoryParams:    memoryParams,
-		}
+		})
 	}
 
 	log.Info("Successfully listed Memories", "count", len(memoryResponses))
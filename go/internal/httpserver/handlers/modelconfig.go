@@ -46,6 +46,9 @@ func (h *ModelConfigHandler) HandleListModelConfigs(w ErrorResponseWriter, r *ht
 
 	configs := make([]api.ModelConfigResponse, 0)
 	for _, config := range modelConfigs.Items {
+		if !IsAllowed(h.Authorizer, r, auth.Resource{Type: "ModelConfig", Name: common.GetObjectRef(&config)}) {
+			continue
+		}
 		modelParams := make(map[string]any)
 
 		if config.Spec.OpenAI != nil {
@@ -60,6 +63,12 @@ func (h *ModelConfigHandler) HandleListModelConfigs(w ErrorResponseWriter, r *ht
 		if config.Spec.Ollama != nil {
 			FlattenStructToMap(config.Spec.Ollama, modelParams)
 		}
+		if config.Spec.OpenAICompatible != nil {
+			FlattenStructToMap(config.Spec.OpenAICompatible, modelParams)
+		}
+		if config.Spec.Bedrock != nil {
+			FlattenStructToMap(config.Spec.Bedrock, modelParams)
+		}
 
 		responseItem := api.ModelConfigResponse{
 			Ref:             common.GetObjectRef(&config),
@@ -143,6 +152,12 @@ func (h *ModelConfigHandler) HandleGetModelConfig(w ErrorResponseWriter, r *http
 	if modelConfig.Spec.Ollama != nil {
 		FlattenStructToMap(modelConfig.Spec.Ollama, modelParams)
 	}
+	if modelConfig.Spec.OpenAICompatible != nil {
+		FlattenStructToMap(modelConfig.Spec.OpenAICompatible, modelParams)
+	}
+	if modelConfig.Spec.Bedrock != nil {
+		FlattenStructToMap(modelConfig.Spec.Bedrock, modelParams)
+	}
 
 	responseItem := api.ModelConfigResponse{
 		Ref:             common.GetObjectRef(modelConfig),
@@ -240,7 +255,7 @@ func (h *ModelConfigHandler) HandleCreateModelConfig(w ErrorResponseWriter, r *h
 	}
 
 	// Set secret references if needed, but don't create secret yet
-	if providerTypeEnum != v1alpha2.ModelProviderOllama && req.APIKey != "" {
+	if usesAPIKeySecret(providerTypeEnum) && req.APIKey != "" {
 		secretName := modelConfigRef.Name
 		secretKey := fmt.Sprintf("%s_API_KEY", strings.ToUpper(req.Provider.Type))
 		modelConfigSpec.APIKeySecret = secretName
@@ -312,6 +327,20 @@ func (h *ModelConfigHandler) HandleCreateModelConfig(w ErrorResponseWriter, r *h
 		} else {
 			log.V(1).Info("No AnthropicVertexAI params provided in create.")
 		}
+	case v1alpha2.ModelProviderOpenAICompatible:
+		if req.OpenAICompatibleParams == nil || req.OpenAICompatibleParams.BaseURL == "" {
+			providerConfigErr = fmt.Errorf("missing required OpenAICompatible parameters: baseUrl")
+		} else {
+			modelConfig.Spec.OpenAICompatible = req.OpenAICompatibleParams
+			log.V(1).Info("Assigned OpenAICompatible params to spec")
+		}
+	case v1alpha2.ModelProviderBedrock:
+		if req.BedrockParams == nil || req.BedrockParams.Region == "" {
+			providerConfigErr = fmt.Errorf("missing required Bedrock parameters: region")
+		} else {
+			modelConfig.Spec.Bedrock = req.BedrockParams
+			log.V(1).Info("Assigned Bedrock params to spec")
+		}
 	default:
 		providerConfigErr = fmt.Errorf("unsupported provider type: %s", req.Provider.Type)
 	}
@@ -329,7 +358,7 @@ func (h *ModelConfigHandler) HandleCreateModelConfig(w ErrorResponseWriter, r *h
 	}
 	log.V(1).Info("Successfully created ModelConfig")
 
-	if providerTypeEnum != v1alpha2.ModelProviderOllama && req.APIKey != "" {
+	if usesAPIKeySecret(providerTypeEnum) && req.APIKey != "" {
 		secretName := modelConfigRef.Name
 		secretNamespace := modelConfigRef.Namespace
 		secretKey := fmt.Sprintf("%s_API_KEY", strings.ToUpper(req.Provider.Type))
@@ -432,10 +461,12 @@ func (h *ModelConfigHandler) HandleUpdateModelConfig(w ErrorResponseWriter, r *h
 		Gemini:            nil,
 		GeminiVertexAI:    nil,
 		AnthropicVertexAI: nil,
+		OpenAICompatible:  nil,
+		Bedrock:           nil,
 	}
 
-	// --- Update Secret if API Key is provided (and not Ollama) ---
-	shouldUpdateSecret := req.APIKey != nil && *req.APIKey != "" && modelConfig.Spec.Provider != v1alpha2.ModelProviderOllama
+	// --- Update Secret if API Key is provided (and not Ollama or Bedrock) ---
+	shouldUpdateSecret := req.APIKey != nil && *req.APIKey != "" && usesAPIKeySecret(modelConfig.Spec.Provider)
 	if shouldUpdateSecret {
 		log.V(1).Info("Updating API key secret")
 
@@ -511,6 +542,20 @@ func (h *ModelConfigHandler) HandleUpdateModelConfig(w ErrorResponseWriter, r *h
 		} else {
 			log.V(1).Info("No AnthropicVertexAI params provided in update.")
 		}
+	case v1alpha2.ModelProviderOpenAICompatible:
+		if req.OpenAICompatibleParams == nil || req.OpenAICompatibleParams.BaseURL == "" {
+			providerConfigErr = fmt.Errorf("missing required OpenAICompatible parameters: baseUrl")
+		} else {
+			modelConfig.Spec.OpenAICompatible = req.OpenAICompatibleParams
+			log.V(1).Info("Assigned updated OpenAICompatible params to spec")
+		}
+	case v1alpha2.ModelProviderBedrock:
+		if req.BedrockParams == nil || req.BedrockParams.Region == "" {
+			providerConfigErr = fmt.Errorf("missing required Bedrock parameters: region")
+		} else {
+			modelConfig.Spec.Bedrock = req.BedrockParams
+			log.V(1).Info("Assigned updated Bedrock params to spec")
+		}
 	default:
 		providerConfigErr = fmt.Errorf("unsupported provider type specified: %s", req.Provider.Type)
 	}
@@ -536,6 +581,10 @@ func (h *ModelConfigHandler) HandleUpdateModelConfig(w ErrorResponseWriter, r *h
 		FlattenStructToMap(modelConfig.Spec.AzureOpenAI, updatedParams)
 	} else if modelConfig.Spec.Ollama != nil {
 		FlattenStructToMap(modelConfig.Spec.Ollama, updatedParams)
+	} else if modelConfig.Spec.OpenAICompatible != nil {
+		FlattenStructToMap(modelConfig.Spec.OpenAICompatible, updatedParams)
+	} else if modelConfig.Spec.Bedrock != nil {
+		FlattenStructToMap(modelConfig.Spec.Bedrock, updatedParams)
 	}
 
 	responseItem := api.ModelConfigResponse{
@@ -613,3 +662,10 @@ func (h *ModelConfigHandler) HandleDeleteModelConfig(w ErrorResponseWriter, r *h
 	data := api.NewResponse(struct{}{}, "Successfully deleted ModelConfig", false)
 	RespondWithJSON(w, http.StatusOK, data)
 }
+
+// usesAPIKeySecret reports whether the provider authenticates with an API key
+// stored in the ModelConfig's secret. Ollama needs no key and Bedrock uses AWS
+// credentials instead.
+func usesAPIKeySecret(provider v1alpha2.ModelProvider) bool {
+	return provider != v1alpha2.ModelProviderOllama && provider != v1alpha2.ModelProviderBedrock
+}
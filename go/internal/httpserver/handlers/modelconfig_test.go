@@ -201,6 +201,57 @@ func TestModelConfigHandler(t *testing.T) {
 			assert.Empty(t, config.Data.Spec.APIKeySecret)
 		})
 
+		t.Run("Success_Bedrock_NoAPIKeySecret", func(t *testing.T) {
+			handler, _, responseRecorder := setupHandler()
+
+			reqBody := api.CreateModelConfigRequest{
+				Ref:      "default/test-bedrock",
+				Provider: api.Provider{Type: "Bedrock"},
+				Model:    "anthropic.claude-sonnet-4-20250514-v1:0",
+				APIKey:   "ignored",
+				BedrockParams: &v1alpha2.BedrockConfig{
+					Region:               "us-east-1",
+					CredentialsSecretRef: "aws-credentials",
+				},
+			}
+
+			jsonBody, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest("POST", "/api/modelconfigs/", bytes.NewBuffer(jsonBody))
+			req = setUser(req, "test-user")
+			req.Header.Set("Content-Type", "application/json")
+
+			handler.HandleCreateModelConfig(responseRecorder, req)
+
+			assert.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+
+			var config api.StandardResponse[v1alpha2.ModelConfig]
+			err := json.Unmarshal(responseRecorder.Body.Bytes(), &config)
+			require.NoError(t, err)
+			assert.Equal(t, v1alpha2.ModelProviderBedrock, config.Data.Spec.Provider)
+			assert.Equal(t, "us-east-1", config.Data.Spec.Bedrock.Region)
+			assert.Empty(t, config.Data.Spec.APIKeySecret)
+		})
+
+		t.Run("OpenAICompatible_MissingBaseURL", func(t *testing.T) {
+			handler, _, responseRecorder := setupHandler()
+
+			reqBody := api.CreateModelConfigRequest{
+				Ref:                    "default/test-vllm",
+				Provider:               api.Provider{Type: "OpenAICompatible"},
+				Model:                  "meta-llama/Llama-3.1-8B-Instruct",
+				OpenAICompatibleParams: &v1alpha2.OpenAICompatibleConfig{AuthHeader: "X-Api-Key"},
+			}
+
+			jsonBody, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest("POST", "/api/modelconfigs/", bytes.NewBuffer(jsonBody))
+			req = setUser(req, "test-user")
+			req.Header.Set("Content-Type", "application/json")
+
+			handler.HandleCreateModelConfig(responseRecorder, req)
+
+			assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+		})
+
 		t.Run("Success_AzureOpenAI", func(t *testing.T) {
 			handler, _, responseRecorder := setupHandler()
 
@@ -2,90 +2,198 @@ package handlers
 
 import (
 	"net/http"
+	"sync"
 
 	v1alpha2 "github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/controller/modelprobe"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/internal/modelcatalog"
+	common "github.com/kagent-dev/kagent/go/internal/utils"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	kclient "github.com/kagent-dev/kagent/go/pkg/client"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // ModelHandler handles model requests
 type ModelHandler struct {
 	*Base
+	Catalog *modelcatalog.Catalog
 }
 
 // NewModelHandler creates a new ModelHandler
-func NewModelHandler(base *Base) *ModelHandler {
-	return &ModelHandler{Base: base}
+func NewModelHandler(base *Base, catalog *modelcatalog.Catalog) *ModelHandler {
+	return &ModelHandler{Base: base, Catalog: catalog}
 }
 
+// HandleListSupportedModels handles GET /api/models requests. The models of
+// each provider are listed with the credentials of the ModelConfigs using it;
+// providers without a ModelConfig offer the well known models of the catalog.
+// The provider query parameter limits the response to a single provider and
+// the modelConfig parameter (namespace/name) to the models of that ModelConfig.
 func (h *ModelHandler) HandleListSupportedModels(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("model-handler").WithValues("operation", "list-supported-models")
 
-	log.Info("Listing supported models")
-
-	// Create a map of provider names to their supported models
-	// The keys need to match what the UI expects (camelCase for API keys)
-	supportedModels := kclient.ProviderModels{
-		v1alpha2.ModelProviderOpenAI: {
-			{Name: "gpt-5", FunctionCalling: true},
-			{Name: "gpt-5-mini", FunctionCalling: true},
-			{Name: "gpt-5-nano", FunctionCalling: true},
-			{Name: "gpt-4o", FunctionCalling: true},
-			{Name: "o4-mini", FunctionCalling: true},
-			{Name: "gpt-4-turbo", FunctionCalling: true},
-			{Name: "gpt-4", FunctionCalling: true},
-			{Name: "gpt-3.5-turbo", FunctionCalling: true},
-		},
-		v1alpha2.ModelProviderAnthropic: {
-			{Name: "claude-opus-4-1-20250805", FunctionCalling: true},
-			{Name: "claude-opus-4-20250514", FunctionCalling: true},
-			{Name: "claude-sonnet-4-20250514", FunctionCalling: true},
-			{Name: "claude-3-7-sonnet-20250219", FunctionCalling: true},
-			{Name: "claude-3-5-sonnet-20240620", FunctionCalling: true},
-			{Name: "claude-sonnet-4-5", FunctionCalling: true},
-		},
-		v1alpha2.ModelProviderAzureOpenAI: {
-			{Name: "gpt-4", FunctionCalling: true},
-			{Name: "gpt-35-turbo", FunctionCalling: true},
-			{Name: "gpt-oss-120b", FunctionCalling: true},
-			{Name: "gpt-4.1", FunctionCalling: true},
-			{Name: "gpt-4.1-mini", FunctionCalling: true},
-			{Name: "gpt-4.1-nano", FunctionCalling: true},
-			{Name: "gpt-4o", FunctionCalling: true},
-			{Name: "gpt-4o-mini", FunctionCalling: true}, {Name: "o4-mini", FunctionCalling: true},
-			{Name: "o3", FunctionCalling: true},
-			{Name: "o3-mini", FunctionCalling: true},
-		},
-		v1alpha2.ModelProviderOllama: {
-			{Name: "llama2", FunctionCalling: false},
-			{Name: "llama2:13b", FunctionCalling: false},
-			{Name: "llama2:70b", FunctionCalling: false},
-			{Name: "mistral", FunctionCalling: false},
-			{Name: "mixtral", FunctionCalling: false},
-		},
-		v1alpha2.ModelProviderGemini: {
-			{Name: "gemini-2.5-pro", FunctionCalling: true},
-			{Name: "gemini-2.5-flash", FunctionCalling: true},
-			{Name: "gemini-2.5-flash-lite", FunctionCalling: true},
-			{Name: "gemini-2.0-flash", FunctionCalling: true},
-			{Name: "gemini-2.0-flash-lite", FunctionCalling: true},
-		},
-		v1alpha2.ModelProviderGeminiVertexAI: {
-			{Name: "gemini-2.5-pro", FunctionCalling: true},
-			{Name: "gemini-2.5-flash", FunctionCalling: true},
-			{Name: "gemini-2.5-flash-lite", FunctionCalling: true},
-			{Name: "gemini-2.0-flash", FunctionCalling: true},
-			{Name: "gemini-2.0-flash-lite", FunctionCalling: true},
-		},
-		v1alpha2.ModelProviderAnthropicVertexAI: {
-			{Name: "claude-opus-4-1@20250805", FunctionCalling: true},
-			{Name: "claude-sonnet-4@20250514", FunctionCalling: true},
-			{Name: "claude-3-5-haiku@20241022", FunctionCalling: true},
-		},
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Model"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	provider := v1alpha2.ModelProvider(r.URL.Query().Get("provider"))
+	if modelConfigRef := r.URL.Query().Get("modelConfig"); modelConfigRef != "" {
+		h.listModelConfigModels(w, r, modelConfigRef, provider)
+		return
+	}
+
+	log.Info("Listing supported models", "provider", provider)
+
+	modelConfigs := &v1alpha2.ModelConfigList{}
+	if err := h.KubeClient.List(r.Context(), modelConfigs); err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list ModelConfigs from Kubernetes", err))
+		return
+	}
+
+	// Providers are asked concurrently, as each may take up to the request timeout
+	var listable []*v1alpha2.ModelConfig
+	for i := range modelConfigs.Items {
+		modelConfig := &modelConfigs.Items[i]
+		if provider != "" && modelConfig.Spec.Provider != provider {
+			continue
+		}
+		if !IsAllowed(h.Authorizer, r, auth.Resource{Type: "ModelConfig", Name: common.GetObjectRef(modelConfig)}) {
+			continue
+		}
+		listable = append(listable, modelConfig)
+	}
+	listed := make([][]kclient.ModelInfo, len(listable))
+	var wg sync.WaitGroup
+	for i, modelConfig := range listable {
+		wg.Go(func() {
+			models, err := h.Catalog.ListModels(r.Context(), modelConfig)
+			if err != nil {
+				// A single unreachable provider shouldn't fail the listing
+				log.Info("Failed to list models of ModelConfig", "ref", common.GetObjectRef(modelConfig), "error", err.Error())
+				return
+			}
+			listed[i] = models
+		})
+	}
+	wg.Wait()
+
+	supportedModels := kclient.ProviderModels{}
+	seen := map[v1alpha2.ModelProvider]map[string]struct{}{}
+	for i, modelConfig := range listable {
+		if listed[i] == nil {
+			continue
+		}
+		if seen[modelConfig.Spec.Provider] == nil {
+			seen[modelConfig.Spec.Provider] = map[string]struct{}{}
+		}
+		for _, model := range listed[i] {
+			if _, ok := seen[modelConfig.Spec.Provider][model.Name]; ok {
+				continue
+			}
+			seen[modelConfig.Spec.Provider][model.Name] = struct{}{}
+			supportedModels[modelConfig.Spec.Provider] = append(supportedModels[modelConfig.Spec.Provider], model)
+		}
+	}
+
+	capabilities := h.Catalog.Capabilities()
+	for _, p := range capabilities.Providers() {
+		if provider != "" && p != provider {
+			continue
+		}
+		if _, ok := supportedModels[p]; !ok {
+			supportedModels[p] = capabilities.Models(p)
+		}
 	}
 
 	log.Info("Successfully listed supported models", "count", len(supportedModels))
 	data := api.NewResponse(supportedModels, "Successfully listed supported models", false)
 	RespondWithJSON(w, http.StatusOK, data)
 }
+
+func (h *ModelHandler) listModelConfigModels(w ErrorResponseWriter, r *http.Request, ref string, provider v1alpha2.ModelProvider) {
+	log := ctrllog.FromContext(r.Context()).WithName("model-handler").WithValues("operation", "list-model-config-models", "modelConfig", ref)
+
+	modelConfigRef, err := common.ParseRefString(ref, common.GetResourceNamespace())
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid ModelConfig reference", err))
+		return
+	}
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "ModelConfig", Name: modelConfigRef.String()}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	modelConfig := &v1alpha2.ModelConfig{}
+	if err := h.KubeClient.Get(r.Context(), modelConfigRef, modelConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			w.RespondWithError(errors.NewNotFoundError("ModelConfig not found", nil))
+			return
+		}
+		w.RespondWithError(errors.NewInternalServerError("Failed to get ModelConfig", err))
+		return
+	}
+	if provider != "" && modelConfig.Spec.Provider != provider {
+		w.RespondWithError(errors.NewBadRequestError("ModelConfig does not use the requested provider", nil))
+		return
+	}
+
+	models, err := h.Catalog.ListModels(r.Context(), modelConfig)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list models of ModelConfig", err))
+		return
+	}
+
+	log.Info("Successfully listed models of ModelConfig", "count", len(models))
+	data := api.NewResponse(kclient.ProviderModels{modelConfig.Spec.Provider: models}, "Successfully listed models", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleDiscoverModels handles POST /api/models requests, which list the
+// models of a provider with the supplied credentials, e.g. before creating a
+// ModelConfig for them. As the controller sends requests to the supplied
+// endpoints, only principals that may create ModelConfigs may discover models.
+func (h *ModelHandler) HandleDiscoverModels(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("model-handler").WithValues("operation", "discover-models")
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "ModelConfig"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	var req api.DiscoverModelsRequest
+	if err := DecodeJSONBody(r, &req); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	if req.Provider.Type == "" {
+		w.RespondWithError(errors.NewBadRequestError("provider is required", nil))
+		return
+	}
+
+	modelConfig := &v1alpha2.ModelConfig{
+		Spec: v1alpha2.ModelConfigSpec{
+			Provider:         v1alpha2.ModelProvider(req.Provider.Type),
+			OpenAI:           req.OpenAIParams,
+			Anthropic:        req.AnthropicParams,
+			AzureOpenAI:      req.AzureParams,
+			Ollama:           req.OllamaParams,
+			OpenAICompatible: req.OpenAICompatibleParams,
+		},
+	}
+	log = log.WithValues("provider", modelConfig.Spec.Provider)
+
+	models, err := h.Catalog.ListModelsWithCredentials(r.Context(), modelConfig, modelprobe.Credentials{APIKey: req.APIKey})
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to list models of provider", err))
+		return
+	}
+
+	log.Info("Successfully discovered models", "count", len(models))
+	data := api.NewResponse(kclient.ProviderModels{modelConfig.Spec.Provider: models}, "Successfully listed models", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
@@ -0,0 +1,167 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
+	"github.com/kagent-dev/kagent/go/internal/modelcatalog"
+	pkgauth "github.com/kagent-dev/kagent/go/pkg/auth"
+	kclient "github.com/kagent-dev/kagent/go/pkg/client"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+func TestModelHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": [{"id": "gpt-4o"}, {"id": "my-fine-tune"}]}`))
+	}))
+	defer provider.Close()
+
+	setupHandler := func() (*handlers.ModelHandler, *mockErrorResponseWriter) {
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "openai", Namespace: "default"},
+				Data:       map[string][]byte{"api-key": []byte("sk-test")},
+			},
+			&v1alpha2.ModelConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "openai", Namespace: "default"},
+				Spec: v1alpha2.ModelConfigSpec{
+					Provider:        v1alpha2.ModelProviderOpenAI,
+					Model:           "gpt-4o",
+					APIKeySecret:    "openai",
+					APIKeySecretKey: "api-key",
+					OpenAI:          &v1alpha2.OpenAIConfig{BaseURL: provider.URL},
+				},
+			},
+		).Build()
+		base := &handlers.Base{
+			KubeClient:         kubeClient,
+			DefaultModelConfig: types.NamespacedName{Namespace: "default", Name: "default"},
+			Authorizer:         &auth.NoopAuthorizer{},
+		}
+		catalog := modelcatalog.New(kubeClient, modelcatalog.DefaultCapabilities(), time.Minute)
+		return handlers.NewModelHandler(base, catalog), newMockErrorResponseWriter()
+	}
+
+	list := func(t *testing.T, target string) (kclient.ProviderModels, *mockErrorResponseWriter) {
+		handler, responseRecorder := setupHandler()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = setUser(req, "test-user")
+		handler.HandleListSupportedModels(responseRecorder, req)
+
+		var response api.StandardResponse[kclient.ProviderModels]
+		if responseRecorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+		}
+		return response.Data, responseRecorder
+	}
+
+	t.Run("lists models of ModelConfigs and well known models of other providers", func(t *testing.T) {
+		models, responseRecorder := list(t, "/api/models")
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+		assert.Equal(t, []kclient.ModelInfo{
+			{Name: "gpt-4o", FunctionCalling: true, ContextWindow: 128000, Vision: true},
+			{Name: "my-fine-tune"},
+		}, models[v1alpha2.ModelProviderOpenAI])
+		assert.NotEmpty(t, models[v1alpha2.ModelProviderAnthropic])
+		assert.Contains(t, models, v1alpha2.ModelProviderOpenAICompatible)
+	})
+
+	t.Run("filters by provider", func(t *testing.T) {
+		models, responseRecorder := list(t, "/api/models?provider=Anthropic")
+		require.Equal(t, http.StatusOK, responseRecorder.Code)
+		assert.Len(t, models, 1)
+		assert.Contains(t, models, v1alpha2.ModelProviderAnthropic)
+	})
+
+	t.Run("lists models of a ModelConfig", func(t *testing.T) {
+		models, responseRecorder := list(t, "/api/models?modelConfig=default/openai")
+		require.Equal(t, http.StatusOK, responseRecorder.Code)
+		assert.Len(t, models, 1)
+		assert.Len(t, models[v1alpha2.ModelProviderOpenAI], 2)
+	})
+
+	t.Run("ModelConfig not found", func(t *testing.T) {
+		_, responseRecorder := list(t, "/api/models?modelConfig=default/missing")
+		assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+	})
+
+	t.Run("discovers models with supplied credentials", func(t *testing.T) {
+		handler, responseRecorder := setupHandler()
+		body, _ := json.Marshal(api.DiscoverModelsRequest{
+			Provider:     api.Provider{Type: string(v1alpha2.ModelProviderOpenAI)},
+			APIKey:       "sk-test",
+			OpenAIParams: &v1alpha2.OpenAIConfig{BaseURL: provider.URL},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/models", bytes.NewBuffer(body))
+		req = setUser(req, "test-user")
+		handler.HandleDiscoverModels(responseRecorder, req)
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+		var response api.StandardResponse[kclient.ProviderModels]
+		require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+		assert.Len(t, response.Data[v1alpha2.ModelProviderOpenAI], 2)
+	})
+
+	t.Run("discovery fails with wrong credentials", func(t *testing.T) {
+		handler, responseRecorder := setupHandler()
+		body, _ := json.Marshal(api.DiscoverModelsRequest{
+			Provider:     api.Provider{Type: string(v1alpha2.ModelProviderOpenAI)},
+			APIKey:       "wrong",
+			OpenAIParams: &v1alpha2.OpenAIConfig{BaseURL: provider.URL},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/models", bytes.NewBuffer(body))
+		req = setUser(req, "test-user")
+		handler.HandleDiscoverModels(responseRecorder, req)
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	})
+
+	t.Run("discovery requires permission to create ModelConfigs", func(t *testing.T) {
+		handler, responseRecorder := setupHandler()
+		policy, err := auth.ParseRBACPolicy([]byte(`
+roles:
+  user:
+  - resources: [Model, ModelConfig]
+    verbs: [get]
+`))
+		require.NoError(t, err)
+		authorizer := auth.NewRBACAuthorizer()
+		authorizer.SetPolicy(policy)
+		handler.Authorizer = authorizer
+
+		body, _ := json.Marshal(api.DiscoverModelsRequest{
+			Provider:     api.Provider{Type: string(v1alpha2.ModelProviderOpenAI)},
+			APIKey:       "sk-test",
+			OpenAIParams: &v1alpha2.OpenAIConfig{BaseURL: provider.URL},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/models", bytes.NewBuffer(body))
+		req = req.WithContext(pkgauth.AuthSessionTo(req.Context(), &auth.SimpleSession{
+			P: pkgauth.Principal{User: pkgauth.User{ID: "test-user", Roles: []string{"user"}}},
+		}))
+		handler.HandleDiscoverModels(responseRecorder, req)
+		assert.Equal(t, http.StatusForbidden, responseRecorder.Code)
+	})
+}
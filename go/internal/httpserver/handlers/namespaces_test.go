@@ -16,6 +16,7 @@ import (
 	ctrl_client "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	"github.com/kagent-dev/kmcp/api/v1alpha1"
@@ -34,6 +35,7 @@ func TestNamespacesHandler(t *testing.T) {
 		base := &handlers.Base{
 			KubeClient:         kubeClient,
 			DefaultModelConfig: types.NamespacedName{Namespace: "default", Name: "default"},
+			Authorizer:         &authimpl.NoopAuthorizer{},
 		}
 		handler := handlers.NewNamespacesHandler(base, watchedNamespaces)
 		responseRecorder := newMockErrorResponseWriter()
@@ -69,6 +71,7 @@ func TestNamespacesHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			req := httptest.NewRequest("GET", "/api/namespaces", nil)
+			req = setUser(req, "test-user")
 			handler.HandleListNamespaces(responseRecorder, req)
 
 			assert.Equal(t, http.StatusOK, responseRecorder.Code)
@@ -113,6 +116,7 @@ func TestNamespacesHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			req := httptest.NewRequest("GET", "/api/namespaces", nil)
+			req = setUser(req, "test-user")
 			handler.HandleListNamespaces(responseRecorder, req)
 
 			assert.Equal(t, http.StatusOK, responseRecorder.Code)
@@ -150,6 +154,7 @@ func TestNamespacesHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			req := httptest.NewRequest("GET", "/api/namespaces", nil)
+			req = setUser(req, "test-user")
 			handler.HandleListNamespaces(responseRecorder, req)
 
 			assert.Equal(t, http.StatusOK, responseRecorder.Code)
@@ -184,6 +189,7 @@ func TestNamespacesHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			req := httptest.NewRequest("GET", "/api/namespaces", nil)
+			req = setUser(req, "test-user")
 			handler.HandleListNamespaces(responseRecorder, req)
 
 			assert.Equal(t, http.StatusOK, responseRecorder.Code)
@@ -218,6 +224,7 @@ func TestNamespacesHandler(t *testing.T) {
 			require.NoError(t, err)
 
 			req := httptest.NewRequest("GET", "/api/namespaces", nil)
+			req = setUser(req, "test-user")
 			handler.HandleListNamespaces(responseRecorder, req)
 
 			assert.Equal(t, http.StatusOK, responseRecorder.Code)
@@ -235,6 +242,7 @@ func TestNamespacesHandler(t *testing.T) {
 			handler, _, responseRecorder := setupHandler([]string{})
 
 			req := httptest.NewRequest("GET", "/api/namespaces", nil)
+			req = setUser(req, "test-user")
 			handler.HandleListNamespaces(responseRecorder, req)
 
 			assert.Equal(t, http.StatusOK, responseRecorder.Code)
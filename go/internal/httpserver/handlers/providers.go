@@ -6,6 +6,7 @@ import (
 
 	"github.com/kagent-dev/kagent/go/api/v1alpha1"
 	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -26,6 +27,10 @@ func getRequiredKeysForModelProvider(providerType v1alpha2.ModelProvider) []stri
 	case v1alpha2.ModelProviderAzureOpenAI:
 		// Based on the +required comments in the AzureOpenAIConfig struct definition
 		return []string{"azureEndpoint", "apiVersion"}
+	case v1alpha2.ModelProviderOpenAICompatible:
+		return []string{"baseUrl"}
+	case v1alpha2.ModelProviderBedrock:
+		return []string{"region"}
 	case v1alpha2.ModelProviderOpenAI, v1alpha2.ModelProviderAnthropic, v1alpha2.ModelProviderOllama:
 		// These providers currently have no fields marked as strictly required in the API definition
 		return []string{}
@@ -47,6 +52,11 @@ func getRequiredKeysForMemoryProvider(providerType v1alpha1.MemoryProvider) []st
 func (h *ProviderHandler) HandleListSupportedMemoryProviders(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("provider-handler").WithValues("operation", "list-supported-memory-providers")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "MemoryProvider"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	log.Info("Listing supported memory providers with parameters")
 
 	providersData := []struct {
@@ -88,6 +98,11 @@ func (h *ProviderHandler) HandleListSupportedMemoryProviders(w ErrorResponseWrit
 func (h *ProviderHandler) HandleListSupportedModelProviders(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("provider-handler").WithValues("operation", "list-supported-model-providers")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "ModelProvider"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	log.Info("Listing supported model providers with parameters")
 
 	providersData := []struct {
@@ -101,6 +116,8 @@ func (h *ProviderHandler) HandleListSupportedModelProviders(w ErrorResponseWrite
 		{v1alpha2.ModelProviderGemini, reflect.TypeFor[v1alpha2.GeminiConfig]()},
 		{v1alpha2.ModelProviderGeminiVertexAI, reflect.TypeFor[v1alpha2.GeminiVertexAIConfig]()},
 		{v1alpha2.ModelProviderAnthropicVertexAI, reflect.TypeFor[v1alpha2.AnthropicVertexAIConfig]()},
+		{v1alpha2.ModelProviderOpenAICompatible, reflect.TypeFor[v1alpha2.OpenAICompatibleConfig]()},
+		{v1alpha2.ModelProviderBedrock, reflect.TypeFor[v1alpha2.BedrockConfig]()},
 	}
 
 	providersResponse := []map[string]any{}
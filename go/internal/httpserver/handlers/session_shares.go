@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// shareLinkTokenBytes is the number of random bytes of share link tokens
+const shareLinkTokenBytes = 32
+
+// HandleListSharedSessions handles GET /api/sessions/shared requests. It lists
+// the sessions other users shared with the requesting user.
+func (h *SessionsHandler) HandleListSharedSessions(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "list-shared")
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+	log = log.WithValues("userID", userID)
+
+	sessions, err := h.DatabaseService.WithContext(r.Context()).ListSharedSessions(userID)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list shared sessions", err))
+		return
+	}
+
+	log.Info("Successfully listed shared sessions", "count", len(sessions))
+	data := api.NewResponse(sessions, "Successfully listed shared sessions", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleListSessionShares handles GET /api/sessions/{session_id}/shares
+// requests. It lists the users the session is shared with and its share links.
+// Only the owner of the session can list them.
+func (h *SessionsHandler) HandleListSessionShares(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "list-shares")
+
+	sessionID, userID, ok := h.ownedSession(w, r)
+	if !ok {
+		return
+	}
+	log = log.WithValues("session_id", sessionID, "userID", userID)
+
+	db := h.DatabaseService.WithContext(r.Context())
+	shares, err := db.ListSessionShares(sessionID, userID)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list session shares", err))
+		return
+	}
+	links, err := db.ListSessionShareLinks(sessionID, userID)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list session share links", err))
+		return
+	}
+
+	log.Info("Successfully listed session shares", "shares", len(shares), "links", len(links))
+	data := api.NewResponse(api.SessionShares{Shares: shares, Links: links}, "Successfully listed session shares", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleShareSession handles PUT /api/sessions/{session_id}/shares requests. It
+// shares the session with a user as a viewer or a collaborator, or changes the
+// role of a user it's shared with.
+func (h *SessionsHandler) HandleShareSession(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "share")
+
+	sessionID, userID, ok := h.ownedSession(w, r)
+	if !ok {
+		return
+	}
+	log = log.WithValues("session_id", sessionID, "userID", userID)
+
+	var shareRequest api.SessionShareRequest
+	if err := DecodeJSONBody(r, &shareRequest); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	if shareRequest.UserID == "" {
+		w.RespondWithError(errors.NewBadRequestError("user_id is required", nil))
+		return
+	}
+	if shareRequest.UserID == userID {
+		w.RespondWithError(errors.NewBadRequestError("Sessions can't be shared with their owner", nil))
+		return
+	}
+	if !isShareRole(shareRequest.Role) {
+		w.RespondWithError(errors.NewBadRequestError("role must be viewer or collaborator", nil))
+		return
+	}
+
+	share := &database.SessionShare{
+		SessionID: sessionID,
+		OwnerID:   userID,
+		UserID:    shareRequest.UserID,
+		Role:      shareRequest.Role,
+	}
+	if err := h.DatabaseService.WithContext(r.Context()).StoreSessionShare(share); err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to share session", err))
+		return
+	}
+
+	log.Info("Successfully shared session", "sharedWith", share.UserID, "role", share.Role)
+	data := api.NewResponse(share, "Successfully shared session", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleUnshareSession handles DELETE /api/sessions/{session_id}/shares/{user_id}
+// requests. It revokes the access of a user to the session.
+func (h *SessionsHandler) HandleUnshareSession(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "unshare")
+
+	sessionID, userID, ok := h.ownedSession(w, r)
+	if !ok {
+		return
+	}
+	sharedWith, err := GetPathParam(r, "user_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID from path", err))
+		return
+	}
+	log = log.WithValues("session_id", sessionID, "userID", userID, "sharedWith", sharedWith)
+
+	if err := h.DatabaseService.WithContext(r.Context()).DeleteSessionShare(sessionID, userID, sharedWith); err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to unshare session", err))
+		return
+	}
+
+	log.Info("Successfully unshared session")
+	data := api.NewResponse(struct{}{}, "Successfully unshared session", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleCreateSessionShareLink handles POST /api/sessions/{session_id}/share-links
+// requests. Users redeeming the link get its role in the session until it
+// expires. The token of the link is only returned in the response.
+func (h *SessionsHandler) HandleCreateSessionShareLink(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "create-share-link")
+
+	sessionID, userID, ok := h.ownedSession(w, r)
+	if !ok {
+		return
+	}
+	log = log.WithValues("session_id", sessionID, "userID", userID)
+
+	var linkRequest api.SessionShareLinkRequest
+	if err := DecodeJSONBody(r, &linkRequest); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	if !isShareRole(linkRequest.Role) {
+		w.RespondWithError(errors.NewBadRequestError("role must be viewer or collaborator", nil))
+		return
+	}
+	if linkRequest.ExpiresAt != nil && !linkRequest.ExpiresAt.After(time.Now()) {
+		w.RespondWithError(errors.NewBadRequestError("expires_at must be in the future", nil))
+		return
+	}
+
+	token, err := newShareLinkToken()
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to generate share link token", err))
+		return
+	}
+	link := &database.SessionShareLink{
+		ID:        uuid.NewString(),
+		TokenHash: hashShareLinkToken(token),
+		SessionID: sessionID,
+		OwnerID:   userID,
+		Role:      linkRequest.Role,
+		ExpiresAt: linkRequest.ExpiresAt,
+	}
+	if err := h.DatabaseService.WithContext(r.Context()).StoreSessionShareLink(link); err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to create share link", err))
+		return
+	}
+
+	log.Info("Successfully created share link", "linkID", link.ID, "role", link.Role)
+	data := api.NewResponse(api.CreatedSessionShareLink{SessionShareLink: *link, Token: token}, "Successfully created share link", false)
+	RespondWithJSON(w, http.StatusCreated, data)
+}
+
+// HandleDeleteSessionShareLink handles DELETE
+// /api/sessions/{session_id}/share-links/{link_id} requests. Users who redeemed
+// the link keep their access to the session.
+func (h *SessionsHandler) HandleDeleteSessionShareLink(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "delete-share-link")
+
+	sessionID, userID, ok := h.ownedSession(w, r)
+	if !ok {
+		return
+	}
+	linkID, err := GetPathParam(r, "link_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get link ID from path", err))
+		return
+	}
+	log = log.WithValues("session_id", sessionID, "userID", userID, "linkID", linkID)
+
+	if err := h.DatabaseService.WithContext(r.Context()).DeleteSessionShareLink(sessionID, userID, linkID); err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to delete share link", err))
+		return
+	}
+
+	log.Info("Successfully deleted share link")
+	data := api.NewResponse(struct{}{}, "Successfully deleted share link", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleRedeemSessionShareLink handles POST /api/sessions/shared/{token}
+// requests. It shares the session of the link with the requesting user, with
+// the role of the link. Redeeming a viewer link doesn't demote a collaborator.
+func (h *SessionsHandler) HandleRedeemSessionShareLink(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "redeem-share-link")
+
+	token, err := GetPathParam(r, "token")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get token from path", err))
+		return
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+	log = log.WithValues("userID", userID)
+
+	db := h.DatabaseService.WithContext(r.Context())
+	link, err := db.GetSessionShareLink(hashShareLinkToken(token))
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Share link not found", err))
+		return
+	}
+	log = log.WithValues("session_id", link.SessionID, "linkID", link.ID)
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		w.RespondWithError(errors.NewForbiddenError("Share link has expired", nil))
+		return
+	}
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: link.SessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	if _, err := db.GetSession(link.SessionID, link.OwnerID); err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
+		return
+	}
+
+	share := &database.SessionShare{
+		SessionID: link.SessionID,
+		OwnerID:   link.OwnerID,
+		UserID:    userID,
+		Role:      link.Role,
+	}
+	if userID == link.OwnerID {
+		share.Role = database.SessionRoleOwner
+	} else {
+		existing, err := db.GetSessionShare(link.SessionID, userID)
+		if err == nil && existing.OwnerID == link.OwnerID && existing.Role == database.SessionRoleCollaborator {
+			share = existing
+		} else if err := db.StoreSessionShare(share); err != nil {
+			w.RespondWithError(errors.NewInternalServerError("Failed to share session", err))
+			return
+		}
+	}
+
+	log.Info("Successfully redeemed share link", "role", share.Role)
+	data := api.NewResponse(share, "Successfully redeemed share link", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// ownedSession returns the session ID of a request on a session and the ID of
+// the requesting user, after checking that they own the session. It responds
+// with an error otherwise.
+func (h *SessionsHandler) ownedSession(w ErrorResponseWriter, r *http.Request) (string, string, bool) {
+	sessionID, err := GetPathParam(r, "session_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get session ID from path", err))
+		return "", "", false
+	}
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return "", "", false
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return "", "", false
+	}
+
+	if _, err := h.DatabaseService.WithContext(r.Context()).GetSession(sessionID, userID); err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
+		return "", "", false
+	}
+	return sessionID, userID, true
+}
+
+func isShareRole(role string) bool {
+	return role == database.SessionRoleViewer || role == database.SessionRoleCollaborator
+}
+
+func newShareLinkToken() (string, error) {
+	b := make([]byte, shareLinkTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashShareLinkToken returns the hash share link tokens are stored as.
+func hashShareLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,15 +1,24 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kagent-dev/kagent/go/internal/database"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/internal/sessionexport"
+	"github.com/kagent-dev/kagent/go/internal/usage"
 	"github.com/kagent-dev/kagent/go/internal/utils"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
+	"k8s.io/utils/ptr"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
@@ -17,11 +26,13 @@ import (
 // SessionsHandler handles session-related requests
 type SessionsHandler struct {
 	*Base
+	Usage *usage.Recorder
 }
 
-// NewSessionsHandler creates a new SessionsHandler
-func NewSessionsHandler(base *Base) *SessionsHandler {
-	return &SessionsHandler{Base: base}
+// NewSessionsHandler creates a new SessionsHandler. If usageRecorder is set,
+// the token usage of events added to sessions is recorded.
+func NewSessionsHandler(base *Base, usageRecorder *usage.Recorder) *SessionsHandler {
+	return &SessionsHandler{Base: base, Usage: usageRecorder}
 }
 
 // RunRequest represents a run creation request
@@ -46,6 +57,11 @@ func (h *SessionsHandler) HandleGetSessionsForAgent(w ErrorResponseWriter, r *ht
 	}
 	log = log.WithValues("agentName", agentName)
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := getUserIDOrAgentUser(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -53,14 +69,14 @@ func (h *SessionsHandler) HandleGetSessionsForAgent(w ErrorResponseWriter, r *ht
 	}
 
 	// Get agent ID from agent ref
-	agent, err := h.DatabaseService.GetAgent(utils.ConvertToPythonIdentifier(namespace + "/" + agentName))
+	agent, err := h.DatabaseService.WithContext(r.Context()).GetAgent(utils.ConvertToPythonIdentifier(namespace + "/" + agentName))
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Agent not found", err))
 		return
 	}
 
 	log.V(1).Info("Getting sessions for agent from database")
-	sessions, err := h.DatabaseService.ListSessionsForAgent(agent.ID, userID)
+	sessions, err := h.DatabaseService.WithContext(r.Context()).ListSessionsForAgent(agent.ID, userID)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to get sessions for agent", err))
 		return
@@ -75,6 +91,11 @@ func (h *SessionsHandler) HandleGetSessionsForAgent(w ErrorResponseWriter, r *ht
 func (h *SessionsHandler) HandleListSessions(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "list-db")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -83,7 +104,7 @@ func (h *SessionsHandler) HandleListSessions(w ErrorResponseWriter, r *http.Requ
 	log = log.WithValues("userID", userID)
 
 	log.V(1).Info("Listing sessions from database")
-	sessions, err := h.DatabaseService.ListSessions(userID)
+	sessions, err := h.DatabaseService.WithContext(r.Context()).ListSessions(userID)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to list sessions", err))
 		return
@@ -94,6 +115,65 @@ func (h *SessionsHandler) HandleListSessions(w ErrorResponseWriter, r *http.Requ
 	RespondWithJSON(w, http.StatusOK, data)
 }
 
+// HandleSearchSessions handles GET /api/sessions/search requests. It searches
+// the text of the user's events and their session names for the words of q,
+// best matches first. agent (namespace/name) restricts the search to the
+// sessions of an agent, from and to (RFC 3339 timestamps or YYYY-MM-DD dates) to
+// a time range, and limit caps the number of results.
+func (h *SessionsHandler) HandleSearchSessions(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "search")
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+	log = log.WithValues("userID", userID)
+
+	query := r.URL.Query()
+	search := database.SearchQuery{
+		Query:  strings.TrimSpace(query.Get("q")),
+		UserID: userID,
+	}
+	if search.Query == "" {
+		w.RespondWithError(errors.NewBadRequestError("q is required", nil))
+		return
+	}
+	if agentRef := query.Get("agent"); agentRef != "" {
+		search.AgentID = utils.ConvertToPythonIdentifier(agentRef)
+	}
+	if search.From, err = parseTimeParam(query.Get("from"), false); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid from", err))
+		return
+	}
+	if search.To, err = parseTimeParam(query.Get("to"), true); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid to", err))
+		return
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if search.Limit, err = strconv.Atoi(limit); err != nil || search.Limit < 0 {
+			w.RespondWithError(errors.NewBadRequestError("Invalid limit", err))
+			return
+		}
+	}
+
+	log.V(1).Info("Searching sessions", "query", search.Query)
+	results, err := h.DatabaseService.WithContext(r.Context()).SearchSessions(search)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to search sessions", err))
+		return
+	}
+
+	log.Info("Successfully searched sessions", "count", len(results))
+	data := api.NewResponse(results, "Successfully searched sessions", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
 // HandleCreateSession handles POST /api/sessions requests using database
 func (h *SessionsHandler) HandleCreateSession(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "create-db")
@@ -123,9 +203,14 @@ func (h *SessionsHandler) HandleCreateSession(w ErrorResponseWriter, r *http.Req
 		id = *sessionRequest.ID
 	}
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: id}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	log.V(1).Info("Getting agent from database", "session_request", sessionRequest)
 
-	agent, err := h.DatabaseService.GetAgent(utils.ConvertToPythonIdentifier(*sessionRequest.AgentRef))
+	agent, err := h.DatabaseService.WithContext(r.Context()).GetAgent(utils.ConvertToPythonIdentifier(*sessionRequest.AgentRef))
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError(fmt.Sprintf("Agent ref is invalid, please check the agent ref %s", *sessionRequest.AgentRef), err))
 		return
@@ -142,7 +227,7 @@ func (h *SessionsHandler) HandleCreateSession(w ErrorResponseWriter, r *http.Req
 		"agentRef", sessionRequest.AgentRef,
 		"name", sessionRequest.Name)
 
-	if err := h.DatabaseService.StoreSession(session); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreSession(session); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to create session", err))
 		return
 	}
@@ -155,6 +240,8 @@ func (h *SessionsHandler) HandleCreateSession(w ErrorResponseWriter, r *http.Req
 type SessionResponse struct {
 	Session *database.Session `json:"session"`
 	Events  []*database.Event `json:"events"`
+	// Role is the role of the requesting user in the session
+	Role string `json:"role,omitempty"`
 }
 
 // HandleGetSession handles GET /api/sessions/{session_id} requests using database
@@ -168,6 +255,11 @@ func (h *SessionsHandler) HandleGetSession(w ErrorResponseWriter, r *http.Reques
 	}
 	log = log.WithValues("session_id", sessionID)
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := getUserIDOrAgentUser(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -176,7 +268,7 @@ func (h *SessionsHandler) HandleGetSession(w ErrorResponseWriter, r *http.Reques
 	log = log.WithValues("userID", userID)
 
 	log.V(1).Info("Getting session from database")
-	session, err := h.DatabaseService.GetSession(sessionID, userID)
+	session, role, err := database.GetSessionForUser(h.DatabaseService.WithContext(r.Context()), sessionID, userID)
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
 		return
@@ -204,7 +296,7 @@ func (h *SessionsHandler) HandleGetSession(w ErrorResponseWriter, r *http.Reques
 		}
 	}
 
-	events, err := h.DatabaseService.ListEventsForSession(sessionID, userID, queryOptions)
+	events, err := h.DatabaseService.WithContext(r.Context()).ListEventsForSession(sessionID, session.UserID, queryOptions)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to get events for session", err))
 		return
@@ -214,6 +306,7 @@ func (h *SessionsHandler) HandleGetSession(w ErrorResponseWriter, r *http.Reques
 	data := api.NewResponse(SessionResponse{
 		Session: session,
 		Events:  events,
+		Role:    role,
 	}, "Successfully retrieved session", false)
 	RespondWithJSON(w, http.StatusOK, data)
 }
@@ -239,19 +332,24 @@ func (h *SessionsHandler) HandleUpdateSession(w ErrorResponseWriter, r *http.Req
 	}
 	log = log.WithValues("agentRef", *sessionRequest.AgentRef)
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: *sessionRequest.Name}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
 		return
 	}
 	// Get existing session
-	session, err := h.DatabaseService.GetSession(*sessionRequest.Name, userID)
+	session, err := h.DatabaseService.WithContext(r.Context()).GetSession(*sessionRequest.Name, userID)
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
 		return
 	}
 
-	agent, err := h.DatabaseService.GetAgent(utils.ConvertToPythonIdentifier(*sessionRequest.AgentRef))
+	agent, err := h.DatabaseService.WithContext(r.Context()).GetAgent(utils.ConvertToPythonIdentifier(*sessionRequest.AgentRef))
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Agent not found", err))
 		return
@@ -260,7 +358,7 @@ func (h *SessionsHandler) HandleUpdateSession(w ErrorResponseWriter, r *http.Req
 	// Update fields
 	session.AgentID = &agent.ID
 
-	if err := h.DatabaseService.StoreSession(session); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreSession(session); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to update session", err))
 		return
 	}
@@ -288,7 +386,12 @@ func (h *SessionsHandler) HandleDeleteSession(w ErrorResponseWriter, r *http.Req
 	}
 	log = log.WithValues("session_id", sessionID)
 
-	if err := h.DatabaseService.DeleteSession(sessionID, userID); err != nil {
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	if err := h.DatabaseService.WithContext(r.Context()).DeleteSession(sessionID, userID); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to delete session", err))
 		return
 	}
@@ -309,6 +412,11 @@ func (h *SessionsHandler) HandleListTasksForSession(w ErrorResponseWriter, r *ht
 	}
 	log = log.WithValues("session_id", sessionID)
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -317,14 +425,14 @@ func (h *SessionsHandler) HandleListTasksForSession(w ErrorResponseWriter, r *ht
 	log = log.WithValues("userID", userID)
 
 	// Verify session exists
-	_, err = h.DatabaseService.GetSession(sessionID, userID)
+	_, _, err = database.GetSessionForUser(h.DatabaseService.WithContext(r.Context()), sessionID, userID)
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Session not found for given ID", err))
 		return
 	}
 
 	log.V(1).Info("Getting session tasks from database")
-	tasks, err := h.DatabaseService.ListTasksForSession(sessionID)
+	tasks, err := h.DatabaseService.WithContext(r.Context()).ListTasksForSession(sessionID)
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to get session runs", err))
 		return
@@ -335,8 +443,12 @@ func (h *SessionsHandler) HandleListTasksForSession(w ErrorResponseWriter, r *ht
 	RespondWithJSON(w, http.StatusOK, data)
 }
 
-func (h *SessionsHandler) HandleAddEventToSession(w ErrorResponseWriter, r *http.Request) {
-	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "add-event")
+// HandleExportSession handles GET /api/sessions/{session_id}/export requests. It
+// renders the session with its tasks and events as json (the default), jsonl or
+// markdown, as set by format.
+func (h *SessionsHandler) HandleExportSession(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "export")
+
 	sessionID, err := GetPathParam(r, "session_id")
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get session ID from path", err))
@@ -344,18 +456,324 @@ func (h *SessionsHandler) HandleAddEventToSession(w ErrorResponseWriter, r *http
 	}
 	log = log.WithValues("session_id", sessionID)
 
-	principal, err := GetPrincipal(r)
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	userID, err := getUserIDOrAgentUser(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
 		return
 	}
-	userID, err := getUserID(r)
+	log = log.WithValues("userID", userID)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = api.SessionExportFormatJSON
+	}
+	contentType, extension, err := sessionexport.ContentType(format)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid format", err))
+		return
+	}
+	log = log.WithValues("format", format)
+
+	db := h.DatabaseService.WithContext(r.Context())
+	session, _, err := database.GetSessionForUser(db, sessionID, userID)
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
+		return
+	}
+	tasks, err := db.ListTasksForSession(sessionID)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to get tasks for session", err))
+		return
+	}
+	events, err := db.ListEventsForSession(sessionID, session.UserID, database.QueryOptions{})
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to get events for session", err))
+		return
+	}
+
+	var body bytes.Buffer
+	if err := sessionexport.Write(&body, sessionexport.New(session, tasks, events, time.Now()), format); err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to export session", err))
+		return
+	}
+
+	log.Info("Successfully exported session", "tasks", len(tasks), "events", len(events))
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+sessionID+"."+extension))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes()) //nolint:errcheck
+}
+
+// HandleImportSession handles POST /api/sessions/import requests. It recreates
+// a session, its tasks and its events from their json export, as a session of
+// the requesting user. The session, its tasks and its events are stored with
+// new IDs.
+func (h *SessionsHandler) HandleImportSession(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "import")
+
+	var export api.SessionExport
+	if err := DecodeJSONBody(r, &export); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	if err := sessionexport.Validate(&export); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid session export", err))
+		return
+	}
+	// Imported sessions and their tasks get new IDs, as those of the export may
+	// be IDs of sessions and tasks of other users.
+	sessionID := protocol.GenerateContextID()
+	log = log.WithValues("session_id", sessionID)
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
 		return
 	}
 	log = log.WithValues("userID", userID)
 
+	db := h.DatabaseService.WithContext(r.Context())
+	if export.Session.AgentID == nil {
+		w.RespondWithError(errors.NewBadRequestError("agent_id of the session is required", nil))
+		return
+	}
+	if _, err := db.GetAgent(*export.Session.AgentID); err != nil {
+		w.RespondWithError(errors.NewBadRequestError(fmt.Sprintf("Agent %s of the session doesn't exist", *export.Session.AgentID), err))
+		return
+	}
+
+	session := &database.Session{
+		ID:        sessionID,
+		Name:      export.Session.Name,
+		UserID:    userID,
+		AgentID:   export.Session.AgentID,
+		CreatedAt: export.Session.CreatedAt,
+	}
+	taskIDs := make(map[string]string, len(export.Tasks))
+	for _, task := range export.Tasks {
+		taskIDs[task.ID] = protocol.GenerateTaskID()
+	}
+	// Events are stored with new IDs, as event IDs are only unique for a user,
+	// so those of the export may be IDs of events of other sessions.
+	events := make([]*database.Event, 0, len(export.Events))
+	for _, event := range export.Events {
+		id := uuid.NewString()
+		events = append(events, &database.Event{
+			ID:        id,
+			SessionID: session.ID,
+			UserID:    userID,
+			AuthorID:  copiedEventAuthor(event.AuthorID, export.Session.UserID, userID),
+			CreatedAt: event.CreatedAt,
+			Data:      forkEventData(sessionexport.EventData(event), event.ID, id),
+		})
+	}
+	err = db.Transaction(func(tx database.Client) error {
+		if err := tx.StoreSession(session); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		for _, task := range export.Tasks {
+			importedTask(task, taskIDs[task.ID], session.ID)
+			if err := tx.StoreTask(task); err != nil {
+				return fmt.Errorf("failed to store task %s: %w", task.ID, err)
+			}
+		}
+		if err := tx.StoreEvents(events...); err != nil {
+			return fmt.Errorf("failed to store events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to import session", err))
+		return
+	}
+
+	log.Info("Successfully imported session", "tasks", len(export.Tasks), "events", len(events))
+	data := api.NewResponse(session, "Successfully imported session", false)
+	RespondWithJSON(w, http.StatusCreated, data)
+}
+
+// importedTask moves an exported task to the imported session, giving it its
+// new ID.
+func importedTask(task *protocol.Task, id, sessionID string) {
+	task.ID = id
+	task.ContextID = sessionID
+	for i := range task.History {
+		if task.History[i].TaskID != nil {
+			task.History[i].TaskID = &id
+		}
+		if task.History[i].ContextID != nil {
+			task.History[i].ContextID = &sessionID
+		}
+	}
+}
+
+// HandleForkSession handles POST /api/sessions/{session_id}/fork requests. It
+// creates a session for the same agent with copies of the events of the session
+// up to and including event_id, so the conversation can be continued from that
+// point without changing the original.
+func (h *SessionsHandler) HandleForkSession(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "fork")
+
+	sessionID, err := GetPathParam(r, "session_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get session ID from path", err))
+		return
+	}
+	log = log.WithValues("session_id", sessionID)
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+	log = log.WithValues("userID", userID)
+
+	var forkRequest api.SessionForkRequest
+	if err := DecodeJSONBody(r, &forkRequest); err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid request body", err))
+		return
+	}
+	if forkRequest.EventID == "" {
+		w.RespondWithError(errors.NewBadRequestError("event_id is required", nil))
+		return
+	}
+	log = log.WithValues("event_id", forkRequest.EventID)
+
+	db := h.DatabaseService.WithContext(r.Context())
+	// Users the session is shared with fork it into a session of their own
+	parent, _, err := database.GetSessionForUser(db, sessionID, userID)
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
+		return
+	}
+	events, err := db.ListEventsForSession(sessionID, parent.UserID, database.QueryOptions{})
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to get events for session", err))
+		return
+	}
+	slices.SortStableFunc(events, func(a, b *database.Event) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	last := slices.IndexFunc(events, func(e *database.Event) bool { return e.ID == forkRequest.EventID })
+	if last < 0 {
+		w.RespondWithError(errors.NewNotFoundError("Event not found in session", nil))
+		return
+	}
+
+	name := forkRequest.Name
+	if name == nil && parent.Name != nil {
+		name = ptr.To(*parent.Name + " (fork)")
+	}
+	fork := &database.Session{
+		ID:              protocol.GenerateContextID(),
+		Name:            name,
+		UserID:          userID,
+		AgentID:         parent.AgentID,
+		ParentSessionID: &parent.ID,
+		ParentEventID:   &forkRequest.EventID,
+	}
+	eventIDs := make(map[string]string, last+1)
+	copies := make([]*database.Event, 0, last+1)
+	for _, event := range events[:last+1] {
+		id := uuid.NewString()
+		eventIDs[event.ID] = id
+		copies = append(copies, &database.Event{
+			ID:        id,
+			SessionID: fork.ID,
+			UserID:    userID,
+			AuthorID:  copiedEventAuthor(event.AuthorID, parent.UserID, userID),
+			// Copies keep the creation times of the events, which order them
+			CreatedAt: event.CreatedAt,
+			Data:      forkEventData(event.Data, event.ID, id),
+		})
+	}
+	err = db.Transaction(func(tx database.Client) error {
+		if err := tx.StoreSession(fork); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		if err := tx.StoreEvents(copies...); err != nil {
+			return fmt.Errorf("failed to copy events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to fork session", err))
+		return
+	}
+
+	log.Info("Successfully forked session", "forkID", fork.ID, "events", len(copies))
+	data := api.NewResponse(&api.SessionFork{Session: fork, EventIDs: eventIDs}, "Successfully forked session", false)
+	RespondWithJSON(w, http.StatusCreated, data)
+}
+
+// copiedEventAuthor returns the author of a copy of an event of owner's
+// session stored in userID's session, so copies stay attributed to the users
+// who added the events.
+func copiedEventAuthor(author, owner, userID string) string {
+	if author == "" {
+		author = owner
+	}
+	if author == userID {
+		return ""
+	}
+	return author
+}
+
+// forkEventData returns the data of a copy of an event. ADK events carry their
+// ID, which is replaced with the ID of the copy.
+func forkEventData(data, id, copyID string) string {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return data
+	}
+	var dataID string
+	if err := json.Unmarshal(event["id"], &dataID); err != nil || dataID != id {
+		return data
+	}
+	event["id"], _ = json.Marshal(copyID)
+	copied, err := json.Marshal(event)
+	if err != nil {
+		return data
+	}
+	return string(copied)
+}
+
+func (h *SessionsHandler) HandleAddEventToSession(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("sessions-handler").WithValues("operation", "add-event")
+	sessionID, err := GetPathParam(r, "session_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get session ID from path", err))
+		return
+	}
+	log = log.WithValues("session_id", sessionID)
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Session", Name: sessionID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	// The user of agents acting on behalf of users is resolved by the authenticator
+	principal, err := GetPrincipal(r)
+	if err != nil || principal.User.ID == "" {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+	userID := principal.User.ID
+	log = log.WithValues("userID", userID)
+
 	var eventData struct {
 		ID   string `json:"id"`
 		Data string `json:"data"`
@@ -366,27 +784,45 @@ func (h *SessionsHandler) HandleAddEventToSession(w ErrorResponseWriter, r *http
 	}
 
 	// Get session to verify it exists
-	session, err := h.DatabaseService.GetSession(sessionID, userID)
+	session, role, err := database.GetSessionForUser(h.DatabaseService.WithContext(r.Context()), sessionID, userID)
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
 		return
 	}
+	if role == database.SessionRoleViewer {
+		w.RespondWithError(errors.NewForbiddenError("Viewers can't add events to a session", nil))
+		return
+	}
 
 	if session.AgentID != nil && *session.AgentID != utils.ConvertToPythonIdentifier(principal.Agent.ID) {
 		w.RespondWithError(errors.NewForbiddenError("Session does not belong to this agent", nil))
 		return
 	}
+	// Events of shared sessions belong to the owner, and record the
+	// collaborator who added them
 	event := &database.Event{
 		ID:        eventData.ID,
 		SessionID: sessionID,
 		Data:      eventData.Data,
-		UserID:    userID,
+		UserID:    session.UserID,
+	}
+	if userID != session.UserID {
+		event.AuthorID = userID
 	}
-	if err := h.DatabaseService.StoreEvents(event); err != nil {
+	if err := h.DatabaseService.WithContext(r.Context()).StoreEvents(event); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to store event", err))
 		return
 	}
 
+	// Only agents report usage, so the usage in events posted by users isn't
+	// accounted. The event is stored, so failing to account for it shouldn't
+	// fail the request.
+	if h.Usage != nil && principal.Agent.ID != "" {
+		if err := h.Usage.RecordSessionEvent(r.Context(), principal.Agent.ID, event); err != nil {
+			log.Error(err, "Failed to record token usage of event")
+		}
+	}
+
 	log.Info("Successfully added event to session")
 	data := api.NewResponse(event, "Event added to session successfully", false)
 	RespondWithJSON(w, http.StatusCreated, data)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -14,11 +15,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 
 	"github.com/kagent-dev/kagent/go/internal/database"
 	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
 	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
+	"github.com/kagent-dev/kagent/go/internal/usage"
 	"github.com/kagent-dev/kagent/go/internal/utils"
 	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
@@ -49,8 +52,9 @@ func TestSessionsHandler(t *testing.T) {
 			KubeClient:         kubeClient,
 			DatabaseService:    dbClient,
 			DefaultModelConfig: types.NamespacedName{Namespace: "default", Name: "default"},
+			Authorizer:         &authimpl.NoopAuthorizer{},
 		}
-		handler := handlers.NewSessionsHandler(base)
+		handler := handlers.NewSessionsHandler(base, usage.NewRecorder(dbClient, kubeClient))
 		responseRecorder := newMockErrorResponseWriter()
 		return handler, dbClient.(*database_fake.InMemoryFakeClient), responseRecorder
 	}
@@ -479,4 +483,517 @@ func TestSessionsHandler(t *testing.T) {
 			assert.NotNil(t, responseRecorder.errorReceived)
 		})
 	})
+
+	t.Run("HandleAddEventToSession", func(t *testing.T) {
+		addEvent := func(handler *handlers.SessionsHandler, responseRecorder *mockErrorResponseWriter, sessionID, eventID, data string) {
+			body, _ := json.Marshal(map[string]string{"id": eventID, "data": data})
+			req := httptest.NewRequest("POST", "/api/sessions/"+sessionID+"/events?user_id=test-user", bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{"session_id": sessionID})
+			req = req.WithContext(auth.AuthSessionTo(req.Context(), &authimpl.SimpleSession{
+				P: auth.Principal{
+					User:  auth.User{ID: "test-user"},
+					Agent: auth.Agent{ID: "default/test-agent"},
+				},
+			}))
+			handler.HandleAddEventToSession(responseRecorder, req)
+		}
+
+		t.Run("RecordsUsage", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestSession(dbClient, "test-session", "test-user", utils.ConvertToPythonIdentifier("default/test-agent"))
+
+			addEvent(handler, responseRecorder, "test-session", "event-1",
+				`{"id": "event-1", "author": "test_agent", "model_version": "gpt-4o-2024-08-06", "usage_metadata": {"prompt_token_count": 100, "candidates_token_count": 20, "total_token_count": 120}}`)
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+
+			aggregates, err := dbClient.SummarizeUsage(database.UsageGroupByNone, database.UsageFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, []database.UsageAggregate{{
+				Model:            "gpt-4o-2024-08-06",
+				Calls:            1,
+				PromptTokens:     100,
+				CompletionTokens: 20,
+				TotalTokens:      120,
+			}}, aggregates)
+
+			filtered, err := dbClient.SummarizeUsage(database.UsageGroupByAgent, database.UsageFilter{AgentID: "default/test-agent", UserID: "test-user", SessionID: "test-session"})
+			require.NoError(t, err)
+			assert.Len(t, filtered, 1)
+		})
+
+		t.Run("IgnoresUsageOfUsers", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			require.NoError(t, dbClient.StoreSession(&database.Session{ID: "test-session", UserID: "test-user"}))
+
+			body, _ := json.Marshal(map[string]string{"id": "event-1", "data": `{"id": "event-1", "usage_metadata": {"prompt_token_count": 1000000, "total_token_count": 1000000}}`})
+			req := httptest.NewRequest("POST", "/api/sessions/test-session/events", bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{"session_id": "test-session"})
+			req = setUser(req, "test-user")
+			handler.HandleAddEventToSession(responseRecorder, req)
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+
+			aggregates, err := dbClient.SummarizeUsage(database.UsageGroupByNone, database.UsageFilter{})
+			require.NoError(t, err)
+			assert.Empty(t, aggregates, "only agents report usage")
+		})
+
+		t.Run("EventWithoutUsage", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestSession(dbClient, "test-session", "test-user", utils.ConvertToPythonIdentifier("default/test-agent"))
+
+			addEvent(handler, responseRecorder, "test-session", "event-1", `{"id": "event-1", "author": "user"}`)
+			require.Equal(t, http.StatusCreated, responseRecorder.Code)
+
+			aggregates, err := dbClient.SummarizeUsage(database.UsageGroupByNone, database.UsageFilter{})
+			require.NoError(t, err)
+			assert.Empty(t, aggregates)
+		})
+	})
+
+	t.Run("HandleSearchSessions", func(t *testing.T) {
+		search := func(handler *handlers.SessionsHandler, responseRecorder *mockErrorResponseWriter, query string) {
+			req := httptest.NewRequest("GET", "/api/sessions/search?"+query, nil)
+			req = setUser(req, "test-user")
+			handler.HandleSearchSessions(responseRecorder, req)
+		}
+
+		t.Run("Success", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestSession(dbClient, "ingress-session", "test-user", utils.ConvertToPythonIdentifier("default/k8s-agent"))
+			createTestSession(dbClient, "other-session", "test-user", utils.ConvertToPythonIdentifier("default/helm-agent"))
+			require.NoError(t, dbClient.StoreEvents(
+				&database.Event{ID: "event-1", SessionID: "ingress-session", UserID: "test-user", Data: `{"content":{"parts":[{"text":"Fixed the ingress TLS secret"}]}}`},
+				&database.Event{ID: "event-2", SessionID: "other-session", UserID: "test-user", Data: `{"content":{"parts":[{"text":"The ingress is fine"}]}}`},
+			))
+
+			search(handler, responseRecorder, "q=ingress&agent=default/k8s-agent")
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+			var response api.StandardResponse[[]api.SessionSearchResult]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			require.Len(t, response.Data, 2)
+			for _, result := range response.Data {
+				assert.Equal(t, "ingress-session", result.SessionID)
+			}
+		})
+
+		t.Run("InvalidQuery", func(t *testing.T) {
+			for _, query := range []string{"", "q=%20", "q=ingress&from=yesterday", "q=ingress&limit=-1"} {
+				handler, _, responseRecorder := setupHandler()
+				search(handler, responseRecorder, query)
+				assert.Equal(t, http.StatusBadRequest, responseRecorder.Code, query)
+			}
+		})
+	})
+
+	t.Run("HandleExportSession", func(t *testing.T) {
+		export := func(handler *handlers.SessionsHandler, responseRecorder *mockErrorResponseWriter, sessionID, format string) {
+			req := httptest.NewRequest("GET", "/api/sessions/"+sessionID+"/export?format="+format, nil)
+			req = mux.SetURLVars(req, map[string]string{"session_id": sessionID})
+			req = setUser(req, "test-user")
+			handler.HandleExportSession(responseRecorder, req)
+		}
+
+		t.Run("Success", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestSession(dbClient, "session-1", "test-user", "1")
+			require.NoError(t, dbClient.StoreTask(&protocol.Task{ID: "task-1", ContextID: "session-1"}))
+			require.NoError(t, dbClient.StoreEvents(
+				&database.Event{ID: "event-1", SessionID: "session-1", UserID: "test-user", Data: `{"kind":"message","role":"user","parts":[{"kind":"text","text":"hello"}]}`},
+			))
+
+			export(handler, responseRecorder, "session-1", "")
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			assert.Equal(t, "application/json", responseRecorder.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="session-session-1.json"`, responseRecorder.Header().Get("Content-Disposition"))
+
+			var exported api.SessionExport
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &exported))
+			assert.Equal(t, "session-1", exported.Session.ID)
+			require.Len(t, exported.Tasks, 1)
+			assert.Equal(t, "task-1", exported.Tasks[0].ID)
+			require.Len(t, exported.Events, 1)
+			assert.Equal(t, "event-1", exported.Events[0].ID)
+
+			handler, dbClient, responseRecorder = setupHandler()
+			createTestSession(dbClient, "session-1", "test-user", "1")
+			export(handler, responseRecorder, "session-1", api.SessionExportFormatMarkdown)
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			assert.Equal(t, "text/markdown; charset=utf-8", responseRecorder.Header().Get("Content-Type"))
+			assert.Contains(t, responseRecorder.Body.String(), "# session-1\n")
+		})
+
+		t.Run("InvalidFormat", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestSession(dbClient, "session-1", "test-user", "1")
+			export(handler, responseRecorder, "session-1", "yaml")
+			assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+		})
+
+		t.Run("SessionNotFound", func(t *testing.T) {
+			handler, _, responseRecorder := setupHandler()
+			export(handler, responseRecorder, "missing", api.SessionExportFormatJSON)
+			assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+		})
+	})
+
+	t.Run("HandleImportSession", func(t *testing.T) {
+		importSession := func(handler *handlers.SessionsHandler, responseRecorder *mockErrorResponseWriter, export *api.SessionExport) string {
+			body, _ := json.Marshal(export)
+			req := httptest.NewRequest("POST", "/api/sessions/import", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setUser(req, "importing-user")
+			handler.HandleImportSession(responseRecorder, req)
+
+			var response api.StandardResponse[*database.Session]
+			if responseRecorder.Code != http.StatusCreated || json.Unmarshal(responseRecorder.Body.Bytes(), &response) != nil || response.Data == nil {
+				return ""
+			}
+			return response.Data.ID
+		}
+		newExport := func() *api.SessionExport {
+			return &api.SessionExport{
+				Version: api.SessionExportVersion,
+				Session: api.Session{ID: "session-1", Name: ptr.To("Imported"), UserID: "exporting-user", AgentID: ptr.To("default__NS__test_agent")},
+				Tasks: []*protocol.Task{{ID: "task-1", ContextID: "other-session", History: []protocol.Message{
+					{Kind: protocol.KindMessage, MessageID: "message-1", Role: protocol.MessageRoleUser, TaskID: ptr.To("task-1"), ContextID: ptr.To("other-session")},
+				}}},
+				Events: []api.ExportedEvent{
+					{ID: "event-1", Data: json.RawMessage(`{"kind":"message","role":"user","parts":[{"kind":"text","text":"hello"}]}`)},
+					{ID: "event-2", Data: json.RawMessage(`"not json"`)},
+				},
+			}
+		}
+
+		t.Run("Success", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestAgent(dbClient, "default__NS__test_agent")
+
+			sessionID := importSession(handler, responseRecorder, newExport())
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+			assert.NotEqual(t, "session-1", sessionID, "imported sessions get a new ID")
+
+			session, err := dbClient.GetSession(sessionID, "importing-user")
+			require.NoError(t, err)
+			assert.Equal(t, "Imported", *session.Name)
+			tasks, err := dbClient.ListTasksForSession(sessionID)
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			assert.NotEqual(t, "task-1", tasks[0].ID, "imported tasks get a new ID")
+			assert.Equal(t, sessionID, tasks[0].ContextID)
+			require.Len(t, tasks[0].History, 1)
+			assert.Equal(t, tasks[0].ID, *tasks[0].History[0].TaskID)
+			assert.Equal(t, sessionID, *tasks[0].History[0].ContextID)
+			events, err := dbClient.ListEventsForSession(sessionID, "importing-user", database.QueryOptions{})
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, "importing-user", events[0].UserID)
+			assert.Equal(t, "not json", events[1].Data)
+
+			responseRecorder = newMockErrorResponseWriter()
+			again := importSession(handler, responseRecorder, newExport())
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+			assert.NotEqual(t, sessionID, again, "each import creates a session")
+		})
+
+		t.Run("CollidingEventIDs", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestAgent(dbClient, "default__NS__test_agent")
+			createTestSession(dbClient, "other-session", "importing-user", "1")
+			require.NoError(t, dbClient.StoreEvents(&database.Event{ID: "event-1", SessionID: "other-session", UserID: "importing-user", Data: "kept"}))
+
+			export := newExport()
+			export.Events[0].Data = json.RawMessage(`{"id":"event-1","author":"user"}`)
+			sessionID := importSession(handler, responseRecorder, export)
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+
+			others, err := dbClient.ListEventsForSession("other-session", "importing-user", database.QueryOptions{})
+			require.NoError(t, err)
+			require.Len(t, others, 1)
+			assert.Equal(t, "kept", others[0].Data)
+
+			events, err := dbClient.ListEventsForSession(sessionID, "importing-user", database.QueryOptions{})
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			for _, event := range events {
+				assert.NotEqual(t, "event-1", event.ID)
+				if event.Data != "not json" {
+					assert.JSONEq(t, `{"id":"`+event.ID+`","author":"user"}`, event.Data, "the ADK event carries its new ID")
+				}
+			}
+		})
+
+		t.Run("CollidingIDs", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			createTestAgent(dbClient, "default__NS__test_agent")
+			createTestSession(dbClient, "session-1", "other-user", "1")
+			require.NoError(t, dbClient.StoreTask(&protocol.Task{ID: "task-1", ContextID: "session-1"}))
+
+			importSession(handler, responseRecorder, newExport())
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+			_, err := dbClient.GetSession("session-1", "importing-user")
+			assert.Error(t, err, "the session of another user isn't shadowed")
+			task, err := dbClient.GetTask("task-1")
+			require.NoError(t, err)
+			assert.Equal(t, "session-1", task.ContextID, "the task of another user isn't overwritten")
+		})
+
+		t.Run("InvalidExport", func(t *testing.T) {
+			handler, dbClient, _ := setupHandler()
+			createTestAgent(dbClient, "default__NS__test_agent")
+			for name, modify := range map[string]func(*api.SessionExport){
+				"version":       func(e *api.SessionExport) { e.Version = 0 },
+				"missing agent": func(e *api.SessionExport) { e.Session.AgentID = nil },
+				"unknown agent": func(e *api.SessionExport) { e.Session.AgentID = ptr.To("default__NS__other_agent") },
+				"event id":      func(e *api.SessionExport) { e.Events[0].ID = "" },
+				"duplicate task": func(e *api.SessionExport) {
+					e.Tasks = append(e.Tasks, &protocol.Task{ID: "task-1"})
+				},
+				"duplicate event": func(e *api.SessionExport) { e.Events[1].ID = "event-1" },
+			} {
+				export := newExport()
+				modify(export)
+				responseRecorder := newMockErrorResponseWriter()
+				importSession(handler, responseRecorder, export)
+				assert.Equal(t, http.StatusBadRequest, responseRecorder.Code, name)
+			}
+		})
+	})
+
+	t.Run("HandleForkSession", func(t *testing.T) {
+		fork := func(handler *handlers.SessionsHandler, responseRecorder *mockErrorResponseWriter, sessionID string, request any) {
+			body, _ := json.Marshal(request)
+			req := httptest.NewRequest("POST", "/api/sessions/"+sessionID+"/fork", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"session_id": sessionID})
+			req = setUser(req, "test-user")
+			handler.HandleForkSession(responseRecorder, req)
+		}
+		setupSession := func(dbClient *database_fake.InMemoryFakeClient) {
+			createTestSession(dbClient, "session-1", "test-user", "1")
+			start := time.Now().Add(-time.Hour)
+			require.NoError(t, dbClient.StoreEvents(
+				&database.Event{ID: "event-1", SessionID: "session-1", UserID: "test-user", CreatedAt: start, Data: `{"id":"event-1","author":"user","content":{"parts":[{"text":"why is my pod crashing?"}]}}`},
+				&database.Event{ID: "event-2", SessionID: "session-1", UserID: "test-user", CreatedAt: start.Add(time.Second), Data: `{"kind":"message","role":"agent","parts":[{"kind":"text","text":"let me delete it"}]}`},
+				&database.Event{ID: "event-3", SessionID: "session-1", UserID: "test-user", CreatedAt: start.Add(2 * time.Second), Data: `{"id":"event-3","author":"agent"}`},
+			))
+		}
+
+		t.Run("Success", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			setupSession(dbClient)
+
+			fork(handler, responseRecorder, "session-1", api.SessionForkRequest{EventID: "event-2"})
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+
+			var response api.StandardResponse[api.SessionFork]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			forked := response.Data.Session
+			assert.NotEqual(t, "session-1", forked.ID)
+			assert.Equal(t, "session-1 (fork)", *forked.Name)
+			assert.Equal(t, "1", *forked.AgentID)
+			assert.Equal(t, "session-1", *forked.ParentSessionID)
+			assert.Equal(t, "event-2", *forked.ParentEventID)
+			require.Len(t, response.Data.EventIDs, 2)
+
+			stored, err := dbClient.GetSession(forked.ID, "test-user")
+			require.NoError(t, err)
+			assert.Equal(t, "session-1", *stored.ParentSessionID)
+
+			events, err := dbClient.ListEventsForSession(forked.ID, "test-user", database.QueryOptions{})
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, response.Data.EventIDs["event-1"], events[0].ID)
+			assert.JSONEq(t, `{"id":"`+events[0].ID+`","author":"user","content":{"parts":[{"text":"why is my pod crashing?"}]}}`, events[0].Data, "ADK events carry the ID of the copy")
+			assert.Equal(t, response.Data.EventIDs["event-2"], events[1].ID)
+			assert.Equal(t, `{"kind":"message","role":"agent","parts":[{"kind":"text","text":"let me delete it"}]}`, events[1].Data)
+			assert.True(t, events[0].CreatedAt.Before(events[1].CreatedAt))
+
+			original, err := dbClient.ListEventsForSession("session-1", "test-user", database.QueryOptions{})
+			require.NoError(t, err)
+			assert.Len(t, original, 3, "the original session is unchanged")
+		})
+
+		t.Run("Name", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			setupSession(dbClient)
+
+			fork(handler, responseRecorder, "session-1", api.SessionForkRequest{EventID: "event-1", Name: ptr.To("retry")})
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+			var response api.StandardResponse[api.SessionFork]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			assert.Equal(t, "retry", *response.Data.Session.Name)
+			assert.Len(t, response.Data.EventIDs, 1)
+		})
+
+		t.Run("MissingEventID", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			setupSession(dbClient)
+			fork(handler, responseRecorder, "session-1", api.SessionForkRequest{})
+			assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			handler, dbClient, responseRecorder := setupHandler()
+			setupSession(dbClient)
+			fork(handler, responseRecorder, "session-1", api.SessionForkRequest{EventID: "other-event"})
+			assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+
+			responseRecorder = newMockErrorResponseWriter()
+			fork(handler, responseRecorder, "missing", api.SessionForkRequest{EventID: "event-1"})
+			assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+		})
+	})
+
+	t.Run("SessionSharing", func(t *testing.T) {
+		agentID := utils.ConvertToPythonIdentifier("default/test-agent")
+		sessionRequest := func(method, path, userID string, vars map[string]string, body any) *http.Request {
+			var reader *bytes.Buffer
+			if body != nil {
+				data, _ := json.Marshal(body)
+				reader = bytes.NewBuffer(data)
+			} else {
+				reader = &bytes.Buffer{}
+			}
+			req := httptest.NewRequest(method, path, reader)
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, vars)
+			return setUser(req, userID)
+		}
+		getSession := func(handler *handlers.SessionsHandler, userID string) *mockErrorResponseWriter {
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleGetSession(responseRecorder, sessionRequest("GET", "/api/sessions/session-1", userID, map[string]string{"session_id": "session-1"}, nil))
+			return responseRecorder
+		}
+		// addEvent adds an event as the agent does when userID messages it in the session
+		addEvent := func(handler *handlers.SessionsHandler, userID, eventID string) *mockErrorResponseWriter {
+			body, _ := json.Marshal(map[string]string{"id": eventID, "data": `{"author":"user"}`})
+			req := httptest.NewRequest("POST", "/api/sessions/session-1/events", bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{"session_id": "session-1"})
+			req = req.WithContext(auth.AuthSessionTo(req.Context(), &authimpl.SimpleSession{
+				P: auth.Principal{User: auth.User{ID: userID}, Agent: auth.Agent{ID: "default/test-agent"}},
+			}))
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleAddEventToSession(responseRecorder, req)
+			return responseRecorder
+		}
+		share := func(handler *handlers.SessionsHandler, userID string, request api.SessionShareRequest) *mockErrorResponseWriter {
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleShareSession(responseRecorder, sessionRequest("PUT", "/api/sessions/session-1/shares", userID, map[string]string{"session_id": "session-1"}, request))
+			return responseRecorder
+		}
+		createLink := func(handler *handlers.SessionsHandler, request api.SessionShareLinkRequest) *mockErrorResponseWriter {
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleCreateSessionShareLink(responseRecorder, sessionRequest("POST", "/api/sessions/session-1/share-links", "owner", map[string]string{"session_id": "session-1"}, request))
+			return responseRecorder
+		}
+		redeem := func(handler *handlers.SessionsHandler, userID, token string) *mockErrorResponseWriter {
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleRedeemSessionShareLink(responseRecorder, sessionRequest("POST", "/api/sessions/shared/"+token, userID, map[string]string{"token": token}, nil))
+			return responseRecorder
+		}
+
+		t.Run("Viewer", func(t *testing.T) {
+			handler, dbClient, _ := setupHandler()
+			createTestSession(dbClient, "session-1", "owner", agentID)
+
+			assert.Equal(t, http.StatusNotFound, getSession(handler, "viewer").Code)
+
+			responseRecorder := share(handler, "owner", api.SessionShareRequest{UserID: "viewer", Role: database.SessionRoleViewer})
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+			responseRecorder = getSession(handler, "viewer")
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			var response api.StandardResponse[handlers.SessionResponse]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			assert.Equal(t, "owner", response.Data.Session.UserID)
+			assert.Equal(t, database.SessionRoleViewer, response.Data.Role)
+
+			assert.Equal(t, http.StatusForbidden, addEvent(handler, "viewer", "event-1").Code)
+
+			responseRecorder = newMockErrorResponseWriter()
+			handler.HandleUnshareSession(responseRecorder, sessionRequest("DELETE", "/api/sessions/session-1/shares/viewer", "owner", map[string]string{"session_id": "session-1", "user_id": "viewer"}, nil))
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			assert.Equal(t, http.StatusNotFound, getSession(handler, "viewer").Code)
+		})
+
+		t.Run("CollaboratorLink", func(t *testing.T) {
+			handler, dbClient, _ := setupHandler()
+			createTestSession(dbClient, "session-1", "owner", agentID)
+
+			responseRecorder := createLink(handler, api.SessionShareLinkRequest{Role: database.SessionRoleCollaborator, ExpiresAt: ptr.To(time.Now().Add(time.Hour))})
+			require.Equal(t, http.StatusCreated, responseRecorder.Code, responseRecorder.Body.String())
+			var link api.StandardResponse[api.CreatedSessionShareLink]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &link))
+			require.NotEmpty(t, link.Data.Token)
+			assert.NotContains(t, responseRecorder.Body.String(), "token_hash")
+
+			responseRecorder = redeem(handler, "collaborator", link.Data.Token)
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+			require.Equal(t, http.StatusCreated, addEvent(handler, "collaborator", "event-1").Code)
+			require.Equal(t, http.StatusCreated, addEvent(handler, "owner", "event-2").Code)
+			events, err := dbClient.ListEventsForSession("session-1", "owner", database.QueryOptions{})
+			require.NoError(t, err)
+			authors := map[string]string{}
+			for _, event := range events {
+				assert.Equal(t, "owner", event.UserID, "events of shared sessions belong to the owner")
+				authors[event.ID] = event.AuthorID
+			}
+			assert.Equal(t, map[string]string{"event-1": "collaborator", "event-2": ""}, authors)
+
+			responseRecorder = newMockErrorResponseWriter()
+			handler.HandleListSharedSessions(responseRecorder, sessionRequest("GET", "/api/sessions/shared", "collaborator", nil, nil))
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			var shared api.StandardResponse[[]*api.Session]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &shared))
+			require.Len(t, shared.Data, 1)
+			assert.Equal(t, "session-1", shared.Data[0].ID)
+
+			// Redeeming a viewer link doesn't demote a collaborator
+			responseRecorder = createLink(handler, api.SessionShareLinkRequest{Role: database.SessionRoleViewer})
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &link))
+			require.Equal(t, http.StatusOK, redeem(handler, "collaborator", link.Data.Token).Code)
+			collaborator, err := dbClient.GetSessionShare("session-1", "collaborator")
+			require.NoError(t, err)
+			assert.Equal(t, database.SessionRoleCollaborator, collaborator.Role)
+
+			responseRecorder = newMockErrorResponseWriter()
+			handler.HandleListSessionShares(responseRecorder, sessionRequest("GET", "/api/sessions/session-1/shares", "owner", map[string]string{"session_id": "session-1"}, nil))
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			var shares api.StandardResponse[api.SessionShares]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &shares))
+			assert.Len(t, shares.Data.Shares, 1)
+			assert.Len(t, shares.Data.Links, 2)
+		})
+
+		t.Run("InvalidLinks", func(t *testing.T) {
+			handler, dbClient, _ := setupHandler()
+			createTestSession(dbClient, "session-1", "owner", agentID)
+
+			assert.Equal(t, http.StatusBadRequest, createLink(handler, api.SessionShareLinkRequest{Role: database.SessionRoleOwner}).Code)
+			assert.Equal(t, http.StatusBadRequest, createLink(handler, api.SessionShareLinkRequest{Role: database.SessionRoleViewer, ExpiresAt: ptr.To(time.Now().Add(-time.Hour))}).Code)
+			assert.Equal(t, http.StatusNotFound, redeem(handler, "viewer", "unknown-token").Code)
+
+			responseRecorder := createLink(handler, api.SessionShareLinkRequest{Role: database.SessionRoleViewer})
+			var link api.StandardResponse[api.CreatedSessionShareLink]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &link))
+			stored, err := dbClient.ListSessionShareLinks("session-1", "owner")
+			require.NoError(t, err)
+			require.Len(t, stored, 1)
+			stored[0].ExpiresAt = ptr.To(time.Now().Add(-time.Minute))
+			require.NoError(t, dbClient.StoreSessionShareLink(&stored[0]))
+			assert.Equal(t, http.StatusForbidden, redeem(handler, "viewer", link.Data.Token).Code)
+		})
+
+		t.Run("OwnerOnly", func(t *testing.T) {
+			handler, dbClient, _ := setupHandler()
+			createTestSession(dbClient, "session-1", "owner", agentID)
+			require.NoError(t, dbClient.StoreSessionShare(&database.SessionShare{SessionID: "session-1", OwnerID: "owner", UserID: "collaborator", Role: database.SessionRoleCollaborator}))
+
+			assert.Equal(t, http.StatusNotFound, share(handler, "collaborator", api.SessionShareRequest{UserID: "other", Role: database.SessionRoleViewer}).Code)
+			assert.Equal(t, http.StatusBadRequest, share(handler, "owner", api.SessionShareRequest{UserID: "owner", Role: database.SessionRoleViewer}).Code)
+			assert.Equal(t, http.StatusBadRequest, share(handler, "owner", api.SessionShareRequest{UserID: "other", Role: "admin"}).Code)
+		})
+	})
 }
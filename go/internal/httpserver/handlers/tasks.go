@@ -1,22 +1,46 @@
 package handlers
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/kagent-dev/kagent/go/internal/a2a"
+	"github.com/kagent-dev/kagent/go/internal/database"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/internal/utils"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
+	"k8s.io/utils/ptr"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
+// Human-in-the-loop decisions understood by agents.
+// See python/packages/kagent-core/src/kagent/core/a2a/_consts.py
+const (
+	hitlDecisionTypeKey = "decision_type"
+	hitlDecisionApprove = "approve"
+	hitlDecisionReject  = "reject"
+)
+
+// AgentMessenger sends messages to agents by their "<namespace>/<name>" reference
+// through the A2A proxy, on behalf of the caller of ctx
+type AgentMessenger interface {
+	SendMessage(ctx context.Context, agentRef string, params protocol.SendMessageParams) (*protocol.MessageResult, error)
+}
+
 // TasksHandler handles task-related requests
 type TasksHandler struct {
 	*Base
+	Agents AgentMessenger
 }
 
 // NewTasksHandler creates a new TasksHandler
-func NewTasksHandler(base *Base) *TasksHandler {
-	return &TasksHandler{Base: base}
+func NewTasksHandler(base *Base, agents AgentMessenger) *TasksHandler {
+	return &TasksHandler{Base: base, Agents: agents}
 }
 
 func (h *TasksHandler) HandleGetTask(w ErrorResponseWriter, r *http.Request) {
@@ -29,11 +53,20 @@ func (h *TasksHandler) HandleGetTask(w ErrorResponseWriter, r *http.Request) {
 	}
 	log = log.WithValues("task_id", taskID)
 
-	task, err := h.DatabaseService.GetTask(taskID)
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Task", Name: taskID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	task, err := h.DatabaseService.WithContext(r.Context()).GetTask(taskID)
 	if err != nil {
 		w.RespondWithError(errors.NewNotFoundError("Task not found", err))
 		return
 	}
+	if apiErr := h.checkTaskAccess(r, task, false); apiErr != nil {
+		w.RespondWithError(apiErr)
+		return
+	}
 
 	log.Info("Successfully retrieved task")
 	data := api.NewResponse(task, "Successfully retrieved task", false)
@@ -53,7 +86,25 @@ func (h *TasksHandler) HandleCreateTask(w ErrorResponseWriter, r *http.Request)
 	}
 	log = log.WithValues("task_id", task.ID)
 
-	if err := h.DatabaseService.StoreTask(&task); err != nil {
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Task", Name: task.ID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	// storing a task replaces the one with the same ID, so the caller must
+	// have access to both
+	if existing, err := h.DatabaseService.WithContext(r.Context()).GetTask(task.ID); err == nil {
+		if apiErr := h.checkTaskAccess(r, existing, true); apiErr != nil {
+			w.RespondWithError(apiErr)
+			return
+		}
+	}
+	if apiErr := h.checkTaskAccess(r, &task, true); apiErr != nil {
+		w.RespondWithError(apiErr)
+		return
+	}
+
+	if err := h.DatabaseService.WithContext(r.Context()).StoreTask(&task); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to create task", err))
 		return
 	}
@@ -73,7 +124,22 @@ func (h *TasksHandler) HandleDeleteTask(w ErrorResponseWriter, r *http.Request)
 	}
 	log = log.WithValues("task_id", taskID)
 
-	if err := h.DatabaseService.DeleteTask(taskID); err != nil {
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Task", Name: taskID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	task, err := h.DatabaseService.WithContext(r.Context()).GetTask(taskID)
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Task not found", err))
+		return
+	}
+	if apiErr := h.checkTaskAccess(r, task, true); apiErr != nil {
+		w.RespondWithError(apiErr)
+		return
+	}
+
+	if err := h.DatabaseService.WithContext(r.Context()).DeleteTask(taskID); err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to delete task", err))
 		return
 	}
@@ -81,3 +147,184 @@ func (h *TasksHandler) HandleDeleteTask(w ErrorResponseWriter, r *http.Request)
 	log.Info("Successfully deleted task")
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// checkTaskAccess checks that the caller may access task. Tasks belong to the
+// users their session is owned by or shared with, where viewers may only read
+// them, and to the agent of their session, which stores them without acting
+// on behalf of a user.
+func (h *TasksHandler) checkTaskAccess(r *http.Request, task *protocol.Task, modify bool) *errors.APIError {
+	principal, err := GetPrincipal(r)
+	if err != nil {
+		return errors.NewBadRequestError("Failed to get user ID", err)
+	}
+	if principal.Agent.ID != "" {
+		return h.checkAgentTaskAccess(r, task, principal.Agent.ID)
+	}
+
+	_, role, err := database.GetSessionForUser(h.DatabaseService.WithContext(r.Context()), task.ContextID, principal.User.ID)
+	if err != nil {
+		return errors.NewNotFoundError("Task not found", err)
+	}
+	if modify && role == database.SessionRoleViewer {
+		return errors.NewForbiddenError("Viewers can't modify the tasks of a session", nil)
+	}
+	return nil
+}
+
+// checkAgentTaskAccess checks that task belongs to a session of agentRef.
+// Agents store the tasks of a session before the session itself, so tasks
+// without a session are theirs too.
+func (h *TasksHandler) checkAgentTaskAccess(r *http.Request, task *protocol.Task, agentRef string) *errors.APIError {
+	sessions, err := h.DatabaseService.WithContext(r.Context()).ListSessionsWithID(task.ContextID)
+	if err != nil {
+		return errors.NewInternalServerError("Failed to get session of task", err)
+	}
+	agentID := utils.ConvertToPythonIdentifier(agentRef)
+	for _, session := range sessions {
+		if session.AgentID == nil || *session.AgentID != agentID {
+			return errors.NewNotFoundError("Task not found", nil)
+		}
+	}
+	return nil
+}
+
+// HandleListPushNotificationDeliveries lists the attempts of the controller to
+// deliver the push notifications of a task, oldest first
+func (h *TasksHandler) HandleListPushNotificationDeliveries(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("tasks-handler").WithValues("operation", "list-push-notification-deliveries")
+
+	taskID, err := GetPathParam(r, "task_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get task ID from path", err))
+		return
+	}
+	log = log.WithValues("task_id", taskID)
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Task", Name: taskID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	principal, err := GetPrincipal(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+
+	task, err := h.DatabaseService.WithContext(r.Context()).GetTask(taskID)
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Task not found", err))
+		return
+	}
+	// the session lookup also ensures the task belongs to the caller
+	if _, err := h.DatabaseService.WithContext(r.Context()).GetSession(task.ContextID, principal.User.ID); err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
+		return
+	}
+
+	deliveries, err := h.DatabaseService.WithContext(r.Context()).ListPushNotificationDeliveries(taskID)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to list push notification deliveries", err))
+		return
+	}
+
+	log.Info("Successfully listed push notification deliveries", "count", len(deliveries))
+	data := api.NewResponse(deliveries, "Successfully listed push notification deliveries", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+// HandleApproveTask resumes a task that is waiting for tool call approval
+func (h *TasksHandler) HandleApproveTask(w ErrorResponseWriter, r *http.Request) {
+	h.handleTaskDecision(w, r, hitlDecisionApprove)
+}
+
+// HandleRejectTask resumes a task that is waiting for tool call approval, rejecting the calls
+func (h *TasksHandler) HandleRejectTask(w ErrorResponseWriter, r *http.Request) {
+	h.handleTaskDecision(w, r, hitlDecisionReject)
+}
+
+func (h *TasksHandler) handleTaskDecision(w ErrorResponseWriter, r *http.Request, decision string) {
+	log := ctrllog.FromContext(r.Context()).WithName("tasks-handler").WithValues("operation", decision+"-task")
+
+	taskID, err := GetPathParam(r, "task_id")
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get task ID from path", err))
+		return
+	}
+	log = log.WithValues("task_id", taskID)
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Task", Name: taskID}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	// the body is optional
+	decisionRequest := api.TaskDecisionRequest{}
+	if r.ContentLength != 0 {
+		if err := DecodeJSONBody(r, &decisionRequest); err != nil {
+			w.RespondWithError(errors.NewBadRequestError("Invalid request body", err))
+			return
+		}
+	}
+
+	principal, err := GetPrincipal(r)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
+		return
+	}
+
+	task, err := h.DatabaseService.WithContext(r.Context()).GetTask(taskID)
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Task not found", err))
+		return
+	}
+	if task.Status.State != protocol.TaskStateInputRequired {
+		w.RespondWithError(errors.NewConflictError(
+			"Task is not awaiting approval",
+			fmt.Errorf("task %s is in state %s", taskID, task.Status.State),
+		))
+		return
+	}
+
+	// the session lookup also ensures the task belongs to the caller
+	session, err := h.DatabaseService.WithContext(r.Context()).GetSession(task.ContextID, principal.User.ID)
+	if err != nil {
+		w.RespondWithError(errors.NewNotFoundError("Session not found", err))
+		return
+	}
+	if session.AgentID == nil {
+		w.RespondWithError(errors.NewBadRequestError("Session has no agent", nil))
+		return
+	}
+	agentRef := utils.ConvertToKubernetesIdentifier(*session.AgentID)
+	log = log.WithValues("agent", agentRef)
+
+	parts := []protocol.Part{protocol.NewDataPart(map[string]any{hitlDecisionTypeKey: decision})}
+	if decisionRequest.Reason != "" {
+		parts = append(parts, protocol.NewTextPart(decisionRequest.Reason))
+	}
+	message := protocol.NewMessageWithContext(protocol.MessageRoleUser, parts, &task.ID, &task.ContextID)
+
+	// don't wait for the resumed task to finish, clients follow it via the A2A API
+	result, err := h.Agents.SendMessage(r.Context(), agentRef, protocol.SendMessageParams{
+		Message:       message,
+		Configuration: &protocol.SendMessageConfiguration{Blocking: ptr.To(false)},
+	})
+	var limitErr *a2a.LimitError
+	switch {
+	case stderrors.Is(err, a2a.ErrAgentNotFound):
+		w.RespondWithError(errors.NewNotFoundError("Agent not found", err))
+		return
+	case stderrors.As(err, &limitErr):
+		w.Header().Set("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+		w.RespondWithError(errors.NewTooManyRequestsError("Rate limit exceeded", err))
+		return
+	case err != nil:
+		w.RespondWithError(errors.NewInternalServerError("Failed to resume task", err))
+		return
+	}
+
+	log.Info("Successfully resumed task", "decision", decision)
+	data := api.NewResponse(result, fmt.Sprintf("Successfully sent %s decision to task", decision), false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
@@ -0,0 +1,283 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	a2aclient "trpc.group/trpc-go/trpc-a2a-go/client"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/server"
+
+	"github.com/kagent-dev/kagent/go/internal/a2a"
+	"github.com/kagent-dev/kagent/go/internal/database"
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+func TestTasksHandler_Decisions(t *testing.T) {
+	// fake agent recording the messages it receives
+	var received []protocol.Message
+	agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any                        `json:"id"`
+			Params protocol.SendMessageParams `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received = append(received, req.Params.Message)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": protocol.Task{
+				Kind:      protocol.KindTask,
+				ID:        *req.Params.Message.TaskID,
+				ContextID: *req.Params.Message.ContextID,
+				Status:    protocol.TaskStatus{State: protocol.TaskStateWorking},
+			},
+		})
+	}))
+	defer agentServer.Close()
+
+	agentClient, err := a2aclient.NewA2AClient(agentServer.URL)
+	require.NoError(t, err)
+
+	setupHandlerWithLimits := func(t *testing.T, state protocol.TaskState, limits a2a.Limits) (*handlers.TasksHandler, database.Client) {
+		dbClient := database_fake.NewClient()
+		require.NoError(t, dbClient.StoreSession(&database.Session{
+			ID:      "session-1",
+			UserID:  "test-user",
+			AgentID: ptr.To("kagent__NS__k8s_agent"),
+		}))
+		require.NoError(t, dbClient.StoreTask(&protocol.Task{
+			ID:        "task-1",
+			ContextID: "session-1",
+			Status:    protocol.TaskStatus{State: state},
+		}))
+
+		base := &handlers.Base{
+			DatabaseService: dbClient,
+			Authorizer:      &authimpl.NoopAuthorizer{},
+		}
+		agents := a2a.NewA2AHttpMux("/api/a2a", &authimpl.UnsecureAuthenticator{}, nil, nil, a2a.NewTaskStore(dbClient), nil, limits)
+		require.NoError(t, agents.SetAgentHandler("kagent/k8s-agent", agentClient, server.AgentCard{Name: "k8s_agent"}, nil))
+		return handlers.NewTasksHandler(base, agents), dbClient
+	}
+	setupHandler := func(t *testing.T, state protocol.TaskState) *handlers.TasksHandler {
+		handler, _ := setupHandlerWithLimits(t, state, a2a.Limits{})
+		return handler
+	}
+
+	newRequest := func(path, user string, body any) *http.Request {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(jsonBody))
+		req = mux.SetURLVars(req, map[string]string{"task_id": "task-1"})
+		return setUser(req, user)
+	}
+
+	t.Run("approve resumes the task", func(t *testing.T) {
+		received = nil
+		handler := setupHandler(t, protocol.TaskStateInputRequired)
+		responseRecorder := newMockErrorResponseWriter()
+
+		handler.HandleApproveTask(responseRecorder, newRequest("/api/tasks/task-1/approve", "test-user", api.TaskDecisionRequest{}))
+
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+		require.Len(t, received, 1)
+		assert.Equal(t, "task-1", *received[0].TaskID)
+		assert.Equal(t, "session-1", *received[0].ContextID)
+		require.Len(t, received[0].Parts, 1)
+		dataPart, ok := received[0].Parts[0].(*protocol.DataPart)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"decision_type": "approve"}, dataPart.Data)
+	})
+
+	t.Run("resumed tasks are stored", func(t *testing.T) {
+		received = nil
+		handler, dbClient := setupHandlerWithLimits(t, protocol.TaskStateInputRequired, a2a.Limits{})
+		responseRecorder := newMockErrorResponseWriter()
+
+		handler.HandleApproveTask(responseRecorder, newRequest("/api/tasks/task-1/approve", "test-user", nil))
+
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+		task, err := dbClient.GetTask("task-1")
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateWorking, task.Status.State)
+	})
+
+	t.Run("decisions count against the agent's limits", func(t *testing.T) {
+		received = nil
+		handler, dbClient := setupHandlerWithLimits(t, protocol.TaskStateInputRequired, a2a.Limits{RequestsPerMinutePerUser: 1})
+
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleRejectTask(responseRecorder, newRequest("/api/tasks/task-1/reject", "test-user", nil))
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+		// the task awaits approval again
+		require.NoError(t, dbClient.StoreTask(&protocol.Task{
+			ID:        "task-1",
+			ContextID: "session-1",
+			Status:    protocol.TaskStatus{State: protocol.TaskStateInputRequired},
+		}))
+		responseRecorder = newMockErrorResponseWriter()
+		handler.HandleRejectTask(responseRecorder, newRequest("/api/tasks/task-1/reject", "test-user", nil))
+		assert.Equal(t, http.StatusTooManyRequests, responseRecorder.Code)
+		assert.NotEmpty(t, responseRecorder.Header().Get("Retry-After"))
+		assert.Len(t, received, 1)
+	})
+
+	t.Run("reject passes the reason", func(t *testing.T) {
+		received = nil
+		handler := setupHandler(t, protocol.TaskStateInputRequired)
+		responseRecorder := newMockErrorResponseWriter()
+
+		handler.HandleRejectTask(responseRecorder, newRequest("/api/tasks/task-1/reject", "test-user", api.TaskDecisionRequest{Reason: "not in prod"}))
+
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+		require.Len(t, received, 1)
+		require.Len(t, received[0].Parts, 2)
+		dataPart, ok := received[0].Parts[0].(*protocol.DataPart)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"decision_type": "reject"}, dataPart.Data)
+		textPart, ok := received[0].Parts[1].(*protocol.TextPart)
+		require.True(t, ok)
+		assert.Equal(t, "not in prod", textPart.Text)
+	})
+
+	t.Run("task not awaiting approval", func(t *testing.T) {
+		received = nil
+		handler := setupHandler(t, protocol.TaskStateCompleted)
+		responseRecorder := newMockErrorResponseWriter()
+
+		handler.HandleApproveTask(responseRecorder, newRequest("/api/tasks/task-1/approve", "test-user", nil))
+
+		assert.Equal(t, http.StatusConflict, responseRecorder.Code)
+		assert.Empty(t, received)
+	})
+
+	t.Run("task of another user", func(t *testing.T) {
+		received = nil
+		handler := setupHandler(t, protocol.TaskStateInputRequired)
+		responseRecorder := newMockErrorResponseWriter()
+
+		handler.HandleApproveTask(responseRecorder, newRequest("/api/tasks/task-1/approve", "other-user", nil))
+
+		assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+		assert.Empty(t, received)
+	})
+}
+
+func TestTasksHandler_ListPushNotificationDeliveries(t *testing.T) {
+	dbClient := database_fake.NewClient()
+	require.NoError(t, dbClient.StoreSession(&database.Session{ID: "session-1", UserID: "test-user"}))
+	require.NoError(t, dbClient.StoreTask(&protocol.Task{ID: "task-1", ContextID: "session-1"}))
+	require.NoError(t, dbClient.StorePushNotificationDelivery(&database.PushNotificationDelivery{ID: "1", TaskID: "task-1", ConfigID: "config-1", State: "working", Attempt: 1, StatusCode: http.StatusServiceUnavailable}))
+	require.NoError(t, dbClient.StorePushNotificationDelivery(&database.PushNotificationDelivery{ID: "2", TaskID: "task-1", ConfigID: "config-1", State: "working", Attempt: 2, StatusCode: http.StatusOK, Delivered: true}))
+	require.NoError(t, dbClient.StorePushNotificationDelivery(&database.PushNotificationDelivery{ID: "3", TaskID: "task-2", ConfigID: "config-2", Attempt: 1}))
+
+	handler := handlers.NewTasksHandler(&handlers.Base{
+		DatabaseService: dbClient,
+		Authorizer:      &authimpl.NoopAuthorizer{},
+	}, nil)
+	list := func(user string) *mockErrorResponseWriter {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/task-1/push-notifications/deliveries", nil)
+		req = mux.SetURLVars(req, map[string]string{"task_id": "task-1"})
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleListPushNotificationDeliveries(responseRecorder, setUser(req, user))
+		return responseRecorder
+	}
+
+	responseRecorder := list("test-user")
+	require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+	var response api.StandardResponse[[]api.PushNotificationDelivery]
+	require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.False(t, response.Data[0].Delivered)
+	assert.True(t, response.Data[1].Delivered)
+
+	assert.Equal(t, http.StatusNotFound, list("other-user").Code)
+}
+
+func TestTasksHandler_Access(t *testing.T) {
+	setupHandler := func(t *testing.T) *handlers.TasksHandler {
+		dbClient := database_fake.NewClient()
+		require.NoError(t, dbClient.StoreSession(&database.Session{ID: "session-1", UserID: "owner", AgentID: ptr.To("kagent__NS__k8s_agent")}))
+		require.NoError(t, dbClient.StoreSessionShare(&database.SessionShare{SessionID: "session-1", OwnerID: "owner", UserID: "viewer", Role: database.SessionRoleViewer}))
+		require.NoError(t, dbClient.StoreTask(&protocol.Task{ID: "task-1", ContextID: "session-1"}))
+		return handlers.NewTasksHandler(&handlers.Base{
+			DatabaseService: dbClient,
+			Authorizer:      &authimpl.NoopAuthorizer{},
+		}, nil)
+	}
+	agentNamed := func(name string) func(*http.Request) *http.Request {
+		return func(req *http.Request) *http.Request {
+			return req.WithContext(auth.AuthSessionTo(req.Context(), &authimpl.SimpleSession{
+				P: auth.Principal{User: auth.User{ID: "system:serviceaccount:kagent:" + name}, Agent: auth.Agent{ID: "kagent/" + name}},
+			}))
+		}
+	}
+	agent := agentNamed("k8s-agent")
+	otherAgent := agentNamed("helm-agent")
+
+	get := func(handler *handlers.TasksHandler, withCaller func(*http.Request) *http.Request) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/task-1", nil)
+		req = mux.SetURLVars(req, map[string]string{"task_id": "task-1"})
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleGetTask(responseRecorder, withCaller(req))
+		return responseRecorder.Code
+	}
+	del := func(handler *handlers.TasksHandler, withCaller func(*http.Request) *http.Request) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/tasks/task-1", nil)
+		req = mux.SetURLVars(req, map[string]string{"task_id": "task-1"})
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleDeleteTask(responseRecorder, withCaller(req))
+		return responseRecorder.Code
+	}
+	create := func(handler *handlers.TasksHandler, withCaller func(*http.Request) *http.Request, task protocol.Task) int {
+		body, _ := json.Marshal(task)
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBuffer(body))
+		responseRecorder := newMockErrorResponseWriter()
+		handler.HandleCreateTask(responseRecorder, withCaller(req))
+		return responseRecorder.Code
+	}
+	user := func(id string) func(*http.Request) *http.Request {
+		return func(req *http.Request) *http.Request { return setUser(req, id) }
+	}
+
+	t.Run("get", func(t *testing.T) {
+		handler := setupHandler(t)
+		assert.Equal(t, http.StatusOK, get(handler, user("owner")))
+		assert.Equal(t, http.StatusOK, get(handler, user("viewer")))
+		assert.Equal(t, http.StatusOK, get(handler, agent))
+		assert.Equal(t, http.StatusNotFound, get(handler, otherAgent), "agents only get the tasks of their sessions")
+		assert.Equal(t, http.StatusNotFound, get(handler, user("other-user")))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		handler := setupHandler(t)
+		assert.Equal(t, http.StatusNotFound, del(handler, user("other-user")))
+		assert.Equal(t, http.StatusNotFound, del(handler, otherAgent))
+		assert.Equal(t, http.StatusForbidden, del(handler, user("viewer")))
+		assert.Equal(t, http.StatusNoContent, del(handler, user("owner")))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		handler := setupHandler(t)
+		assert.Equal(t, http.StatusNotFound, create(handler, user("other-user"), protocol.Task{ID: "task-1", ContextID: "session-2"}), "tasks of other users can't be replaced")
+		assert.Equal(t, http.StatusNotFound, create(handler, user("other-user"), protocol.Task{ID: "task-2", ContextID: "session-1"}), "tasks can't be added to sessions of other users")
+		assert.Equal(t, http.StatusForbidden, create(handler, user("viewer"), protocol.Task{ID: "task-2", ContextID: "session-1"}))
+		assert.Equal(t, http.StatusCreated, create(handler, user("owner"), protocol.Task{ID: "task-2", ContextID: "session-1"}))
+		assert.Equal(t, http.StatusCreated, create(handler, agent, protocol.Task{ID: "task-3", ContextID: "session-3"}), "agents store tasks before their session")
+		assert.Equal(t, http.StatusNotFound, create(handler, otherAgent, protocol.Task{ID: "task-1", ContextID: "session-3"}), "agents can't replace the tasks of other agents")
+		assert.Equal(t, http.StatusNotFound, create(handler, otherAgent, protocol.Task{ID: "task-4", ContextID: "session-1"}), "agents can't add tasks to sessions of other agents")
+		assert.Equal(t, http.StatusCreated, create(handler, agent, protocol.Task{ID: "task-4", ContextID: "session-1"}))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -22,6 +23,11 @@ func NewToolsHandler(base *Base) *ToolsHandler {
 func (h *ToolsHandler) HandleListTools(w ErrorResponseWriter, r *http.Request) {
 	log := ctrllog.FromContext(r.Context()).WithName("tools-handler").WithValues("operation", "list-db")
 
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Tool"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		w.RespondWithError(errors.NewBadRequestError("Failed to get user ID", err))
@@ -30,7 +36,7 @@ func (h *ToolsHandler) HandleListTools(w ErrorResponseWriter, r *http.Request) {
 	log = log.WithValues("userID", userID)
 
 	log.V(1).Info("Listing tools from database")
-	tools, err := h.DatabaseService.ListTools()
+	tools, err := h.DatabaseService.WithContext(r.Context()).ListTools()
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to list tools", err))
 		return
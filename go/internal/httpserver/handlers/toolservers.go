@@ -50,15 +50,18 @@ func (h *ToolServersHandler) HandleListToolServers(w ErrorResponseWriter, r *htt
 		return
 	}
 
-	toolServers, err := h.DatabaseService.ListToolServers()
+	toolServers, err := h.DatabaseService.WithContext(r.Context()).ListToolServers()
 	if err != nil {
 		w.RespondWithError(errors.NewInternalServerError("Failed to list ToolServers from database", err))
 		return
 	}
 
-	toolServerWithTools := make([]api.ToolServerResponse, len(toolServers))
-	for i, toolServer := range toolServers {
-		tools, err := h.DatabaseService.ListToolsForServer(toolServer.Name, toolServer.GroupKind)
+	toolServerWithTools := make([]api.ToolServerResponse, 0, len(toolServers))
+	for _, toolServer := range toolServers {
+		if !IsAllowed(h.Authorizer, r, auth.Resource{Type: "ToolServer", Name: toolServer.Name}) {
+			continue
+		}
+		tools, err := h.DatabaseService.WithContext(r.Context()).ListToolsForServer(toolServer.Name, toolServer.GroupKind)
 		if err != nil {
 			w.RespondWithError(errors.NewInternalServerError("Failed to list tools for ToolServer from database", err))
 			return
@@ -72,11 +75,11 @@ func (h *ToolServersHandler) HandleListToolServers(w ErrorResponseWriter, r *htt
 			}
 		}
 
-		toolServerWithTools[i] = api.ToolServerResponse{
+		toolServerWithTools = append(toolServerWithTools, api.ToolServerResponse{
 			Ref:             toolServer.Name,
 			GroupKind:       toolServer.GroupKind,
 			DiscoveredTools: discoveredTools,
-		}
+		})
 	}
 
 	log.Info("Successfully listed ToolServers", "count", len(toolServerWithTools))
@@ -218,7 +221,7 @@ func (h *ToolServersHandler) HandleDeleteToolServer(w ErrorResponseWriter, r *ht
 
 	// Find the tool server in the database to get its groupKind
 	ref := fmt.Sprintf("%s/%s", namespace, toolServerName)
-	toolServers, err := h.DatabaseService.ListToolServers()
+	toolServers, err := h.DatabaseService.WithContext(r.Context()).ListToolServers()
 	if err != nil {
 		log.Error(err, "Failed to list tool servers from database")
 		w.RespondWithError(errors.NewInternalServerError("Failed to list tool servers from database", err))
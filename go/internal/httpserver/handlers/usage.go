@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/errors"
+	"github.com/kagent-dev/kagent/go/internal/usage"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// UsageHandler handles token usage requests
+type UsageHandler struct {
+	*Base
+	Prices usage.PriceTable
+}
+
+// NewUsageHandler creates a new UsageHandler
+func NewUsageHandler(base *Base, prices usage.PriceTable) *UsageHandler {
+	return &UsageHandler{Base: base, Prices: prices}
+}
+
+// usageAdminResource is the resource type principals must be allowed to get to
+// query the usage of other users.
+const usageAdminResource = "UsageAdmin"
+
+// HandleGetUsage handles GET /api/usage requests. The group_by query parameter
+// (agent, user, session, model or day) splits the usage into groups, from and
+// to (RFC 3339 timestamps or YYYY-MM-DD dates, to exclusive unless it's a date)
+// limit it to a time range, and agent, user, session and model filter it. The
+// usage of principals that may not get UsageAdmin resources is limited to their own.
+func (h *UsageHandler) HandleGetUsage(w ErrorResponseWriter, r *http.Request) {
+	log := ctrllog.FromContext(r.Context()).WithName("usage-handler").WithValues("operation", "get")
+
+	if err := Check(h.Authorizer, r, auth.Resource{Type: "Usage"}); err != nil {
+		w.RespondWithError(err)
+		return
+	}
+
+	query := r.URL.Query()
+	groupBy := database.UsageGroupBy(query.Get("group_by"))
+	switch groupBy {
+	case database.UsageGroupByNone, database.UsageGroupByAgent, database.UsageGroupByUser,
+		database.UsageGroupBySession, database.UsageGroupByModel, database.UsageGroupByDay:
+	default:
+		w.RespondWithError(errors.NewBadRequestError("Invalid group_by, must be one of agent, user, session, model or day", nil))
+		return
+	}
+
+	from, err := parseTimeParam(query.Get("from"), false)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid from", err))
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"), true)
+	if err != nil {
+		w.RespondWithError(errors.NewBadRequestError("Invalid to", err))
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		w.RespondWithError(errors.NewBadRequestError("to must be after from", nil))
+		return
+	}
+
+	filter := database.UsageFilter{
+		From:      from,
+		To:        to,
+		AgentID:   query.Get("agent"),
+		SessionID: query.Get("session"),
+		Model:     query.Get("model"),
+	}
+	var apiErr *errors.APIError
+	if filter.UserID, apiErr = ScopeToUser(h.Authorizer, r, usageAdminResource, query.Get("user")); apiErr != nil {
+		w.RespondWithError(apiErr)
+		return
+	}
+	log = log.WithValues("groupBy", groupBy, "from", from, "to", to)
+
+	aggregates, err := h.DatabaseService.WithContext(r.Context()).SummarizeUsage(groupBy, filter)
+	if err != nil {
+		w.RespondWithError(errors.NewInternalServerError("Failed to query usage", err))
+		return
+	}
+
+	report := api.UsageReport{
+		GroupBy:  string(groupBy),
+		Currency: "USD",
+		Groups:   []api.UsageGroup{},
+	}
+	if !from.IsZero() {
+		report.From = &from
+	}
+	if !to.IsZero() {
+		report.To = &to
+	}
+
+	groups := map[string]int{}
+	for _, aggregate := range aggregates {
+		totals := h.usageTotals(aggregate)
+		addUsageTotals(&report.Total, totals)
+		if groupBy == database.UsageGroupByNone {
+			continue
+		}
+
+		i, ok := groups[aggregate.Group]
+		if !ok {
+			i = len(report.Groups)
+			groups[aggregate.Group] = i
+			report.Groups = append(report.Groups, api.UsageGroup{Key: aggregate.Group, Models: map[string]api.UsageTotals{}})
+		}
+		addUsageTotals(&report.Groups[i].UsageTotals, totals)
+		report.Groups[i].Models[aggregate.Model] = totals
+	}
+
+	log.Info("Successfully queried usage", "groups", len(report.Groups))
+	data := api.NewResponse(report, "Successfully queried usage", false)
+	RespondWithJSON(w, http.StatusOK, data)
+}
+
+func (h *UsageHandler) usageTotals(aggregate database.UsageAggregate) api.UsageTotals {
+	totals := api.UsageTotals{
+		Calls:            aggregate.Calls,
+		PromptTokens:     aggregate.PromptTokens,
+		CompletionTokens: aggregate.CompletionTokens,
+		CachedTokens:     aggregate.CachedTokens,
+		TotalTokens:      aggregate.TotalTokens,
+	}
+	cost, ok := h.Prices.Cost(aggregate.Model, aggregate.PromptTokens, aggregate.CompletionTokens, aggregate.CachedTokens)
+	if ok {
+		totals.Cost = cost
+	} else {
+		totals.UnpricedTokens = aggregate.TotalTokens
+	}
+	return totals
+}
+
+func addUsageTotals(totals *api.UsageTotals, other api.UsageTotals) {
+	totals.Calls += other.Calls
+	totals.PromptTokens += other.PromptTokens
+	totals.CompletionTokens += other.CompletionTokens
+	totals.CachedTokens += other.CachedTokens
+	totals.TotalTokens += other.TotalTokens
+	totals.Cost += other.Cost
+	totals.UnpricedTokens += other.UnpricedTokens
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a date. A date that ends a
+// range includes the whole day.
+func parseTimeParam(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a YYYY-MM-DD date", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
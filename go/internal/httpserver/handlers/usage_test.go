@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
+	"github.com/kagent-dev/kagent/go/internal/usage"
+	pkgauth "github.com/kagent-dev/kagent/go/pkg/auth"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+func TestUsageHandler(t *testing.T) {
+	setupHandler := func(t *testing.T) *handlers.UsageHandler {
+		dbClient := database_fake.NewClient()
+		for _, u := range []*database.Usage{
+			{ID: "1", AgentID: "default/a", UserID: "user-1", Model: "gpt-4o", PromptTokens: 1_000_000, CompletionTokens: 100_000, TotalTokens: 1_100_000, CreatedAt: time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC)},
+			{ID: "2", AgentID: "default/a", UserID: "user-2", Model: "llama3.2", PromptTokens: 1000, CompletionTokens: 100, TotalTokens: 1100, CreatedAt: time.Date(2026, time.October, 2, 10, 0, 0, 0, time.UTC)},
+			{ID: "3", AgentID: "default/b", UserID: "user-1", Model: "gpt-4o", PromptTokens: 2_000_000, TotalTokens: 2_000_000, CreatedAt: time.Date(2026, time.October, 3, 10, 0, 0, 0, time.UTC)},
+		} {
+			require.NoError(t, dbClient.StoreUsage(u))
+		}
+		base := &handlers.Base{
+			DatabaseService: dbClient,
+			Authorizer:      &authimpl.NoopAuthorizer{},
+		}
+		return handlers.NewUsageHandler(base, usage.DefaultPrices())
+	}
+
+	get := func(t *testing.T, target string) (api.UsageReport, *mockErrorResponseWriter) {
+		handler := setupHandler(t)
+		responseRecorder := newMockErrorResponseWriter()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = setUser(req, "test-user")
+		handler.HandleGetUsage(responseRecorder, req)
+
+		var response api.StandardResponse[api.UsageReport]
+		if responseRecorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+		}
+		return response.Data, responseRecorder
+	}
+
+	t.Run("totals with cost", func(t *testing.T) {
+		report, responseRecorder := get(t, "/api/usage")
+		require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+
+		assert.Empty(t, report.Groups)
+		assert.Equal(t, "USD", report.Currency)
+		assert.Equal(t, int64(3), report.Total.Calls)
+		assert.Equal(t, int64(3_101_100), report.Total.TotalTokens)
+		assert.InDelta(t, 3*2.5+0.1*10, report.Total.Cost, 1e-9)
+		assert.Equal(t, int64(1100), report.Total.UnpricedTokens)
+	})
+
+	t.Run("grouped by agent", func(t *testing.T) {
+		report, responseRecorder := get(t, "/api/usage?group_by=agent")
+		require.Equal(t, http.StatusOK, responseRecorder.Code)
+
+		require.Len(t, report.Groups, 2)
+		assert.Equal(t, "default/a", report.Groups[0].Key)
+		assert.Equal(t, int64(2), report.Groups[0].Calls)
+		assert.Len(t, report.Groups[0].Models, 2)
+		assert.InDelta(t, 2.5+1, report.Groups[0].Models["gpt-4o"].Cost, 1e-9)
+		assert.Equal(t, "default/b", report.Groups[1].Key)
+		assert.InDelta(t, 5, report.Groups[1].Cost, 1e-9)
+	})
+
+	t.Run("grouped by day within a date range", func(t *testing.T) {
+		report, responseRecorder := get(t, "/api/usage?group_by=day&from=2026-10-02&to=2026-10-03&user=user-1")
+		require.Equal(t, http.StatusOK, responseRecorder.Code)
+
+		require.Len(t, report.Groups, 1)
+		assert.Equal(t, "2026-10-03", report.Groups[0].Key)
+		require.NotNil(t, report.To)
+		assert.Equal(t, time.Date(2026, time.October, 4, 0, 0, 0, 0, time.UTC), *report.To)
+	})
+
+	t.Run("invalid group by", func(t *testing.T) {
+		_, responseRecorder := get(t, "/api/usage?group_by=tool")
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	})
+
+	t.Run("invalid time range", func(t *testing.T) {
+		_, responseRecorder := get(t, "/api/usage?from=2026-10-03&to=2026-10-01")
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+
+		_, responseRecorder = get(t, "/api/usage?from=yesterday")
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	})
+
+	t.Run("limits usage to the caller unless admin", func(t *testing.T) {
+		handler := setupHandler(t)
+		policy, err := authimpl.ParseRBACPolicy([]byte(`
+roles:
+  user:
+  - resources: [Usage]
+    verbs: [get]
+  admin:
+  - resources: ["*"]
+    verbs: ["*"]
+`))
+		require.NoError(t, err)
+		authorizer := authimpl.NewRBACAuthorizer()
+		authorizer.SetPolicy(policy)
+		handler.Authorizer = authorizer
+
+		get := func(target, role string) *mockErrorResponseWriter {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req = req.WithContext(pkgauth.AuthSessionTo(req.Context(), &authimpl.SimpleSession{
+				P: pkgauth.Principal{User: pkgauth.User{ID: "user-2", Roles: []string{role}}},
+			}))
+			responseRecorder := newMockErrorResponseWriter()
+			handler.HandleGetUsage(responseRecorder, req)
+			return responseRecorder
+		}
+		calls := func(responseRecorder *mockErrorResponseWriter) int64 {
+			require.Equal(t, http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+			var response api.StandardResponse[api.UsageReport]
+			require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &response))
+			return response.Data.Total.Calls
+		}
+
+		assert.Equal(t, int64(1), calls(get("/api/usage", "user")))
+		assert.Equal(t, int64(1), calls(get("/api/usage?user=user-2", "user")))
+		assert.Equal(t, http.StatusForbidden, get("/api/usage?user=user-1", "user").Code)
+		assert.Equal(t, int64(3), calls(get("/api/usage", "admin")))
+		assert.Equal(t, int64(2), calls(get("/api/usage?user=user-1", "admin")))
+	})
+}
@@ -0,0 +1,58 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_http_requests_total",
+		Help: "Number of HTTP requests served, by route template, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kagent_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests, by route template and method. A2A streams are measured until they close.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(httpRequests, httpRequestDuration)
+}
+
+// metricsMiddleware records the count and latency of requests. Routes are
+// labeled by their path template to keep the label's cardinality bounded.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := newStatusResponseWriter(w)
+		next.ServeHTTP(ww, r)
+
+		route := routeTemplate(r)
+		if route == "" {
+			route = "unknown"
+		}
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(ww.status)).Inc()
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeTemplate returns the path template of the route matching r, or an empty
+// string if there is none.
+func routeTemplate(r *http.Request) string {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return ""
+	}
+	template, err := current.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return template
+}
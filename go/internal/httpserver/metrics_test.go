@@ -0,0 +1,32 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/metrics-test/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["name"] == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok")) //nolint:errcheck
+	}).Methods(http.MethodGet)
+	router.Use(metricsMiddleware)
+
+	for _, path := range []string{"/api/metrics-test/a", "/api/metrics-test/b", "/api/metrics-test/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	route := "/api/metrics-test/{name}"
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues(route, http.MethodGet, "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues(route, http.MethodGet, "404")))
+	assert.Equal(t, 1, testutil.CollectAndCount(httpRequestDuration))
+}
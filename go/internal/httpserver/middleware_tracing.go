@@ -0,0 +1,29 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingMiddleware starts a server span for each request, continuing the trace
+// of the W3C traceparent header if present. Spans are named after the method
+// and route template.
+func tracingMiddleware(next http.Handler) http.Handler {
+	withRoute := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := routeTemplate(r); route != "" {
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.route", route))
+		}
+		next.ServeHTTP(w, r)
+	})
+	return otelhttp.NewHandler(withRoute, "http",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if route := routeTemplate(r); route != "" {
+				return r.Method + " " + route
+			}
+			return r.Method
+		}),
+	)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/kagent-dev/kagent/go/internal/a2a"
 	"github.com/kagent-dev/kagent/go/internal/database"
 	"github.com/kagent-dev/kagent/go/internal/httpserver/handlers"
+	"github.com/kagent-dev/kagent/go/internal/modelcatalog"
+	"github.com/kagent-dev/kagent/go/internal/usage"
 	common "github.com/kagent-dev/kagent/go/internal/utils"
 	"github.com/kagent-dev/kagent/go/internal/version"
 	"github.com/kagent-dev/kagent/go/pkg/auth"
@@ -36,6 +38,7 @@ const (
 	APIPathNamespaces      = "/api/namespaces"
 	APIPathA2A             = "/api/a2a"
 	APIPathFeedback        = "/api/feedback"
+	APIPathUsage           = "/api/usage"
 	APIPathLangGraph       = "/api/langgraph"
 	APIPathCrewAI          = "/api/crewai"
 	APIPathDataSources          = "/api/datasources"
@@ -59,6 +62,9 @@ type ServerConfig struct {
 	DbClient          database.Client
 	Authenticator     auth.AuthProvider
 	Authorizer        auth.Authorizer
+	ModelCatalog      *modelcatalog.Catalog
+	UsageRecorder     *usage.Recorder
+	UsagePrices       usage.PriceTable
 }
 
 // HTTPServer is the structure that manages the HTTP server
@@ -78,7 +84,7 @@ func NewHTTPServer(config ServerConfig) (*HTTPServer, error) {
 	return &HTTPServer{
 		config:        config,
 		router:        config.Router,
-		handlers:      handlers.NewHandlers(config.KubeClient, defaultModelConfig, config.DbClient, config.WatchedNamespaces, config.Authorizer),
+		handlers:      handlers.NewHandlers(config.KubeClient, defaultModelConfig, config.DbClient, config.WatchedNamespaces, config.Authorizer, config.A2AHandler, config.ModelCatalog, config.UsageRecorder, config.UsagePrices),
 		authenticator: config.Authenticator,
 	}, nil
 }
@@ -161,9 +167,20 @@ func (s *HTTPServer) setupRoutes() {
 	// Sessions - using database handlers
 	s.router.HandleFunc(APIPathSessions, adaptHandler(s.handlers.Sessions.HandleListSessions)).Methods(http.MethodGet)
 	s.router.HandleFunc(APIPathSessions, adaptHandler(s.handlers.Sessions.HandleCreateSession)).Methods(http.MethodPost)
+	s.router.HandleFunc(APIPathSessions+"/search", adaptHandler(s.handlers.Sessions.HandleSearchSessions)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathSessions+"/import", adaptHandler(s.handlers.Sessions.HandleImportSession)).Methods(http.MethodPost)
+	s.router.HandleFunc(APIPathSessions+"/shared", adaptHandler(s.handlers.Sessions.HandleListSharedSessions)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathSessions+"/shared/{token}", adaptHandler(s.handlers.Sessions.HandleRedeemSessionShareLink)).Methods(http.MethodPost)
 	s.router.HandleFunc(APIPathSessions+"/agent/{namespace}/{name}", adaptHandler(s.handlers.Sessions.HandleGetSessionsForAgent)).Methods(http.MethodGet)
 	s.router.HandleFunc(APIPathSessions+"/{session_id}", adaptHandler(s.handlers.Sessions.HandleGetSession)).Methods(http.MethodGet)
 	s.router.HandleFunc(APIPathSessions+"/{session_id}/tasks", adaptHandler(s.handlers.Sessions.HandleListTasksForSession)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/export", adaptHandler(s.handlers.Sessions.HandleExportSession)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/fork", adaptHandler(s.handlers.Sessions.HandleForkSession)).Methods(http.MethodPost)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/shares", adaptHandler(s.handlers.Sessions.HandleListSessionShares)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/shares", adaptHandler(s.handlers.Sessions.HandleShareSession)).Methods(http.MethodPut)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/shares/{user_id}", adaptHandler(s.handlers.Sessions.HandleUnshareSession)).Methods(http.MethodDelete)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/share-links", adaptHandler(s.handlers.Sessions.HandleCreateSessionShareLink)).Methods(http.MethodPost)
+	s.router.HandleFunc(APIPathSessions+"/{session_id}/share-links/{link_id}", adaptHandler(s.handlers.Sessions.HandleDeleteSessionShareLink)).Methods(http.MethodDelete)
 	s.router.HandleFunc(APIPathSessions+"/{session_id}", adaptHandler(s.handlers.Sessions.HandleDeleteSession)).Methods(http.MethodDelete)
 	s.router.HandleFunc(APIPathSessions+"/{session_id}", adaptHandler(s.handlers.Sessions.HandleUpdateSession)).Methods(http.MethodPut)
 	s.router.HandleFunc(APIPathSessions+"/{session_id}/events", adaptHandler(s.handlers.Sessions.HandleAddEventToSession)).Methods(http.MethodPost)
@@ -172,6 +189,9 @@ func (s *HTTPServer) setupRoutes() {
 	s.router.HandleFunc(APIPathTasks+"/{task_id}", adaptHandler(s.handlers.Tasks.HandleGetTask)).Methods(http.MethodGet)
 	s.router.HandleFunc(APIPathTasks, adaptHandler(s.handlers.Tasks.HandleCreateTask)).Methods(http.MethodPost)
 	s.router.HandleFunc(APIPathTasks+"/{task_id}", adaptHandler(s.handlers.Tasks.HandleDeleteTask)).Methods(http.MethodDelete)
+	s.router.HandleFunc(APIPathTasks+"/{task_id}/approve", adaptHandler(s.handlers.Tasks.HandleApproveTask)).Methods(http.MethodPost)
+	s.router.HandleFunc(APIPathTasks+"/{task_id}/reject", adaptHandler(s.handlers.Tasks.HandleRejectTask)).Methods(http.MethodPost)
+	s.router.HandleFunc(APIPathTasks+"/{task_id}/push-notifications/deliveries", adaptHandler(s.handlers.Tasks.HandleListPushNotificationDeliveries)).Methods(http.MethodGet)
 
 	// Tools - using database handlers
 	s.router.HandleFunc(APIPathTools, adaptHandler(s.handlers.Tools.HandleListTools)).Methods(http.MethodGet)
@@ -197,6 +217,7 @@ func (s *HTTPServer) setupRoutes() {
 
 	// Models
 	s.router.HandleFunc(APIPathModels, adaptHandler(s.handlers.Model.HandleListSupportedModels)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathModels, adaptHandler(s.handlers.Model.HandleDiscoverModels)).Methods(http.MethodPost)
 
 	// Memories
 	s.router.HandleFunc(APIPathMemories, adaptHandler(s.handlers.Memory.HandleListMemories)).Methods(http.MethodGet)
@@ -211,6 +232,11 @@ func (s *HTTPServer) setupRoutes() {
 	// Feedback - using database handlers
 	s.router.HandleFunc(APIPathFeedback, adaptHandler(s.handlers.Feedback.HandleCreateFeedback)).Methods(http.MethodPost)
 	s.router.HandleFunc(APIPathFeedback, adaptHandler(s.handlers.Feedback.HandleListFeedback)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathFeedback+"/stats", adaptHandler(s.handlers.Feedback.HandleGetFeedbackStats)).Methods(http.MethodGet)
+	s.router.HandleFunc(APIPathFeedback+"/export", adaptHandler(s.handlers.Feedback.HandleExportFeedback)).Methods(http.MethodGet)
+
+	// Usage
+	s.router.HandleFunc(APIPathUsage, adaptHandler(s.handlers.Usage.HandleGetUsage)).Methods(http.MethodGet)
 
 	// LangGraph Checkpoints
 	s.router.HandleFunc(APIPathLangGraph+"/checkpoints", adaptHandler(s.handlers.Checkpoints.HandlePutCheckpoint)).Methods(http.MethodPost)
@@ -241,6 +267,8 @@ func (s *HTTPServer) setupRoutes() {
 	s.router.PathPrefix(APIPathA2A + "/{namespace}/{name}").Handler(s.config.A2AHandler)
 
 	// Use middleware for common functionality
+	s.router.Use(tracingMiddleware)
+	s.router.Use(metricsMiddleware)
 	s.router.Use(auth.AuthnMiddleware(s.authenticator))
 	s.router.Use(contentTypeMiddleware)
 	s.router.Use(loggingMiddleware)
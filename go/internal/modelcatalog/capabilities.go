@@ -0,0 +1,124 @@
+package modelcatalog
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	kclient "github.com/kagent-dev/kagent/go/pkg/client"
+)
+
+//go:embed capabilities.yaml
+var defaultCapabilities []byte
+
+// Capabilities describes what a model supports.
+type Capabilities struct {
+	FunctionCalling bool `json:"functionCalling"`
+	ContextWindow   int  `json:"contextWindow,omitempty"`
+	Vision          bool `json:"vision,omitempty"`
+}
+
+// CapabilityTable holds the capabilities of well known models per provider.
+type CapabilityTable map[v1alpha2.ModelProvider]map[string]Capabilities
+
+// DefaultCapabilities returns the table bundled with kagent.
+func DefaultCapabilities() CapabilityTable {
+	table, err := parseCapabilities(defaultCapabilities)
+	if err != nil {
+		panic(fmt.Sprintf("invalid bundled model capabilities: %v", err))
+	}
+	return table
+}
+
+// LoadCapabilities returns the bundled table with the entries of the YAML file
+// at path overriding or extending it. An empty path returns the bundled table.
+func LoadCapabilities(path string) (CapabilityTable, error) {
+	table := DefaultCapabilities()
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model capabilities file: %w", err)
+	}
+	overrides, err := parseCapabilities(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse model capabilities file %s: %w", path, err)
+	}
+	for provider, models := range overrides {
+		if table[provider] == nil {
+			table[provider] = map[string]Capabilities{}
+		}
+		for name, capabilities := range models {
+			table[provider][name] = capabilities
+		}
+	}
+	return table, nil
+}
+
+func parseCapabilities(data []byte) (CapabilityTable, error) {
+	table := CapabilityTable{}
+	if err := yaml.UnmarshalStrict(data, &table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// Lookup returns the capabilities of the model, matching its name exactly or
+// by the longest known prefix.
+func (t CapabilityTable) Lookup(provider v1alpha2.ModelProvider, model string) (Capabilities, bool) {
+	models := t[provider]
+	if capabilities, ok := models[model]; ok {
+		return capabilities, true
+	}
+
+	var match string
+	for name := range models {
+		if len(name) > len(match) && strings.HasPrefix(model, name) {
+			match = name
+		}
+	}
+	if match == "" {
+		return Capabilities{}, false
+	}
+	return models[match], true
+}
+
+// Providers returns the providers of the table.
+func (t CapabilityTable) Providers() []v1alpha2.ModelProvider {
+	providers := make([]v1alpha2.ModelProvider, 0, len(t))
+	for provider := range t {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// Models returns the models of the provider in the table, sorted by name.
+func (t CapabilityTable) Models(provider v1alpha2.ModelProvider) []kclient.ModelInfo {
+	models := make([]kclient.ModelInfo, 0, len(t[provider]))
+	for name, capabilities := range t[provider] {
+		models = append(models, modelInfo(name, capabilities))
+	}
+	sortModels(models)
+	return models
+}
+
+func modelInfo(name string, capabilities Capabilities) kclient.ModelInfo {
+	return kclient.ModelInfo{
+		Name:            name,
+		FunctionCalling: capabilities.FunctionCalling,
+		ContextWindow:   capabilities.ContextWindow,
+		Vision:          capabilities.Vision,
+	}
+}
+
+func sortModels(models []kclient.ModelInfo) {
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+}
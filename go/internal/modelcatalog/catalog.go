@@ -0,0 +1,300 @@
+// Package modelcatalog lists the models a ModelConfig's provider serves,
+// merged with the capabilities of well known models.
+package modelcatalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/controller/modelprobe"
+	kclient "github.com/kagent-dev/kagent/go/pkg/client"
+)
+
+const (
+	// requestTimeout bounds a single list request to a provider.
+	requestTimeout = 10 * time.Second
+	// maxResponseSize bounds the list responses read from providers.
+	maxResponseSize = 8 << 20
+	// errorTTL bounds how long failed listings are cached, so that an
+	// unreachable provider isn't asked on every request but recovers quickly.
+	errorTTL = 30 * time.Second
+)
+
+// Catalog lists the models of providers and caches the results.
+type Catalog struct {
+	kube         client.Client
+	capabilities CapabilityTable
+	ttl          time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+
+	now           func() time.Time
+	geminiBaseURL string
+}
+
+type cacheEntry struct {
+	models  []kclient.ModelInfo
+	err     error
+	expires time.Time
+}
+
+// New returns a Catalog that reads ModelConfig secrets with kube and caches
+// the models of each provider for ttl, and failures for up to errorTTL.
+// Results are not cached if ttl is 0.
+func New(kube client.Client, capabilities CapabilityTable, ttl time.Duration) *Catalog {
+	return &Catalog{
+		kube:          kube,
+		capabilities:  capabilities,
+		ttl:           ttl,
+		cache:         map[string]cacheEntry{},
+		now:           time.Now,
+		geminiBaseURL: modelprobe.DefaultGeminiBaseURL,
+	}
+}
+
+// Capabilities returns the capability table of the catalog.
+func (c *Catalog) Capabilities() CapabilityTable {
+	return c.capabilities
+}
+
+// ListModels lists the models available to modelConfig, using the credentials
+// of the secrets it references.
+func (c *Catalog) ListModels(ctx context.Context, modelConfig *v1alpha2.ModelConfig) ([]kclient.ModelInfo, error) {
+	creds, err := c.credentials(ctx, modelConfig)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListModelsWithCredentials(ctx, modelConfig, creds)
+}
+
+// ListModelsWithCredentials lists the models available to modelConfig with the
+// given credentials. Providers that can't list their models return the models
+// of the capability table.
+func (c *Catalog) ListModelsWithCredentials(ctx context.Context, modelConfig *v1alpha2.ModelConfig, creds modelprobe.Credentials) ([]kclient.ModelInfo, error) {
+	provider := modelConfig.Spec.Provider
+	key, err := cacheKey(modelConfig, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.models, entry.err
+	}
+
+	listed, err := c.list(ctx, modelConfig, creds)
+	if errors.Is(err, modelprobe.ErrUnsupported) {
+		return c.capabilities.Models(provider), nil
+	}
+	if err != nil {
+		// Failures caused by the caller going away say nothing about the provider
+		if ctx.Err() == nil {
+			c.store(key, cacheEntry{err: err}, min(c.ttl, errorTTL))
+		}
+		return nil, err
+	}
+
+	models := make([]kclient.ModelInfo, 0, len(listed))
+	seen := make(map[string]struct{}, len(listed))
+	for _, m := range listed {
+		if _, ok := seen[m.name]; ok {
+			continue
+		}
+		seen[m.name] = struct{}{}
+
+		capabilities, known := c.capabilities.Lookup(provider, m.name)
+		if !known {
+			capabilities = m.capabilities
+		} else if capabilities.ContextWindow == 0 {
+			capabilities.ContextWindow = m.capabilities.ContextWindow
+		}
+		models = append(models, modelInfo(m.name, capabilities))
+	}
+	sortModels(models)
+
+	c.store(key, cacheEntry{models: models}, c.ttl)
+	return models, nil
+}
+
+// store caches entry under key for ttl, unless ttl is 0. Expired entries are
+// swept as new ones are stored, so that the endpoints and credentials of
+// deleted ModelConfigs and rotated secrets don't accumulate.
+func (c *Catalog) store(key string, entry cacheEntry, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	now := c.now()
+	entry.expires = now.Add(ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.cache {
+		if !now.Before(e.expires) {
+			delete(c.cache, k)
+		}
+	}
+	c.cache[key] = entry
+}
+
+// cacheKey identifies the provider endpoint and credentials of modelConfig, so
+// that ModelConfigs sharing them share the cache entry and changed secrets
+// aren't served stale results.
+func cacheKey(modelConfig *v1alpha2.ModelConfig, creds modelprobe.Credentials) (string, error) {
+	spec := modelConfig.Spec.DeepCopy()
+	spec.Model = ""
+	spec.Fallbacks = nil
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+	hash.Write([]byte{0})
+	hash.Write([]byte(creds.APIKey))
+	hash.Write([]byte{0})
+	hash.Write(creds.CACert)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (c *Catalog) credentials(ctx context.Context, modelConfig *v1alpha2.ModelConfig) (modelprobe.Credentials, error) {
+	var creds modelprobe.Credentials
+	if modelConfig.Spec.APIKeySecret != "" {
+		secret := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: modelConfig.Namespace, Name: modelConfig.Spec.APIKeySecret}, secret); err != nil {
+			return creds, fmt.Errorf("failed to get secret %s: %w", modelConfig.Spec.APIKeySecret, err)
+		}
+		creds.APIKey = string(secret.Data[modelConfig.Spec.APIKeySecretKey])
+	}
+	if tls := modelConfig.Spec.TLS; tls != nil && tls.CACertSecretRef != "" {
+		secret := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: modelConfig.Namespace, Name: tls.CACertSecretRef}, secret); err != nil {
+			return creds, fmt.Errorf("failed to get secret %s: %w", tls.CACertSecretRef, err)
+		}
+		creds.CACert = secret.Data[tls.CACertSecretKey]
+	}
+	return creds, nil
+}
+
+// listedModel is a model listed by a provider, with the capabilities the
+// provider reports, if any.
+type listedModel struct {
+	name         string
+	capabilities Capabilities
+}
+
+// list asks the provider for its models:
+//   - OpenAI, Anthropic and OpenAI-compatible servers: list the models
+//   - Ollama: list the local models
+//   - Gemini: list the models, which report their input token limit
+//
+// Azure OpenAI can't list deployments from the data plane, and Vertex AI and
+// Bedrock authenticate with the agent's workload identity, so they are not
+// supported.
+func (c *Catalog) list(ctx context.Context, modelConfig *v1alpha2.ModelConfig, creds modelprobe.Credentials) ([]listedModel, error) {
+	spec := modelConfig.Spec
+	req, err := modelprobe.NewListRequest(ctx, spec, creds, c.geminiBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(req, spec, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	switch spec.Provider {
+	case v1alpha2.ModelProviderOllama:
+		var resp struct {
+			Models []struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode %s models: %w", spec.Provider, err)
+		}
+		models := make([]listedModel, 0, len(resp.Models))
+		for _, m := range resp.Models {
+			models = append(models, listedModel{name: strings.TrimSuffix(m.Name, ":latest")})
+		}
+		return models, nil
+
+	case v1alpha2.ModelProviderGemini:
+		var resp struct {
+			Models []struct {
+				Name                       string   `json:"name"`
+				InputTokenLimit            int      `json:"inputTokenLimit"`
+				SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+			} `json:"models"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode %s models: %w", spec.Provider, err)
+		}
+		models := make([]listedModel, 0, len(resp.Models))
+		for _, m := range resp.Models {
+			// skip embedding and other models that can't generate content
+			if !slices.Contains(m.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			models = append(models, listedModel{
+				name:         strings.TrimPrefix(m.Name, "models/"),
+				capabilities: Capabilities{ContextWindow: m.InputTokenLimit},
+			})
+		}
+		return models, nil
+
+	default:
+		var resp struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode %s models: %w", spec.Provider, err)
+		}
+		models := make([]listedModel, 0, len(resp.Data))
+		for _, m := range resp.Data {
+			models = append(models, listedModel{name: m.ID})
+		}
+		return models, nil
+	}
+}
+
+func (c *Catalog) do(req *http.Request, spec v1alpha2.ModelConfigSpec, creds modelprobe.Credentials) ([]byte, error) {
+	httpClient, err := modelprobe.NewHTTPClient(spec.TLS, creds.CACert)
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Timeout = requestTimeout
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s models: %w", spec.Provider, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned %s: %s", spec.Provider, resp.Status, modelprobe.ErrorMessage(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,285 @@
+package modelcatalog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/controller/modelprobe"
+	kclient "github.com/kagent-dev/kagent/go/pkg/client"
+)
+
+func modelConfig(spec v1alpha2.ModelConfigSpec) *v1alpha2.ModelConfig {
+	return &v1alpha2.ModelConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
+func TestListModels(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     func(url string) v1alpha2.ModelConfigSpec
+		response string
+		assert   func(t *testing.T, r *http.Request)
+		want     []kclient.ModelInfo
+	}{
+		{
+			name: "openai",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderOpenAI,
+					Model:    "gpt-4o",
+					OpenAI:   &v1alpha2.OpenAIConfig{BaseURL: url + "/v1"},
+				}
+			},
+			response: `{"data": [{"id": "gpt-4o-2024-08-06"}, {"id": "gpt-4o-mini"}, {"id": "text-embedding-3-small"}]}`,
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/v1/models", r.URL.Path)
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			},
+			want: []kclient.ModelInfo{
+				{Name: "gpt-4o-2024-08-06", FunctionCalling: true, ContextWindow: 128000, Vision: true},
+				{Name: "gpt-4o-mini", FunctionCalling: true, ContextWindow: 128000, Vision: true},
+				{Name: "text-embedding-3-small"},
+			},
+		},
+		{
+			name: "anthropic",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider:  v1alpha2.ModelProviderAnthropic,
+					Model:     "claude-sonnet-4-5",
+					Anthropic: &v1alpha2.AnthropicConfig{BaseURL: url},
+				}
+			},
+			response: `{"data": [{"id": "claude-sonnet-4-5-20250929", "type": "model"}]}`,
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/v1/models", r.URL.Path)
+				assert.Equal(t, "secret", r.Header.Get("x-api-key"))
+				assert.Equal(t, "2023-06-01", r.Header.Get("anthropic-version"))
+			},
+			want: []kclient.ModelInfo{
+				{Name: "claude-sonnet-4-5-20250929", FunctionCalling: true, ContextWindow: 200000, Vision: true},
+			},
+		},
+		{
+			name: "ollama",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderOllama,
+					Model:    "llama3.2",
+					Ollama:   &v1alpha2.OllamaConfig{Host: url},
+				}
+			},
+			response: `{"models": [{"name": "llama3.2:latest"}, {"name": "qwen3:8b"}]}`,
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/api/tags", r.URL.Path)
+			},
+			want: []kclient.ModelInfo{
+				{Name: "llama3.2", FunctionCalling: true, ContextWindow: 131072},
+				{Name: "qwen3:8b", FunctionCalling: true, ContextWindow: 40960},
+			},
+		},
+		{
+			name: "openai compatible",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderOpenAICompatible,
+					Model:    "meta-llama/Llama-3.1-8B-Instruct",
+					OpenAICompatible: &v1alpha2.OpenAICompatibleConfig{
+						BaseURL:       url,
+						AuthHeader:    "X-Api-Key",
+						ModelListPath: "/v1/models",
+					},
+				}
+			},
+			response: `{"data": [{"id": "meta-llama/Llama-3.1-8B-Instruct"}]}`,
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/v1/models", r.URL.Path)
+				assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+			},
+			want: []kclient.ModelInfo{{Name: "meta-llama/Llama-3.1-8B-Instruct"}},
+		},
+		{
+			name: "gemini reports the context window and skips embedding models",
+			spec: func(url string) v1alpha2.ModelConfigSpec {
+				return v1alpha2.ModelConfigSpec{
+					Provider: v1alpha2.ModelProviderGemini,
+					Model:    "gemini-2.0-flash",
+				}
+			},
+			response: `{"models": [
+				{"name": "models/gemini-2.0-flash", "inputTokenLimit": 1048576, "supportedGenerationMethods": ["generateContent"]},
+				{"name": "models/gemini-3-pro", "inputTokenLimit": 2097152, "supportedGenerationMethods": ["generateContent"]},
+				{"name": "models/text-embedding-004", "inputTokenLimit": 2048, "supportedGenerationMethods": ["embedContent"]}
+			]}`,
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/v1beta/models", r.URL.Path)
+				assert.Equal(t, "secret", r.Header.Get("x-goog-api-key"))
+			},
+			want: []kclient.ModelInfo{
+				{Name: "gemini-2.0-flash", FunctionCalling: true, ContextWindow: 1048576, Vision: true},
+				{Name: "gemini-3-pro", ContextWindow: 2097152},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.assert(t, r)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			catalog := New(nil, DefaultCapabilities(), time.Minute)
+			catalog.geminiBaseURL = server.URL + "/v1beta"
+
+			models, err := catalog.ListModelsWithCredentials(context.Background(), modelConfig(tt.spec(server.URL)), modelprobe.Credentials{APIKey: "secret"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, models)
+		})
+	}
+}
+
+func TestListModelsUnsupportedProvider(t *testing.T) {
+	catalog := New(nil, DefaultCapabilities(), time.Minute)
+
+	models, err := catalog.ListModelsWithCredentials(context.Background(), modelConfig(v1alpha2.ModelConfigSpec{
+		Provider: v1alpha2.ModelProviderBedrock,
+		Model:    "amazon.nova-pro-v1:0",
+		Bedrock:  &v1alpha2.BedrockConfig{Region: "us-east-1"},
+	}), modelprobe.Credentials{})
+	require.NoError(t, err)
+	assert.Equal(t, catalog.Capabilities().Models(v1alpha2.ModelProviderBedrock), models)
+	assert.NotEmpty(t, models)
+}
+
+func TestListModelsCache(t *testing.T) {
+	requests := 0
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": [{"id": "gpt-4o"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	catalog := New(nil, DefaultCapabilities(), time.Minute)
+	catalog.now = func() time.Time { return now }
+
+	openAI := func(model string) *v1alpha2.ModelConfig {
+		return modelConfig(v1alpha2.ModelConfigSpec{
+			Provider: v1alpha2.ModelProviderOpenAI,
+			Model:    model,
+			OpenAI:   &v1alpha2.OpenAIConfig{BaseURL: server.URL},
+		})
+	}
+	list := func(mc *v1alpha2.ModelConfig, apiKey string) error {
+		_, err := catalog.ListModelsWithCredentials(context.Background(), mc, modelprobe.Credentials{APIKey: apiKey})
+		return err
+	}
+
+	require.NoError(t, list(openAI("gpt-4o"), "secret"))
+	require.NoError(t, list(openAI("gpt-4o-mini"), "secret"))
+	assert.Equal(t, 1, requests, "ModelConfigs of the same endpoint and credentials share the cache")
+
+	require.NoError(t, list(openAI("gpt-4o"), "rotated"))
+	assert.Equal(t, 2, requests, "changed credentials are not served from the cache")
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, list(openAI("gpt-4o"), "secret"))
+	assert.Equal(t, 3, requests, "expired entries are refreshed")
+
+	status = http.StatusUnauthorized
+	now = now.Add(2 * time.Minute)
+	assert.EqualError(t, list(openAI("gpt-4o"), "secret"), "OpenAI returned 401 Unauthorized: Incorrect API key provided")
+	assert.EqualError(t, list(openAI("gpt-4o"), "secret"), "OpenAI returned 401 Unauthorized: Incorrect API key provided")
+	assert.Equal(t, 4, requests, "errors are cached briefly")
+
+	status = http.StatusOK
+	now = now.Add(errorTTL)
+	require.NoError(t, list(openAI("gpt-4o"), "secret"))
+	assert.Equal(t, 5, requests, "errors expire before results")
+	assert.Len(t, catalog.cache, 1, "expired entries are swept")
+}
+
+func TestListModelsReadsSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	kube := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "openai", Namespace: "default"},
+		Data:       map[string][]byte{"key": []byte("from-secret")},
+	}).Build()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer from-secret", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	catalog := New(kube, DefaultCapabilities(), 0)
+	mc := modelConfig(v1alpha2.ModelConfigSpec{
+		Provider:        v1alpha2.ModelProviderOpenAI,
+		Model:           "gpt-4o",
+		APIKeySecret:    "openai",
+		APIKeySecretKey: "key",
+		OpenAI:          &v1alpha2.OpenAIConfig{BaseURL: server.URL},
+	})
+	_, err := catalog.ListModels(context.Background(), mc)
+	require.NoError(t, err)
+
+	mc.Spec.APIKeySecret = "missing"
+	_, err = catalog.ListModels(context.Background(), mc)
+	assert.ErrorContains(t, err, "failed to get secret missing")
+}
+
+func TestLoadCapabilities(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capabilities.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+OpenAI:
+  gpt-4o: {functionCalling: false, contextWindow: 64000}
+OpenAICompatible:
+  meta-llama/Llama-3.1: {functionCalling: true, contextWindow: 131072}
+`), 0o600))
+
+	table, err := LoadCapabilities(path)
+	require.NoError(t, err)
+
+	capabilities, ok := table.Lookup(v1alpha2.ModelProviderOpenAI, "gpt-4o-2024-08-06")
+	assert.True(t, ok)
+	assert.Equal(t, Capabilities{ContextWindow: 64000}, capabilities)
+
+	capabilities, ok = table.Lookup(v1alpha2.ModelProviderOpenAI, "gpt-4o-mini")
+	assert.True(t, ok, "bundled entries are kept")
+	assert.True(t, capabilities.FunctionCalling)
+
+	capabilities, ok = table.Lookup(v1alpha2.ModelProviderOpenAICompatible, "meta-llama/Llama-3.1-8B-Instruct")
+	assert.True(t, ok)
+	assert.Equal(t, 131072, capabilities.ContextWindow)
+
+	_, ok = table.Lookup(v1alpha2.ModelProviderAnthropic, "gpt-4o")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte(`OpenAI: {gpt-4o: {functionCaling: true}}`), 0o600))
+	_, err = LoadCapabilities(path)
+	assert.ErrorContains(t, err, "failed to parse model capabilities file")
+}
@@ -0,0 +1,72 @@
+package sessionexport
+
+import (
+	"strings"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+// Ratings of feedback dataset records
+const (
+	RatingPositive = "positive"
+	RatingNegative = "negative"
+)
+
+// DatasetMessage converts the data of a session event or of an A2A message to
+// a message of a dataset record, with its text, tool calls and tool results
+// rendered as in the markdown export. ok is false if the data has none of them.
+func DatasetMessage(data string) (message api.DatasetMessage, ok bool) {
+	role, blocks := eventBlocks(eventData(data))
+	if len(blocks) == 0 {
+		return api.DatasetMessage{}, false
+	}
+	if role != "user" {
+		role = "assistant"
+	}
+
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeBlock(&b, block)
+	}
+	return api.DatasetMessage{Role: role, Content: strings.TrimSuffix(b.String(), "\n")}, true
+}
+
+// NewDatasetRecord builds the dataset record of feedback on the response at
+// index i of a conversation, given as the data of its events or A2A messages
+// in chronological order. ok is false if the response has no content.
+func NewDatasetRecord(feedback *database.Feedback, conversation []string, i int) (record *api.FeedbackDatasetRecord, ok bool) {
+	response, ok := DatasetMessage(conversation[i])
+	if !ok {
+		return nil, false
+	}
+
+	record = &api.FeedbackDatasetRecord{
+		FeedbackID:      feedback.ID,
+		CreatedAt:       feedback.CreatedAt.UTC(),
+		AgentID:         feedback.AgentID,
+		AgentGeneration: feedback.AgentGeneration,
+		ModelConfig:     feedback.ModelConfig,
+		SessionID:       feedback.SessionID,
+		TaskID:          feedback.TaskID,
+		EventID:         feedback.EventID,
+		MessageID:       feedback.MessageID,
+		Context:         []api.DatasetMessage{},
+		Response:        response,
+		Rating:          RatingNegative,
+		IssueType:       feedback.IssueType,
+		FeedbackText:    feedback.FeedbackText,
+	}
+	if feedback.IsPositive {
+		record.Rating = RatingPositive
+	}
+	for _, data := range conversation[:i] {
+		if message, ok := DatasetMessage(data); ok {
+			record.Context = append(record.Context, message)
+		}
+	}
+	return record, true
+}
@@ -0,0 +1,35 @@
+package sessionexport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+func TestNewDatasetRecord(t *testing.T) {
+	issueType := database.FeedbackIssueTypeTool
+	feedback := &database.Feedback{UserID: "user-1", AgentID: "default/k8s-agent", EventID: "call", IsPositive: false, FeedbackText: "wrong tool", IssueType: &issueType}
+	conversation := []string{
+		`{"author":"user","content":{"role":"user","parts":[{"text":"Why is my ingress down?"}]}}`,
+		`{"author":"k8s_agent","actions":{"state_delta":{}}}`,
+		`{"kind":"message","role":"agent","parts":[{"kind":"text","text":"Let me look."},{"kind":"data","data":{"id":"call-1","name":"k8s_get_resources","args":{"kind":"ingress"}},"metadata":{"kagent_type":"function_call"}}]}`,
+	}
+
+	record, ok := NewDatasetRecord(feedback, conversation, 2)
+	require.True(t, ok)
+	assert.Equal(t, []api.DatasetMessage{{Role: "user", Content: "Why is my ingress down?"}}, record.Context, "events without content are left out")
+	assert.Equal(t, api.DatasetMessage{
+		Role:    "assistant",
+		Content: "Let me look.\n\n**Tool call: `k8s_get_resources` (id: `call-1`)**\n\n```json\n{\n  \"kind\": \"ingress\"\n}\n```",
+	}, record.Response)
+	assert.Equal(t, RatingNegative, record.Rating)
+	assert.Equal(t, "default/k8s-agent", record.AgentID)
+	assert.Equal(t, &issueType, record.IssueType)
+
+	_, ok = NewDatasetRecord(feedback, conversation, 1)
+	assert.False(t, ok, "responses without content have no record")
+}
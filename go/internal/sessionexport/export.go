@@ -0,0 +1,161 @@
+// Package sessionexport renders a session with its tasks and events in the
+// formats sessions are exported in, and reads sessions back from the json
+// format.
+package sessionexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+type format struct {
+	contentType string
+	extension   string
+}
+
+var formats = map[string]format{
+	api.SessionExportFormatJSON:     {contentType: "application/json", extension: "json"},
+	api.SessionExportFormatJSONL:    {contentType: "application/x-ndjson", extension: "jsonl"},
+	api.SessionExportFormatMarkdown: {contentType: "text/markdown; charset=utf-8", extension: "md"},
+}
+
+// ContentType returns the content type and file extension of an export format,
+// or an error if the format isn't supported.
+func ContentType(name string) (contentType, extension string, err error) {
+	f, ok := formats[name]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported export format %q, must be one of json, jsonl or markdown", name)
+	}
+	return f.contentType, f.extension, nil
+}
+
+// New builds the export of a session, with its events in chronological order.
+func New(session *database.Session, tasks []*protocol.Task, events []*database.Event, now time.Time) *api.SessionExport {
+	export := &api.SessionExport{
+		Version:    api.SessionExportVersion,
+		ExportedAt: now.UTC(),
+		Session:    *session,
+		Tasks:      tasks,
+		Events:     make([]api.ExportedEvent, 0, len(events)),
+	}
+	if export.Tasks == nil {
+		export.Tasks = []*protocol.Task{}
+	}
+	for _, event := range events {
+		export.Events = append(export.Events, api.ExportedEvent{
+			ID:        event.ID,
+			CreatedAt: event.CreatedAt,
+			AuthorID:  event.AuthorID,
+			Data:      eventData(event.Data),
+		})
+	}
+	slices.SortStableFunc(export.Events, func(a, b api.ExportedEvent) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return export
+}
+
+// eventData embeds the data of an event as JSON, or as a JSON string if it
+// isn't valid JSON.
+func eventData(data string) json.RawMessage {
+	if json.Valid([]byte(data)) {
+		return json.RawMessage(data)
+	}
+	quoted, _ := json.Marshal(data)
+	return quoted
+}
+
+// EventData returns the data of an exported event as stored.
+func EventData(event api.ExportedEvent) string {
+	var data string
+	if err := json.Unmarshal(event.Data, &data); err == nil {
+		return data
+	}
+	return string(event.Data)
+}
+
+// Write writes the export of a session in a format.
+func Write(w io.Writer, export *api.SessionExport, format string) error {
+	switch format {
+	case api.SessionExportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(export)
+	case api.SessionExportFormatJSONL:
+		return writeJSONL(w, export)
+	case api.SessionExportFormatMarkdown:
+		return writeMarkdown(w, export)
+	default:
+		_, _, err := ContentType(format)
+		return err
+	}
+}
+
+// jsonlRecord is a line of the jsonl format: the session first, then its tasks
+// and its events.
+type jsonlRecord struct {
+	Type       string             `json:"type"`
+	Version    int                `json:"version,omitempty"`
+	ExportedAt *time.Time         `json:"exported_at,omitempty"`
+	Session    *api.Session       `json:"session,omitempty"`
+	Task       *protocol.Task     `json:"task,omitempty"`
+	Event      *api.ExportedEvent `json:"event,omitempty"`
+}
+
+func writeJSONL(w io.Writer, export *api.SessionExport) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(jsonlRecord{Type: "session", Version: export.Version, ExportedAt: &export.ExportedAt, Session: &export.Session}); err != nil {
+		return err
+	}
+	for _, task := range export.Tasks {
+		if err := encoder.Encode(jsonlRecord{Type: "task", Task: task}); err != nil {
+			return err
+		}
+	}
+	for i := range export.Events {
+		if err := encoder.Encode(jsonlRecord{Type: "event", Event: &export.Events[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that an export can be imported.
+func Validate(export *api.SessionExport) error {
+	if export.Version != api.SessionExportVersion {
+		return fmt.Errorf("unsupported export version %d, expected %d", export.Version, api.SessionExportVersion)
+	}
+	if export.Session.ID == "" {
+		return fmt.Errorf("session id is required")
+	}
+	taskIDs := make(map[string]bool, len(export.Tasks))
+	for _, task := range export.Tasks {
+		if task == nil || task.ID == "" {
+			return fmt.Errorf("task id is required")
+		}
+		if taskIDs[task.ID] {
+			return fmt.Errorf("duplicate task %s", task.ID)
+		}
+		taskIDs[task.ID] = true
+	}
+	eventIDs := make(map[string]bool, len(export.Events))
+	for _, event := range export.Events {
+		if event.ID == "" {
+			return fmt.Errorf("event id is required")
+		}
+		if eventIDs[event.ID] {
+			return fmt.Errorf("duplicate event %s", event.ID)
+		}
+		eventIDs[event.ID] = true
+		if len(event.Data) == 0 {
+			return fmt.Errorf("data of event %s is required", event.ID)
+		}
+	}
+	return nil
+}
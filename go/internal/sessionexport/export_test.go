@@ -0,0 +1,121 @@
+package sessionexport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+func testExport(t *testing.T) *api.SessionExport {
+	t.Helper()
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	session := &database.Session{ID: "session-1", Name: ptr.To("Ingress debugging"), UserID: "user-1", AgentID: ptr.To("kagent__NS__k8s_agent"), CreatedAt: start}
+	tasks := []*protocol.Task{{ID: "task-1", ContextID: "session-1", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}}}
+	// Events are listed newest first
+	events := []*database.Event{
+		{ID: "result", CreatedAt: start.Add(3 * time.Second), Data: `{"author":"k8s_agent","content":{"role":"user","parts":[{"function_response":{"id":"call-1","name":"k8s_get_resources","response":{"result":"ingress-nginx"}}}]}}`},
+		{ID: "call", CreatedAt: start.Add(2 * time.Second), Data: `{"kind":"message","role":"agent","parts":[{"kind":"text","text":"Let me look."},{"kind":"data","data":{"id":"call-1","name":"k8s_get_resources","args":{"kind":"ingress"}},"metadata":{"kagent_type":"function_call"}}]}`},
+		{ID: "question", CreatedAt: start.Add(time.Second), Data: `{"kind":"message","role":"user","parts":[{"kind":"text","text":"Why is my ingress down?"}]}`},
+		{ID: "raw", CreatedAt: start, Data: "not json"},
+	}
+	return New(session, tasks, events, start.Add(time.Hour))
+}
+
+func TestNew(t *testing.T) {
+	export := testExport(t)
+
+	assert.Equal(t, api.SessionExportVersion, export.Version)
+	var ids []string
+	for _, event := range export.Events {
+		ids = append(ids, event.ID)
+	}
+	assert.Equal(t, []string{"raw", "question", "call", "result"}, ids, "events are in chronological order")
+	assert.JSONEq(t, `"not json"`, string(export.Events[0].Data), "invalid JSON is exported as a string")
+	assert.Equal(t, "not json", EventData(export.Events[0]))
+	assert.JSONEq(t, string(export.Events[1].Data), EventData(export.Events[1]))
+	assert.NoError(t, Validate(export))
+
+	assert.Equal(t, []*protocol.Task{}, New(&database.Session{ID: "empty"}, nil, nil, time.Now()).Tasks)
+}
+
+func TestWrite(t *testing.T) {
+	export := testExport(t)
+
+	t.Run("json round trips", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, Write(&b, export, api.SessionExportFormatJSON))
+		var decoded api.SessionExport
+		require.NoError(t, json.Unmarshal(b.Bytes(), &decoded))
+		assert.Equal(t, export.Session.ID, decoded.Session.ID)
+		assert.Equal(t, "task-1", decoded.Tasks[0].ID)
+		require.Len(t, decoded.Events, 4)
+		assert.Equal(t, "not json", EventData(decoded.Events[0]))
+		assert.NoError(t, Validate(&decoded))
+	})
+
+	t.Run("jsonl has a line per record", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, Write(&b, export, api.SessionExportFormatJSONL))
+		var types []string
+		scanner := bufio.NewScanner(&b)
+		for scanner.Scan() {
+			var record jsonlRecord
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			types = append(types, record.Type)
+		}
+		assert.Equal(t, []string{"session", "task", "event", "event", "event", "event"}, types)
+	})
+
+	t.Run("markdown renders text, tool calls and tool results", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, Write(&b, export, api.SessionExportFormatMarkdown))
+		markdown := b.String()
+		assert.Contains(t, markdown, "# Ingress debugging\n")
+		assert.Contains(t, markdown, "- `task-1`: completed\n")
+		assert.Contains(t, markdown, "### user · 2025-01-02T03:04:06Z\n\nWhy is my ingress down?\n")
+		assert.Contains(t, markdown, "### agent · 2025-01-02T03:04:07Z\n\nLet me look.\n")
+		assert.Contains(t, markdown, "**Tool call: `k8s_get_resources` (id: `call-1`)**\n\n```json\n{\n  \"kind\": \"ingress\"\n}\n```\n")
+		assert.Contains(t, markdown, "### k8s_agent · 2025-01-02T03:04:08Z\n\n**Tool result: `k8s_get_resources` (id: `call-1`)**\n\n```json\n{\n  \"result\": \"ingress-nginx\"\n}\n```\n")
+		assert.Contains(t, markdown, "### unknown · 2025-01-02T03:04:05Z\n\nnot json\n")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		assert.Error(t, Write(&bytes.Buffer{}, export, "yaml"))
+		_, _, err := ContentType("yaml")
+		assert.Error(t, err)
+	})
+}
+
+func TestCodeFence(t *testing.T) {
+	assert.Equal(t, "```", codeFence(`{"a": "b"}`))
+	assert.Equal(t, "````", codeFence("```go\n```"))
+}
+
+func TestValidate(t *testing.T) {
+	for name, modify := range map[string]func(*api.SessionExport){
+		"version":    func(e *api.SessionExport) { e.Version = 2 },
+		"session id": func(e *api.SessionExport) { e.Session.ID = "" },
+		"task id":    func(e *api.SessionExport) { e.Tasks[0].ID = "" },
+		"event id":   func(e *api.SessionExport) { e.Events[0].ID = "" },
+		"event data": func(e *api.SessionExport) { e.Events[0].Data = nil },
+		"nil task":   func(e *api.SessionExport) { e.Tasks = append(e.Tasks, nil) },
+		"duplicate task": func(e *api.SessionExport) {
+			e.Tasks = append(e.Tasks, &protocol.Task{ID: e.Tasks[0].ID})
+		},
+		"duplicate event": func(e *api.SessionExport) { e.Events = append(e.Events, e.Events[0]) },
+	} {
+		export := testExport(t)
+		modify(export)
+		assert.Error(t, Validate(export), name)
+	}
+}
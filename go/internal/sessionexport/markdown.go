@@ -0,0 +1,210 @@
+package sessionexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+// Metadata types of the data parts of A2A messages carrying tool calls and
+// their results.
+const (
+	kagentTypeKey              = "kagent_type"
+	kagentTypeFunctionCall     = "function_call"
+	kagentTypeFunctionResponse = "function_response"
+)
+
+// block is a part of an event as rendered in a transcript.
+type block struct {
+	text string
+	// title and code render a labelled code block, e.g. a tool call.
+	title string
+	code  string
+}
+
+func writeMarkdown(w io.Writer, export *api.SessionExport) error {
+	var b strings.Builder
+	session := export.Session
+	title := session.ID
+	if session.Name != nil && *session.Name != "" {
+		title = *session.Name
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", session.ID)
+	if session.AgentID != nil {
+		fmt.Fprintf(&b, "- **Agent:** `%s`\n", *session.AgentID)
+	}
+	fmt.Fprintf(&b, "- **User:** `%s`\n", session.UserID)
+	fmt.Fprintf(&b, "- **Created:** %s\n", formatTime(session.CreatedAt))
+	fmt.Fprintf(&b, "- **Exported:** %s\n", formatTime(export.ExportedAt))
+
+	if len(export.Tasks) > 0 {
+		b.WriteString("\n## Tasks\n\n")
+		for _, task := range export.Tasks {
+			fmt.Fprintf(&b, "- `%s`: %s\n", task.ID, task.Status.State)
+		}
+	}
+
+	b.WriteString("\n## Transcript\n")
+	for _, event := range export.Events {
+		role, blocks := eventBlocks(event.Data)
+		if len(blocks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s · %s\n", role, formatTime(event.CreatedAt))
+		for _, block := range blocks {
+			b.WriteString("\n")
+			writeBlock(&b, block)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeBlock(b *strings.Builder, block block) {
+	if block.title == "" {
+		b.WriteString(block.text + "\n")
+		return
+	}
+	fence := codeFence(block.code)
+	fmt.Fprintf(b, "**%s**\n\n%sjson\n%s\n%s\n", block.title, fence, block.code, fence)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// eventBlocks returns the author of an event and its text, tool calls and tool
+// results. Events are either A2A messages, with tool calls in data parts, or
+// ADK events, with the parts of their content.
+func eventBlocks(data json.RawMessage) (string, []block) {
+	var event map[string]any
+	if err := json.Unmarshal(data, &event); err != nil {
+		var text string
+		if json.Unmarshal(data, &text) == nil && strings.TrimSpace(text) != "" {
+			return "unknown", []block{{text: text}}
+		}
+		return "unknown", nil
+	}
+
+	if parts, ok := event["parts"].([]any); ok {
+		return stringField(event, "role"), messageBlocks(parts)
+	}
+
+	role := stringField(event, "author")
+	content, _ := event["content"].(map[string]any)
+	if role == "" {
+		role = stringField(content, "role")
+	}
+	parts, _ := content["parts"].([]any)
+	return role, adkBlocks(parts)
+}
+
+func messageBlocks(parts []any) []block {
+	var blocks []block
+	for _, p := range parts {
+		part, _ := p.(map[string]any)
+		switch stringField(part, "kind") {
+		case "text":
+			if text := stringField(part, "text"); strings.TrimSpace(text) != "" {
+				blocks = append(blocks, block{text: text})
+			}
+		case "data":
+			metadata, _ := part["metadata"].(map[string]any)
+			data, _ := part["data"].(map[string]any)
+			switch stringField(metadata, kagentTypeKey) {
+			case kagentTypeFunctionCall:
+				blocks = append(blocks, toolCallBlock(data, "args"))
+			case kagentTypeFunctionResponse:
+				blocks = append(blocks, toolResultBlock(data, "response"))
+			default:
+				blocks = append(blocks, block{title: "Data", code: indentJSON(part["data"])})
+			}
+		case "file":
+			file, _ := part["file"].(map[string]any)
+			name := stringField(file, "name")
+			if name == "" {
+				name = stringField(file, "uri")
+			}
+			blocks = append(blocks, block{text: fmt.Sprintf("_File: %s_", name)})
+		}
+	}
+	return blocks
+}
+
+func adkBlocks(parts []any) []block {
+	var blocks []block
+	for _, p := range parts {
+		part, _ := p.(map[string]any)
+		if text := stringField(part, "text"); strings.TrimSpace(text) != "" {
+			blocks = append(blocks, block{text: text})
+		}
+		if call, ok := field(part, "function_call", "functionCall").(map[string]any); ok {
+			blocks = append(blocks, toolCallBlock(call, "args"))
+		}
+		if response, ok := field(part, "function_response", "functionResponse").(map[string]any); ok {
+			blocks = append(blocks, toolResultBlock(response, "response"))
+		}
+	}
+	return blocks
+}
+
+func toolCallBlock(call map[string]any, argsKey string) block {
+	return block{title: toolTitle("Tool call", call), code: indentJSON(call[argsKey])}
+}
+
+func toolResultBlock(result map[string]any, responseKey string) block {
+	return block{title: toolTitle("Tool result", result), code: indentJSON(result[responseKey])}
+}
+
+func toolTitle(label string, tool map[string]any) string {
+	title := fmt.Sprintf("%s: `%s`", label, stringField(tool, "name"))
+	if id := stringField(tool, "id"); id != "" {
+		title += fmt.Sprintf(" (id: `%s`)", id)
+	}
+	return title
+}
+
+func indentJSON(value any) string {
+	if value == nil {
+		return "{}"
+	}
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(out)
+}
+
+// codeFence returns a fence longer than any run of backticks in code.
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func field(m map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if value, ok := m[key]; ok && value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+func stringField(m map[string]any, keys ...string) string {
+	value, _ := field(m, keys...).(string)
+	return value
+}
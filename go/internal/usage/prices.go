@@ -0,0 +1,113 @@
+package usage
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed prices.yaml
+var defaultPrices []byte
+
+// Price is the price of a model in USD per million tokens.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+	// Cached is the price of prompt tokens read from the provider's cache.
+	// Zero charges them at the input price.
+	Cached float64 `json:"cached,omitempty"`
+}
+
+// PriceTable holds the prices of models by name.
+type PriceTable map[string]Price
+
+// DefaultPrices returns the table bundled with kagent.
+func DefaultPrices() PriceTable {
+	table, err := parsePrices(defaultPrices)
+	if err != nil {
+		panic(fmt.Sprintf("invalid bundled model prices: %v", err))
+	}
+	return table
+}
+
+// LoadPrices returns the bundled table with the entries of the YAML file at
+// path overriding or extending it. An empty path returns the bundled table.
+func LoadPrices(path string) (PriceTable, error) {
+	table := DefaultPrices()
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model prices file: %w", err)
+	}
+	overrides, err := parsePrices(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse model prices file %s: %w", path, err)
+	}
+	for model, price := range overrides {
+		table[model] = price
+	}
+	return table, nil
+}
+
+func parsePrices(data []byte) (PriceTable, error) {
+	table := PriceTable{}
+	if err := yaml.UnmarshalStrict(data, &table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// Lookup returns the price of the model, matching its name exactly or by the
+// longest known prefix. Names prefixed with a provider, such as
+// openai/gpt-4o, are matched without the prefix too.
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := t.lookup(model); ok {
+		return price, true
+	}
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		return t.lookup(model[i+1:])
+	}
+	return Price{}, false
+}
+
+func (t PriceTable) lookup(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	var match string
+	for name := range t {
+		if len(name) > len(match) && strings.HasPrefix(model, name) {
+			match = name
+		}
+	}
+	if match == "" {
+		return Price{}, false
+	}
+	return t[match], true
+}
+
+// Cost returns the cost in USD of the tokens used with the model, and whether
+// the model has a price. Cached tokens are part of the prompt tokens.
+func (t PriceTable) Cost(model string, promptTokens, completionTokens, cachedTokens int64) (float64, bool) {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+
+	cachedPrice := price.Cached
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	cachedTokens = min(cachedTokens, promptTokens)
+	cost := float64(promptTokens-cachedTokens)*price.Input +
+		float64(cachedTokens)*cachedPrice +
+		float64(completionTokens)*price.Output
+	return cost / 1_000_000, true
+}
@@ -0,0 +1,192 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/database"
+	common "github.com/kagent-dev/kagent/go/internal/utils"
+)
+
+// Metadata keys of the A2A events emitted by kagent's ADK runtime.
+const (
+	metadataKeyUsage        = "kagent_usage_metadata"
+	metadataKeyEventID      = "kagent_event_id"
+	metadataKeyModelVersion = "kagent_model_version"
+	metadataKeyUserID       = "kagent_user_id"
+	metadataKeySessionID    = "kagent_session_id"
+)
+
+// Recorder persists the token usage reported by agents.
+type Recorder struct {
+	db   database.Client
+	kube client.Reader
+}
+
+// NewRecorder creates a Recorder. The kube client resolves the model of agents
+// whose events don't name it.
+func NewRecorder(db database.Client, kube client.Reader) *Recorder {
+	return &Recorder{db: db, kube: kube}
+}
+
+// RecordA2AMetadata records the usage in the metadata of an A2A event the agent
+// agentRef (namespace/name) streamed for a task. The kagent metadata of the
+// event takes precedence over userID and contextID. Events that don't carry
+// the ID of the ADK event are skipped: their usage can't be told apart from the
+// same usage posted to the session, which older runtimes report only there.
+func (r *Recorder) RecordA2AMetadata(ctx context.Context, agentRef, userID, taskID, contextID string, metadata map[string]any) error {
+	usageMetadata, ok := metadata[metadataKeyUsage].(map[string]any)
+	if !ok {
+		return nil
+	}
+	eventID := stringField(metadata, metadataKeyEventID)
+	if eventID == "" {
+		return nil
+	}
+
+	usage := usageFromMetadata(usageMetadata)
+	if usage == nil {
+		return nil
+	}
+	usage.ID = eventID
+	usage.AgentID = agentRef
+	usage.UserID = firstNonEmpty(stringField(metadata, metadataKeyUserID), userID)
+	usage.SessionID = firstNonEmpty(stringField(metadata, metadataKeySessionID), contextID)
+	usage.TaskID = taskID
+	usage.Model = stringField(metadata, metadataKeyModelVersion)
+	return r.store(ctx, usage)
+}
+
+// RecordSessionEvent records the usage of an ADK event the agent agentRef
+// (namespace/name) posted to its session. Callers must have verified that the
+// event was posted by that agent.
+func (r *Recorder) RecordSessionEvent(ctx context.Context, agentRef string, event *database.Event) error {
+	var data map[string]any
+	if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
+		return fmt.Errorf("failed to parse event %s: %w", event.ID, err)
+	}
+	usageMetadata, ok := field(data, "usage_metadata", "usageMetadata").(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	usage := usageFromMetadata(usageMetadata)
+	if usage == nil {
+		return nil
+	}
+	usage.ID = event.ID
+	usage.AgentID = agentRef
+	// Usage of collaborators in shared sessions is accounted to them
+	usage.UserID = event.UserID
+	if event.AuthorID != "" {
+		usage.UserID = event.AuthorID
+	}
+	usage.SessionID = event.SessionID
+	usage.Model = stringField(data, "model_version", "modelVersion")
+	return r.store(ctx, usage)
+}
+
+func (r *Recorder) store(ctx context.Context, usage *database.Usage) error {
+	if usage.Model == "" {
+		usage.Model = r.agentModel(ctx, usage.AgentID)
+	}
+	if err := r.db.WithContext(ctx).StoreUsage(usage); err != nil {
+		return fmt.Errorf("failed to store usage of agent %s: %w", usage.AgentID, err)
+	}
+	return nil
+}
+
+// agentModel returns the model of the ModelConfig of a declarative agent, or an
+// empty string if it can't be resolved.
+func (r *Recorder) agentModel(ctx context.Context, agentRef string) string {
+	if r.kube == nil || agentRef == "" {
+		return ""
+	}
+	ref, err := common.ParseRefString(agentRef, common.GetResourceNamespace())
+	if err != nil {
+		return ""
+	}
+
+	agent := &v1alpha2.Agent{}
+	if err := r.kube.Get(ctx, ref, agent); err != nil {
+		return ""
+	}
+	if agent.Spec.Declarative == nil || agent.Spec.Declarative.ModelConfig == "" {
+		return ""
+	}
+	modelConfigRef, err := common.ParseRefString(agent.Spec.Declarative.ModelConfig, agent.Namespace)
+	if err != nil {
+		return ""
+	}
+
+	modelConfig := &v1alpha2.ModelConfig{}
+	if err := r.kube.Get(ctx, modelConfigRef, modelConfig); err != nil {
+		return ""
+	}
+	return modelConfig.Spec.Model
+}
+
+// usageFromMetadata reads the token counts of ADK usage metadata, which are
+// camelCase in A2A metadata and snake_case in session events. Thinking tokens
+// are billed as output and count as completion tokens. It returns nil if no
+// tokens were used.
+func usageFromMetadata(metadata map[string]any) *database.Usage {
+	usage := &database.Usage{
+		PromptTokens: intField(metadata, "promptTokenCount", "prompt_token_count"),
+		CompletionTokens: intField(metadata, "candidatesTokenCount", "candidates_token_count") +
+			intField(metadata, "thoughtsTokenCount", "thoughts_token_count"),
+		CachedTokens: intField(metadata, "cachedContentTokenCount", "cached_content_token_count"),
+		TotalTokens:  intField(metadata, "totalTokenCount", "total_token_count"),
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if usage.TotalTokens == 0 {
+		return nil
+	}
+	return usage
+}
+
+func field(m map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if value, ok := m[key]; ok && value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+func stringField(m map[string]any, keys ...string) string {
+	value, _ := field(m, keys...).(string)
+	return value
+}
+
+func intField(m map[string]any, keys ...string) int64 {
+	switch value := field(m, keys...).(type) {
+	case float64:
+		return int64(math.Round(value))
+	case int:
+		return int64(value)
+	case int64:
+		return value
+	case json.Number:
+		n, _ := value.Int64()
+		return n
+	default:
+		return 0
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
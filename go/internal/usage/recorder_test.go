@@ -0,0 +1,165 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kagent-dev/kagent/go/api/v1alpha2"
+	"github.com/kagent-dev/kagent/go/internal/database"
+	database_fake "github.com/kagent-dev/kagent/go/internal/database/fake"
+)
+
+func newTestRecorder(t *testing.T) (*Recorder, database.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha2.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-agent", Namespace: "default"},
+			Spec: v1alpha2.AgentSpec{
+				Type:        v1alpha2.AgentType_Declarative,
+				Declarative: &v1alpha2.DeclarativeAgentSpec{ModelConfig: "gpt"},
+			},
+		},
+		&v1alpha2.ModelConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpt", Namespace: "default"},
+			Spec:       v1alpha2.ModelConfigSpec{Provider: v1alpha2.ModelProviderOpenAI, Model: "gpt-4o"},
+		},
+	).Build()
+	dbClient := database_fake.NewClient()
+	return NewRecorder(dbClient, kubeClient), dbClient
+}
+
+func summarize(t *testing.T, dbClient database.Client, groupBy database.UsageGroupBy) []database.UsageAggregate {
+	t.Helper()
+	aggregates, err := dbClient.SummarizeUsage(groupBy, database.UsageFilter{})
+	require.NoError(t, err)
+	return aggregates
+}
+
+func TestRecordA2AMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records streamed usage once per event", func(t *testing.T) {
+		recorder, dbClient := newTestRecorder(t)
+		metadata := func(prompt, candidates float64) map[string]any {
+			return map[string]any{
+				"kagent_event_id":       "event-1",
+				"kagent_user_id":        "user-1",
+				"kagent_session_id":     "session-1",
+				"kagent_model_version":  "gemini-2.5-flash",
+				"kagent_usage_metadata": map[string]any{"promptTokenCount": prompt, "candidatesTokenCount": candidates, "cachedContentTokenCount": float64(50)},
+			}
+		}
+
+		require.NoError(t, recorder.RecordA2AMetadata(ctx, "default/test-agent", "ignored", "task-1", "context-1", metadata(100, 5)))
+		require.NoError(t, recorder.RecordA2AMetadata(ctx, "default/test-agent", "ignored", "task-1", "context-1", metadata(100, 20)))
+
+		assert.Equal(t, []database.UsageAggregate{{
+			Group:            "user-1",
+			Model:            "gemini-2.5-flash",
+			Calls:            1,
+			PromptTokens:     100,
+			CompletionTokens: 20,
+			CachedTokens:     50,
+			TotalTokens:      120,
+		}}, summarize(t, dbClient, database.UsageGroupByUser))
+	})
+
+	t.Run("falls back to the model of the agent and the request context", func(t *testing.T) {
+		recorder, dbClient := newTestRecorder(t)
+		require.NoError(t, recorder.RecordA2AMetadata(ctx, "default/test-agent", "user-1", "task-1", "context-1", map[string]any{
+			"kagent_event_id":       "event-1",
+			"kagent_usage_metadata": map[string]any{"promptTokenCount": float64(10), "totalTokenCount": float64(12)},
+		}))
+
+		aggregates := summarize(t, dbClient, database.UsageGroupBySession)
+		require.Len(t, aggregates, 1)
+		assert.Equal(t, "context-1", aggregates[0].Group)
+		assert.Equal(t, "gpt-4o", aggregates[0].Model)
+		assert.Equal(t, int64(12), aggregates[0].TotalTokens)
+	})
+
+	t.Run("skips events without usage or event ID", func(t *testing.T) {
+		recorder, dbClient := newTestRecorder(t)
+		require.NoError(t, recorder.RecordA2AMetadata(ctx, "default/test-agent", "user-1", "task-1", "context-1", map[string]any{
+			"kagent_event_id": "event-1",
+		}))
+		require.NoError(t, recorder.RecordA2AMetadata(ctx, "default/test-agent", "user-1", "task-1", "context-1", map[string]any{
+			"kagent_usage_metadata": map[string]any{"promptTokenCount": float64(10)},
+		}))
+		assert.Empty(t, summarize(t, dbClient, database.UsageGroupByNone))
+	})
+}
+
+func TestRecordSessionEvent(t *testing.T) {
+	ctx := context.Background()
+	recorder, dbClient := newTestRecorder(t)
+
+	event := &database.Event{
+		ID:        "event-1",
+		SessionID: "session-1",
+		UserID:    "user-1",
+		Data:      `{"id": "event-1", "model_version": "claude-sonnet-4-20250514", "usage_metadata": {"prompt_token_count": 1000, "candidates_token_count": 100, "thoughts_token_count": 50, "total_token_count": 1150}}`,
+	}
+	require.NoError(t, recorder.RecordSessionEvent(ctx, "default/test-agent", event))
+
+	// The same event streamed through the A2A proxy is not counted twice
+	require.NoError(t, recorder.RecordA2AMetadata(ctx, "default/test-agent", "user-1", "task-1", "session-1", map[string]any{
+		"kagent_event_id":       "event-1",
+		"kagent_usage_metadata": map[string]any{"promptTokenCount": float64(1000), "candidatesTokenCount": float64(100), "thoughtsTokenCount": float64(50), "totalTokenCount": float64(1150)},
+	}))
+
+	assert.Equal(t, []database.UsageAggregate{{
+		Group:            "default/test-agent",
+		Model:            "claude-sonnet-4-20250514",
+		Calls:            1,
+		PromptTokens:     1000,
+		CompletionTokens: 150,
+		TotalTokens:      1150,
+	}}, summarize(t, dbClient, database.UsageGroupByAgent))
+
+	require.Error(t, recorder.RecordSessionEvent(ctx, "default/test-agent", &database.Event{ID: "event-2", Data: "not json"}))
+}
+
+func TestPrices(t *testing.T) {
+	prices := DefaultPrices()
+
+	price, ok := prices.Lookup("gpt-4o-mini-2024-07-18")
+	require.True(t, ok)
+	assert.Equal(t, 0.15, price.Input)
+
+	_, ok = prices.Lookup("openai/gpt-4o")
+	assert.True(t, ok)
+
+	_, ok = prices.Lookup("llama3.2")
+	assert.False(t, ok)
+
+	// 1M prompt tokens of which half are cached, and 100k completion tokens
+	cost, ok := prices.Cost("gpt-4o", 1_000_000, 100_000, 500_000)
+	require.True(t, ok)
+	assert.InDelta(t, 0.5*2.5+0.5*1.25+0.1*10, cost, 1e-9)
+
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("llama3.2: {input: 0.1, output: 0.2}\ngpt-4o: {input: 5, output: 20}\n"), 0o600))
+	loaded, err := LoadPrices(path)
+	require.NoError(t, err)
+
+	cost, ok = loaded.Cost("llama3.2", 1_000_000, 1_000_000, 1_000_000)
+	require.True(t, ok)
+	assert.InDelta(t, 0.3, cost, 1e-9, "cached tokens are charged at the input price without a cached price")
+	price, _ = loaded.Lookup("gpt-4o")
+	assert.Equal(t, 5.0, price.Input)
+
+	require.NoError(t, os.WriteFile(path, []byte("gpt-4o: {input: 5, outptu: 20}\n"), 0o600))
+	_, err = LoadPrices(path)
+	assert.Error(t, err)
+}
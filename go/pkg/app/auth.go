@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	authimpl "github.com/kagent-dev/kagent/go/internal/httpserver/auth"
+	"github.com/kagent-dev/kagent/go/pkg/auth"
+)
+
+const (
+	AuthProviderUnsecure    = "unsecure"
+	AuthProviderOIDC        = "oidc"
+	AuthProviderTokenReview = "tokenreview"
+
+	AuthorizerNoop = "noop"
+	AuthorizerRBAC = "rbac"
+)
+
+// NewAuthenticator builds the authenticator selected by the --auth-providers flag.
+// When several providers are configured they are tried in order. If
+// --token-exchange-url is set, tokens forwarded to agents are exchanged for
+// on-behalf-of tokens.
+func NewAuthenticator(ctx context.Context, cfg *Config, kube client.Client) (auth.AuthProvider, error) {
+	provider, err := newAuthProvider(ctx, cfg, kube)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Auth.TokenExchange.URL == "" {
+		return provider, nil
+	}
+
+	tokenExchange, err := authimpl.NewTokenExchangeAuthenticator(provider, kube, authimpl.TokenExchangeConfig{
+		TokenURL:            cfg.Auth.TokenExchange.URL,
+		Audience:            cfg.Auth.TokenExchange.Audience,
+		Scope:               cfg.Auth.TokenExchange.Scope,
+		ActorTokenAudiences: splitList(cfg.Auth.TokenExchange.ActorTokenAudiences),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token exchange authenticator: %w", err)
+	}
+	return tokenExchange, nil
+}
+
+func newAuthProvider(ctx context.Context, cfg *Config, kube client.Client) (auth.AuthProvider, error) {
+	names := splitList(cfg.Auth.Providers)
+	// Requests rejected by the other providers would fall back to trusting headers
+	if len(names) > 1 && slices.Contains(names, AuthProviderUnsecure) {
+		return nil, fmt.Errorf("auth provider %q trusts request headers and can't be combined with other providers", AuthProviderUnsecure)
+	}
+
+	var providers []auth.AuthProvider
+	for _, name := range names {
+		switch name {
+		case AuthProviderUnsecure:
+			providers = append(providers, &authimpl.UnsecureAuthenticator{})
+		case AuthProviderOIDC:
+			oidc, err := authimpl.NewOIDCAuthenticator(ctx, authimpl.OIDCConfig{
+				IssuerURL:     cfg.Auth.OIDC.IssuerURL,
+				Audience:      cfg.Auth.OIDC.Audience,
+				JWKSURL:       cfg.Auth.OIDC.JWKSURL,
+				UsernameClaim: cfg.Auth.OIDC.UsernameClaim,
+				RolesClaim:    cfg.Auth.OIDC.RolesClaim,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create oidc authenticator: %w", err)
+			}
+			providers = append(providers, oidc)
+		case AuthProviderTokenReview:
+			providers = append(providers, authimpl.NewTokenReviewAuthenticator(kube, splitList(cfg.Auth.TokenReview.Audiences)))
+		default:
+			return nil, fmt.Errorf("unsupported auth provider %q", name)
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("no auth providers configured")
+	case 1:
+		return providers[0], nil
+	default:
+		return authimpl.NewChainAuthenticator(providers...), nil
+	}
+}
+
+// NewAuthorizer builds the authorizer selected by the --authorizer flag. The rbac
+// authorizer registers a watcher with the manager to hot reload its policy.
+func NewAuthorizer(cfg *Config, mgr manager.Manager) (auth.Authorizer, error) {
+	switch cfg.Auth.Authorizer {
+	case AuthorizerNoop:
+		return &authimpl.NoopAuthorizer{}, nil
+	case AuthorizerRBAC:
+		authorizer := authimpl.NewRBACAuthorizer()
+		if err := mgr.Add(authimpl.NewRBACPolicyWatcher(
+			mgr.GetConfig(),
+			mgr.GetScheme(),
+			cfg.Auth.RBAC.PolicyConfigMap,
+			authorizer,
+		)); err != nil {
+			return nil, fmt.Errorf("failed to add rbac policy watcher: %w", err)
+		}
+		return authorizer, nil
+	default:
+		return nil, fmt.Errorf("unsupported authorizer %q", cfg.Auth.Authorizer)
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
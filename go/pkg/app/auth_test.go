@@ -0,0 +1,25 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuthProvider(t *testing.T) {
+	cfg := &Config{}
+	cfg.Auth.Providers = AuthProviderUnsecure
+	provider, err := newAuthProvider(context.Background(), cfg, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	cfg.Auth.Providers = "tokenreview, unsecure"
+	_, err = newAuthProvider(context.Background(), cfg, nil)
+	assert.ErrorContains(t, err, `"unsecure" trusts request headers`)
+
+	cfg.Auth.Providers = ""
+	_, err = newAuthProvider(context.Background(), cfg, nil)
+	assert.EqualError(t, err, "no auth providers configured")
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+)
+
+var (
+	retentionLog = ctrl.Log.WithName("retention")
+
+	retentionRowsPruned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_retention_rows_pruned_total",
+		Help: "Number of database rows removed by retention, by table and action (delete for soft-deletes, purge for permanent removal).",
+	}, []string{"table", "action"})
+	retentionRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kagent_retention_runs_total",
+		Help: "Number of retention runs, by result.",
+	}, []string{"result"})
+	retentionLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kagent_retention_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful retention run.",
+	})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(retentionRowsPruned, retentionRuns, retentionLastSuccess)
+}
+
+// pruner is the subset of the database manager used by the retention runnable.
+type pruner interface {
+	Prune(ctx context.Context, policy database.RetentionPolicy, now time.Time) (*database.PruneResult, error)
+}
+
+// retentionRunnable applies the retention policy periodically. It runs on the
+// leader only, so replicas don't prune concurrently.
+type retentionRunnable struct {
+	pruner   pruner
+	policy   database.RetentionPolicy
+	interval time.Duration
+}
+
+var _ manager.Runnable = (*retentionRunnable)(nil)
+var _ manager.LeaderElectionRunnable = (*retentionRunnable)(nil)
+
+func newRetentionRunnable(p pruner, policy database.RetentionPolicy, interval time.Duration) *retentionRunnable {
+	return &retentionRunnable{
+		pruner:   p,
+		policy:   policy,
+		interval: interval,
+	}
+}
+
+func (r *retentionRunnable) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *retentionRunnable) Start(ctx context.Context) error {
+	retentionLog.Info("starting retention", "maxAge", r.policy.MaxAge, "maxSessionsPerAgent", r.policy.MaxSessionsPerAgent,
+		"purgeDeletedAfter", r.policy.PurgeDeletedAfter, "interval", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.prune(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *retentionRunnable) prune(ctx context.Context) {
+	start := time.Now()
+	result, err := r.pruner.Prune(ctx, r.policy, start)
+
+	// rows pruned before an error are still counted
+	if result != nil {
+		for table, rows := range result.Deleted {
+			retentionRowsPruned.WithLabelValues(table, "delete").Add(float64(rows))
+		}
+		for table, rows := range result.Purged {
+			retentionRowsPruned.WithLabelValues(table, "purge").Add(float64(rows))
+		}
+	}
+
+	if err != nil {
+		retentionRuns.WithLabelValues("error").Inc()
+		retentionLog.Error(err, "retention run failed")
+		return
+	}
+
+	retentionRuns.WithLabelValues("success").Inc()
+	retentionLastSuccess.SetToCurrentTime()
+	retentionLog.Info("retention run completed", "deleted", result.Deleted, "purged", result.Purged, "duration", time.Since(start))
+}
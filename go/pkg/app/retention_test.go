@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kagent-dev/kagent/go/internal/database"
+)
+
+type fakePruner struct {
+	result *database.PruneResult
+	err    error
+	calls  int
+}
+
+func (f *fakePruner) Prune(ctx context.Context, policy database.RetentionPolicy, now time.Time) (*database.PruneResult, error) {
+	f.calls++
+	return f.result, f.err
+}
+
+func TestRetentionRunnable(t *testing.T) {
+	pruner := &fakePruner{result: &database.PruneResult{
+		Deleted: map[string]int64{"session": 2, "event": 5},
+		Purged:  map[string]int64{"event": 3},
+	}}
+	runnable := newRetentionRunnable(pruner, database.RetentionPolicy{MaxAge: time.Hour}, time.Hour)
+	assert.True(t, runnable.NeedLeaderElection())
+
+	sessionsBefore := testutil.ToFloat64(retentionRowsPruned.WithLabelValues("session", "delete"))
+	purgedBefore := testutil.ToFloat64(retentionRowsPruned.WithLabelValues("event", "purge"))
+	errorsBefore := testutil.ToFloat64(retentionRuns.WithLabelValues("error"))
+
+	runnable.prune(context.Background())
+	assert.Equal(t, sessionsBefore+2, testutil.ToFloat64(retentionRowsPruned.WithLabelValues("session", "delete")))
+	assert.Equal(t, purgedBefore+3, testutil.ToFloat64(retentionRowsPruned.WithLabelValues("event", "purge")))
+
+	pruner.err = errors.New("database is locked")
+	runnable.prune(context.Background())
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(retentionRuns.WithLabelValues("error")))
+
+	// Start prunes immediately and stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := pruner.calls
+	cancel()
+	assert.NoError(t, runnable.Start(ctx))
+	assert.Equal(t, calls+1, pruner.calls)
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	cfg := &Config{}
+	cfg.Retention.Interval = 0
+
+	policy, err := cfg.retentionPolicy()
+	assert.NoError(t, err, "the interval of disabled retention is unused")
+	assert.False(t, policy.Enabled())
+
+	cfg.Retention.MaxAge = 24 * time.Hour
+	_, err = cfg.retentionPolicy()
+	assert.EqualError(t, err, "retention-interval must be positive, got 0s")
+
+	cfg.Retention.Interval = time.Hour
+	policy, err = cfg.retentionPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, policy.MaxAge)
+}
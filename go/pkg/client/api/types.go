@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/kagent-dev/kagent/go/api/v1alpha1"
 	"github.com/kagent-dev/kagent/go/api/v1alpha2"
 	"github.com/kagent-dev/kagent/go/internal/database"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
 // Common types
@@ -65,6 +69,8 @@ type CreateModelConfigRequest struct {
 	GeminiParams            *v1alpha2.GeminiConfig            `json:"gemini,omitempty"`
 	GeminiVertexAIParams    *v1alpha2.GeminiVertexAIConfig    `json:"geminiVertexAI,omitempty"`
 	AnthropicVertexAIParams *v1alpha2.AnthropicVertexAIConfig `json:"anthropicVertexAI,omitempty"`
+	OpenAICompatibleParams  *v1alpha2.OpenAICompatibleConfig  `json:"openAICompatible,omitempty"`
+	BedrockParams           *v1alpha2.BedrockConfig           `json:"bedrock,omitempty"`
 }
 
 // UpdateModelConfigRequest represents a request to update a model configuration
@@ -79,6 +85,20 @@ type UpdateModelConfigRequest struct {
 	GeminiParams            *v1alpha2.GeminiConfig            `json:"gemini,omitempty"`
 	GeminiVertexAIParams    *v1alpha2.GeminiVertexAIConfig    `json:"geminiVertexAI,omitempty"`
 	AnthropicVertexAIParams *v1alpha2.AnthropicVertexAIConfig `json:"anthropicVertexAI,omitempty"`
+	OpenAICompatibleParams  *v1alpha2.OpenAICompatibleConfig  `json:"openAICompatible,omitempty"`
+	BedrockParams           *v1alpha2.BedrockConfig           `json:"bedrock,omitempty"`
+}
+
+// DiscoverModelsRequest represents a request to list the models of a provider
+// with the supplied credentials
+type DiscoverModelsRequest struct {
+	Provider               Provider                         `json:"provider"`
+	APIKey                 string                           `json:"apiKey,omitempty"`
+	OpenAIParams           *v1alpha2.OpenAIConfig           `json:"openAI,omitempty"`
+	AnthropicParams        *v1alpha2.AnthropicConfig        `json:"anthropic,omitempty"`
+	AzureParams            *v1alpha2.AzureOpenAIConfig      `json:"azureOpenAI,omitempty"`
+	OllamaParams           *v1alpha2.OllamaConfig           `json:"ollama,omitempty"`
+	OpenAICompatibleParams *v1alpha2.OpenAICompatibleConfig `json:"openAICompatible,omitempty"`
 }
 
 // Agent types
@@ -105,6 +125,49 @@ type SessionRequest struct {
 	ID       *string `json:"id,omitempty"`
 }
 
+// SessionForkRequest represents a request to fork a session from one of its events
+type SessionForkRequest struct {
+	// EventID is the last event copied to the fork.
+	EventID string  `json:"event_id"`
+	Name    *string `json:"name,omitempty"`
+}
+
+// SessionFork represents a session forked from another one
+type SessionFork struct {
+	Session *Session `json:"session"`
+	// EventIDs maps the IDs of the copied events to the IDs of their copies.
+	EventIDs map[string]string `json:"event_ids"`
+}
+
+// SessionShareRequest represents a request to share a session with a user, or
+// to change their role in it
+type SessionShareRequest struct {
+	UserID string `json:"user_id"`
+	// Role is viewer or collaborator.
+	Role string `json:"role"`
+}
+
+// SessionShareLinkRequest represents a request to create a share link of a session
+type SessionShareLinkRequest struct {
+	// Role is viewer or collaborator.
+	Role string `json:"role"`
+	// ExpiresAt is when the link stops granting access. Links without it don't expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// SessionShares represents the users a session is shared with and its share links
+type SessionShares struct {
+	Shares []SessionShare     `json:"shares"`
+	Links  []SessionShareLink `json:"links"`
+}
+
+// CreatedSessionShareLink represents a new share link with its token, which
+// isn't returned again
+type CreatedSessionShareLink struct {
+	SessionShareLink
+	Token string `json:"token"`
+}
+
 // Run types
 
 // RunRequest represents a run creation request
@@ -115,12 +178,59 @@ type RunRequest struct {
 // Run represents a run from the database
 type Task = database.Task
 
+// TaskDecisionRequest approves or rejects the tool calls a paused (input-required) task is waiting on
+type TaskDecisionRequest struct {
+	// Reason is passed to the agent along with the decision
+	Reason string `json:"reason,omitempty"`
+}
+
 // Message represents a message from the database
 type Message = database.Event
 
+// PushNotificationDelivery represents an attempt to deliver a push notification of a task
+type PushNotificationDelivery = database.PushNotificationDelivery
+
 // Session represents a session from the database
 type Session = database.Session
 
+// SessionShare represents a user a session is shared with
+type SessionShare = database.SessionShare
+
+// SessionShareLink represents a share link of a session
+type SessionShareLink = database.SessionShareLink
+
+// SessionSearchResult represents an event or session name matching a session search
+type SessionSearchResult = database.SearchResult
+
+// Formats sessions are exported in
+const (
+	SessionExportFormatJSON     = "json"
+	SessionExportFormatJSONL    = "jsonl"
+	SessionExportFormatMarkdown = "markdown"
+)
+
+// SessionExportVersion is the version of the SessionExport format
+const SessionExportVersion = 1
+
+// SessionExport represents a session exported in the json format, which
+// sessions are imported from
+type SessionExport struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Session    Session          `json:"session"`
+	Tasks      []*protocol.Task `json:"tasks"`
+	Events     []ExportedEvent  `json:"events"`
+}
+
+// ExportedEvent represents an event of an exported session. Data is the
+// event's data as stored, embedded as JSON if it is.
+type ExportedEvent struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	AuthorID  string          `json:"author_id,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
 // Agent represents an agent from the database
 type Agent = database.Agent
 
@@ -132,6 +242,68 @@ type Tool = database.Tool
 // Feedback represents a feedback from the database
 type Feedback = database.Feedback
 
+// FeedbackTotals represents the amount of feedback and how much of it is positive
+type FeedbackTotals struct {
+	Total    int64 `json:"total"`
+	Positive int64 `json:"positive"`
+	// PositiveRatio is Positive over Total, 0 without feedback
+	PositiveRatio float64 `json:"positive_ratio"`
+}
+
+// FeedbackPeriod represents the feedback of one period of a group
+type FeedbackPeriod struct {
+	// Period is the day (YYYY-MM-DD) of the feedback
+	Period string `json:"period"`
+	FeedbackTotals
+}
+
+// FeedbackGroup represents the feedback of one group of a feedback report
+type FeedbackGroup struct {
+	// Key is the agent, agent version (namespace/name@generation), ModelConfig
+	// or issue type of the group
+	Key string `json:"key"`
+	FeedbackTotals
+	// Periods splits the feedback of the group over time, if an interval is set
+	Periods []FeedbackPeriod `json:"periods,omitempty"`
+}
+
+// FeedbackReport represents the response of a feedback analytics query
+type FeedbackReport struct {
+	GroupBy  string          `json:"group_by,omitempty"`
+	Interval string          `json:"interval,omitempty"`
+	From     *time.Time      `json:"from,omitempty"`
+	To       *time.Time      `json:"to,omitempty"`
+	Groups   []FeedbackGroup `json:"groups"`
+	Total    FeedbackTotals  `json:"total"`
+}
+
+// DatasetMessage represents a message of the conversation of a dataset record
+type DatasetMessage struct {
+	// Role is user or assistant
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// FeedbackDatasetRecord represents a line of the JSONL evaluation dataset of
+// feedback: a rated agent response with the conversation leading to it
+type FeedbackDatasetRecord struct {
+	FeedbackID      uint             `json:"feedback_id"`
+	CreatedAt       time.Time        `json:"created_at"`
+	AgentID         string           `json:"agent_id,omitempty"`
+	AgentGeneration int64            `json:"agent_generation,omitempty"`
+	ModelConfig     string           `json:"model_config,omitempty"`
+	SessionID       string           `json:"session_id,omitempty"`
+	TaskID          string           `json:"task_id,omitempty"`
+	EventID         string           `json:"event_id,omitempty"`
+	MessageID       string           `json:"message_id,omitempty"`
+	Context         []DatasetMessage `json:"context"`
+	Response        DatasetMessage   `json:"response"`
+	// Rating is positive or negative
+	Rating       string                      `json:"rating"`
+	IssueType    *database.FeedbackIssueType `json:"issue_type,omitempty"`
+	FeedbackText string                      `json:"feedback_text,omitempty"`
+}
+
 // ToolServer types
 
 // ToolServerResponse represents a tool server response
@@ -183,6 +355,40 @@ type ProviderInfo struct {
 	OptionalParams []string `json:"optionalParams"`
 }
 
+// Usage types
+
+// UsageTotals sums token usage and its cost
+type UsageTotals struct {
+	Calls            int64 `json:"calls"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	CachedTokens     int64 `json:"cached_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	// Cost is the cost in USD of the tokens of models with a known price
+	Cost float64 `json:"cost"`
+	// UnpricedTokens counts the tokens of models without a known price, which Cost leaves out
+	UnpricedTokens int64 `json:"unpriced_tokens,omitempty"`
+}
+
+// UsageGroup represents the usage of one group of a usage report
+type UsageGroup struct {
+	// Key is the agent, user, session, model or day (YYYY-MM-DD) of the group
+	Key string `json:"key"`
+	UsageTotals
+	// Models splits the usage of the group by model
+	Models map[string]UsageTotals `json:"models,omitempty"`
+}
+
+// UsageReport represents the response of a usage query
+type UsageReport struct {
+	GroupBy  string       `json:"group_by,omitempty"`
+	From     *time.Time   `json:"from,omitempty"`
+	To       *time.Time   `json:"to,omitempty"`
+	Currency string       `json:"currency"`
+	Groups   []UsageGroup `json:"groups"`
+	Total    UsageTotals  `json:"total"`
+}
+
 // SessionRunsResponse represents the response for session runs
 type SessionRunsResponse struct {
 	Status bool `json:"status"`
@@ -3,14 +3,60 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
+	"time"
 
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 )
 
+// FeedbackQuery selects the feedback to report or export. Empty fields are not
+// filtered on.
+type FeedbackQuery struct {
+	// GroupBy is one of agent, agent_version, model_config or issue_type.
+	GroupBy string
+	// Interval is day to split each group over time.
+	Interval string
+	From     time.Time
+	To       time.Time
+	// Agent is the agent reference (namespace/name).
+	Agent string
+	User  string
+	// ModelConfig is the ModelConfig reference (namespace/name).
+	ModelConfig string
+	// Rating is positive or negative.
+	Rating string
+}
+
+func (q FeedbackQuery) values() url.Values {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"group_by":     q.GroupBy,
+		"interval":     q.Interval,
+		"agent":        q.Agent,
+		"user":         q.User,
+		"model_config": q.ModelConfig,
+		"rating":       q.Rating,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if !q.From.IsZero() {
+		values.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		values.Set("to", q.To.Format(time.RFC3339))
+	}
+	return values
+}
+
 // Feedback defines the feedback operations
 type Feedback interface {
 	CreateFeedback(ctx context.Context, feedback *api.Feedback, userID string) error
 	ListFeedback(ctx context.Context, userID string) (*api.StandardResponse[[]api.Feedback], error)
+	GetFeedbackStats(ctx context.Context, query FeedbackQuery) (*api.StandardResponse[api.FeedbackReport], error)
+	ExportFeedbackDataset(ctx context.Context, query FeedbackQuery) ([]byte, error)
 }
 
 // feedbackClient handles feedback-related requests
@@ -54,3 +100,41 @@ func (c *feedbackClient) ListFeedback(ctx context.Context, userID string) (*api.
 
 	return &feedback, nil
 }
+
+// GetFeedbackStats reports the amount of feedback and its positive ratio
+func (c *feedbackClient) GetFeedbackStats(ctx context.Context, query FeedbackQuery) (*api.StandardResponse[api.FeedbackReport], error) {
+	path := "/api/feedback/stats"
+	if values := query.values(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	resp, err := c.client.Get(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var report api.StandardResponse[api.FeedbackReport]
+	if err := DecodeResponse(resp, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// ExportFeedbackDataset exports the rated responses with their conversation as
+// JSONL, one api.FeedbackDatasetRecord per line. GroupBy and Interval are ignored.
+func (c *feedbackClient) ExportFeedbackDataset(ctx context.Context, query FeedbackQuery) ([]byte, error) {
+	query.GroupBy, query.Interval = "", ""
+	path := "/api/feedback/export"
+	if values := query.values(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	resp, err := c.client.Get(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
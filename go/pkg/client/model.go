@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"net/url"
 
 	v1alpha2 "github.com/kagent-dev/kagent/go/api/v1alpha2"
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
@@ -11,6 +12,10 @@ import (
 type ModelInfo struct {
 	Name            string `json:"name"`
 	FunctionCalling bool   `json:"function_calling"`
+	// ContextWindow is the maximum number of input tokens, if known.
+	ContextWindow int `json:"context_window,omitempty"`
+	// Vision is set for models that accept images.
+	Vision bool `json:"vision,omitempty"`
 }
 
 // ProviderModels represents a map of provider names to their supported models
@@ -19,6 +24,8 @@ type ProviderModels map[v1alpha2.ModelProvider][]ModelInfo
 // Model defines the model operations
 type Model interface {
 	ListSupportedModels(ctx context.Context) (*api.StandardResponse[ProviderModels], error)
+	ListModels(ctx context.Context, provider v1alpha2.ModelProvider, modelConfigRef string) (*api.StandardResponse[ProviderModels], error)
+	DiscoverModels(ctx context.Context, request *api.DiscoverModelsRequest) (*api.StandardResponse[ProviderModels], error)
 }
 
 // modelClient handles model-related requests
@@ -45,3 +52,46 @@ func (c *modelClient) ListSupportedModels(ctx context.Context) (*api.StandardRes
 
 	return &models, nil
 }
+
+// ListModels lists the models of a provider, or of a ModelConfig
+// (namespace/name). Empty arguments are not filtered on.
+func (c *modelClient) ListModels(ctx context.Context, provider v1alpha2.ModelProvider, modelConfigRef string) (*api.StandardResponse[ProviderModels], error) {
+	query := url.Values{}
+	if provider != "" {
+		query.Set("provider", string(provider))
+	}
+	if modelConfigRef != "" {
+		query.Set("modelConfig", modelConfigRef)
+	}
+	path := "/api/models"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp, err := c.client.Get(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var models api.StandardResponse[ProviderModels]
+	if err := DecodeResponse(resp, &models); err != nil {
+		return nil, err
+	}
+
+	return &models, nil
+}
+
+// DiscoverModels lists the models of a provider with the supplied credentials
+func (c *modelClient) DiscoverModels(ctx context.Context, request *api.DiscoverModelsRequest) (*api.StandardResponse[ProviderModels], error) {
+	resp, err := c.client.Post(ctx, "/api/models", request, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var models api.StandardResponse[ProviderModels]
+	if err := DecodeResponse(resp, &models); err != nil {
+		return nil, err
+	}
+
+	return &models, nil
+}
@@ -3,10 +3,26 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/kagent-dev/kagent/go/pkg/client/api"
 )
 
+// SessionSearchQuery selects the sessions and events to search. Empty fields
+// other than Query are not filtered on.
+type SessionSearchQuery struct {
+	// Query is the text searched for. Results match all of its words.
+	Query string
+	// Agent is the agent reference (namespace/name).
+	Agent string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
 // Session defines the session operations
 type Session interface {
 	ListSessions(ctx context.Context) (*api.StandardResponse[[]*api.Session], error)
@@ -15,6 +31,17 @@ type Session interface {
 	UpdateSession(ctx context.Context, request *api.SessionRequest) (*api.StandardResponse[*api.Session], error)
 	DeleteSession(ctx context.Context, sessionName string) error
 	ListSessionRuns(ctx context.Context, sessionName string) (*api.StandardResponse[any], error)
+	SearchSessions(ctx context.Context, query SessionSearchQuery) (*api.StandardResponse[[]api.SessionSearchResult], error)
+	ExportSession(ctx context.Context, sessionID string, format string) ([]byte, error)
+	ImportSession(ctx context.Context, export *api.SessionExport) (*api.StandardResponse[*api.Session], error)
+	ForkSession(ctx context.Context, sessionID string, request *api.SessionForkRequest) (*api.StandardResponse[*api.SessionFork], error)
+	ListSharedSessions(ctx context.Context) (*api.StandardResponse[[]*api.Session], error)
+	ListSessionShares(ctx context.Context, sessionID string) (*api.StandardResponse[*api.SessionShares], error)
+	ShareSession(ctx context.Context, sessionID string, request *api.SessionShareRequest) (*api.StandardResponse[*api.SessionShare], error)
+	UnshareSession(ctx context.Context, sessionID, userID string) error
+	CreateSessionShareLink(ctx context.Context, sessionID string, request *api.SessionShareLinkRequest) (*api.StandardResponse[*api.CreatedSessionShareLink], error)
+	DeleteSessionShareLink(ctx context.Context, sessionID, linkID string) error
+	RedeemSessionShareLink(ctx context.Context, token string) (*api.StandardResponse[*api.SessionShare], error)
 }
 
 // sessionClient handles session-related requests
@@ -142,3 +169,229 @@ func (c *sessionClient) ListSessionRuns(ctx context.Context, sessionName string)
 
 	return &response, nil
 }
+
+// SearchSessions searches the text of the user's events and their session names
+func (c *sessionClient) SearchSessions(ctx context.Context, query SessionSearchQuery) (*api.StandardResponse[[]api.SessionSearchResult], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	if query.Query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	values := url.Values{}
+	values.Set("q", query.Query)
+	if query.Agent != "" {
+		values.Set("agent", query.Agent)
+	}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	resp, err := c.client.Get(ctx, "/api/sessions/search?"+values.Encode(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[[]api.SessionSearchResult]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ExportSession exports a session with its tasks and events in a format, one of
+// api.SessionExportFormatJSON, api.SessionExportFormatJSONL or
+// api.SessionExportFormatMarkdown
+func (c *sessionClient) ExportSession(ctx context.Context, sessionID string, format string) ([]byte, error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/export?format=%s", url.PathEscape(sessionID), url.QueryEscape(format))
+	resp, err := c.client.Get(ctx, path, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// ImportSession recreates a session with its tasks and events from its json export
+func (c *sessionClient) ImportSession(ctx context.Context, export *api.SessionExport) (*api.StandardResponse[*api.Session], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	resp, err := c.client.Post(ctx, "/api/sessions/import", export, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*api.Session]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ForkSession creates a session with copies of the events of a session up to an event
+func (c *sessionClient) ForkSession(ctx context.Context, sessionID string, request *api.SessionForkRequest) (*api.StandardResponse[*api.SessionFork], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/fork", url.PathEscape(sessionID))
+	resp, err := c.client.Post(ctx, path, request, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*api.SessionFork]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListSharedSessions lists the sessions other users shared with the user
+func (c *sessionClient) ListSharedSessions(ctx context.Context) (*api.StandardResponse[[]*api.Session], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	resp, err := c.client.Get(ctx, "/api/sessions/shared", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[[]*api.Session]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListSessionShares lists the users a session of the user is shared with and its share links
+func (c *sessionClient) ListSessionShares(ctx context.Context, sessionID string) (*api.StandardResponse[*api.SessionShares], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/shares", url.PathEscape(sessionID))
+	resp, err := c.client.Get(ctx, path, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*api.SessionShares]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ShareSession shares a session of the user with another user, or changes their role in it
+func (c *sessionClient) ShareSession(ctx context.Context, sessionID string, request *api.SessionShareRequest) (*api.StandardResponse[*api.SessionShare], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/shares", url.PathEscape(sessionID))
+	resp, err := c.client.Put(ctx, path, request, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*api.SessionShare]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// UnshareSession revokes the access of another user to a session of the user
+func (c *sessionClient) UnshareSession(ctx context.Context, sessionID, sharedWith string) error {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/shares/%s", url.PathEscape(sessionID), url.PathEscape(sharedWith))
+	_, err := c.client.Delete(ctx, path, userID)
+	return err
+}
+
+// CreateSessionShareLink creates a link sharing a session of the user with the users redeeming it
+func (c *sessionClient) CreateSessionShareLink(ctx context.Context, sessionID string, request *api.SessionShareLinkRequest) (*api.StandardResponse[*api.CreatedSessionShareLink], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/share-links", url.PathEscape(sessionID))
+	resp, err := c.client.Post(ctx, path, request, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*api.CreatedSessionShareLink]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DeleteSessionShareLink deletes a share link of a session of the user
+func (c *sessionClient) DeleteSessionShareLink(ctx context.Context, sessionID, linkID string) error {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/%s/share-links/%s", url.PathEscape(sessionID), url.PathEscape(linkID))
+	_, err := c.client.Delete(ctx, path, userID)
+	return err
+}
+
+// RedeemSessionShareLink gives the user access to the session of a share link
+func (c *sessionClient) RedeemSessionShareLink(ctx context.Context, token string) (*api.StandardResponse[*api.SessionShare], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	path := fmt.Sprintf("/api/sessions/shared/%s", url.PathEscape(token))
+	resp, err := c.client.Post(ctx, path, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*api.SessionShare]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// Task defines the task operations
+type Task interface {
+	GetTask(ctx context.Context, taskID string) (*api.StandardResponse[*protocol.Task], error)
+	ApproveTask(ctx context.Context, taskID string, request *api.TaskDecisionRequest) (*api.StandardResponse[*protocol.MessageResult], error)
+	RejectTask(ctx context.Context, taskID string, request *api.TaskDecisionRequest) (*api.StandardResponse[*protocol.MessageResult], error)
+	ListPushNotificationDeliveries(ctx context.Context, taskID string) (*api.StandardResponse[[]api.PushNotificationDelivery], error)
+}
+
+// taskClient handles task-related requests
+type taskClient struct {
+	client *BaseClient
+}
+
+// NewTaskClient creates a new task client
+func NewTaskClient(client *BaseClient) Task {
+	return &taskClient{client: client}
+}
+
+// GetTask retrieves a specific task
+func (c *taskClient) GetTask(ctx context.Context, taskID string) (*api.StandardResponse[*protocol.Task], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	resp, err := c.client.Get(ctx, fmt.Sprintf("/api/tasks/%s", taskID), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*protocol.Task]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ApproveTask approves the tool calls a paused task is waiting on and resumes it
+func (c *taskClient) ApproveTask(ctx context.Context, taskID string, request *api.TaskDecisionRequest) (*api.StandardResponse[*protocol.MessageResult], error) {
+	return c.decide(ctx, taskID, "approve", request)
+}
+
+// RejectTask rejects the tool calls a paused task is waiting on and resumes it
+func (c *taskClient) RejectTask(ctx context.Context, taskID string, request *api.TaskDecisionRequest) (*api.StandardResponse[*protocol.MessageResult], error) {
+	return c.decide(ctx, taskID, "reject", request)
+}
+
+// ListPushNotificationDeliveries lists the attempts to deliver the push notifications of a task
+func (c *taskClient) ListPushNotificationDeliveries(ctx context.Context, taskID string) (*api.StandardResponse[[]api.PushNotificationDelivery], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	resp, err := c.client.Get(ctx, fmt.Sprintf("/api/tasks/%s/push-notifications/deliveries", taskID), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[[]api.PushNotificationDelivery]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *taskClient) decide(ctx context.Context, taskID, decision string, request *api.TaskDecisionRequest) (*api.StandardResponse[*protocol.MessageResult], error) {
+	userID := c.client.GetUserIDOrDefault("")
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	if request == nil {
+		request = &api.TaskDecisionRequest{}
+	}
+
+	resp, err := c.client.Post(ctx, fmt.Sprintf("/api/tasks/%s/%s", taskID, decision), request, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.StandardResponse[*protocol.MessageResult]
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
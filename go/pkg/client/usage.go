@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/kagent-dev/kagent/go/pkg/client/api"
+)
+
+// UsageQuery selects the token usage to report. Empty fields are not filtered on.
+type UsageQuery struct {
+	// GroupBy is one of agent, user, session, model or day.
+	GroupBy string
+	From    time.Time
+	To      time.Time
+	// Agent is the agent reference (namespace/name).
+	Agent   string
+	User    string
+	Session string
+	Model   string
+}
+
+// Usage defines the token usage operations
+type Usage interface {
+	GetUsage(ctx context.Context, query UsageQuery) (*api.StandardResponse[api.UsageReport], error)
+}
+
+// usageClient handles usage-related requests
+type usageClient struct {
+	client *BaseClient
+}
+
+// NewUsageClient creates a new usage client
+func NewUsageClient(client *BaseClient) Usage {
+	return &usageClient{client: client}
+}
+
+// GetUsage reports the token usage and its cost
+func (c *usageClient) GetUsage(ctx context.Context, query UsageQuery) (*api.StandardResponse[api.UsageReport], error) {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"group_by": query.GroupBy,
+		"agent":    query.Agent,
+		"user":     query.User,
+		"session":  query.Session,
+		"model":    query.Model,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	path := "/api/usage"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	resp, err := c.client.Get(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var report api.StandardResponse[api.UsageReport]
+	if err := DecodeResponse(resp, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
@@ -27,12 +27,21 @@ from opentelemetry import trace
 from pydantic import BaseModel
 from typing_extensions import override
 
-from kagent.core.a2a import TaskResultAggregator, get_kagent_metadata_key
+from kagent.core.a2a import (
+    KAGENT_HITL_DECISION_TYPE_DENY,
+    TaskResultAggregator,
+    ToolApprovalRequest,
+    extract_decision_from_message,
+    get_kagent_metadata_key,
+    handle_tool_approval_interrupt,
+    is_input_required_task,
+)
 from kagent.core.tracing._span_processor import (
     clear_kagent_span_attributes,
     set_kagent_span_attributes,
 )
 
+from ._approval import APPROVED_TOOL_CALL_STATE_KEY, PENDING_TOOL_APPROVAL_STATE_KEY, resume_tool_approval
 from .converters.event_converter import convert_event_to_a2a_events
 from .converters.request_converter import convert_a2a_request_to_adk_run_args
 
@@ -199,8 +208,22 @@ class A2aAgentExecutor(AgentExecutor):
         headers = context.call_context.state.get("headers", {})
         state_changes = {
             "headers": headers,
+            # approvals only apply to the invocation resumed with them
+            APPROVED_TOOL_CALL_STATE_KEY: None,
         }
 
+        # resume a task waiting for the approval of a tool call with the decision of the user
+        pending_approval = session.state.get(PENDING_TOOL_APPROVAL_STATE_KEY)
+        if pending_approval and context.current_task and is_input_required_task(context.current_task.status.state):
+            decision = extract_decision_from_message(context.message)
+            if not decision:
+                logger.warning(
+                    "Could not determine decision from message for task %s, defaulting to deny", context.task_id
+                )
+                decision = KAGENT_HITL_DECISION_TYPE_DENY
+            approval_changes, run_args["new_message"] = resume_tool_approval(pending_approval, decision)
+            state_changes.update(approval_changes)
+
         actions_with_update = EventActions(state_delta=state_changes)
         system_event = Event(
             invocation_id="header_update",
@@ -236,14 +259,29 @@ class A2aAgentExecutor(AgentExecutor):
         )
 
         task_result_aggregator = TaskResultAggregator()
+        pending_approval = None
         async with Aclosing(runner.run_async(**run_args)) as agen:
             async for adk_event in agen:
+                if adk_event.actions and adk_event.actions.state_delta.get(PENDING_TOOL_APPROVAL_STATE_KEY):
+                    pending_approval = adk_event.actions.state_delta[PENDING_TOOL_APPROVAL_STATE_KEY]
                 for a2a_event in convert_event_to_a2a_events(
                     adk_event, invocation_context, context.task_id, context.context_id
                 ):
                     task_result_aggregator.process_event(a2a_event)
                     await event_queue.enqueue_event(a2a_event)
 
+        # a tool call is waiting for approval, the task is resumed by the next message
+        if pending_approval:
+            await handle_tool_approval_interrupt(
+                action_requests=[ToolApprovalRequest(**pending_approval)],
+                task_id=context.task_id,
+                context_id=context.context_id,
+                event_queue=event_queue,
+                task_store=context.task_store,
+                app_name=runner.app_name,
+            )
+            return
+
         # publish the task result event - this is final
         if (
             task_result_aggregator.task_state == TaskState.working
@@ -0,0 +1,65 @@
+"""Human-in-the-loop approval of tool calls for ADK agents.
+
+Calls of tools that require approval are held back by a before-tool callback,
+which records the call as pending in the session state and ends the invocation.
+The executor then moves the task to input-required, and on the next message
+records an approval in the session state for the rest of that invocation, so
+that the callback runs the call when the model makes it again.
+"""
+
+from __future__ import annotations
+
+import logging
+from typing import Any, Optional
+
+from google.adk.tools.base_tool import BaseTool
+from google.adk.tools.tool_context import ToolContext
+from google.genai import types as genai_types
+
+from kagent.core.a2a import KAGENT_HITL_DECISION_TYPE_APPROVE, DecisionType
+
+logger = logging.getLogger("kagent_adk." + __name__)
+
+# Session state of the call waiting for a decision of the user
+PENDING_TOOL_APPROVAL_STATE_KEY = "kagent_pending_tool_approval"
+# Session state of the approved call, only set while resuming the invocation that made it
+APPROVED_TOOL_CALL_STATE_KEY = "kagent_approved_tool_call"
+
+
+def make_tool_approval_callback(require_approval: set[str]):
+    """Return a before-tool callback holding back the calls of the given tools until they are approved."""
+
+    def before_tool(tool: BaseTool, args: dict[str, Any], tool_context: ToolContext) -> Optional[dict]:
+        if tool.name not in require_approval:
+            return None
+
+        approved = tool_context.state.get(APPROVED_TOOL_CALL_STATE_KEY)
+        if approved and approved.get("name") == tool.name and approved.get("args") == args:
+            # An approval applies to a single call
+            tool_context.state[APPROVED_TOOL_CALL_STATE_KEY] = None
+            return None
+
+        tool_context.state[PENDING_TOOL_APPROVAL_STATE_KEY] = {
+            "name": tool.name,
+            "args": args,
+            "id": tool_context.function_call_id,
+        }
+        # Stop after this call and wait for the decision of the user
+        tool_context.actions.skip_summarization = True
+        tool_context._invocation_context.end_invocation = True
+        return {"status": f"The call of tool {tool.name} is waiting for approval by the user."}
+
+    return before_tool
+
+
+def resume_tool_approval(pending: dict[str, Any], decision: DecisionType) -> tuple[dict[str, Any], genai_types.Content]:
+    """Return the session state changes recording the decision on a pending call,
+    and the message telling the model about it."""
+    state_delta: dict[str, Any] = {PENDING_TOOL_APPROVAL_STATE_KEY: None}
+    if decision == KAGENT_HITL_DECISION_TYPE_APPROVE:
+        state_delta[APPROVED_TOOL_CALL_STATE_KEY] = {"name": pending["name"], "args": pending["args"]}
+        text = f"I approve the call of tool {pending['name']}. Call it again with the same arguments."
+    else:
+        text = f"I reject the call of tool {pending['name']}. Do not call it again."
+    logger.info("Resuming call of tool %s with decision %s", pending["name"], decision)
+    return state_delta, genai_types.Content(role="user", parts=[genai_types.Part(text=text)])
@@ -108,9 +108,11 @@ class KAgentSessionService(BaseSessionService):
                 events.append(Event.model_validate_json(event_data["data"]))
 
             # Convert to ADK Session format
+            # The session may be shared with user_id by its owner, so events
+            # appended to it are attributed to the requesting user
             session = Session(
                 id=session_data["id"],
-                user_id=session_data["user_id"],
+                user_id=user_id,
                 events=events,
                 app_name=app_name,
                 state={},
@@ -79,7 +79,11 @@ def _get_context_metadata(event: Event, invocation_context: InvocationContext) -
         }
 
         # Add optional metadata fields if present
+        # The event ID and model let the controller account for the token usage
+        # once, whether it sees it on the A2A stream or in the session events.
         optional_fields = [
+            ("event_id", event.id),
+            ("model_version", event.model_version),
             ("branch", event.branch),
             ("grounding_metadata", event.grounding_metadata),
             ("custom_metadata", event.custom_metadata),
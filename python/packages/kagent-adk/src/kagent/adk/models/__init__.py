@@ -1,3 +1,4 @@
-from ._openai import AzureOpenAI, OpenAI
+from ._fallback import FallbackLlm
+from ._openai import AzureOpenAI, OpenAI, OpenAICompatible
 
-__all__ = ["OpenAI", "AzureOpenAI"]
+__all__ = ["OpenAI", "AzureOpenAI", "OpenAICompatible", "FallbackLlm"]
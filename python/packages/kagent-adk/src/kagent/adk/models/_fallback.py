@@ -0,0 +1,111 @@
+from __future__ import annotations
+
+import logging
+import re
+from typing import TYPE_CHECKING, AsyncGenerator, Literal, Optional
+
+from google.adk.models import BaseLlm
+from google.adk.models.llm_response import LlmResponse
+
+if TYPE_CHECKING:
+    from google.adk.models.llm_request import LlmRequest
+
+logger = logging.getLogger(__name__)
+
+FallbackTrigger = Literal["rate_limited", "server_error", "timeout", "connection_error"]
+
+# Status codes as rendered in the error messages of the OpenAI, Anthropic and LiteLLM clients,
+# e.g. "Error code: 429 - {...}" or "status_code: 503"
+_STATUS_CODE = re.compile(r"\b(?:error code|status(?:[ _]code)?)\D{0,3}(\d{3})\b", re.IGNORECASE)
+
+# Error codes of Gemini responses
+_ERROR_CODE_TRIGGERS: dict[str, FallbackTrigger] = {
+    "RESOURCE_EXHAUSTED": "rate_limited",
+    "INTERNAL": "server_error",
+    "UNAVAILABLE": "server_error",
+    "DEADLINE_EXCEEDED": "timeout",
+}
+
+
+def _status_trigger(status: int) -> Optional[FallbackTrigger]:
+    if status == 429:
+        return "rate_limited"
+    if 500 <= status < 600:
+        return "server_error"
+    return None
+
+
+def classify_exception(error: BaseException) -> Optional[FallbackTrigger]:
+    """Return the fallback trigger a failed model call matches, if any."""
+    for attr in ("status_code", "code"):
+        status = getattr(error, attr, None)
+        if isinstance(status, int) and (trigger := _status_trigger(status)):
+            return trigger
+
+    # The clients of the providers each define their own error types, so they are matched by name.
+    # Timeouts are checked first as e.g. openai.APITimeoutError is an APIConnectionError.
+    names = [cls.__name__ for cls in type(error).__mro__]
+    if any("RateLimit" in name for name in names):
+        return "rate_limited"
+    if any("Timeout" in name for name in names):
+        return "timeout"
+    if isinstance(error, ConnectionError) or any("Connect" in name for name in names):
+        return "connection_error"
+    if any(name in ("InternalServerError", "ServiceUnavailableError", "BadGatewayError") for name in names):
+        return "server_error"
+    return classify_error_message(str(error))
+
+
+def classify_error_message(message: str, error_code: Optional[str] = None) -> Optional[FallbackTrigger]:
+    """Return the fallback trigger an error response of a model matches, if any."""
+    if error_code and (trigger := _ERROR_CODE_TRIGGERS.get(error_code.upper())):
+        return trigger
+    if match := _STATUS_CODE.search(message):
+        return _status_trigger(int(match.group(1)))
+    lower = message.lower()
+    if "rate limit" in lower:
+        return "rate_limited"
+    if "timed out" in lower or "timeout" in lower:
+        return "timeout"
+    if "connection error" in lower or "connection refused" in lower:
+        return "connection_error"
+    return None
+
+
+class FallbackLlm(BaseLlm):
+    """Model that calls the first of a chain of models, and moves on to the next
+    one when a call fails with one of the triggers of the next model.
+
+    A call only moves on before any response was returned, as a partially
+    streamed response can't be taken back.
+    """
+
+    models: list[BaseLlm]
+    # Failures of the previous model that move on to each model. Those of the first model are unused.
+    triggers: list[list[FallbackTrigger]]
+
+    async def generate_content_async(
+        self, llm_request: LlmRequest, stream: bool = False
+    ) -> AsyncGenerator[LlmResponse, None]:
+        for i, model in enumerate(self.models):
+            next_triggers = self.triggers[i + 1] if i + 1 < len(self.models) else []
+            request = llm_request.model_copy(update={"model": model.model})
+            responded = False
+            failure: Optional[FallbackTrigger] = None
+            try:
+                async for response in model.generate_content_async(request, stream=stream):
+                    if not responded and response.error_code:
+                        failure = classify_error_message(response.error_message or "", response.error_code)
+                        if failure in next_triggers:
+                            break
+                    responded = True
+                    yield response
+                else:
+                    return
+            except Exception as e:
+                failure = classify_exception(e)
+                if responded or failure not in next_triggers:
+                    raise
+            logger.warning(
+                "Model %s failed with %s, falling back to %s", model.model, failure, self.models[i + 1].model
+            )
@@ -11,7 +11,7 @@ from google.adk.models import BaseLlm
 from google.adk.models.llm_response import LlmResponse
 from google.genai import types
 from google.genai.types import FunctionCall, FunctionResponse
-from openai import AsyncAzureOpenAI, AsyncOpenAI, DefaultAsyncHttpxClient
+from openai import AsyncAzureOpenAI, AsyncOpenAI, DefaultAsyncHttpxClient, Omit
 from openai.types.chat import (
     ChatCompletion,
     ChatCompletionAssistantMessageParam,
@@ -475,3 +475,38 @@ class AzureOpenAI(BaseOpenAI):
             default_headers=self.default_headers,
             http_client=http_client,
         )
+
+
+class OpenAICompatible(BaseOpenAI):
+    """Model served by a server implementing the OpenAI API, e.g. vLLM or LiteLLM proxy.
+
+    The API key is sent in auth_header, or as a bearer token if auth_header is not set.
+    Servers without an API key are called without credentials.
+    """
+
+    type: Literal["openai_compatible"]
+    auth_header: Optional[str] = None
+
+    @cached_property
+    def _client(self) -> AsyncOpenAI:
+        """Get the OpenAI client with the API key in the configured header."""
+        headers: dict[str, str | Omit] = dict(self.default_headers or {})
+        if not self.api_key:
+            headers["Authorization"] = Omit()
+        elif self.auth_header:
+            if self.auth_header.lower() == "authorization":
+                headers["Authorization"] = self.api_key
+            else:
+                headers[self.auth_header] = self.api_key
+                headers["Authorization"] = Omit()
+
+        http_client = self._create_http_client()
+
+        return AsyncOpenAI(
+            # The client requires an API key, which is dropped from the headers above if unset
+            api_key=self.api_key or "unused",
+            base_url=self.base_url or None,
+            default_headers=headers,
+            timeout=self.timeout,
+            http_client=http_client,
+        )
@@ -1,9 +1,11 @@
 from .bash_tool import BashTool
 from .file_tools import EditFileTool, ReadFileTool, WriteFileTool
+from .memory_tool import PineconeMemoryTool
 
 __all__ = [
     "BashTool",
     "EditFileTool",
+    "PineconeMemoryTool",
     "ReadFileTool",
     "WriteFileTool",
 ]
@@ -0,0 +1,108 @@
+"""Tool for searching the Memory resources referenced by an agent."""
+
+from __future__ import annotations
+
+import logging
+import os
+from typing import Any, Dict
+
+import httpx
+from google.adk.tools import BaseTool, ToolContext
+from google.genai import types
+
+logger = logging.getLogger("kagent_adk." + __name__)
+
+PINECONE_API_VERSION = "2025-01"
+PINECONE_DEFAULT_NAMESPACE = "__default__"
+
+
+class PineconeMemoryTool(BaseTool):
+    """Search a Pinecone index with integrated embedding for records relevant to a query.
+
+    The index embeds the query text itself, so the agent only sends text. Records
+    scoring below the score threshold are left out.
+    """
+
+    def __init__(
+        self,
+        name: str,
+        index_host: str,
+        top_k: int = 5,
+        namespace: str | None = None,
+        record_fields: list[str] | None = None,
+        score_threshold: float | None = None,
+        api_key_env: str | None = None,
+        http_client: httpx.AsyncClient | None = None,
+    ):
+        super().__init__(
+            name=f"search_memory_{name}",
+            description=(
+                f"Search the {name} memory for information relevant to a query. "
+                "Use it to look up knowledge that may not be in the conversation."
+            ),
+        )
+        if not index_host.startswith(("http://", "https://")):
+            index_host = f"https://{index_host}"
+        self.index_host = index_host.rstrip("/")
+        self.top_k = top_k or 5
+        self.namespace = namespace or PINECONE_DEFAULT_NAMESPACE
+        self.record_fields = record_fields
+        self.score_threshold = score_threshold
+        self.api_key_env = api_key_env
+        self._http_client = http_client
+
+    def _get_declaration(self) -> types.FunctionDeclaration:
+        return types.FunctionDeclaration(
+            name=self.name,
+            description=self.description,
+            parameters=types.Schema(
+                type=types.Type.OBJECT,
+                properties={
+                    "query": types.Schema(
+                        type=types.Type.STRING,
+                        description="Text to search the memory for.",
+                    ),
+                },
+                required=["query"],
+            ),
+        )
+
+    async def run_async(self, *, args: Dict[str, Any], tool_context: ToolContext) -> dict[str, Any]:
+        query = str(args.get("query", "")).strip()
+        if not query:
+            return {"error": "No query provided"}
+
+        try:
+            return {"records": await self.search(query)}
+        except Exception as e:
+            logger.error(f"Failed to search memory {self.name}: {e}")
+            return {"error": f"Failed to search memory: {e}"}
+
+    async def search(self, query: str) -> list[dict[str, Any]]:
+        """Returns the fields, ID and score of the records matching the query, best first."""
+        body: dict[str, Any] = {"query": {"inputs": {"text": query}, "top_k": self.top_k}}
+        if self.record_fields:
+            body["fields"] = self.record_fields
+
+        headers = {"X-Pinecone-API-Version": PINECONE_API_VERSION}
+        if self.api_key_env:
+            api_key = os.environ.get(self.api_key_env)
+            if not api_key:
+                raise ValueError(f"Pinecone API key environment variable {self.api_key_env} is not set")
+            headers["Api-Key"] = api_key
+
+        url = f"{self.index_host}/records/namespaces/{self.namespace}/search"
+        if self._http_client is not None:
+            response = await self._http_client.post(url, json=body, headers=headers)
+        else:
+            async with httpx.AsyncClient(timeout=30) as client:
+                response = await client.post(url, json=body, headers=headers)
+        response.raise_for_status()
+
+        records = []
+        for hit in response.json().get("result", {}).get("hits", []):
+            score = hit.get("_score")
+            if self.score_threshold is not None and score is not None and score < self.score_threshold:
+                continue
+            records.append({"id": hit.get("_id"), "score": score, **hit.get("fields", {})})
+        return records
@@ -1,5 +1,6 @@
 import logging
-from typing import Any, Literal, Optional, Union
+import os
+from typing import Annotated, Any, Literal, Optional, Union
 
 import httpx
 from google.adk.agents import Agent
@@ -7,6 +8,7 @@ from google.adk.agents.base_agent import BaseAgent
 from google.adk.agents.llm_agent import ToolUnion
 from google.adk.agents.remote_a2a_agent import AGENT_CARD_WELL_KNOWN_PATH, DEFAULT_TIMEOUT, RemoteA2aAgent
 from google.adk.code_executors.base_code_executor import BaseCodeExecutor
+from google.adk.models import BaseLlm
 from google.adk.models.anthropic_llm import Claude as ClaudeLLM
 from google.adk.models.google_llm import Gemini as GeminiLLM
 from google.adk.models.lite_llm import LiteLlm
@@ -15,9 +17,13 @@ from google.adk.tools.mcp_tool import MCPToolset, SseConnectionParams, Streamabl
 from pydantic import BaseModel, Field
 
 from kagent.adk.sandbox_code_executer import SandboxedLocalCodeExecutor
+from kagent.adk.tools.memory_tool import PineconeMemoryTool
 
+from ._approval import make_tool_approval_callback
 from .models import AzureOpenAI as OpenAIAzure
+from .models import FallbackLlm
 from .models import OpenAI as OpenAINative
+from .models import OpenAICompatible as OpenAICompatibleNative
 
 logger = logging.getLogger(__name__)
 
@@ -25,11 +31,13 @@ logger = logging.getLogger(__name__)
 class HttpMcpServerConfig(BaseModel):
     params: StreamableHTTPConnectionParams
     tools: list[str] = Field(default_factory=list)
+    require_approval: list[str] = Field(default_factory=list)  # tools whose calls wait for approval by the user
 
 
 class SseMcpServerConfig(BaseModel):
     params: SseConnectionParams
     tools: list[str] = Field(default_factory=list)
+    require_approval: list[str] = Field(default_factory=list)  # tools whose calls wait for approval by the user
 
 
 class RemoteAgentConfig(BaseModel):
@@ -91,27 +99,230 @@ class Gemini(BaseLLM):
     type: Literal["gemini"]
 
 
+class OpenAICompatible(BaseLLM):
+    base_url: str
+    auth_header: str | None = None  # header carrying the API key, Authorization bearer token if unset
+    max_tokens: int | None = None
+    temperature: float | None = None
+    top_p: float | None = None
+
+    type: Literal["openai_compatible"]
+
+
+class Bedrock(BaseLLM):
+    region: str
+    inference_profile: str | None = None  # inference profile ID or ARN invoked instead of the model
+    max_tokens: int | None = None
+    temperature: float | None = None
+    top_p: float | None = None
+
+    type: Literal["bedrock"]
+
+
+LLMConfig = Annotated[
+    Union[OpenAI, Anthropic, GeminiVertexAI, GeminiAnthropic, Ollama, AzureOpenAI, Gemini, OpenAICompatible, Bedrock],
+    Field(discriminator="type"),
+]
+
+
+class ModelFallback(BaseModel):
+    model: LLMConfig
+    # Failures of the previous model of the chain that move on to this one
+    triggers: list[Literal["rate_limited", "server_error", "timeout", "connection_error"]]
+    # Environment variables the model reads by default, mapped to the renamed ones holding their values
+    env: dict[str, str] = Field(default_factory=dict)
+
+
+class FallbackModel(BaseModel):
+    primary: LLMConfig
+    fallbacks: list[ModelFallback]
+
+    type: Literal["fallback"]
+
+
+def _create_llm(config: LLMConfig, env: dict[str, str] | None = None) -> BaseLlm | str:
+    """Create the model of a model config.
+
+    env maps the environment variables the model reads by default to the ones
+    holding their values, as they are renamed for the fallback models of a chain.
+    """
+    env = env or {}
+
+    def getenv(name: str) -> str | None:
+        return os.environ.get(env.get(name, name))
+
+    def env_kwargs(args: dict[str, str]) -> dict[str, str]:
+        """Keyword arguments of the models that are set in the environment, by environment variable."""
+        return {arg: value for name, arg in args.items() if (value := getenv(name))}
+
+    extra_headers = config.headers or {}
+
+    if config.type == "openai":
+        model = OpenAINative(
+            type="openai",
+            base_url=config.base_url,
+            default_headers=extra_headers,
+            frequency_penalty=config.frequency_penalty,
+            max_tokens=config.max_tokens,
+            model=config.model,
+            api_key=getenv("OPENAI_API_KEY"),
+            n=config.n,
+            presence_penalty=config.presence_penalty,
+            reasoning_effort=config.reasoning_effort,
+            seed=config.seed,
+            temperature=config.temperature,
+            timeout=config.timeout,
+            top_p=config.top_p,
+            # TLS configuration
+            tls_disable_verify=config.tls_disable_verify,
+            tls_ca_cert_path=config.tls_ca_cert_path,
+            tls_disable_system_cas=config.tls_disable_system_cas,
+        )
+    elif config.type == "anthropic":
+        model = LiteLlm(
+            model=f"anthropic/{config.model}",
+            base_url=config.base_url,
+            extra_headers=extra_headers,
+            **env_kwargs({"ANTHROPIC_API_KEY": "api_key"}),
+        )
+    elif config.type == "gemini_vertex_ai":
+        model = GeminiLLM(model=config.model)
+    elif config.type == "gemini_anthropic":
+        model = ClaudeLLM(model=config.model)
+    elif config.type == "ollama":
+        model = LiteLlm(
+            model=f"ollama_chat/{config.model}",
+            extra_headers=extra_headers,
+            **env_kwargs({"OLLAMA_API_BASE": "api_base"}),
+        )
+    elif config.type == "azure_openai":
+        model = OpenAIAzure(
+            model=config.model,
+            type="azure_openai",
+            api_key=getenv("AZURE_OPENAI_API_KEY"),
+            api_version=getenv("OPENAI_API_VERSION"),
+            azure_endpoint=getenv("AZURE_OPENAI_ENDPOINT"),
+            default_headers=extra_headers,
+            # TLS configuration
+            tls_disable_verify=config.tls_disable_verify,
+            tls_ca_cert_path=config.tls_ca_cert_path,
+            tls_disable_system_cas=config.tls_disable_system_cas,
+        )
+    elif config.type == "gemini":
+        model = config.model
+    elif config.type == "openai_compatible":
+        model = OpenAICompatibleNative(
+            type="openai_compatible",
+            model=config.model,
+            base_url=config.base_url,
+            api_key=getenv("OPENAI_COMPATIBLE_API_KEY"),
+            auth_header=config.auth_header,
+            default_headers=extra_headers,
+            max_tokens=config.max_tokens,
+            temperature=config.temperature,
+            top_p=config.top_p,
+            # TLS configuration
+            tls_disable_verify=config.tls_disable_verify,
+            tls_ca_cert_path=config.tls_ca_cert_path,
+            tls_disable_system_cas=config.tls_disable_system_cas,
+        )
+    elif config.type == "bedrock":
+        kwargs = {}
+        if config.inference_profile:
+            # Bedrock invokes the inference profile, LiteLLM keeps handling requests for the model
+            kwargs["model_id"] = config.inference_profile
+        for arg in ("max_tokens", "temperature", "top_p"):
+            if (value := getattr(config, arg)) is not None:
+                kwargs[arg] = value
+        # Static credentials, if set. Otherwise LiteLLM resolves credentials with the
+        # default AWS credential chain, e.g. from the web identity token of IRSA.
+        credentials = env_kwargs(
+            {
+                "AWS_ACCESS_KEY_ID": "aws_access_key_id",
+                "AWS_SECRET_ACCESS_KEY": "aws_secret_access_key",
+                "AWS_SESSION_TOKEN": "aws_session_token",
+            }
+        )
+        model = LiteLlm(model=f"bedrock/{config.model}", aws_region_name=config.region, **credentials, **kwargs)
+    else:
+        raise ValueError(f"Invalid model type: {config.type}")
+    return model
+
+
+def _export_env(env: dict[str, str]) -> None:
+    """Set the default environment variables of a model that only reads them from
+    the environment to the values of their renamed ones, unless they are set already."""
+    for name, renamed in env.items():
+        if (value := os.environ.get(renamed)) is None:
+            continue
+        if os.environ.get(name, value) != value:
+            logger.warning("%s is already set, so the fallback model can't read it from %s", name, renamed)
+            continue
+        os.environ[name] = value
+
+
+def _create_fallback_llm(config: FallbackModel) -> FallbackLlm:
+    chain = [(config.primary, {})] + [(fallback.model, fallback.env) for fallback in config.fallbacks]
+    models: list[BaseLlm] = []
+    for model_config, env in chain:
+        # Gemini models only read their settings from the environment
+        if model_config.type in ("gemini", "gemini_vertex_ai", "gemini_anthropic"):
+            _export_env(env)
+        model = _create_llm(model_config, env)
+        models.append(GeminiLLM(model=model) if isinstance(model, str) else model)
+    triggers = [[]] + [list(fallback.triggers) for fallback in config.fallbacks]
+    return FallbackLlm(model=models[0].model, models=models, triggers=triggers)
+
+
+class PineconeMemoryConfig(BaseModel):
+    index_host: str
+    top_k: int = 5
+    namespace: str | None = None
+    record_fields: list[str] | None = None
+    score_threshold: float | None = None
+    api_key_env: str | None = None  # environment variable holding the Pinecone API key
+
+
+class MemoryConfig(BaseModel):
+    name: str
+    type: Literal["pinecone"]
+    pinecone: PineconeMemoryConfig | None = None
+
+
 class AgentConfig(BaseModel):
-    model: Union[OpenAI, Anthropic, GeminiVertexAI, GeminiAnthropic, Ollama, AzureOpenAI, Gemini] = Field(
-        discriminator="type"
-    )
+    model: Union[
+        OpenAI,
+        Anthropic,
+        GeminiVertexAI,
+        GeminiAnthropic,
+        Ollama,
+        AzureOpenAI,
+        Gemini,
+        OpenAICompatible,
+        Bedrock,
+        FallbackModel,
+    ] = Field(discriminator="type")
     description: str
     instruction: str
     http_tools: list[HttpMcpServerConfig] | None = None  # Streamable HTTP MCP tools
     sse_tools: list[SseMcpServerConfig] | None = None  # SSE MCP tools
     remote_agents: list[RemoteAgentConfig] | None = None  # remote agents
     execute_code: bool | None = None
+    memory: list[MemoryConfig] | None = None  # Memory resources searchable by the agent
 
     def to_agent(self, name: str) -> Agent:
         if name is None or not str(name).strip():
             raise ValueError("Agent name must be a non-empty string.")
         tools: list[ToolUnion] = []
+        require_approval: set[str] = set()
         if self.http_tools:
             for http_tool in self.http_tools:  # add http tools
                 tools.append(MCPToolset(connection_params=http_tool.params, tool_filter=http_tool.tools))
+                require_approval.update(http_tool.require_approval)
         if self.sse_tools:
             for sse_tool in self.sse_tools:  # add sse tools
                 tools.append(MCPToolset(connection_params=sse_tool.params, tool_filter=sse_tool.tools))
+                require_approval.update(sse_tool.require_approval)
         if self.remote_agents:
             for remote_agent in self.remote_agents:  # Add remote agents as tools
                 client = None
@@ -129,55 +340,17 @@ class AgentConfig(BaseModel):
                 )
 
                 tools.append(AgentTool(agent=remote_a2a_agent))
-
-        extra_headers = self.model.headers or {}
+        if self.memory:
+            for memory in self.memory:  # add a search tool per memory
+                if memory.type == "pinecone" and memory.pinecone:
+                    tools.append(PineconeMemoryTool(name=memory.name, **memory.pinecone.model_dump()))
 
         code_executor = SandboxedLocalCodeExecutor() if self.execute_code else None
 
-        if self.model.type == "openai":
-            model = OpenAINative(
-                type="openai",
-                base_url=self.model.base_url,
-                default_headers=extra_headers,
-                frequency_penalty=self.model.frequency_penalty,
-                max_tokens=self.model.max_tokens,
-                model=self.model.model,
-                n=self.model.n,
-                presence_penalty=self.model.presence_penalty,
-                reasoning_effort=self.model.reasoning_effort,
-                seed=self.model.seed,
-                temperature=self.model.temperature,
-                timeout=self.model.timeout,
-                top_p=self.model.top_p,
-                # TLS configuration
-                tls_disable_verify=self.model.tls_disable_verify,
-                tls_ca_cert_path=self.model.tls_ca_cert_path,
-                tls_disable_system_cas=self.model.tls_disable_system_cas,
-            )
-        elif self.model.type == "anthropic":
-            model = LiteLlm(
-                model=f"anthropic/{self.model.model}", base_url=self.model.base_url, extra_headers=extra_headers
-            )
-        elif self.model.type == "gemini_vertex_ai":
-            model = GeminiLLM(model=self.model.model)
-        elif self.model.type == "gemini_anthropic":
-            model = ClaudeLLM(model=self.model.model)
-        elif self.model.type == "ollama":
-            model = LiteLlm(model=f"ollama_chat/{self.model.model}", extra_headers=extra_headers)
-        elif self.model.type == "azure_openai":
-            model = OpenAIAzure(
-                model=self.model.model,
-                type="azure_openai",
-                default_headers=extra_headers,
-                # TLS configuration
-                tls_disable_verify=self.model.tls_disable_verify,
-                tls_ca_cert_path=self.model.tls_ca_cert_path,
-                tls_disable_system_cas=self.model.tls_disable_system_cas,
-            )
-        elif self.model.type == "gemini":
-            model = self.model.model
+        if self.model.type == "fallback":
+            model = _create_fallback_llm(self.model)
         else:
-            raise ValueError(f"Invalid model type: {self.model.type}")
+            model = _create_llm(self.model)
         return Agent(
             name=name,
             model=model,
@@ -185,4 +358,5 @@ class AgentConfig(BaseModel):
             instruction=self.instruction,
             tools=tools,
             code_executor=code_executor,
+            before_tool_callback=make_tool_approval_callback(require_approval) if require_approval else None,
         )
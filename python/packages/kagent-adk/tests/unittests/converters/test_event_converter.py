@@ -20,6 +20,8 @@ def _create_mock_invocation_context():
 def _create_mock_event(error_code=None, content=None, invocation_id="test_invocation", author="test_author"):
     """Create a mock event for testing."""
     event = Mock()
+    event.id = "test_event"
+    event.model_version = None
     event.error_code = error_code
     event.content = content
     event.invocation_id = invocation_id
@@ -117,3 +119,25 @@ class TestEventConverter:
         error_code_key = get_kagent_metadata_key("error_code")
         assert error_code_key in error_event.metadata
         assert error_event.metadata[error_code_key] == str(genai_types.FinishReason.MALFORMED_FUNCTION_CALL)
+
+    def test_usage_metadata(self):
+        """Test that usage metadata carries the event ID and model, so the controller can account for it."""
+
+        invocation_context = _create_mock_invocation_context()
+        event = _create_mock_event(error_code=genai_types.FinishReason.MALFORMED_FUNCTION_CALL)
+        event.model_version = "gemini-2.5-flash"
+        event.usage_metadata = genai_types.GenerateContentResponseUsageMetadata(
+            prompt_token_count=100, candidates_token_count=20, total_token_count=120
+        )
+
+        result = convert_event_to_a2a_events(event, invocation_context, task_id="test_task", context_id="test_context")
+        assert len(result) == 1
+
+        metadata = result[0].metadata
+        assert metadata[get_kagent_metadata_key("event_id")] == "test_event"
+        assert metadata[get_kagent_metadata_key("model_version")] == "gemini-2.5-flash"
+        assert metadata[get_kagent_metadata_key("usage_metadata")] == {
+            "promptTokenCount": 100,
+            "candidatesTokenCount": 20,
+            "totalTokenCount": 120,
+        }
@@ -0,0 +1,145 @@
+from typing import AsyncGenerator
+
+import httpx
+import openai
+import pytest
+from google.adk.models import BaseLlm
+from google.adk.models.llm_request import LlmRequest
+from google.adk.models.llm_response import LlmResponse
+from google.genai import types
+from pydantic import ConfigDict
+
+from kagent.adk.models import FallbackLlm
+from kagent.adk.models._fallback import classify_error_message, classify_exception
+
+
+class FakeLlm(BaseLlm):
+    """Model that responds with the given responses, then fails with error if set."""
+
+    model_config = ConfigDict(arbitrary_types_allowed=True)
+
+    error: Exception | None = None
+    responses: list[LlmResponse] = []
+    requested_models: list[str] = []
+
+    async def generate_content_async(
+        self, llm_request: LlmRequest, stream: bool = False
+    ) -> AsyncGenerator[LlmResponse, None]:
+        self.requested_models.append(llm_request.model)
+        for response in self.responses:
+            yield response
+        if self.error:
+            raise self.error
+
+
+def text_response(text: str) -> LlmResponse:
+    return LlmResponse(content=types.Content(role="model", parts=[types.Part.from_text(text=text)]))
+
+
+def status_error(status: int) -> openai.APIStatusError:
+    request = httpx.Request("POST", "https://api.example.com/v1/chat/completions")
+    return openai.APIStatusError(f"status {status}", response=httpx.Response(status, request=request), body=None)
+
+
+async def generate(llm: FallbackLlm) -> list[LlmResponse]:
+    return [response async for response in llm.generate_content_async(LlmRequest(model=llm.model))]
+
+
+@pytest.mark.parametrize(
+    "error, expected",
+    [
+        (status_error(429), "rate_limited"),
+        (status_error(503), "server_error"),
+        (status_error(400), None),
+        (openai.APITimeoutError(request=httpx.Request("POST", "https://api.example.com")), "timeout"),
+        (openai.APIConnectionError(request=httpx.Request("POST", "https://api.example.com")), "connection_error"),
+        (httpx.ConnectError("refused"), "connection_error"),
+        (TimeoutError(), "timeout"),
+        (ValueError("invalid"), None),
+    ],
+)
+def test_classify_exception(error, expected):
+    assert classify_exception(error) == expected
+
+
+@pytest.mark.parametrize(
+    "message, error_code, expected",
+    [
+        ("Error code: 429 - {'error': 'slow down'}", "API_ERROR", "rate_limited"),
+        ("Error code: 502 - bad gateway", "API_ERROR", "server_error"),
+        ("Error code: 400 - invalid timeout parameter", "API_ERROR", None),
+        ("Request timed out.", "API_ERROR", "timeout"),
+        ("Connection error.", "API_ERROR", "connection_error"),
+        ("Quota exceeded", "RESOURCE_EXHAUSTED", "rate_limited"),
+    ],
+)
+def test_classify_error_message(message, error_code, expected):
+    assert classify_error_message(message, error_code) == expected
+
+
+@pytest.mark.asyncio
+async def test_falls_back_on_trigger():
+    primary = FakeLlm(model="primary", error=status_error(429), requested_models=[])
+    fallback = FakeLlm(model="fallback", responses=[text_response("hello")], requested_models=[])
+    llm = FallbackLlm(model="primary", models=[primary, fallback], triggers=[[], ["rate_limited"]])
+
+    responses = await generate(llm)
+
+    assert [r.content.parts[0].text for r in responses] == ["hello"]
+    assert primary.requested_models == ["primary"]
+    assert fallback.requested_models == ["fallback"]
+
+
+@pytest.mark.asyncio
+async def test_falls_back_on_error_response():
+    primary = FakeLlm(
+        model="primary",
+        responses=[LlmResponse(error_code="API_ERROR", error_message="Error code: 503 - unavailable")],
+        requested_models=[],
+    )
+    fallback = FakeLlm(model="fallback", responses=[text_response("hello")], requested_models=[])
+    llm = FallbackLlm(model="primary", models=[primary, fallback], triggers=[[], ["server_error"]])
+
+    responses = await generate(llm)
+
+    assert [r.error_code for r in responses] == [None]
+    assert fallback.requested_models == ["fallback"]
+
+
+@pytest.mark.asyncio
+async def test_does_not_fall_back_on_other_failures():
+    primary = FakeLlm(model="primary", error=status_error(400), requested_models=[])
+    fallback = FakeLlm(model="fallback", responses=[text_response("hello")], requested_models=[])
+    llm = FallbackLlm(model="primary", models=[primary, fallback], triggers=[[], ["rate_limited", "server_error"]])
+
+    with pytest.raises(openai.APIStatusError):
+        await generate(llm)
+    assert fallback.requested_models == []
+
+
+@pytest.mark.asyncio
+async def test_only_moves_on_to_the_next_model():
+    # The failure of the second model is not a trigger of the third
+    first = FakeLlm(model="first", error=status_error(429), requested_models=[])
+    second = FakeLlm(model="second", error=status_error(503), requested_models=[])
+    third = FakeLlm(model="third", responses=[text_response("hello")], requested_models=[])
+    llm = FallbackLlm(model="first", models=[first, second, third], triggers=[[], ["rate_limited"], ["timeout"]])
+
+    with pytest.raises(openai.APIStatusError):
+        await generate(llm)
+    assert second.requested_models == ["second"]
+    assert third.requested_models == []
+
+
+@pytest.mark.asyncio
+async def test_does_not_fall_back_after_streaming_started():
+    primary = FakeLlm(model="primary", responses=[text_response("hel")], error=status_error(503), requested_models=[])
+    fallback = FakeLlm(model="fallback", responses=[text_response("hello")], requested_models=[])
+    llm = FallbackLlm(model="primary", models=[primary, fallback], triggers=[[], ["server_error"]])
+
+    responses = []
+    with pytest.raises(openai.APIStatusError):
+        async for response in llm.generate_content_async(LlmRequest(model="primary"), stream=True):
+            responses.append(response)
+    assert [r.content.parts[0].text for r in responses] == ["hel"]
+    assert fallback.requested_models == []
@@ -0,0 +1,131 @@
+import json
+from pathlib import Path
+
+import pytest
+from google.adk.models.lite_llm import LiteLlm
+from openai import Omit
+
+from kagent.adk.models import AzureOpenAI, FallbackLlm, OpenAICompatible
+from kagent.adk.tools import PineconeMemoryTool
+from kagent.adk.types import AgentConfig
+
+_FIXTURES = Path(__file__).resolve().parents[1] / "fixtures" / "agent_configs"
+
+
+def load_config(name: str) -> AgentConfig:
+    return AgentConfig.model_validate(json.loads((_FIXTURES / name).read_text()))
+
+
+@pytest.mark.parametrize("name", sorted(p.name for p in _FIXTURES.glob("*.json")))
+def test_translator_configs_load(name):
+    agent = load_config(name).to_agent("test_agent")
+    assert agent.name == "test_agent"
+
+
+def test_memory_config():
+    config = load_config("agent_with_memory.json")
+
+    assert config.memory is not None and len(config.memory) == 1
+    pinecone = config.memory[0].pinecone
+    assert pinecone.index_host == "https://kagent-docs-abc123.svc.pinecone.io"
+    assert pinecone.record_fields == ["content", "url"]
+    assert pinecone.score_threshold == 0.75
+    assert pinecone.api_key_env == "KAGENT_MEMORY_TEST__NS__KAGENT_DOCS_API_KEY"
+
+    tools = [tool for tool in config.to_agent("test_agent").tools if isinstance(tool, PineconeMemoryTool)]
+    assert [tool.name for tool in tools] == ["search_memory_test__NS__kagent_docs"]
+
+
+def test_openai_compatible_config(monkeypatch):
+    monkeypatch.setenv("OPENAI_COMPATIBLE_API_KEY", "secret")
+    config = load_config("openai_compatible_agent.json")
+
+    assert config.model.type == "openai_compatible"
+    assert config.model.auth_header == "X-Api-Key"
+
+    model = config.to_agent("test_agent").model
+    assert isinstance(model, OpenAICompatible)
+    assert model.base_url == "http://vllm.models.svc:8000/v1"
+    assert model.max_tokens == 2048
+    assert model.temperature == 0.2
+    headers = model._client.default_headers
+    assert headers["X-Api-Key"] == "secret"
+    assert isinstance(headers["Authorization"], Omit)
+
+
+def test_openai_compatible_bearer_token(monkeypatch):
+    monkeypatch.setenv("OPENAI_COMPATIBLE_API_KEY", "secret")
+    config = load_config("openai_compatible_agent.json")
+    config.model.auth_header = None
+
+    headers = config.to_agent("test_agent").model._client.default_headers
+    assert headers["Authorization"] == "Bearer secret"
+    assert "X-Api-Key" not in headers
+
+
+def test_bedrock_config_static_credentials(monkeypatch):
+    monkeypatch.setenv("AWS_ACCESS_KEY_ID", "AKIA")
+    monkeypatch.setenv("AWS_SECRET_ACCESS_KEY", "secret")
+    monkeypatch.delenv("AWS_SESSION_TOKEN", raising=False)
+    config = load_config("bedrock_agent.json")
+
+    assert config.model.type == "bedrock"
+    assert config.model.region == "us-east-1"
+
+    model = config.to_agent("test_agent").model
+    assert isinstance(model, LiteLlm)
+    assert model.model == "bedrock/anthropic.claude-sonnet-4-20250514-v1:0"
+    assert model._additional_args["model_id"] == "us.anthropic.claude-sonnet-4-20250514-v1:0"
+    assert model._additional_args["aws_region_name"] == "us-east-1"
+    assert model._additional_args["aws_access_key_id"] == "AKIA"
+    assert model._additional_args["aws_secret_access_key"] == "secret"
+    assert "aws_session_token" not in model._additional_args
+    assert model._additional_args["max_tokens"] == 4096
+
+
+def test_bedrock_config_default_credential_chain(monkeypatch):
+    for env in ("AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"):
+        monkeypatch.delenv(env, raising=False)
+
+    model = load_config("bedrock_agent.json").to_agent("test_agent").model
+    assert "aws_access_key_id" not in model._additional_args
+    assert model._additional_args["aws_region_name"] == "us-east-1"
+
+
+def test_model_fallbacks_config(monkeypatch):
+    monkeypatch.setenv("ANTHROPIC_API_KEY", "primary-key")
+    monkeypatch.setenv("KAGENT_FALLBACK_1_AZURE_OPENAI_API_KEY", "azure-key")
+    monkeypatch.setenv("KAGENT_FALLBACK_1_AZURE_OPENAI_ENDPOINT", "https://example.openai.azure.com")
+    monkeypatch.setenv("KAGENT_FALLBACK_2_ANTHROPIC_API_KEY", "fallback-key")
+    config = load_config("agent_with_model_fallbacks.json")
+
+    assert config.model.type == "fallback"
+    assert [fallback.triggers for fallback in config.model.fallbacks] == [
+        ["rate_limited", "server_error", "connection_error"],
+        ["rate_limited", "server_error", "timeout"],
+    ]
+
+    model = config.to_agent("test_agent").model
+    assert isinstance(model, FallbackLlm)
+    primary, azure, anthropic = model.models
+    assert model.triggers == [[], *(fallback.triggers for fallback in config.model.fallbacks)]
+
+    # Each model of the chain reads its credentials from its own environment variables
+    assert isinstance(primary, LiteLlm)
+    assert primary._additional_args["api_key"] == "primary-key"
+    assert isinstance(azure, AzureOpenAI)
+    assert azure.model == "gpt-4o"
+    assert azure.api_key == "azure-key"
+    assert azure.azure_endpoint == "https://example.openai.azure.com"
+    assert isinstance(anthropic, LiteLlm)
+    assert anthropic._additional_args["api_key"] == "fallback-key"
+    assert anthropic._additional_args["base_url"] == "https://anthropic-proxy.internal"
+
+
+def test_tool_approval_config():
+    config = load_config("agent_with_tool_approval.json")
+
+    assert config.http_tools[0].require_approval == ["k8s_delete_resource"]
+    assert config.to_agent("test_agent").before_tool_callback is not None
+    config.http_tools[0].require_approval = []
+    assert config.to_agent("test_agent").before_tool_callback is None
@@ -0,0 +1,85 @@
+from types import SimpleNamespace
+
+from kagent.adk._approval import (
+    APPROVED_TOOL_CALL_STATE_KEY,
+    PENDING_TOOL_APPROVAL_STATE_KEY,
+    make_tool_approval_callback,
+    resume_tool_approval,
+)
+
+
+def make_tool_context(state: dict | None = None) -> SimpleNamespace:
+    return SimpleNamespace(
+        state=state if state is not None else {},
+        function_call_id="call-1",
+        actions=SimpleNamespace(skip_summarization=False),
+        _invocation_context=SimpleNamespace(end_invocation=False),
+    )
+
+
+def test_callback_ignores_tools_without_approval():
+    callback = make_tool_approval_callback({"k8s_delete_resource"})
+    tool_context = make_tool_context()
+
+    assert callback(SimpleNamespace(name="k8s_get_resources"), {}, tool_context) is None
+    assert tool_context.state == {}
+    assert not tool_context._invocation_context.end_invocation
+
+
+def test_callback_holds_back_call_for_approval():
+    callback = make_tool_approval_callback({"k8s_delete_resource"})
+    tool_context = make_tool_context()
+    args = {"name": "nginx"}
+
+    response = callback(SimpleNamespace(name="k8s_delete_resource"), args, tool_context)
+
+    assert "waiting for approval" in response["status"]
+    assert tool_context.state[PENDING_TOOL_APPROVAL_STATE_KEY] == {
+        "name": "k8s_delete_resource",
+        "args": args,
+        "id": "call-1",
+    }
+    assert tool_context.actions.skip_summarization
+    assert tool_context._invocation_context.end_invocation
+
+
+def test_callback_runs_approved_call_once():
+    callback = make_tool_approval_callback({"k8s_delete_resource"})
+    tool = SimpleNamespace(name="k8s_delete_resource")
+    args = {"name": "nginx"}
+    state, _ = resume_tool_approval({"name": tool.name, "args": args, "id": "call-1"}, "approve")
+    tool_context = make_tool_context(state)
+
+    assert callback(tool, args, tool_context) is None
+    assert tool_context.state[APPROVED_TOOL_CALL_STATE_KEY] is None
+
+    # Another call waits for approval again
+    assert callback(tool, args, tool_context) is not None
+    assert tool_context._invocation_context.end_invocation
+
+
+def test_callback_holds_back_call_with_other_args():
+    callback = make_tool_approval_callback({"k8s_delete_resource"})
+    tool = SimpleNamespace(name="k8s_delete_resource")
+    state, _ = resume_tool_approval({"name": tool.name, "args": {"name": "nginx"}, "id": "call-1"}, "approve")
+    tool_context = make_tool_context(state)
+
+    assert callback(tool, {"name": "postgres"}, tool_context) is not None
+    assert tool_context.state[PENDING_TOOL_APPROVAL_STATE_KEY]["args"] == {"name": "postgres"}
+
+
+def test_resume_tool_approval():
+    pending = {"name": "k8s_delete_resource", "args": {"name": "nginx"}, "id": "call-1"}
+
+    state, message = resume_tool_approval(pending, "approve")
+    assert state == {
+        PENDING_TOOL_APPROVAL_STATE_KEY: None,
+        APPROVED_TOOL_CALL_STATE_KEY: {"name": "k8s_delete_resource", "args": {"name": "nginx"}},
+    }
+    assert message.role == "user"
+    assert "I approve the call of tool k8s_delete_resource" in message.parts[0].text
+
+    for decision in ("reject", "deny"):
+        state, message = resume_tool_approval(pending, decision)
+        assert state == {PENDING_TOOL_APPROVAL_STATE_KEY: None}
+        assert "I reject the call of tool k8s_delete_resource" in message.parts[0].text
@@ -0,0 +1,66 @@
+import json
+
+import httpx
+import pytest
+
+from kagent.adk.tools import PineconeMemoryTool
+
+
+@pytest.fixture
+def pinecone_requests(monkeypatch):
+    monkeypatch.setenv("PINECONE_API_KEY", "pc-test")
+    requests: list[httpx.Request] = []
+
+    def handler(request: httpx.Request) -> httpx.Response:
+        requests.append(request)
+        return httpx.Response(
+            200,
+            json={
+                "result": {
+                    "hits": [
+                        {"_id": "doc-1", "_score": 0.9, "fields": {"content": "kagent runs agents on Kubernetes"}},
+                        {"_id": "doc-2", "_score": 0.5, "fields": {"content": "unrelated"}},
+                    ]
+                }
+            },
+        )
+
+    return requests, httpx.AsyncClient(transport=httpx.MockTransport(handler))
+
+
+async def test_search(pinecone_requests):
+    requests, client = pinecone_requests
+    tool = PineconeMemoryTool(
+        name="docs",
+        index_host="docs-abc123.svc.pinecone.io",
+        top_k=3,
+        namespace="kagent",
+        record_fields=["content"],
+        score_threshold=0.75,
+        api_key_env="PINECONE_API_KEY",
+        http_client=client,
+    )
+
+    records = await tool.search("what is kagent?")
+
+    assert records == [{"id": "doc-1", "score": 0.9, "content": "kagent runs agents on Kubernetes"}]
+    assert len(requests) == 1
+    assert str(requests[0].url) == "https://docs-abc123.svc.pinecone.io/records/namespaces/kagent/search"
+    assert requests[0].headers["Api-Key"] == "pc-test"
+    assert json.loads(requests[0].content) == {
+        "query": {"inputs": {"text": "what is kagent?"}, "top_k": 3},
+        "fields": ["content"],
+    }
+
+
+async def test_search_without_api_key(monkeypatch, pinecone_requests):
+    monkeypatch.delenv("PINECONE_API_KEY")
+    requests, client = pinecone_requests
+    tool = PineconeMemoryTool(
+        name="docs", index_host="https://docs", api_key_env="PINECONE_API_KEY", http_client=client
+    )
+
+    result = await tool.run_async(args={"query": "kagent"}, tool_context=None)
+
+    assert "PINECONE_API_KEY is not set" in result["error"]
+    assert requests == []
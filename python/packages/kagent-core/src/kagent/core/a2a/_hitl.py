@@ -309,6 +309,9 @@ async def handle_tool_approval_interrupt(
 
     # Wait for the event consumer to persist the task (event-based sync)
     # This prevents race condition where approval arrives before task is saved
+    # Only the KAgentTaskStore saves tasks asynchronously, e.g. not the InMemoryTaskStore of local runs
+    if not hasattr(task_store, "wait_for_save"):
+        return
     try:
         await task_store.wait_for_save(task_id, timeout=5.0)
     except TimeoutError:
@@ -1,6 +1,6 @@
 'use server'
 
-import { FeedbackData, FeedbackIssueType } from "@/types";
+import { FeedbackData, FeedbackIssueType, FeedbackTarget } from "@/types";
 import { fetchApi, getCurrentUserId } from "./utils";
 
 /**
@@ -14,6 +14,8 @@ async function submitFeedback(feedbackData: FeedbackData): Promise<any> {
         feedback_text: feedbackData.feedbackText,
         issue_type: feedbackData.issueType,
         message_id: feedbackData.messageId,
+        task_id: feedbackData.taskId,
+        session_id: feedbackData.sessionId,
         user_id: userID
     };
     return await fetchApi('/feedback', {
@@ -26,14 +28,14 @@ async function submitFeedback(feedbackData: FeedbackData): Promise<any> {
  * Submit positive feedback for an agent response
  */
 export async function submitPositiveFeedback(
-    message_id: number,
+    target: FeedbackTarget,
     feedback_text: string,
 ) {
     // Create feedback data object
     const feedbackData: FeedbackData = {
+        ...target,
         isPositive: true,
         feedbackText: feedback_text,
-        messageId: message_id,
     };
     return await submitFeedback(feedbackData);
 }
@@ -42,16 +44,16 @@ export async function submitPositiveFeedback(
  * Submit negative feedback for an agent response
  */
 export async function submitNegativeFeedback(
-    message_id: number,
+    target: FeedbackTarget,
     feedback_text: string,
     issue_type?: string,
 ) {
     // Create feedback data object
     const feedbackData: FeedbackData = {
+        ...target,
         isPositive: false,
         feedbackText: feedback_text,
         issueType: issue_type as FeedbackIssueType,
-        messageId: message_id,
     };
 
     return await submitFeedback(feedbackData);
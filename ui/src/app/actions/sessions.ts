@@ -1,6 +1,6 @@
 "use server";
 
-import { BaseResponse, CreateSessionRequest } from "@/types";
+import { BaseResponse, CreateSessionRequest, CreateSessionShareLinkRequest, CreatedSessionShareLink, ForkSessionRequest, SessionFork, SessionShare, ShareSessionRequest } from "@/types";
 import { Session } from "@/types";
 import { revalidatePath } from "next/cache";
 import { fetchApi, createErrorResponse } from "./utils";
@@ -136,3 +136,100 @@ export async function updateSession(session: Session): Promise<BaseResponse<Sess
     return createErrorResponse<Session>(error, "Error updating session");
   }
 }
+
+/**
+ * Forks a session from one of its events
+ * @param sessionId The session ID
+ * @param request The last event copied to the fork and the name of the fork
+ * @returns A promise with the fork and the IDs of the copied events
+ */
+export async function forkSession(sessionId: string, request: ForkSessionRequest): Promise<BaseResponse<SessionFork>> {
+  try {
+    const response = await fetchApi<BaseResponse<SessionFork>>(`/sessions/${sessionId}/fork`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response) {
+      throw new Error("Failed to fork session");
+    }
+
+    revalidatePath("/");
+    return { message: "Session forked successfully", data: response.data };
+  } catch (error) {
+    return createErrorResponse<SessionFork>(error, "Error forking session");
+  }
+}
+
+/**
+ * Shares a session with a user, or changes their role in it
+ * @param sessionId The session ID
+ * @param request The user and their role
+ * @returns A promise with the share
+ */
+export async function shareSession(sessionId: string, request: ShareSessionRequest): Promise<BaseResponse<SessionShare>> {
+  try {
+    const response = await fetchApi<BaseResponse<SessionShare>>(`/sessions/${sessionId}/shares`, {
+      method: "PUT",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response) {
+      throw new Error("Failed to share session");
+    }
+
+    return { message: "Session shared successfully", data: response.data };
+  } catch (error) {
+    return createErrorResponse<SessionShare>(error, "Error sharing session");
+  }
+}
+
+/**
+ * Creates a link sharing a session with the users redeeming it
+ * @param sessionId The session ID
+ * @param request The role the link grants and its expiry
+ * @returns A promise with the link and its token, which isn't returned again
+ */
+export async function createSessionShareLink(sessionId: string, request: CreateSessionShareLinkRequest): Promise<BaseResponse<CreatedSessionShareLink>> {
+  try {
+    const response = await fetchApi<BaseResponse<CreatedSessionShareLink>>(`/sessions/${sessionId}/share-links`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response) {
+      throw new Error("Failed to create share link");
+    }
+
+    return { message: "Share link created successfully", data: response.data };
+  } catch (error) {
+    return createErrorResponse<CreatedSessionShareLink>(error, "Error creating share link");
+  }
+}
+
+/**
+ * Gets the sessions other users shared with the current user
+ * @returns A promise with the shared sessions
+ */
+export async function getSharedSessions(): Promise<BaseResponse<Session[]>> {
+  try {
+    const response = await fetchApi<BaseResponse<Session[]>>(`/sessions/shared`);
+
+    if (!response) {
+      throw new Error("Failed to get shared sessions");
+    }
+
+    return { message: "Shared sessions fetched successfully", data: response.data };
+  } catch (error) {
+    return createErrorResponse<Session[]>(error, "Error getting shared sessions");
+  }
+}
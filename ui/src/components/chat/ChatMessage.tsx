@@ -57,10 +57,6 @@ export default function ChatMessage({ message, allMessages, agentContext }: Chat
   };
 
   const displayName = getDisplayName();
-  const numericMessageId = messageId ? Math.abs(messageId.split('').reduce((a, b) => {
-    a = ((a << 5) - a) + b.charCodeAt(0);
-    return a & a;
-  }, 0)) : 0;
 
   if (!message) {
     return null;
@@ -152,7 +148,7 @@ export default function ChatMessage({ message, allMessages, agentContext }: Chat
         isOpen={feedbackDialogOpen}
         onClose={() => setFeedbackDialogOpen(false)}
         isPositive={isPositiveFeedback}
-        messageId={numericMessageId}
+        target={{ messageId, taskId: message.taskId, sessionId: message.contextId }}
       />
     )}
   </div>
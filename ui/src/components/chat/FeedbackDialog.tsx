@@ -5,15 +5,16 @@ import { Textarea } from "@/components/ui/textarea";
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
 import { submitPositiveFeedback, submitNegativeFeedback } from "@/app/actions/feedback";
 import { toast } from "sonner";
+import { FeedbackTarget } from "@/types";
 
 interface FeedbackDialogProps {
   isOpen: boolean;
   onClose: () => void;
   isPositive: boolean;
-  messageId: number;
+  target: FeedbackTarget;
 }
 
-export function FeedbackDialog({ isOpen, onClose, isPositive, messageId }: FeedbackDialogProps) {
+export function FeedbackDialog({ isOpen, onClose, isPositive, target }: FeedbackDialogProps) {
   const [feedbackText, setFeedbackText] = useState("");
   const [issueType, setIssueType] = useState<string | undefined>();
   const [isSubmitting, setIsSubmitting] = useState(false);
@@ -23,9 +24,9 @@ export function FeedbackDialog({ isOpen, onClose, isPositive, messageId }: Feedb
 
     try {
       if (isPositive) {
-        await submitPositiveFeedback(messageId, feedbackText);
+        await submitPositiveFeedback(target, feedbackText);
       } else {
-        await submitNegativeFeedback(messageId, feedbackText, issueType);
+        await submitNegativeFeedback(target, feedbackText, issueType);
       }
       toast.success("Thank you for your feedback!");
       setFeedbackText("");
@@ -9,7 +9,7 @@ import {
   AlertDialogHeader,
   AlertDialogFooter,
 } from "@/components/ui/alert-dialog";
-import { MoreHorizontal, Trash2, Download } from "lucide-react";
+import { MoreHorizontal, Trash2, Download, GitFork } from "lucide-react";
 import { SidebarMenu, SidebarMenuAction, SidebarMenuButton, SidebarMenuItem } from "@/components/ui/sidebar";
 import Link from "next/link";
 import { DropdownMenu, DropdownMenuContent, DropdownMenuItem, DropdownMenuTrigger } from "@/components/ui/dropdown-menu";
@@ -23,9 +23,10 @@ interface ChatItemProps {
   sessionName?: string;
   onDownload?: (sessionId: string) => Promise<void>;
   createdAt?: string;
+  parentSessionId?: string;
 }
 
-const ChatItem = ({ sessionId, agentName, agentNamespace, onDelete, sessionName, onDownload, createdAt }: ChatItemProps) => {
+const ChatItem = ({ sessionId, agentName, agentNamespace, onDelete, sessionName, onDownload, createdAt, parentSessionId }: ChatItemProps) => {
   const title = sessionName || "Untitled";
   
   // Format timestamp based on how recent it is
@@ -53,6 +54,12 @@ const ChatItem = ({ sessionId, agentName, agentNamespace, onDelete, sessionName,
         <SidebarMenuItem key={sessionId}>
           <SidebarMenuButton asChild className="overflow-hidden relative group/chatitem">
             <Link href={`/agents/${agentNamespace}/${agentName}/chat/${sessionId}`} className="flex items-center w-full">
+              {parentSessionId && (
+                <span title={`Forked from ${parentSessionId}`} className="mr-1 shrink-0 text-muted-foreground">
+                  <GitFork className="h-3 w-3" />
+                  <span className="sr-only">Forked from {parentSessionId}</span>
+                </span>
+              )}
               <span className="text-sm whitespace-nowrap" title={title}>{title}</span>
               <span className="absolute right-8 top-1/2 -translate-y-1/2 text-xs text-muted-foreground whitespace-nowrap pl-6"
                 style={{
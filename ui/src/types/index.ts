@@ -17,6 +17,54 @@ export interface CreateSessionRequest {
   id?: string;
 }
 
+export interface ForkSessionRequest {
+  event_id: string;
+  name?: string;
+}
+
+export interface SessionFork {
+  session: Session;
+  // Maps the IDs of the copied events to the IDs of their copies
+  event_ids: Record<string, string>;
+}
+
+// Roles granted to the users a session is shared with
+export type SessionShareRole = "viewer" | "collaborator";
+
+export interface ShareSessionRequest {
+  user_id: string;
+  role: SessionShareRole;
+}
+
+export interface CreateSessionShareLinkRequest {
+  role: SessionShareRole;
+  // Links without an expiry don't expire
+  expires_at?: string;
+}
+
+export interface SessionShare {
+  session_id: string;
+  owner_id: string;
+  user_id: string;
+  role: SessionShareRole | "owner";
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SessionShareLink {
+  id: string;
+  session_id: string;
+  owner_id: string;
+  role: SessionShareRole;
+  expires_at?: string;
+  created_at: string;
+}
+
+export interface CreatedSessionShareLink extends SessionShareLink {
+  // Only returned when the link is created
+  token: string;
+}
+
 export interface BaseResponse<T> {
   message: string;
   data?: T;
@@ -149,7 +197,20 @@ export enum FeedbackIssueType {
 /**
 * Feedback data structure that will be sent to the API
 */
-export interface FeedbackData {
+// The response feedback is given on. The server links feedback on a message of
+// a task to its session, the session event and the agent.
+export interface FeedbackTarget {
+  // ID of the A2A message this feedback pertains to
+  messageId: string;
+
+  // ID of the task of the message
+  taskId?: string;
+
+  // ID of the session (A2A context) of the message
+  sessionId?: string;
+}
+
+export interface FeedbackData extends FeedbackTarget {
   // Whether the feedback is positive
   isPositive: boolean;
 
@@ -158,9 +219,6 @@ export interface FeedbackData {
 
   // The type of issue for negative feedback
   issueType?: FeedbackIssueType;
-
-  // ID of the message this feedback pertains to
-  messageId: number;
 }
 
 export interface FunctionCall {
@@ -177,6 +235,9 @@ export interface Session {
   created_at: string;
   updated_at: string;
   deleted_at: string;
+  // Set on sessions forked from an event of another session
+  parent_session_id?: string;
+  parent_event_id?: string;
 }
 
 export interface ToolsResponse {